@@ -6,17 +6,30 @@
 package app
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/diagnose"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+
+	// register the intake connectivity and configuration diagnoses
+	_ "github.com/DataDog/datadog-agent/pkg/diagnose/connectivity"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
+var (
+	diagnoseJSON       bool
+	diagnoseCategories []string
+)
+
 func init() {
+	diagnoseCommand.Flags().BoolVarP(&diagnoseJSON, "json", "j", false, "print out the results as json")
+	diagnoseCommand.Flags().StringSliceVarP(&diagnoseCategories, "category", "c", nil, "only run the diagnoses of these categories (ex: cloud-provider,container-runtime)")
 	AgentCmd.AddCommand(diagnoseCommand)
 }
 
@@ -44,12 +57,35 @@ func doDiagnose(cmd *cobra.Command, args []string) error {
 		common.DefaultLogFile,
 		config.GetSyslogURI(),
 		config.Datadog.GetBool("syslog_rfc"),
-		config.Datadog.GetBool("log_to_console"),
+		// the json output must not be mixed with logs
+		config.Datadog.GetBool("log_to_console") && !diagnoseJSON,
 		config.Datadog.GetBool("log_format_json"),
 	)
 	if err != nil {
 		return fmt.Errorf("Error while setting up logging, exiting: %v", err)
 	}
 
-	return diagnose.RunAll(color.Output)
+	categories := make([]diagnosis.Category, 0, len(diagnoseCategories))
+	for _, category := range diagnoseCategories {
+		categories = append(categories, diagnosis.Category(category))
+	}
+
+	if !diagnoseJSON {
+		_, err = diagnose.Run(color.Output, categories)
+		return err
+	}
+
+	results, err := diagnose.Run(ioutil.Discard, categories)
+	if err != nil {
+		return err
+	}
+	if results == nil {
+		results = []diagnosis.Result{}
+	}
+	out, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
 }
@@ -0,0 +1,240 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+)
+
+var (
+	retryQueueJSON          bool
+	retryQueueAll           bool
+	retryQueueResendTimeout time.Duration
+
+	forwarderCmd = &cobra.Command{
+		Use:   "forwarder",
+		Short: "Inspect the forwarder of the Agent",
+		Long:  ``,
+	}
+
+	retryQueueCmd = &cobra.Command{
+		Use:   "retry-queue",
+		Short: "Inspect the transactions stored on disk by the retry queue of the forwarder",
+		Long: `Inspect the transactions stored on disk by the retry queue of the forwarder.
+
+The transactions are identified by <file name>:<index>, a file name selects all
+the transactions of the file. The purge and resend commands update the files
+read by the running Agent, they are best run while the Agent is stopped.`,
+	}
+
+	retryQueueListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the transactions stored on disk",
+		Long:  ``,
+		Args:  cobra.NoArgs,
+		RunE:  doRetryQueueList,
+	}
+
+	retryQueueShowCmd = &cobra.Command{
+		Use:   "show <id>",
+		Short: "Print a transaction stored on disk with its decoded payload",
+		Long:  ``,
+		Args:  cobra.ExactArgs(1),
+		RunE:  doRetryQueueShow,
+	}
+
+	retryQueuePurgeCmd = &cobra.Command{
+		Use:   "purge [<id>...]",
+		Short: "Remove transactions from the disk",
+		Long:  ``,
+		RunE:  doRetryQueuePurge,
+	}
+
+	retryQueueResendCmd = &cobra.Command{
+		Use:   "resend [<id>...]",
+		Short: "Send transactions again and remove from the disk the ones handled by the intake",
+		Long:  ``,
+		RunE:  doRetryQueueResend,
+	}
+)
+
+func init() {
+	retryQueueListCmd.Flags().BoolVarP(&retryQueueJSON, "json", "j", false, "print out raw json")
+	retryQueuePurgeCmd.Flags().BoolVarP(&retryQueueAll, "all", "", false, "remove all the transactions")
+	retryQueueResendCmd.Flags().BoolVarP(&retryQueueAll, "all", "", false, "send all the transactions")
+	retryQueueResendCmd.Flags().DurationVarP(&retryQueueResendTimeout, "timeout", "", 20*time.Second, "timeout of a single request")
+
+	retryQueueCmd.AddCommand(retryQueueListCmd, retryQueueShowCmd, retryQueuePurgeCmd, retryQueueResendCmd)
+	forwarderCmd.AddCommand(retryQueueCmd)
+	AgentCmd.AddCommand(forwarderCmd)
+}
+
+func newRetryQueueInspector() (*forwarder.RetryQueueInspector, error) {
+	if flagNoColor {
+		color.NoColor = true
+	}
+
+	err := common.SetupConfig(confFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+
+	err = config.SetupLogger(loggerName, config.GetEnvDefault("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+	if err != nil {
+		fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+		return nil, err
+	}
+
+	keysPerDomain, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return nil, fmt.Errorf("misconfiguration of agent endpoints: %s", err)
+	}
+	return forwarder.NewRetryQueueInspector(keysPerDomain)
+}
+
+// listRetryTransactions lists the stored transactions, the files which cannot
+// be read are reported without failing the command
+func listRetryTransactions(inspector *forwarder.RetryQueueInspector) []forwarder.RetryTransaction {
+	transactions, err := inspector.List()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, color.YellowString("Some transactions cannot be read: %v", err))
+	}
+	return transactions
+}
+
+func selectRetryTransactions(inspector *forwarder.RetryQueueInspector, args []string) ([]forwarder.RetryTransaction, error) {
+	if retryQueueAll == (len(args) > 0) {
+		return nil, fmt.Errorf("either transaction IDs or --all must be given")
+	}
+	transactions := listRetryTransactions(inspector)
+	if retryQueueAll {
+		return transactions, nil
+	}
+	return forwarder.SelectRetryTransactions(transactions, args)
+}
+
+func doRetryQueueList(cmd *cobra.Command, args []string) error {
+	inspector, err := newRetryQueueInspector()
+	if err != nil {
+		return err
+	}
+	transactions := listRetryTransactions(inspector)
+
+	if retryQueueJSON {
+		if transactions == nil {
+			transactions = []forwarder.RetryTransaction{}
+		}
+		return printJSON(transactions)
+	}
+
+	if len(transactions) == 0 {
+		fmt.Printf("No transaction stored in %s\n", inspector.StoragePath())
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDOMAIN\tENDPOINT\tAGE\tPRIORITY\tERRORS\tSIZE")
+	now := time.Now()
+	for _, tr := range transactions {
+		domain := tr.Domain
+		if domain == "" {
+			domain = "unknown"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\n",
+			tr.ID, domain, tr.Endpoint, now.Sub(tr.CreatedAt).Truncate(time.Second), tr.Priority, tr.ErrorCount, tr.PayloadSize)
+	}
+	return w.Flush()
+}
+
+func doRetryQueueShow(cmd *cobra.Command, args []string) error {
+	inspector, err := newRetryQueueInspector()
+	if err != nil {
+		return err
+	}
+	transactions, err := forwarder.SelectRetryTransactions(listRetryTransactions(inspector), args)
+	if err != nil {
+		return err
+	}
+	if len(transactions) != 1 {
+		return fmt.Errorf("%q selects %d transactions, use a transaction ID", args[0], len(transactions))
+	}
+
+	decoded, err := inspector.Decode(transactions[0])
+	if err != nil {
+		return err
+	}
+	return printJSON(decoded)
+}
+
+func doRetryQueuePurge(cmd *cobra.Command, args []string) error {
+	inspector, err := newRetryQueueInspector()
+	if err != nil {
+		return err
+	}
+	transactions, err := selectRetryTransactions(inspector, args)
+	if err != nil {
+		return err
+	}
+
+	if err := inspector.Purge(transactions); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d transaction(s)\n", len(transactions))
+	return nil
+}
+
+func doRetryQueueResend(cmd *cobra.Command, args []string) error {
+	inspector, err := newRetryQueueInspector()
+	if err != nil {
+		return err
+	}
+	transactions, err := selectRetryTransactions(inspector, args)
+	if err != nil {
+		return err
+	}
+
+	results, err := inspector.Resend(transactions, retryQueueResendTimeout)
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil && result.Removed:
+			failed++
+			fmt.Printf("%s %s: %v\n", color.YellowString("REJECTED"), result.Transaction.ID, result.Err)
+		case result.Err != nil:
+			failed++
+			fmt.Printf("%s %s: %v\n", color.RedString("FAILED"), result.Transaction.ID, result.Err)
+		default:
+			fmt.Printf("%s %s (status code %d)\n", color.GreenString("SENT"), result.Transaction.ID, result.StatusCode)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot remove the handled transactions from the disk: %v", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d transaction(s) could not be sent", failed, len(results))
+	}
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(bytes))
+	return nil
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
 	"github.com/DataDog/datadog-agent/pkg/otlp"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
+	"github.com/DataDog/datadog-agent/pkg/serializer/openmetrics"
 	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
@@ -72,6 +73,7 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/disk"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/filehandles"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/memory"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/processgroup"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/uptime"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/winkmem"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/winproc"
@@ -373,6 +375,16 @@ func StartAgent() error {
 	demux = aggregator.InitAndStartAgentDemultiplexer(opts, hostname)
 	demux.AddAgentStartupTelemetry(version.AgentVersion)
 
+	// start the local OpenMetrics endpoint
+	if snapshot := demux.OpenMetricsSnapshot(); snapshot != nil {
+		port := config.Datadog.GetInt("openmetrics_endpoint.port")
+		if err := openmetrics.Serve(common.MainCtx, port, snapshot); err != nil {
+			log.Errorf("Could not start the OpenMetrics endpoint on port %d: %v", port, err)
+		} else {
+			log.Infof("OpenMetrics endpoint listening on 127.0.0.1:%d", port)
+		}
+	}
+
 	// start dogstatsd
 	if config.Datadog.GetBool("use_dogstatsd") {
 		var err error
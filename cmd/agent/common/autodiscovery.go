@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	confad "github.com/DataDog/datadog-agent/pkg/config/autodiscovery"
+	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -182,9 +183,36 @@ func setupAutoDiscovery(confSearchPaths []string, metaScheduler *scheduler.MetaS
 	return ad
 }
 
-// StartAutoConfig starts auto discovery
+// StartAutoConfig starts auto discovery, and the refresh of the secrets
+// reloading the configurations referencing the rotated ones
 func StartAutoConfig() {
 	AC.LoadAndRun()
+	secrets.StartRefresh(reloadRotatedSecrets)
+}
+
+// reloadRotatedSecrets reloads the check configurations referencing rotated
+// secrets, the main configuration requires a restart
+func reloadRotatedSecrets(rotated map[string][]string) {
+	reload := map[string]bool{}
+	for handle, origins := range rotated {
+		for _, origin := range origins {
+			if origin == "datadog.yaml" {
+				log.Warnf("Secret '%s' of datadog.yaml was rotated, restart the Agent to use its new value", handle)
+				continue
+			}
+			reload[origin] = true
+		}
+	}
+	if len(reload) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(reload))
+	for name := range reload {
+		names = append(names, name)
+	}
+	log.Infof("Reloading the configurations referencing rotated secrets: %v", names)
+	AC.ReloadConfigs(names)
 }
 
 // WaitForConfigs retries the collection of Autodiscovery configs until the checkMatcher function (which
@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/process/checks"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// discoveredServicesHandler returns the languages and services inferred for the
+// processes found by the last run of the process discovery check
+func discoveredServicesHandler(w http.ResponseWriter, _ *http.Request) {
+	services := checks.ProcessDiscovery.Services()
+	if services == nil {
+		services = []*checks.DiscoveredService{}
+	}
+
+	b, err := json.Marshal(services)
+	if err != nil {
+		_ = log.Warn("failed to serialize the discovered services:", err)
+		writeError(err, http.StatusInternalServerError, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(b); err != nil {
+		_ = log.Warn("failed to write the discovered services:", err)
+	}
+}
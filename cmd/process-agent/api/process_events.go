@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/process/checks"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// processEventsHandler returns the process lifecycle events collected after
+// the cursor of the request
+func processEventsHandler(w http.ResponseWriter, r *http.Request) {
+	var cursor uint64
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		var err error
+		if cursor, err = strconv.ParseUint(c, 10, 64); err != nil {
+			writeError(fmt.Errorf("invalid cursor %q", c), http.StatusBadRequest, w)
+			return
+		}
+	}
+
+	payload, err := checks.Process.ProcessEvents(cursor)
+	if err == checks.ErrProcessEventsDisabled {
+		writeError(err, http.StatusNotFound, w)
+		return
+	}
+	if err != nil {
+		writeError(err, http.StatusInternalServerError, w)
+		return
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		_ = log.Warn("failed to serialize process events:", err)
+		writeError(err, http.StatusInternalServerError, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(b); err != nil {
+		_ = log.Warn("failed to write the process events:", err)
+	}
+}
@@ -25,6 +25,8 @@ func setupHandlers(r *mux.Router) {
 	r.HandleFunc("/config/{setting}", settingshttp.Server.GetValue).Methods("GET")
 	r.HandleFunc("/config/{setting}", settingshttp.Server.SetValue).Methods("POST")
 	r.HandleFunc("/agent/status", statusHandler).Methods("GET")
+	r.HandleFunc("/process-events", processEventsHandler).Methods("GET")
+	r.HandleFunc("/discovery/services", discoveredServicesHandler).Methods("GET")
 }
 
 // StartServer starts the config server
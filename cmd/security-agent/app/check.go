@@ -10,6 +10,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/compliance/agent"
 	"github.com/DataDog/datadog-agent/pkg/compliance/checks"
 	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/export"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
@@ -25,6 +27,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/flavor"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
 	"github.com/cihub/seelog"
 	"github.com/spf13/cobra"
 )
@@ -38,6 +41,9 @@ var (
 		overrideRegoInput string
 		dumpRegoInput     string
 		dumpReports       string
+		reportFormat      string
+		rootFilesystem    string
+		image             string
 	}{}
 )
 
@@ -49,6 +55,9 @@ func setupCheckCmd(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&checkArgs.overrideRegoInput, "override-rego-input", "", "", "Rego input to use when running rego checks")
 	cmd.Flags().StringVarP(&checkArgs.dumpRegoInput, "dump-rego-input", "", "", "Path to file where to dump the Rego input JSON")
 	cmd.Flags().StringVarP(&checkArgs.dumpReports, "dump-reports", "", "", "Path to file where to dump reports")
+	cmd.Flags().StringVarP(&checkArgs.rootFilesystem, "rootfs", "", "", "Path to a root filesystem to run the checks against, instead of the host")
+	cmd.Flags().StringVarP(&checkArgs.image, "image", "", "", "Path to an OCI or docker save image tarball to run the checks against, instead of the host")
+	cmd.Flags().StringVarP(&checkArgs.reportFormat, "report-format", "", string(export.FormatJSON), fmt.Sprintf("Format of the dumped reports, one of %v", export.Formats))
 }
 
 // CheckCmd returns a cobra command to run security agent checks
@@ -71,6 +80,17 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		return err
 	}
 
+	reportFormat, err := export.ParseFormat(checkArgs.reportFormat)
+	if err != nil {
+		return err
+	}
+	if reportFormat != export.FormatJSON && checkArgs.dumpReports == "" {
+		return fmt.Errorf("--report-format %s requires --dump-reports", reportFormat)
+	}
+	if checkArgs.rootFilesystem != "" && checkArgs.image != "" {
+		return errors.New("--rootfs and --image are mutually exclusive")
+	}
+
 	// We need to set before calling `SetupConfig`
 	configName := "datadog"
 	if flavor.GetFlavor() == flavor.ClusterAgent {
@@ -84,7 +104,11 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 
 	options := []checks.BuilderOption{}
 
-	if flavor.GetFlavor() == flavor.ClusterAgent {
+	if checkArgs.rootFilesystem != "" {
+		options = append(options, checks.WithRootFilesystem(checkArgs.rootFilesystem))
+	} else if checkArgs.image != "" {
+		options = append(options, checks.WithImageTarball(checkArgs.image))
+	} else if flavor.GetFlavor() == flavor.ClusterAgent {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
@@ -99,6 +123,7 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 			checks.WithHostRootMount(os.Getenv("HOST_ROOT")),
 			checks.MayFail(checks.WithDocker()),
 			checks.MayFail(checks.WithAudit()),
+			checks.MayFail(checks.WithSystemd()),
 		}...)
 
 		if config.IsKubernetes() {
@@ -130,6 +155,12 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 	if err != nil {
 		return err
 	}
+	reporter.reportFormat = reportFormat
+	reporter.metadata = export.Metadata{
+		Hostname:     hostname,
+		AgentVersion: version.AgentVersion,
+		StartTime:    time.Now(),
+	}
 
 	if ruleID != "" {
 		log.Infof("Looking for rule with ID=%s", ruleID)
@@ -162,6 +193,8 @@ func runCheck(cmd *cobra.Command, confPathArray []string, args []string) error {
 		return err
 	}
 
+	reporter.metadata.EndTime = time.Now()
+
 	if err := reporter.dumpReports(); err != nil {
 		log.Errorf("Failed to dump reports %v", err)
 		return err
@@ -193,6 +226,8 @@ type RunCheckReporter struct {
 	reporter        event.Reporter
 	events          map[string][]*event.Event
 	dumpReportsPath string
+	reportFormat    export.Format
+	metadata        export.Metadata
 }
 
 func NewCheckReporter(stopper restart.Stopper, report bool, dumpReportsPath string) (*RunCheckReporter, error) {
@@ -215,6 +250,7 @@ func NewCheckReporter(stopper restart.Stopper, report bool, dumpReportsPath stri
 
 	r.events = make(map[string][]*event.Event)
 	r.dumpReportsPath = dumpReportsPath
+	r.reportFormat = export.FormatJSON
 
 	return r, nil
 }
@@ -240,15 +276,25 @@ func (r *RunCheckReporter) ReportRaw(content []byte, service string, tags ...str
 }
 
 func (r *RunCheckReporter) dumpReports() error {
-	if r.dumpReportsPath != "" {
-		reportsJSON, err := checks.PrettyPrintJSON(r.events, "\t")
-		if err != nil {
-			return err
-		}
+	if r.dumpReportsPath == "" {
+		return nil
+	}
 
-		return os.WriteFile(r.dumpReportsPath, reportsJSON, 0644)
+	var events []*event.Event
+	for _, ruleEvents := range r.events {
+		events = append(events, ruleEvents...)
 	}
-	return nil
+
+	f, err := os.OpenFile(r.dumpReportsPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err := export.WriteReport(f, r.reportFormat, events, r.metadata); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 func init() {
@@ -134,6 +134,7 @@ func startCompliance(hostname string, stopper restart.Stopper, statsdClient *ddg
 		checks.WithHostRootMount(os.Getenv("HOST_ROOT")),
 		checks.MayFail(checks.WithDocker()),
 		checks.MayFail(checks.WithAudit()),
+		checks.MayFail(checks.WithSystemd()),
 	}
 
 	if coreconfig.IsKubernetes() {
@@ -0,0 +1,349 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/spf13/cobra"
+
+	"github.com/DataDog/datadog-agent/pkg/network/encoding"
+)
+
+const connectionsURL = "http://unix/connections"
+
+func init() {
+	connectionsCommand.Flags().Int32VarP(&connectionsArgs.pid, "pid", "p", 0, "only show the connections of this PID")
+	connectionsCommand.Flags().StringVarP(&connectionsArgs.container, "container", "", "", "only show the connections of the processes running in this container ID, or ID prefix")
+	connectionsCommand.Flags().Int32VarP(&connectionsArgs.port, "port", "", 0, "only show the connections with this local or remote port")
+	connectionsCommand.Flags().StringVarP(&connectionsArgs.dns, "dns", "", "", "only show the connections to a remote address resolved from a DNS name containing this string")
+	connectionsCommand.Flags().StringVarP(&connectionsArgs.sortBy, "sort", "s", "bytes", fmt.Sprintf("sort the connections by %s", strings.Join(connectionSortKeys(), ", ")))
+	connectionsCommand.Flags().BoolVarP(&connectionsArgs.reverse, "reverse", "r", false, "reverse the sort order")
+	connectionsCommand.Flags().IntVarP(&connectionsArgs.limit, "limit", "l", 0, "maximum number of connections to show, 0 shows all of them")
+	connectionsCommand.Flags().BoolVarP(&connectionsArgs.watch, "watch", "w", false, "query the connections periodically, the counters are then relative to the previous query")
+	connectionsCommand.Flags().DurationVarP(&connectionsArgs.interval, "interval", "i", 5*time.Second, "interval between two queries in watch mode")
+	connectionsCommand.Flags().BoolVarP(&connectionsArgs.json, "json", "j", false, "print out the connections as json, one document per query")
+
+	SysprobeCmd.AddCommand(connectionsCommand)
+}
+
+var (
+	connectionsArgs = struct {
+		pid       int32
+		container string
+		port      int32
+		dns       string
+		sortBy    string
+		reverse   bool
+		limit     int
+		watch     bool
+		interval  time.Duration
+		json      bool
+	}{}
+
+	connectionsCommand = &cobra.Command{
+		Use:   "connections",
+		Short: "Print the connections tracked by the network tracer of a running system-probe",
+		Long: `Print the connections tracked by the network tracer of a running system-probe.
+
+The byte, retransmit and TCP state counters are relative to the previous query
+of this command, or to the creation of the connection for the first one. The
+RTT is in microseconds.`,
+		Args: cobra.NoArgs,
+		RunE: printConnections,
+	}
+)
+
+// connectionRow is a connection as printed by the connections command
+type connectionRow struct {
+	PID           int32    `json:"pid"`
+	Type          string   `json:"type"`
+	Direction     string   `json:"direction"`
+	Local         string   `json:"local"`
+	Remote        string   `json:"remote"`
+	DNS           []string `json:"dns,omitempty"`
+	Container     string   `json:"container_id,omitempty"`
+	BytesSent     uint64   `json:"bytes_sent"`
+	BytesReceived uint64   `json:"bytes_received"`
+	Retransmits   uint32   `json:"retransmits"`
+	RTT           uint32   `json:"rtt"`
+	RTTVar        uint32   `json:"rtt_var"`
+	NetNS         uint32   `json:"netns"`
+	IntraHost     bool     `json:"intra_host"`
+}
+
+// connectionFilter selects the connections printed by the connections
+// command, the zero value selects all of them
+type connectionFilter struct {
+	pid       int32
+	container string
+	port      int32
+	dns       string
+}
+
+// connectionSorters are the sort keys of the connections command. The
+// counters are sorted in descending order and the other keys in ascending
+// order.
+var connectionSorters = map[string]func(a, b *connectionRow) bool{
+	"pid":         func(a, b *connectionRow) bool { return a.PID < b.PID },
+	"local":       func(a, b *connectionRow) bool { return a.Local < b.Local },
+	"remote":      func(a, b *connectionRow) bool { return a.Remote < b.Remote },
+	"dns":         func(a, b *connectionRow) bool { return strings.Join(a.DNS, ",") < strings.Join(b.DNS, ",") },
+	"bytes":       func(a, b *connectionRow) bool { return a.BytesSent+a.BytesReceived > b.BytesSent+b.BytesReceived },
+	"sent":        func(a, b *connectionRow) bool { return a.BytesSent > b.BytesSent },
+	"received":    func(a, b *connectionRow) bool { return a.BytesReceived > b.BytesReceived },
+	"rtt":         func(a, b *connectionRow) bool { return a.RTT > b.RTT },
+	"retransmits": func(a, b *connectionRow) bool { return a.Retransmits > b.Retransmits },
+}
+
+func connectionSortKeys() []string {
+	keys := make([]string, 0, len(connectionSorters))
+	for key := range connectionSorters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func printConnections(_ *cobra.Command, _ []string) error {
+	less, ok := connectionSorters[connectionsArgs.sortBy]
+	if !ok {
+		return fmt.Errorf("unknown sort key '%s', must be one of %s", connectionsArgs.sortBy, strings.Join(connectionSortKeys(), ", "))
+	}
+	if connectionsArgs.watch && connectionsArgs.interval <= 0 {
+		return fmt.Errorf("the watch interval must be positive")
+	}
+	filter := connectionFilter{
+		pid:       connectionsArgs.pid,
+		container: connectionsArgs.container,
+		port:      connectionsArgs.port,
+		dns:       connectionsArgs.dns,
+	}
+
+	c, err := getSystemProbeClient()
+	if err != nil {
+		return err
+	}
+	// the network tracer computes the counters relative to the previous
+	// query of the same client
+	clientID := fmt.Sprintf("system-probe-cli-%d", os.Getpid())
+
+	query := func() error {
+		conns, err := getConnections(c, clientID)
+		if err != nil {
+			return err
+		}
+		rows := buildConnectionRows(conns, filter, containerIDForPID)
+		sortConnectionRows(rows, less, connectionsArgs.reverse)
+		if connectionsArgs.limit > 0 && len(rows) > connectionsArgs.limit {
+			rows = rows[:connectionsArgs.limit]
+		}
+
+		if connectionsArgs.json {
+			return json.NewEncoder(os.Stdout).Encode(rows)
+		}
+		if connectionsArgs.watch {
+			// clear the terminal to refresh the table in place
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("Every %s: %d connections, %s\n\n", connectionsArgs.interval, len(rows), time.Now().Format(time.RFC3339))
+		}
+		return writeConnectionsTable(os.Stdout, rows)
+	}
+
+	if err := query(); err != nil || !connectionsArgs.watch {
+		return err
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(connectionsArgs.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sig:
+			return nil
+		case <-ticker.C:
+			if err := query(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// getConnections queries the connections of the network tracer module
+func getConnections(c *http.Client, clientID string) (*model.Connections, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s?client_id=%s", connectionsURL, clientID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", encoding.ContentTypeProtobuf)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Could not reach %s: %v \nMake sure the %s is running with the network_config enabled and contact support if you continue having issues", targetProcessName, err, targetProcessName)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("connections request failed: status code %d, make sure the network_config of the %s is enabled", resp.StatusCode, targetProcessName)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return encoding.GetUnmarshaler(resp.Header.Get("Content-type")).Unmarshal(body)
+}
+
+// buildConnectionRows returns the connections selected by filter. The
+// container of each connection is the container of its process, resolved with
+// containerIDForPID.
+func buildConnectionRows(conns *model.Connections, filter connectionFilter, containerIDForPID func(int32) string) []*connectionRow {
+	containers := map[int32]string{}
+	rows := make([]*connectionRow, 0, len(conns.Conns))
+	for _, conn := range conns.Conns {
+		row := newConnectionRow(conn, conns.Dns)
+		containerID, ok := containers[conn.Pid]
+		if !ok {
+			containerID = containerIDForPID(conn.Pid)
+			containers[conn.Pid] = containerID
+		}
+		row.Container = containerID
+		if filter.match(conn, row) {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func newConnectionRow(conn *model.Connection, dns map[string]*model.DNSEntry) *connectionRow {
+	row := &connectionRow{
+		PID:           conn.Pid,
+		Type:          fmt.Sprintf("%s%s", conn.Type, strings.TrimPrefix(conn.Family.String(), "v")),
+		Direction:     conn.Direction.String(),
+		BytesSent:     conn.LastBytesSent,
+		BytesReceived: conn.LastBytesReceived,
+		Retransmits:   conn.LastRetransmits,
+		RTT:           conn.Rtt,
+		RTTVar:        conn.RttVar,
+		NetNS:         conn.NetNS,
+		IntraHost:     conn.IntraHost,
+	}
+	if conn.Laddr != nil {
+		row.Local = formatAddr(conn.Laddr)
+	}
+	if conn.Raddr != nil {
+		row.Remote = formatAddr(conn.Raddr)
+		if entry, ok := dns[conn.Raddr.Ip]; ok && entry != nil {
+			row.DNS = entry.Names
+		}
+	}
+	return row
+}
+
+func formatAddr(addr *model.Addr) string {
+	return net.JoinHostPort(addr.Ip, strconv.Itoa(int(addr.Port)))
+}
+
+func (f connectionFilter) match(conn *model.Connection, row *connectionRow) bool {
+	if f.pid != 0 && conn.Pid != f.pid {
+		return false
+	}
+	if f.container != "" && (row.Container == "" || !strings.HasPrefix(row.Container, f.container)) {
+		return false
+	}
+	if f.port != 0 && !addrHasPort(conn.Laddr, f.port) && !addrHasPort(conn.Raddr, f.port) {
+		return false
+	}
+	if f.dns != "" {
+		found := false
+		for _, name := range row.DNS {
+			if strings.Contains(strings.ToLower(name), strings.ToLower(f.dns)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func addrHasPort(addr *model.Addr, port int32) bool {
+	return addr != nil && addr.Port == port
+}
+
+// sortConnectionRows sorts the rows with less, the remaining ties being
+// broken by PID and addresses to keep a stable order in watch mode
+func sortConnectionRows(rows []*connectionRow, less func(a, b *connectionRow) bool, reverse bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if reverse {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		if a.PID != b.PID {
+			return a.PID < b.PID
+		}
+		if a.Local != b.Local {
+			return a.Local < b.Local
+		}
+		return a.Remote < b.Remote
+	})
+}
+
+func writeConnectionsTable(out io.Writer, rows []*connectionRow) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PID\tTYPE\tDIRECTION\tLOCAL\tREMOTE\tDNS\tCONTAINER\tSENT\tRECEIVED\tRETRANSMITS\tRTT\tRTT VAR")
+	for _, row := range rows {
+		container := row.Container
+		if len(container) > 12 {
+			container = container[:12]
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\n",
+			row.PID,
+			row.Type,
+			row.Direction,
+			row.Local,
+			row.Remote,
+			orDash(strings.Join(row.DNS, ",")),
+			orDash(container),
+			row.BytesSent,
+			row.BytesReceived,
+			row.Retransmits,
+			row.RTT,
+			row.RTTVar,
+		)
+	}
+	return w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package app
+
+import (
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+)
+
+// cgroupV1BaseController is the controller used to resolve the container IDs
+// with cgroup v1, the unified hierarchy is used with cgroup v2
+const cgroupV1BaseController = "memory"
+
+// containerIDForPID returns the ID of the container a process is running in,
+// read from its cgroups, or an empty string if it isn't running in a
+// container or has exited. The network tracer doesn't resolve the containers
+// of the connections, it's done by the process-agent.
+func containerIDForPID(pid int32) string {
+	procPath := util.GetProcRoot()
+	strPid := strconv.Itoa(int(pid))
+	for _, controller := range []string{cgroupV1BaseController, ""} {
+		containerID, _, err := cgroups.ContainerIDFromCgroupReferences(procPath, strPid, controller)
+		if err != nil {
+			return ""
+		}
+		if containerID != "" {
+			return containerID
+		}
+	}
+	return ""
+}
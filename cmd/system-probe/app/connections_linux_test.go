@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerIDForPID(t *testing.T) {
+	containerID := "3726184226f5d3147c25fdeab5b60097e378e8a720503a5e19ecfdf29f869860"
+	procPath := t.TempDir()
+	writeCgroup := func(pid string, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(procPath, pid), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(procPath, pid, "cgroup"), []byte(content), 0644))
+	}
+	// cgroup v1
+	writeCgroup("10", "11:memory:/docker/"+containerID+"\n10:cpu:/docker/"+containerID+"\n")
+	// cgroup v2
+	writeCgroup("20", "0::/system.slice/docker-"+containerID+".scope\n")
+	// host process
+	writeCgroup("30", "11:memory:/user.slice\n0::/user.slice/user-1000.slice\n")
+	t.Setenv("HOST_PROC", procPath)
+
+	assert.Equal(t, containerID, containerIDForPID(10))
+	assert.Equal(t, containerID, containerIDForPID(20))
+	assert.Empty(t, containerIDForPID(30))
+	// exited process
+	assert.Empty(t, containerIDForPID(40))
+
+	conns := testConnections()
+	conns.Conns[0].Pid = 10
+	rows := buildConnectionRows(conns, connectionFilter{container: containerID[:12]}, containerIDForPID)
+	require.Len(t, rows, 1)
+	assert.Equal(t, containerID, rows[0].Container)
+}
@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConnections() *model.Connections {
+	return &model.Connections{
+		Conns: []*model.Connection{
+			{
+				Pid:               1,
+				Laddr:             &model.Addr{Ip: "10.0.0.1", Port: 40000},
+				Raddr:             &model.Addr{Ip: "10.0.0.2", Port: 443},
+				Type:              model.ConnectionType_tcp,
+				Family:            model.ConnectionFamily_v4,
+				Direction:         model.ConnectionDirection_outgoing,
+				LastBytesSent:     100,
+				LastBytesReceived: 1000,
+				Rtt:               300,
+			},
+			{
+				Pid:             2,
+				Laddr:           &model.Addr{Ip: "10.0.0.1", Port: 53000},
+				Raddr:           &model.Addr{Ip: "10.0.0.3", Port: 53},
+				Type:            model.ConnectionType_udp,
+				Family:          model.ConnectionFamily_v4,
+				Direction:       model.ConnectionDirection_outgoing,
+				LastBytesSent:   50,
+				LastRetransmits: 0,
+			},
+			{
+				Pid:               1,
+				Laddr:             &model.Addr{Ip: "::1", Port: 8080},
+				Raddr:             &model.Addr{Ip: "::1", Port: 41000},
+				Type:              model.ConnectionType_tcp,
+				Family:            model.ConnectionFamily_v6,
+				Direction:         model.ConnectionDirection_incoming,
+				LastBytesSent:     5000,
+				LastBytesReceived: 10,
+				LastRetransmits:   3,
+				Rtt:               20,
+			},
+		},
+		Dns: map[string]*model.DNSEntry{
+			"10.0.0.2": {Names: []string{"api.Example.com"}},
+		},
+	}
+}
+
+// testContainerIDForPID runs the PID 1 in a container
+func testContainerIDForPID(pid int32) string {
+	if pid == 1 {
+		return "0123456789abcdef"
+	}
+	return ""
+}
+
+func TestBuildConnectionRows(t *testing.T) {
+	rows := buildConnectionRows(testConnections(), connectionFilter{}, testContainerIDForPID)
+	require.Len(t, rows, 3)
+	assert.Equal(t, &connectionRow{
+		PID:           1,
+		Type:          "tcp4",
+		Direction:     "outgoing",
+		Local:         "10.0.0.1:40000",
+		Remote:        "10.0.0.2:443",
+		DNS:           []string{"api.Example.com"},
+		Container:     "0123456789abcdef",
+		BytesSent:     100,
+		BytesReceived: 1000,
+		RTT:           300,
+	}, rows[0])
+	assert.Equal(t, "udp4", rows[1].Type)
+	assert.Empty(t, rows[1].Container)
+	assert.Equal(t, "[::1]:8080", rows[2].Local)
+	assert.Equal(t, "tcp6", rows[2].Type)
+}
+
+func TestConnectionFilter(t *testing.T) {
+	pids := func(rows []*connectionRow) []int32 {
+		var res []int32
+		for _, row := range rows {
+			res = append(res, row.PID)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name     string
+		filter   connectionFilter
+		expected []int32
+	}{
+		{name: "pid", filter: connectionFilter{pid: 1}, expected: []int32{1, 1}},
+		{name: "container prefix", filter: connectionFilter{container: "0123"}, expected: []int32{1, 1}},
+		{name: "unknown container", filter: connectionFilter{container: "fedc"}, expected: nil},
+		{name: "remote port", filter: connectionFilter{port: 53}, expected: []int32{2}},
+		{name: "local port", filter: connectionFilter{port: 8080}, expected: []int32{1}},
+		{name: "dns", filter: connectionFilter{dns: "example"}, expected: []int32{1}},
+		{name: "combined", filter: connectionFilter{pid: 2, dns: "example"}, expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, pids(buildConnectionRows(testConnections(), tt.filter, testContainerIDForPID)))
+		})
+	}
+}
+
+func TestSortConnectionRows(t *testing.T) {
+	remotes := func(rows []*connectionRow) []string {
+		var res []string
+		for _, row := range rows {
+			res = append(res, row.Remote)
+		}
+		return res
+	}
+
+	rows := buildConnectionRows(testConnections(), connectionFilter{}, testContainerIDForPID)
+	sortConnectionRows(rows, connectionSorters["bytes"], false)
+	assert.Equal(t, []string{"[::1]:41000", "10.0.0.2:443", "10.0.0.3:53"}, remotes(rows))
+
+	sortConnectionRows(rows, connectionSorters["bytes"], true)
+	assert.Equal(t, []string{"10.0.0.3:53", "10.0.0.2:443", "[::1]:41000"}, remotes(rows))
+
+	// ties are broken by PID then addresses
+	sortConnectionRows(rows, connectionSorters["retransmits"], false)
+	assert.Equal(t, []string{"[::1]:41000", "10.0.0.2:443", "10.0.0.3:53"}, remotes(rows))
+
+	sortConnectionRows(rows, connectionSorters["pid"], false)
+	assert.Equal(t, []string{"10.0.0.2:443", "[::1]:41000", "10.0.0.3:53"}, remotes(rows))
+}
+
+func TestWriteConnectionsTable(t *testing.T) {
+	var buf bytes.Buffer
+	rows := buildConnectionRows(testConnections(), connectionFilter{pid: 2}, testContainerIDForPID)
+	require.NoError(t, writeConnectionsTable(&buf, rows))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "PID"))
+	assert.Equal(t, []string{"2", "udp4", "outgoing", "10.0.0.1:53000", "10.0.0.3:53", "-", "-", "50", "0", "0", "0", "0"}, strings.Fields(lines[1]))
+}
@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	netconfig "github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/network/encoding"
+)
+
+// dnsReplayColumns are the response codes with their own column in the
+// output of the replay command, the other ones are summed up
+var dnsReplayColumns = []string{"NOERROR", "NXDOMAIN", "SERVFAIL", "REFUSED"}
+
+func init() {
+	dnsReplayCommand.Flags().BoolVarP(&dnsReplayJSON, "json", "j", false, "print out the stats as json")
+	dnsCommand.AddCommand(dnsReplayCommand)
+	SysprobeCmd.AddCommand(dnsCommand)
+}
+
+var (
+	dnsReplayJSON bool
+
+	dnsCommand = &cobra.Command{
+		Use:   "dns",
+		Short: "DNS monitoring tools",
+		Long:  ``,
+	}
+
+	dnsReplayCommand = &cobra.Command{
+		Use:   "replay <capture file>",
+		Short: "Compute the DNS stats of a pcap or pcapng capture file",
+		Long: `Compute the DNS stats of a pcap or pcapng capture file with the DNS monitoring
+pipeline of the system-probe, and print them by domain and query type.
+
+The network_config settings of the system-probe configuration apply, except
+that the domains are always collected. The latencies are in microseconds.`,
+		Args: cobra.ExactArgs(1),
+		RunE: replayDNSCapture,
+	}
+)
+
+func replayDNSCapture(_ *cobra.Command, args []string) error {
+	if _, err := setupConfig(); err != nil {
+		return err
+	}
+	cfg := netconfig.New()
+	cfg.CollectDNSDomains = true
+
+	result, err := dns.ReplayPcap(cfg, args[0])
+	if err != nil {
+		return fmt.Errorf("could not replay %s: %s", args[0], err)
+	}
+	stats := encoding.FormatDNSDomainStats(result.Stats)
+
+	if dnsReplayJSON {
+		out := struct {
+			Domains   []*encoding.DNSDomainStats `json:"domains"`
+			Telemetry map[string]int64           `json:"telemetry"`
+		}{
+			Domains:   stats,
+			Telemetry: result.Telemetry,
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	if err := writeDNSStatsTable(os.Stdout, stats); err != nil {
+		return err
+	}
+	fmt.Println()
+	return writeDNSTelemetry(os.Stdout, result.Telemetry)
+}
+
+func writeDNSStatsTable(out io.Writer, stats []*encoding.DNSDomainStats) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "DOMAIN\tTYPE")
+	for _, rcode := range dnsReplayColumns {
+		fmt.Fprintf(w, "\t%s", rcode)
+	}
+	fmt.Fprintln(w, "\tOTHER\tTIMEOUTS\tTRUNCATED\tTCP FALLBACKS\tSUCCESS LATENCY\tFAILURE LATENCY")
+
+	for _, ds := range stats {
+		fmt.Fprintf(w, "%s\t%s", orDash(ds.Domain), ds.QueryType)
+		var other uint32
+		for rcode, count := range ds.Responses {
+			other += count
+			for _, column := range dnsReplayColumns {
+				if rcode == column {
+					other -= count
+					break
+				}
+			}
+		}
+		for _, rcode := range dnsReplayColumns {
+			fmt.Fprintf(w, "\t%d", ds.Responses[rcode])
+		}
+		fmt.Fprintf(w, "\t%d\t%d\t%d\t%d\t%d\t%d\n", other, ds.Timeouts, ds.Truncated, ds.TCPFallbacks, ds.SuccessLatencySum, ds.FailureLatencySum)
+	}
+	return w.Flush()
+}
+
+func writeDNSTelemetry(out io.Writer, telemetry map[string]int64) error {
+	names := make([]string, 0, len(telemetry))
+	for name := range telemetry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s:\t%d\n", name, telemetry[name])
+	}
+	return w.Flush()
+}
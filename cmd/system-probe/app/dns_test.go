@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/network/encoding"
+)
+
+func TestWriteDNSStatsTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeDNSStatsTable(&buf, []*encoding.DNSDomainStats{
+		{
+			Domain:            "foo.com",
+			QueryType:         "A",
+			Responses:         map[string]uint32{"NOERROR": 3, "NXDOMAIN": 1, "FORMERR": 2, "NOTIMP": 1},
+			Timeouts:          4,
+			Truncated:         1,
+			TCPFallbacks:      1,
+			SuccessLatencySum: 300,
+			FailureLatencySum: 50,
+		},
+		{
+			QueryType: "AAAA",
+			Responses: map[string]uint32{},
+		},
+	}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "DOMAIN"))
+	assert.Equal(t, []string{"foo.com", "A", "3", "1", "0", "0", "3", "4", "1", "1", "300", "50"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"-", "AAAA", "0", "0", "0", "0", "0", "0", "0", "0", "0", "0"}, strings.Fields(lines[2]))
+}
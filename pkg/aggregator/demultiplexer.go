@@ -21,6 +21,8 @@ import (
 
 	agentruntime "github.com/DataDog/datadog-agent/pkg/runtime"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/serializer/openmetrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer/remotewrite"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -138,11 +140,15 @@ type forwarders struct {
 	orchestrator       *forwarder.DefaultForwarder
 	eventPlatform      epforwarder.EventPlatformForwarder
 	containerLifecycle *forwarder.DefaultForwarder
+	remoteWrite        *forwarder.RemoteWriteForwarder
 }
 
 type dataOutputs struct {
 	forwarders       forwarders
 	sharedSerializer serializer.MetricSerializer
+	// openMetrics holds the last flushed series and sketches, nil if the
+	// OpenMetrics endpoint is disabled
+	openMetrics *openmetrics.Snapshot
 }
 
 // trigger be used to trigger something in the TimeSampler or the BufferedAggregator.
@@ -236,10 +242,28 @@ func initAgentDemultiplexer(options DemultiplexerOptions, hostname string) *Agen
 		sharedForwarder = forwarder.NewDefaultForwarder(options.SharedForwarderOptions)
 	}
 
+	// Prometheus remote-write forwarder
+	var remoteWriteForwarder *forwarder.RemoteWriteForwarder
+	if config.Datadog.GetBool("prometheus_remote_write.enabled") {
+		var err error
+		remoteWriteForwarder, err = forwarder.NewRemoteWriteForwarder(forwarder.NewRemoteWriteOptions())
+		if err != nil {
+			log.Errorf("Could not create the Prometheus remote-write forwarder: %v", err)
+		}
+	}
+
 	// prepare the serializer
 	// ----------------------
 
-	sharedSerializer := serializer.NewSerializer(sharedForwarder, orchestratorForwarder, containerLifecycleForwarder)
+	var sharedSerializer serializer.MetricSerializer = serializer.NewSerializer(sharedForwarder, orchestratorForwarder, containerLifecycleForwarder)
+	if remoteWriteForwarder != nil {
+		sharedSerializer = remotewrite.NewDualShippingSerializer(sharedSerializer, remotewrite.NewExporter(remoteWriteForwarder))
+	}
+	var openMetricsSnapshot *openmetrics.Snapshot
+	if config.Datadog.GetBool("openmetrics_endpoint.enabled") {
+		openMetricsSnapshot = openmetrics.NewSnapshot()
+		sharedSerializer = openmetrics.NewSnapshotSerializer(sharedSerializer, openMetricsSnapshot)
+	}
 
 	// prepare the embedded aggregator
 	// --
@@ -286,9 +310,11 @@ func initAgentDemultiplexer(options DemultiplexerOptions, hostname string) *Agen
 				orchestrator:       orchestratorForwarder,
 				eventPlatform:      eventPlatformForwarder,
 				containerLifecycle: containerLifecycleForwarder,
+				remoteWrite:        remoteWriteForwarder,
 			},
 
 			sharedSerializer: sharedSerializer,
+			openMetrics:      openMetricsSnapshot,
 		},
 
 		senders: newSenders(agg),
@@ -358,6 +384,15 @@ func (d *AgentDemultiplexer) Run() {
 			log.Debug("not starting the container lifecycle forwarder")
 		}
 
+		// Prometheus remote-write forwarder
+		if d.forwarders.remoteWrite != nil {
+			if err := d.forwarders.remoteWrite.Start(); err != nil {
+				log.Errorf("error starting the Prometheus remote-write forwarder: %v", err)
+			}
+		} else {
+			log.Debug("not starting the Prometheus remote-write forwarder")
+		}
+
 		// shared forwarder
 		if d.forwarders.shared != nil {
 			d.forwarders.shared.Start() //nolint:errcheck
@@ -457,6 +492,10 @@ func (d *AgentDemultiplexer) Stop(flush bool) {
 			d.dataOutputs.forwarders.containerLifecycle.Stop()
 			d.dataOutputs.forwarders.containerLifecycle = nil
 		}
+		if d.dataOutputs.forwarders.remoteWrite != nil {
+			d.dataOutputs.forwarders.remoteWrite.Stop()
+			d.dataOutputs.forwarders.remoteWrite = nil
+		}
 		if d.dataOutputs.forwarders.shared != nil {
 			d.dataOutputs.forwarders.shared.Stop()
 			d.dataOutputs.forwarders.shared = nil
@@ -670,6 +709,12 @@ func (d *AgentDemultiplexer) Serializer() serializer.MetricSerializer {
 	return d.dataOutputs.sharedSerializer
 }
 
+// OpenMetricsSnapshot returns the snapshot of the last flushed series and
+// sketches, nil if the OpenMetrics endpoint is disabled.
+func (d *AgentDemultiplexer) OpenMetricsSnapshot() *openmetrics.Snapshot {
+	return d.dataOutputs.openMetrics
+}
+
 // Aggregator returns an aggregator that anyone can use. This method exists
 // to keep compatibility with existing code while introducing the Demultiplexer,
 // however, the plan is to remove it anytime soon.
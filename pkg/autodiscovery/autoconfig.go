@@ -223,6 +223,7 @@ func (ac *AutoConfig) GetAllConfigs() []integration.Config {
 	var resolvedConfigs []integration.Config
 
 	for _, pd := range ac.providers {
+		pd.configsMu.Lock()
 		cfgs, err := pd.provider.Collect(context.TODO())
 		if err != nil {
 			log.Debugf("Unexpected error returned when collecting configurations from provider %v: %v", pd.provider, err)
@@ -255,6 +256,7 @@ func (ac *AutoConfig) GetAllConfigs() []integration.Config {
 		}
 		// Store all raw configs in the provider
 		pd.configs = cfgs
+		pd.configsMu.Unlock()
 
 		// resolve configs if needed
 		for _, config := range cfgs {
@@ -472,6 +474,52 @@ func (ac *AutoConfig) processRemovedConfigs(configs []integration.Config) {
 	}
 }
 
+// ReloadConfigs unschedules the configurations with the given names and
+// schedules them again, e.g. to decrypt again the secrets they reference after
+// a rotation. Templates are resolved again against the known services.
+func (ac *AutoConfig) ReloadConfigs(names []string) {
+	reload := make(map[string]bool, len(names))
+	for _, name := range names {
+		reload[name] = true
+	}
+
+	// the loaded configurations are decrypted, unschedule the ones which don't
+	// come from a template, the resolved templates are removed with them
+	var removed []integration.Config
+	ac.store.mapOverLoadedConfigs(func(loadedConfigs map[string]integration.Config) {
+		for _, c := range loadedConfigs {
+			if reload[c.Name] && c.ServiceID == "" {
+				removed = append(removed, c)
+			}
+		}
+	})
+	ac.processRemovedConfigs(removed)
+
+	ac.m.RLock()
+	pollers := make([]*configPoller, len(ac.providers))
+	copy(pollers, ac.providers)
+	ac.m.RUnlock()
+
+	// the configurations kept by the pollers hold decrypted instances,
+	// collect the raw configurations again
+	for _, pd := range pollers {
+		reloaded, err := pd.reload(context.TODO(), reload)
+		if err != nil {
+			log.Errorf("Unable to collect configurations from provider %s to reload them: %s", pd.provider, err)
+			continue
+		}
+
+		for _, config := range reloaded {
+			if config.IsTemplate() {
+				ac.removeConfigTemplates([]integration.Config{config})
+			}
+			log.Infof("Reloading configuration %s of provider %s", config.Name, pd.provider)
+			config.Provider = pd.provider.String()
+			ac.schedule(ac.processNewConfig(config))
+		}
+	}
+}
+
 func (ac *AutoConfig) removeConfigTemplates(configs []integration.Config) {
 	for _, c := range configs {
 		if c.IsTemplate() {
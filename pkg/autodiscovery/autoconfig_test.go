@@ -543,3 +543,53 @@ func TestSkipSecretDecrypt(t *testing.T) {
 
 	assert.True(t, mockDecrypt.haveAllScenariosNotCalled())
 }
+
+type staticProvider struct {
+	MockProvider
+	configs []integration.Config
+}
+
+func (p *staticProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	// the configurations are decrypted in place, return new ones
+	configs := make([]integration.Config, 0, len(p.configs))
+	for _, c := range p.configs {
+		c.Instances = append([]integration.Data{}, c.Instances...)
+		configs = append(configs, c)
+	}
+	return configs, nil
+}
+
+func TestReloadConfigs(t *testing.T) {
+	ctx := context.Background()
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+
+	password := "foo"
+	originalSecretsDecrypt := secretsDecrypt
+	secretsDecrypt = func(data []byte, origin string) ([]byte, error) {
+		return bytes.Replace(data, []byte("ENC[password]"), []byte(password), -1), nil
+	}
+	defer func() { secretsDecrypt = originalSecretsDecrypt }()
+
+	ac.AddConfigProvider(&staticProvider{configs: []integration.Config{
+		{Name: "cpu", Instances: []integration.Data{integration.Data("password: ENC[password]")}},
+		{Name: "memory", Instances: []integration.Data{integration.Data("password: ENC[password]")}},
+		{Name: "cpu", ADIdentifiers: []string{"redis"}, Instances: []integration.Data{integration.Data("password: ENC[password]")}},
+	}}, false, 0)
+	ac.LoadAndRun()
+
+	service := sharedService
+	ac.processNewService(ctx, &service)
+	require.Equal(t, 3, countLoadedConfigs(ac))
+
+	password = "bar"
+	ac.ReloadConfigs([]string{"cpu"})
+
+	instances := map[string][]string{}
+	ac.MapOverLoadedConfigs(func(loadedConfigs map[string]integration.Config) {
+		for _, c := range loadedConfigs {
+			instances[c.Name] = append(instances[c.Name], string(c.Instances[0]))
+		}
+	})
+	assert.Equal(t, []string{"password: bar", "password: bar"}, instances["cpu"])
+	assert.Equal(t, []string{"password: foo"}, instances["memory"])
+}
@@ -8,6 +8,7 @@ package autodiscovery
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -19,7 +20,11 @@ import (
 // configPoller keeps track of the configurations loaded by a certain
 // `ConfigProvider` and whether it should be polled or not.
 type configPoller struct {
-	provider     providers.ConfigProvider
+	provider providers.ConfigProvider
+	// configsMu serializes the calls to the provider and guards configs, the
+	// configurations are collected by the poll loop and reloaded on secret
+	// refreshes
+	configsMu    sync.Mutex
 	configs      []integration.Config
 	canPoll      bool
 	isPolling    bool
@@ -119,6 +124,9 @@ func (pd *configPoller) poll(ac *AutoConfig) {
 // collect is just a convenient wrapper to fetch configurations from a provider and
 // see what changed from the last time we called Collect().
 func (pd *configPoller) collect(ctx context.Context) ([]integration.Config, []integration.Config) {
+	pd.configsMu.Lock()
+	defer pd.configsMu.Unlock()
+
 	var newConf []integration.Config
 	var removedConf []integration.Config
 	old := pd.configs
@@ -143,3 +151,36 @@ func (pd *configPoller) collect(ctx context.Context) ([]integration.Config, []in
 	}
 	return newConf, removedConf
 }
+
+// reload collects again the configurations with the given names and replaces
+// the ones kept by the poller, it returns the reloaded configurations
+func (pd *configPoller) reload(ctx context.Context, names map[string]bool) ([]integration.Config, error) {
+	pd.configsMu.Lock()
+	defer pd.configsMu.Unlock()
+
+	fetched, err := pd.provider.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, isFileProvider := pd.provider.(*providers.FileConfigProvider)
+
+	configs := make([]integration.Config, 0, len(pd.configs))
+	for _, config := range pd.configs {
+		if !names[config.Name] {
+			configs = append(configs, config)
+		}
+	}
+
+	var reloaded []integration.Config
+	for _, config := range fetched {
+		// the metric files of the JMX checks are not configurations
+		if !names[config.Name] || (isFileProvider && config.MetricConfig != nil) {
+			continue
+		}
+		configs = append(configs, config)
+		reloaded = append(reloaded, config)
+	}
+	pd.configs = configs
+
+	return reloaded, nil
+}
@@ -8,4 +8,10 @@
 
 package common
 
-const EnabledLabelKey = "admission.datadoghq.com/enabled"
+const (
+	// EnabledLabelKey pod label to disable/enable mutations at the pod level.
+	EnabledLabelKey = "admission.datadoghq.com/enabled"
+
+	// InjectionModeLabelKey pod or namespace label to choose the config injection mode.
+	InjectionModeLabelKey = "admission.datadoghq.com/config.mode"
+)
@@ -121,6 +121,7 @@ func TestUpdateOutdatedWebhookV1(t *testing.T) {
 
 func TestGenerateTemplatesV1(t *testing.T) {
 	mockConfig := config.Mock()
+	defer mockConfig.Set("admission_controller.auto_instrumentation.enabled", false)
 	failurePolicy := admiv1.Ignore
 	matchPolicy := admiv1.Exact
 	sideEffects := admiv1.SideEffectClassNone
@@ -314,6 +315,28 @@ func TestGenerateTemplatesV1(t *testing.T) {
 				return []admiv1.MutatingWebhook{webhookConfig, webhookTags}
 			},
 		},
+		{
+			name: "config, tags and lib injection, mutate labelled",
+			setupConfig: func() {
+				mockConfig.Set("admission_controller.mutate_unlabelled", false)
+				mockConfig.Set("admission_controller.inject_config.enabled", true)
+				mockConfig.Set("admission_controller.inject_tags.enabled", true)
+				mockConfig.Set("admission_controller.auto_instrumentation.enabled", true)
+				mockConfig.Set("admission_controller.namespace_selector_fallback", false)
+			},
+			configFunc: func() Config { return NewConfig(false, false) },
+			want: func() []admiv1.MutatingWebhook {
+				selector := &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"admission.datadoghq.com/enabled": "true",
+					},
+				}
+				webhookConfig := webhook("datadog.webhook.config", "/injectconfig", selector, nil)
+				webhookTags := webhook("datadog.webhook.tags", "/injecttags", selector, nil)
+				webhookLib := webhook("datadog.webhook.auto.instrumentation", "/injectlib", selector, nil)
+				return []admiv1.MutatingWebhook{webhookConfig, webhookTags, webhookLib}
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,279 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/metrics"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// Shared library volume name and mount path
+	volumeName = "datadog-auto-instrumentation"
+	mountPath  = "/datadog-lib"
+
+	// Pod annotation formats to select the library version or a custom init container image
+	libVersionAnnotationKeyFormat = "admission.datadoghq.com/%s-lib.version"
+	customLibAnnotationKeyFormat  = "admission.datadoghq.com/%s-lib.custom-image"
+
+	// Env vars
+	javaToolOptionsKey = "JAVA_TOOL_OPTIONS"
+	javaToolOptionsVal = " -javaagent:/datadog-lib/dd-java-agent.jar"
+
+	pythonPathKey = "PYTHONPATH"
+	pythonPathVal = "/datadog-lib/"
+
+	nodeOptionsKey = "NODE_OPTIONS"
+	nodeOptionsVal = " --require=/datadog-lib/node_modules/dd-trace/init"
+
+	dotnetClrEnableProfilingKey   = "CORECLR_ENABLE_PROFILING"
+	dotnetClrEnableProfilingValue = "1"
+	dotnetClrProfilerIDKey        = "CORECLR_PROFILER"
+	dotnetClrProfilerIDValue      = "{846F5F1C-F9AE-4B07-969E-05C26BC060D8}"
+	dotnetClrProfilerPathKey      = "CORECLR_PROFILER_PATH"
+	dotnetClrProfilerPathValue    = "/datadog-lib/Datadog.Trace.ClrProfiler.Native.so"
+	dotnetTracerHomeKey           = "DD_DOTNET_TRACER_HOME"
+	dotnetTracerHomeValue         = "/datadog-lib"
+	dotnetTracerIntegrationsKey   = "DD_INTEGRATIONS"
+	dotnetTracerIntegrationsValue = "/datadog-lib/integrations.json"
+)
+
+type language string
+
+const (
+	java   language = "java"
+	python language = "python"
+	js     language = "js"
+	dotnet language = "dotnet"
+)
+
+// supportedLanguages lists the languages for which a tracing library can be injected
+var supportedLanguages = []language{java, python, js, dotnet}
+
+// libInfo holds the information needed to inject a tracing library
+type libInfo struct {
+	lang  language
+	image string
+}
+
+// envVar describes an env var to inject along with the tracing library,
+// valFunc computes the new value from the existing one (empty if not set)
+type envVar struct {
+	key     string
+	valFunc func(string) string
+}
+
+// InjectAutoInstrumentation injects APM libraries into pods
+func InjectAutoInstrumentation(rawPod []byte, ns string, dc dynamic.Interface) ([]byte, error) {
+	return mutate(rawPod, ns, injectAutoInstrumentation, dc)
+}
+
+// injectAutoInstrumentation adds an init container copying the tracing library
+// requested by the pod annotations and sets the language specific env vars
+func injectAutoInstrumentation(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
+	if pod == nil {
+		metrics.MutationErrors.Inc(metrics.LibInjectionMutationType, "nil pod")
+		return errors.New("cannot inject lib into nil pod")
+	}
+
+	if !shouldInjectLib(pod) {
+		return nil
+	}
+
+	libsToInject := extractLibInfo(pod, config.Datadog.GetString("admission_controller.auto_instrumentation.container_registry"))
+	if len(libsToInject) == 0 {
+		return nil
+	}
+
+	return injectAutoInstruConfig(pod, libsToInject)
+}
+
+// shouldInjectLib returns whether the pod should be considered for library injection.
+// Pods explicitly filtered-out with the admission.datadoghq.com/enabled=false label are ignored.
+func shouldInjectLib(pod *corev1.Pod) bool {
+	return shouldInjectTags(pod)
+}
+
+// extractLibInfo returns the libraries to inject based on the pod annotations
+func extractLibInfo(pod *corev1.Pod, containerRegistry string) []libInfo {
+	libInfoList := []libInfo{}
+	podAnnotations := pod.GetAnnotations()
+	for _, lang := range supportedLanguages {
+		customLibAnnotation := strings.ToLower(fmt.Sprintf(customLibAnnotationKeyFormat, lang))
+		if image, found := podAnnotations[customLibAnnotation]; found {
+			log.Debugf("Found %s library annotation %s, will inject %s image into pod %s", string(lang), customLibAnnotation, image, podString(pod))
+			libInfoList = append(libInfoList, libInfo{lang: lang, image: image})
+			continue
+		}
+
+		libVersionAnnotation := strings.ToLower(fmt.Sprintf(libVersionAnnotationKeyFormat, lang))
+		if version, found := podAnnotations[libVersionAnnotation]; found {
+			image := fmt.Sprintf("%s/dd-lib-%s-init:%s", containerRegistry, lang, version)
+			log.Debugf("Found %s library annotation %s, will inject %s image into pod %s", string(lang), libVersionAnnotation, image, podString(pod))
+			libInfoList = append(libInfoList, libInfo{lang: lang, image: image})
+		}
+	}
+
+	return libInfoList
+}
+
+// injectAutoInstruConfig injects the init containers, the shared volume and the env vars for each library
+func injectAutoInstruConfig(pod *corev1.Pod, libsToInject []libInfo) error {
+	var lastError error
+	for _, lib := range libsToInject {
+		var err error
+		switch lib.lang {
+		case java:
+			injectLibInitContainer(pod, lib.image, lib.lang)
+			err = injectLibConfig(pod, javaToolOptionsKey, javaEnvValFunc)
+		case js:
+			injectLibInitContainer(pod, lib.image, lib.lang)
+			err = injectLibConfig(pod, nodeOptionsKey, jsEnvValFunc)
+		case python:
+			injectLibInitContainer(pod, lib.image, lib.lang)
+			err = injectLibConfig(pod, pythonPathKey, pythonEnvValFunc)
+		case dotnet:
+			injectLibInitContainer(pod, lib.image, lib.lang)
+			err = injectLibEnvs(pod, []envVar{
+				{key: dotnetClrEnableProfilingKey, valFunc: identityValFunc(dotnetClrEnableProfilingValue)},
+				{key: dotnetClrProfilerIDKey, valFunc: identityValFunc(dotnetClrProfilerIDValue)},
+				{key: dotnetClrProfilerPathKey, valFunc: identityValFunc(dotnetClrProfilerPathValue)},
+				{key: dotnetTracerHomeKey, valFunc: identityValFunc(dotnetTracerHomeValue)},
+				{key: dotnetTracerIntegrationsKey, valFunc: identityValFunc(dotnetTracerIntegrationsValue)},
+			})
+		default:
+			metrics.MutationErrors.Inc(metrics.LibInjectionMutationType, "unsupported language")
+			return fmt.Errorf("language %q is not supported. Supported languages are %v", lib.lang, supportedLanguages)
+		}
+
+		metrics.MutationAttempts.Inc(metrics.LibInjectionMutationType, strconv.FormatBool(err == nil))
+		if err != nil {
+			metrics.MutationErrors.Inc(metrics.LibInjectionMutationType, "cannot inject lib config")
+			lastError = err
+		}
+	}
+
+	injectLibVolume(pod)
+
+	return lastError
+}
+
+// injectLibInitContainer adds an init container copying the tracing library into the shared volume
+func injectLibInitContainer(pod *corev1.Pod, image string, lang language) {
+	initCtrName := fmt.Sprintf("datadog-lib-%s-init", lang)
+	for _, ctr := range pod.Spec.InitContainers {
+		if ctr.Name == initCtrName {
+			log.Debugf("Init container %s already exists in pod %s", initCtrName, podString(pod))
+			return
+		}
+	}
+
+	log.Debugf("Injecting init container named %q with image %q into pod %s", initCtrName, image, podString(pod))
+	pod.Spec.InitContainers = append([]corev1.Container{
+		{
+			Name:    initCtrName,
+			Image:   image,
+			Command: []string{"sh", "copy-lib.sh", mountPath},
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      volumeName,
+					MountPath: mountPath,
+				},
+			},
+		},
+	}, pod.Spec.InitContainers...)
+}
+
+// injectLibConfig mounts the shared volume in the application containers and sets the library env var
+func injectLibConfig(pod *corev1.Pod, envKey string, envValFunc func(string) string) error {
+	return injectLibEnvs(pod, []envVar{{key: envKey, valFunc: envValFunc}})
+}
+
+// injectLibEnvs mounts the shared volume in the application containers and sets the library env vars
+func injectLibEnvs(pod *corev1.Pod, envs []envVar) error {
+	for i, ctr := range pod.Spec.Containers {
+		for _, env := range envs {
+			index := envIndex(ctr.Env, env.key)
+			if index < 0 {
+				pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, corev1.EnvVar{
+					Name:  env.key,
+					Value: env.valFunc(""),
+				})
+				continue
+			}
+
+			if pod.Spec.Containers[i].Env[index].ValueFrom != nil {
+				return fmt.Errorf("%q is defined via ValueFrom in container %q of pod %s", env.key, ctr.Name, podString(pod))
+			}
+
+			pod.Spec.Containers[i].Env[index].Value = env.valFunc(pod.Spec.Containers[i].Env[index].Value)
+		}
+
+		if !containsVolumeMount(ctr.VolumeMounts, volumeName) {
+			pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+				Name:      volumeName,
+				MountPath: mountPath,
+			})
+		}
+	}
+
+	return nil
+}
+
+// injectLibVolume adds the emptyDir volume shared between the init containers and the application containers
+func injectLibVolume(pod *corev1.Pod) {
+	if containsVolume(pod.Spec.Volumes, volumeName) {
+		return
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+}
+
+// envIndex returns the index of the env var with a given name, -1 if not found
+func envIndex(envs []corev1.EnvVar, name string) int {
+	for i := range envs {
+		if envs[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func identityValFunc(s string) func(string) string {
+	return func(string) string { return s }
+}
+
+func javaEnvValFunc(predefinedVal string) string {
+	return predefinedVal + javaToolOptionsVal
+}
+
+func jsEnvValFunc(predefinedVal string) string {
+	return predefinedVal + nodeOptionsVal
+}
+
+func pythonEnvValFunc(predefinedVal string) string {
+	if predefinedVal == "" {
+		return pythonPathVal
+	}
+	return fmt.Sprintf("%s:%s", pythonPathVal, predefinedVal)
+}
@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestInjectAutoInstruConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		pod           *corev1.Pod
+		libsToInject  []libInfo
+		expectedEnvs  map[string]string
+		expectedImage string
+		wantErr       bool
+	}{
+		{
+			name:          "nominal case: java",
+			pod:           fakePod("java-pod"),
+			libsToInject:  []libInfo{{lang: java, image: "gcr.io/datadoghq/dd-lib-java-init:v1"}},
+			expectedEnvs:  map[string]string{"JAVA_TOOL_OPTIONS": " -javaagent:/datadog-lib/dd-java-agent.jar"},
+			expectedImage: "gcr.io/datadoghq/dd-lib-java-init:v1",
+		},
+		{
+			name:          "JAVA_TOOL_OPTIONS not empty",
+			pod:           fakePodWithEnv("java-pod", "JAVA_TOOL_OPTIONS"),
+			libsToInject:  []libInfo{{lang: java, image: "gcr.io/datadoghq/dd-lib-java-init:v1"}},
+			expectedEnvs:  map[string]string{"JAVA_TOOL_OPTIONS": "JAVA_TOOL_OPTIONS-env-value -javaagent:/datadog-lib/dd-java-agent.jar"},
+			expectedImage: "gcr.io/datadoghq/dd-lib-java-init:v1",
+		},
+		{
+			name: "JAVA_TOOL_OPTIONS set via ValueFrom",
+			pod: fakePodWithContainer("java-pod", corev1.Container{
+				Name: "java-pod-container",
+				Env: []corev1.EnvVar{{
+					Name:      "JAVA_TOOL_OPTIONS",
+					ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+				}},
+			}),
+			libsToInject: []libInfo{{lang: java, image: "gcr.io/datadoghq/dd-lib-java-init:v1"}},
+			wantErr:      true,
+		},
+		{
+			name:          "nominal case: js",
+			pod:           fakePod("js-pod"),
+			libsToInject:  []libInfo{{lang: js, image: "gcr.io/datadoghq/dd-lib-js-init:v1"}},
+			expectedEnvs:  map[string]string{"NODE_OPTIONS": " --require=/datadog-lib/node_modules/dd-trace/init"},
+			expectedImage: "gcr.io/datadoghq/dd-lib-js-init:v1",
+		},
+		{
+			name:          "nominal case: python",
+			pod:           fakePod("python-pod"),
+			libsToInject:  []libInfo{{lang: python, image: "gcr.io/datadoghq/dd-lib-python-init:v1"}},
+			expectedEnvs:  map[string]string{"PYTHONPATH": "/datadog-lib/"},
+			expectedImage: "gcr.io/datadoghq/dd-lib-python-init:v1",
+		},
+		{
+			name:          "PYTHONPATH not empty",
+			pod:           fakePodWithEnv("python-pod", "PYTHONPATH"),
+			libsToInject:  []libInfo{{lang: python, image: "gcr.io/datadoghq/dd-lib-python-init:v1"}},
+			expectedEnvs:  map[string]string{"PYTHONPATH": "/datadog-lib/:PYTHONPATH-env-value"},
+			expectedImage: "gcr.io/datadoghq/dd-lib-python-init:v1",
+		},
+		{
+			name:         "nominal case: dotnet",
+			pod:          fakePod("dotnet-pod"),
+			libsToInject: []libInfo{{lang: dotnet, image: "gcr.io/datadoghq/dd-lib-dotnet-init:v1"}},
+			expectedEnvs: map[string]string{
+				"CORECLR_ENABLE_PROFILING": "1",
+				"CORECLR_PROFILER":         "{846F5F1C-F9AE-4B07-969E-05C26BC060D8}",
+				"CORECLR_PROFILER_PATH":    "/datadog-lib/Datadog.Trace.ClrProfiler.Native.so",
+				"DD_DOTNET_TRACER_HOME":    "/datadog-lib",
+				"DD_INTEGRATIONS":          "/datadog-lib/integrations.json",
+			},
+			expectedImage: "gcr.io/datadoghq/dd-lib-dotnet-init:v1",
+		},
+		{
+			name:         "unknown language",
+			pod:          fakePod("unknown-pod"),
+			libsToInject: []libInfo{{lang: "unknown", image: "gcr.io/datadoghq/dd-lib-unknown-init:v1"}},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := injectAutoInstruConfig(tt.pod, tt.libsToInject)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, tt.pod.Spec.InitContainers, 1)
+			initCtr := tt.pod.Spec.InitContainers[0]
+			assert.Equal(t, tt.expectedImage, initCtr.Image)
+			assert.Equal(t, []string{"sh", "copy-lib.sh", "/datadog-lib"}, initCtr.Command)
+			assert.Equal(t, []corev1.VolumeMount{{Name: "datadog-auto-instrumentation", MountPath: "/datadog-lib"}}, initCtr.VolumeMounts)
+
+			require.Len(t, tt.pod.Spec.Volumes, 1)
+			assert.Equal(t, "datadog-auto-instrumentation", tt.pod.Spec.Volumes[0].Name)
+			assert.NotNil(t, tt.pod.Spec.Volumes[0].EmptyDir)
+
+			ctr := tt.pod.Spec.Containers[0]
+			assert.Contains(t, ctr.VolumeMounts, corev1.VolumeMount{Name: "datadog-auto-instrumentation", MountPath: "/datadog-lib"})
+			for k, v := range tt.expectedEnvs {
+				assert.Contains(t, ctr.Env, corev1.EnvVar{Name: k, Value: v})
+			}
+		})
+	}
+}
+
+func TestInjectAutoInstruConfigIdempotent(t *testing.T) {
+	pod := fakePod("java-pod")
+	libs := []libInfo{{lang: java, image: "gcr.io/datadoghq/dd-lib-java-init:v1"}}
+
+	require.NoError(t, injectAutoInstruConfig(pod, libs))
+	injectLibInitContainer(pod, libs[0].image, libs[0].lang)
+	injectLibVolume(pod)
+
+	assert.Len(t, pod.Spec.InitContainers, 1)
+	assert.Len(t, pod.Spec.Volumes, 1)
+	assert.Len(t, pod.Spec.Containers[0].VolumeMounts, 1)
+}
+
+func TestExtractLibInfo(t *testing.T) {
+	tests := []struct {
+		name              string
+		pod               *corev1.Pod
+		containerRegistry string
+		expectedLibs      []libInfo
+	}{
+		{
+			name:              "java",
+			pod:               fakePodWithAnnotation("admission.datadoghq.com/java-lib.version", "v1"),
+			containerRegistry: "registry",
+			expectedLibs:      []libInfo{{lang: java, image: "registry/dd-lib-java-init:v1"}},
+		},
+		{
+			name:              "js",
+			pod:               fakePodWithAnnotation("admission.datadoghq.com/js-lib.version", "v1"),
+			containerRegistry: "registry",
+			expectedLibs:      []libInfo{{lang: js, image: "registry/dd-lib-js-init:v1"}},
+		},
+		{
+			name:              "python",
+			pod:               fakePodWithAnnotation("admission.datadoghq.com/python-lib.version", "v1"),
+			containerRegistry: "registry",
+			expectedLibs:      []libInfo{{lang: python, image: "registry/dd-lib-python-init:v1"}},
+		},
+		{
+			name:              "dotnet",
+			pod:               fakePodWithAnnotation("admission.datadoghq.com/dotnet-lib.version", "v1"),
+			containerRegistry: "registry",
+			expectedLibs:      []libInfo{{lang: dotnet, image: "registry/dd-lib-dotnet-init:v1"}},
+		},
+		{
+			name:              "custom image",
+			pod:               fakePodWithAnnotation("admission.datadoghq.com/java-lib.custom-image", "foo/bar:1"),
+			containerRegistry: "registry",
+			expectedLibs:      []libInfo{{lang: java, image: "foo/bar:1"}},
+		},
+		{
+			name:              "unknown language",
+			pod:               fakePodWithAnnotation("admission.datadoghq.com/unknown-lib.version", "v1"),
+			containerRegistry: "registry",
+			expectedLibs:      []libInfo{},
+		},
+		{
+			name:              "no annotation",
+			pod:               fakePod("foo-pod"),
+			containerRegistry: "registry",
+			expectedLibs:      []libInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ElementsMatch(t, tt.expectedLibs, extractLibInfo(tt.pod, tt.containerRegistry))
+		})
+	}
+}
+
+func TestInjectAutoInstrumentation(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.auto_instrumentation.container_registry", "registry")
+
+	pod := fakePodWithAnnotation("admission.datadoghq.com/python-lib.version", "v1")
+	require.NoError(t, injectAutoInstrumentation(pod, "", nil))
+	require.Len(t, pod.Spec.InitContainers, 1)
+	assert.Equal(t, "registry/dd-lib-python-init:v1", pod.Spec.InitContainers[0].Image)
+
+	disabled := fakePodWithAnnotation("admission.datadoghq.com/python-lib.version", "v1")
+	disabled.Labels = map[string]string{"admission.datadoghq.com/enabled": "false"}
+	require.NoError(t, injectAutoInstrumentation(disabled, "", nil))
+	assert.Empty(t, disabled.Spec.InitContainers)
+
+	assert.Error(t, injectAutoInstrumentation(nil, "", nil))
+}
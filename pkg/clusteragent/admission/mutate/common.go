@@ -56,6 +56,46 @@ func contains(envs []corev1.EnvVar, name string) bool {
 	return false
 }
 
+// getEnv returns the env var with a given name from an EnvVar slice
+func getEnv(envs []corev1.EnvVar, name string) (corev1.EnvVar, bool) {
+	for _, env := range envs {
+		if env.Name == name {
+			return env, true
+		}
+	}
+	return corev1.EnvVar{}, false
+}
+
+// containsVolume returns whether Volume slice contains a volume with a given name
+func containsVolume(volumes []corev1.Volume, name string) bool {
+	for _, vol := range volumes {
+		if vol.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// containsVolumeMount returns whether VolumeMount slice contains a volume mount with a given name
+func containsVolumeMount(mounts []corev1.VolumeMount, name string) bool {
+	for _, mount := range mounts {
+		if mount.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// containsVolumeMountPath returns whether VolumeMount slice contains a volume mount at a given path
+func containsVolumeMountPath(mounts []corev1.VolumeMount, path string) bool {
+	for _, mount := range mounts {
+		if mount.MountPath == path {
+			return true
+		}
+	}
+	return false
+}
+
 // injectEnv injects an env var into a pod template if it doesn't exist
 func injectEnv(pod *corev1.Pod, env corev1.EnvVar) bool {
 	injected := false
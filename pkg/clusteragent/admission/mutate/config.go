@@ -9,23 +9,43 @@
 package mutate
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/common"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/metrics"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 )
 
 const (
-	agentHostEnvVarName  = "DD_AGENT_HOST"
-	ddEntityIDEnvVarName = "DD_ENTITY_ID"
+	agentHostEnvVarName     = "DD_AGENT_HOST"
+	ddEntityIDEnvVarName    = "DD_ENTITY_ID"
+	traceURLEnvVarName      = "DD_TRACE_AGENT_URL"
+	dogstatsdURLEnvVarName  = "DD_DOGSTATSD_URL"
+	dogstatsdSocketVolume   = "datadog-dogstatsd-socket"
+	traceAgentSocketVolume  = "datadog-trace-agent-socket"
+	socketsVolume           = "datadog-sockets"
+	namespaceCacheKeyPrefix = "admission_namespace"
+	unixSocketURLPrefix     = "unix://"
+
+	// Config injection modes
+	hostIP = "hostip"
+	socket = "socket"
 )
 
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
 var (
 	agentHostEnvVar = corev1.EnvVar{
 		Name:  agentHostEnvVarName,
@@ -48,16 +68,17 @@ var (
 	}
 )
 
-// InjectConfig adds the DD_AGENT_HOST and DD_ENTITY_ID env vars to the pod template if they don't exist
+// InjectConfig adds the DD_AGENT_HOST and DD_ENTITY_ID env vars to the pod template if they don't exist.
+// In socket mode, the DogStatsD and APM sockets are mounted instead of injecting DD_AGENT_HOST.
 func InjectConfig(rawPod []byte, ns string, dc dynamic.Interface) ([]byte, error) {
 	return mutate(rawPod, ns, injectConfig, dc)
 }
 
-// injectConfig injects DD_AGENT_HOST and DD_ENTITY_ID into a pod template if needed
-func injectConfig(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
-	var injectedHost, injectedEntity bool
+// injectConfig injects DD_AGENT_HOST (or the agent sockets) and DD_ENTITY_ID into a pod template if needed
+func injectConfig(pod *corev1.Pod, ns string, dc dynamic.Interface) error {
+	var injectedConfig, injectedEntity bool
 	defer func() {
-		metrics.MutationAttempts.Inc(metrics.ConfigMutationType, strconv.FormatBool(injectedHost || injectedEntity))
+		metrics.MutationAttempts.Inc(metrics.ConfigMutationType, strconv.FormatBool(injectedConfig || injectedEntity))
 	}()
 
 	if pod == nil {
@@ -65,14 +86,174 @@ func injectConfig(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
 		return errors.New("cannot inject config into nil pod")
 	}
 
-	if shouldInjectConf(pod) {
-		injectedHost = injectEnv(pod, agentHostEnvVar)
-		injectedEntity = injectEnv(pod, ddEntityIDEnvVar)
+	if !shouldInjectConf(pod) {
+		return nil
+	}
+
+	switch mode := injectionMode(pod, ns, dc); mode {
+	case hostIP:
+		injectedConfig = injectEnv(pod, agentHostEnvVar)
+	case socket:
+		injectedConfig = injectSockets(pod)
+	default:
+		metrics.MutationErrors.Inc(metrics.ConfigMutationType, "unknown mode")
+		return fmt.Errorf("invalid injection mode %q", mode)
 	}
 
+	injectedEntity = injectEnv(pod, ddEntityIDEnvVar)
+
 	return nil
 }
 
+// injectionMode returns the config injection mode for a pod.
+// The pod label takes precedence over the namespace label, which takes precedence over the cluster agent config.
+func injectionMode(pod *corev1.Pod, ns string, dc dynamic.Interface) string {
+	if mode, found := pod.GetLabels()[common.InjectionModeLabelKey]; found {
+		return validateMode(mode, "pod "+podString(pod))
+	}
+
+	if ns == "" {
+		ns = pod.GetNamespace()
+	}
+
+	if ns != "" && dc != nil {
+		nsLabels, err := getAndCacheNamespaceLabels(ns, dc)
+		if err != nil {
+			log.Debugf("Cannot get labels of namespace %s, falling back to the global injection mode: %v", ns, err)
+		} else if mode, found := nsLabels[common.InjectionModeLabelKey]; found {
+			return validateMode(mode, "namespace "+ns)
+		}
+	}
+
+	return validateMode(config.Datadog.GetString("admission_controller.inject_config.mode"), "admission_controller.inject_config.mode")
+}
+
+// validateMode returns the normalized mode, defaulting to hostip if the mode is unknown
+func validateMode(mode, source string) string {
+	switch strings.ToLower(mode) {
+	case hostIP:
+		return hostIP
+	case socket:
+		return socket
+	default:
+		log.Warnf("Invalid injection mode %q set on %s, should be either %q or %q, defaulting to %q", mode, source, hostIP, socket, hostIP)
+		return hostIP
+	}
+}
+
+// getAndCacheNamespaceLabels tries to fetch the namespace labels from cache before querying the api server
+func getAndCacheNamespaceLabels(ns string, dc dynamic.Interface) (map[string]string, error) {
+	cacheKey := cache.BuildAgentKey(namespaceCacheKeyPrefix, ns)
+	if cached, hit := cache.Cache.Get(cacheKey); hit {
+		if nsLabels, valid := cached.(map[string]string); valid {
+			return nsLabels, nil
+		}
+		log.Debugf("Invalid labels for namespace '%s', forcing a cache miss", ns)
+	}
+
+	obj, err := dc.Resource(namespacesGVR).Get(context.TODO(), ns, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	nsLabels := obj.GetLabels()
+	cache.Cache.Set(cacheKey, nsLabels, ownerCacheTTL)
+	return nsLabels, nil
+}
+
+// injectSockets mounts the DogStatsD and APM socket directories as hostPath volumes
+// and sets DD_DOGSTATSD_URL and DD_TRACE_AGENT_URL accordingly
+func injectSockets(pod *corev1.Pod) bool {
+	injected := false
+	sockets := []struct {
+		envName    string
+		volumeName string
+		url        string
+	}{
+		{
+			envName:    traceURLEnvVarName,
+			volumeName: traceAgentSocketVolume,
+			url:        config.Datadog.GetString("admission_controller.inject_config.trace_agent_socket"),
+		},
+		{
+			envName:    dogstatsdURLEnvVarName,
+			volumeName: dogstatsdSocketVolume,
+			url:        config.Datadog.GetString("admission_controller.inject_config.dogstatsd_socket"),
+		},
+	}
+
+	// the sockets of the same directory share their volume, as the mount
+	// paths of a container must be unique
+	var dirs []string
+	volumeNames := make(map[string]string)
+	envNames := make(map[string][]string)
+	for _, s := range sockets {
+		socketPath := strings.TrimPrefix(s.url, unixSocketURLPrefix)
+		if socketPath == s.url || !filepath.IsAbs(socketPath) {
+			log.Warnf("Ignoring invalid socket URL %q, it should be formatted as unix:///path/to/socket", s.url)
+			continue
+		}
+
+		if !injectEnv(pod, corev1.EnvVar{Name: s.envName, Value: s.url}) {
+			continue
+		}
+		injected = true
+
+		dir := filepath.Dir(socketPath)
+		if _, found := volumeNames[dir]; found {
+			volumeNames[dir] = socketsVolume
+		} else {
+			dirs = append(dirs, dir)
+			volumeNames[dir] = s.volumeName
+		}
+		envNames[dir] = append(envNames[dir], s.envName)
+	}
+
+	for _, dir := range dirs {
+		injectSocketVolume(pod, volumeNames[dir], dir, envNames[dir])
+	}
+
+	return injected
+}
+
+// injectSocketVolume adds a hostPath volume for a socket directory to the pod
+// and mounts it in the containers where one of the socket env vars points at a socket of this directory
+func injectSocketVolume(pod *corev1.Pod, volumeName, dir string, envNames []string) {
+	if !containsVolume(pod.Spec.Volumes, volumeName) {
+		hostPathType := corev1.HostPathDirectoryOrCreate
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: dir,
+					Type: &hostPathType,
+				},
+			},
+		})
+	}
+
+	for i, ctr := range pod.Spec.Containers {
+		if containsVolumeMount(ctr.VolumeMounts, volumeName) || containsVolumeMountPath(ctr.VolumeMounts, dir) {
+			continue
+		}
+		for _, envName := range envNames {
+			if env, found := getEnv(ctr.Env, envName); found && env.ValueFrom == nil && socketURLInDir(env.Value, dir) {
+				pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+					Name:      volumeName,
+					MountPath: dir,
+				})
+				break
+			}
+		}
+	}
+}
+
+// socketURLInDir returns whether a URL points at a unix socket located in dir
+func socketURLInDir(url, dir string) bool {
+	socketPath := strings.TrimPrefix(url, unixSocketURLPrefix)
+	return socketPath != url && filepath.Dir(socketPath) == dir
+}
+
 // shouldInjectConf returns whether the config should be injected
 // based on the pod labels and the cluster agent config
 func shouldInjectConf(pod *corev1.Pod) bool {
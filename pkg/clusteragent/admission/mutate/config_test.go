@@ -12,7 +12,12 @@ import (
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/fake"
 )
 
 func Test_shouldInjectConf(t *testing.T) {
@@ -69,3 +74,178 @@ func Test_shouldInjectConf(t *testing.T) {
 		})
 	}
 }
+
+func TestInjectionMode(t *testing.T) {
+	mockConfig := config.Mock()
+	defer cache.Cache.Flush()
+
+	socketNs := &unstructured.Unstructured{}
+	socketNs.SetAPIVersion("v1")
+	socketNs.SetKind("Namespace")
+	socketNs.SetName("socket-ns")
+	socketNs.SetLabels(map[string]string{"admission.datadoghq.com/config.mode": "socket"})
+
+	plainNs := &unstructured.Unstructured{}
+	plainNs.SetAPIVersion("v1")
+	plainNs.SetKind("Namespace")
+	plainNs.SetName("plain-ns")
+
+	dc := fake.NewSimpleDynamicClient(scheme, socketNs, plainNs)
+
+	tests := []struct {
+		name       string
+		pod        *corev1.Pod
+		ns         string
+		globalMode string
+		want       string
+	}{
+		{
+			name:       "global default",
+			pod:        fakePod("foo"),
+			ns:         "plain-ns",
+			globalMode: "hostip",
+			want:       hostIP,
+		},
+		{
+			name:       "global socket",
+			pod:        fakePod("foo"),
+			ns:         "plain-ns",
+			globalMode: "socket",
+			want:       socket,
+		},
+		{
+			name:       "namespace label overrides global config",
+			pod:        fakePod("foo"),
+			ns:         "socket-ns",
+			globalMode: "hostip",
+			want:       socket,
+		},
+		{
+			name:       "pod label overrides namespace label",
+			pod:        fakePodWithLabel("admission.datadoghq.com/config.mode", "hostip"),
+			ns:         "socket-ns",
+			globalMode: "socket",
+			want:       hostIP,
+		},
+		{
+			name:       "unknown namespace",
+			pod:        fakePod("foo"),
+			ns:         "unknown-ns",
+			globalMode: "socket",
+			want:       socket,
+		},
+		{
+			name:       "invalid mode",
+			pod:        fakePodWithLabel("admission.datadoghq.com/config.mode", "foo"),
+			ns:         "plain-ns",
+			globalMode: "socket",
+			want:       hostIP,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig.Set("admission_controller.inject_config.mode", tt.globalMode)
+			assert.Equal(t, tt.want, injectionMode(tt.pod, tt.ns, dc))
+		})
+	}
+}
+
+func TestInjectSocket(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.inject_config.mode", "socket")
+	mockConfig.Set("admission_controller.mutate_unlabelled", true)
+
+	pod := fakePod("foo-pod")
+	err := injectConfig(pod, "", nil)
+	assert.NoError(t, err)
+
+	ctr := pod.Spec.Containers[0]
+	assert.Contains(t, ctr.Env, corev1.EnvVar{Name: "DD_TRACE_AGENT_URL", Value: "unix:///var/run/datadog/apm.socket"})
+	assert.Contains(t, ctr.Env, corev1.EnvVar{Name: "DD_DOGSTATSD_URL", Value: "unix:///var/run/datadog/dsd.socket"})
+	assert.True(t, contains(ctr.Env, "DD_ENTITY_ID"))
+	assert.False(t, contains(ctr.Env, "DD_AGENT_HOST"))
+
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "datadog-sockets", MountPath: "/var/run/datadog"},
+	}, ctr.VolumeMounts)
+
+	hostPathType := corev1.HostPathDirectoryOrCreate
+	assert.Equal(t, []corev1.Volume{
+		{
+			Name:         "datadog-sockets",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/datadog", Type: &hostPathType}},
+		},
+	}, pod.Spec.Volumes)
+}
+
+func TestInjectSocketSeparateDirectories(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.inject_config.mode", "socket")
+	mockConfig.Set("admission_controller.mutate_unlabelled", true)
+	mockConfig.Set("admission_controller.inject_config.trace_agent_socket", "unix:///var/run/datadog-apm/apm.socket")
+
+	pod := fakePod("foo-pod")
+	err := injectConfig(pod, "", nil)
+	assert.NoError(t, err)
+
+	assert.ElementsMatch(t, []corev1.VolumeMount{
+		{Name: "datadog-trace-agent-socket", MountPath: "/var/run/datadog-apm"},
+		{Name: "datadog-dogstatsd-socket", MountPath: "/var/run/datadog"},
+	}, pod.Spec.Containers[0].VolumeMounts)
+	assert.Len(t, pod.Spec.Volumes, 2)
+}
+
+func TestInjectSocketAlreadyDeclared(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.inject_config.mode", "socket")
+	mockConfig.Set("admission_controller.mutate_unlabelled", true)
+
+	pod := fakePodWithContainer("foo-pod", corev1.Container{
+		Name: "foo-container",
+		Env: []corev1.EnvVar{
+			fakeEnvWithValue("DD_TRACE_AGENT_URL", "http://trace-agent:8126"),
+			fakeEnvWithValue("DD_DOGSTATSD_URL", "udp://dogstatsd:8125"),
+		},
+	})
+	err := injectConfig(pod, "", nil)
+	assert.NoError(t, err)
+
+	ctr := pod.Spec.Containers[0]
+	assert.Contains(t, ctr.Env, corev1.EnvVar{Name: "DD_TRACE_AGENT_URL", Value: "http://trace-agent:8126"})
+	assert.Contains(t, ctr.Env, corev1.EnvVar{Name: "DD_DOGSTATSD_URL", Value: "udp://dogstatsd:8125"})
+	assert.Empty(t, ctr.VolumeMounts)
+	assert.Empty(t, pod.Spec.Volumes)
+}
+
+func TestInjectSocketSiblingDirectory(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.inject_config.mode", "socket")
+	mockConfig.Set("admission_controller.mutate_unlabelled", true)
+
+	pod := fakePodWithContainer("foo-pod",
+		corev1.Container{
+			Name: "custom-container",
+			Env: []corev1.EnvVar{
+				fakeEnvWithValue("DD_TRACE_AGENT_URL", "unix:///var/run/datadog2/apm.socket"),
+				fakeEnvWithValue("DD_DOGSTATSD_URL", "udp://dogstatsd:8125"),
+			},
+		},
+		corev1.Container{Name: "foo-container"},
+	)
+	err := injectConfig(pod, "", nil)
+	assert.NoError(t, err)
+
+	assert.Empty(t, pod.Spec.Containers[0].VolumeMounts)
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "datadog-sockets", MountPath: "/var/run/datadog"},
+	}, pod.Spec.Containers[1].VolumeMounts)
+	assert.Len(t, pod.Spec.Volumes, 1)
+}
+
+func TestSocketURLInDir(t *testing.T) {
+	assert.True(t, socketURLInDir("unix:///var/run/datadog/apm.socket", "/var/run/datadog"))
+	assert.False(t, socketURLInDir("unix:///var/run/datadog2/apm.socket", "/var/run/datadog"))
+	assert.False(t, socketURLInDir("unix:///var/run/datadog/apm/apm.socket", "/var/run/datadog"))
+	assert.False(t, socketURLInDir("/var/run/datadog/apm.socket", "/var/run/datadog"))
+	assert.False(t, socketURLInDir("http://trace-agent:8126", "/var/run/datadog"))
+}
@@ -14,15 +14,18 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/collectors"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/collectors/inventory"
+	k8sCollectors "github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/collectors/k8s"
 	"github.com/DataDog/datadog-agent/pkg/orchestrator"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/cache"
 )
 
 const (
-	defaultExtraSyncTimeout = 60 * time.Second
+	defaultExtraSyncTimeout          = 60 * time.Second
+	defaultMaxCustomResourcesPerType = 1000
 )
 
 // CollectorBundle is a container for a group of collectors. It provides a way
@@ -67,6 +70,7 @@ func NewCollectorBundle(chk *OrchestratorCheck) *CollectorBundle {
 // prepare initializes the collector bundle internals before it can be used.
 func (cb *CollectorBundle) prepare() {
 	cb.prepareCollectors()
+	cb.prepareCustomResourceCollectors()
 	cb.prepareExtraSyncTimeout()
 }
 
@@ -93,6 +97,34 @@ func (cb *CollectorBundle) prepareCollectors() {
 	}
 }
 
+// prepareCustomResourceCollectors adds a generic collector to the bundle for
+// each custom resource declared in the check configuration.
+func (cb *CollectorBundle) prepareCustomResourceCollectors() {
+	seen := make(map[string]struct{})
+
+	for _, cr := range cb.check.instance.CustomResources {
+		if cr.Version == "" || cr.Resource == "" {
+			_ = cb.check.Warnf("Invalid custom resource configuration, version and resource are required: %+v", cr)
+			continue
+		}
+
+		gvr := schema.GroupVersionResource{Group: cr.Group, Version: cr.Version, Resource: cr.Resource}
+		name := k8sCollectors.CRCollectorName(gvr)
+		if _, found := seen[name]; found {
+			_ = cb.check.Warnf("Duplicate custom resource configuration: %s", name)
+			continue
+		}
+		seen[name] = struct{}{}
+
+		maxItems := cr.MaxItems
+		if maxItems <= 0 {
+			maxItems = defaultMaxCustomResourcesPerType
+		}
+
+		cb.collectors = append(cb.collectors, k8sCollectors.NewCRCollector(gvr, maxItems))
+	}
+}
+
 // prepareExtraSyncTimeout initializes the bundle extra sync timeout.
 func (cb *CollectorBundle) prepareExtraSyncTimeout() {
 	// No extra timeout set in the check configuration.
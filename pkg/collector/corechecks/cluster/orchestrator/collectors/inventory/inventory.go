@@ -31,12 +31,17 @@ func NewCollectorInventory() *CollectorInventory {
 			k8sCollectors.NewCronJobCollector(),
 			k8sCollectors.NewDaemonSetCollector(),
 			k8sCollectors.NewDeploymentCollector(),
+			k8sCollectors.NewHorizontalPodAutoscalerCollector(),
 			k8sCollectors.NewIngressCollector(),
 			k8sCollectors.NewJobCollector(),
+			k8sCollectors.NewLimitRangeCollector(),
+			k8sCollectors.NewNamespaceCollector(),
+			k8sCollectors.NewNetworkPolicyCollector(),
 			k8sCollectors.NewNodeCollector(),
 			k8sCollectors.NewPersistentVolumeCollector(),
 			k8sCollectors.NewPersistentVolumeClaimCollector(),
 			k8sCollectors.NewReplicaSetCollector(),
+			k8sCollectors.NewResourceQuotaCollector(),
 			k8sCollectors.NewRoleCollector(),
 			k8sCollectors.NewRoleBindingCollector(),
 			k8sCollectors.NewServiceCollector(),
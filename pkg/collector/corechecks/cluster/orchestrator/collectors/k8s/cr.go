@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver && orchestrator
+// +build kubeapiserver,orchestrator
+
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/collectors"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/processors"
+	k8sProcessors "github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/processors/k8s"
+	"github.com/DataDog/datadog-agent/pkg/orchestrator"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CRCollector is a generic collector for Kubernetes resources watched through
+// the dynamic client, typically custom resources.
+type CRCollector struct {
+	gvr       schema.GroupVersionResource
+	maxItems  int
+	discovery discovery.DiscoveryInterface
+	informer  informers.GenericInformer
+	lister    cache.GenericLister
+	metadata  *collectors.CollectorMetadata
+	processor *processors.Processor
+}
+
+// NewCRCollector creates a new collector for the resource identified by the
+// given GroupVersionResource. At most maxItems resources are sent per run.
+func NewCRCollector(gvr schema.GroupVersionResource, maxItems int) *CRCollector {
+	return &CRCollector{
+		gvr:      gvr,
+		maxItems: maxItems,
+		metadata: &collectors.CollectorMetadata{
+			IsStable: false,
+			Name:     CRCollectorName(gvr),
+			NodeType: orchestrator.K8sCR,
+		},
+		processor: processors.NewProcessor(new(k8sProcessors.CRHandlers)),
+	}
+}
+
+// CRCollectorName returns the collector name for a GroupVersionResource,
+// formatted as <group>/<version>/<resource>.
+func CRCollectorName(gvr schema.GroupVersionResource) string {
+	return strings.TrimPrefix(fmt.Sprintf("%s/%s/%s", gvr.Group, gvr.Version, gvr.Resource), "/")
+}
+
+// Informer returns the shared informer.
+func (c *CRCollector) Informer() cache.SharedInformer {
+	return c.informer.Informer()
+}
+
+// Init is used to initialize the collector.
+func (c *CRCollector) Init(rcfg *collectors.CollectorRunConfig) {
+	c.discovery = rcfg.APIClient.DiscoveryCl
+	factory, err := rcfg.APIClient.DynamicInformerFactory()
+	if err != nil {
+		log.Warnf("Cannot build the dynamic informer factory for %s: %v", c.metadata.Name, err)
+		return
+	}
+	c.informer = factory.ForResource(c.gvr)
+	c.lister = c.informer.Lister()
+}
+
+// IsAvailable returns whether the collector is available, i.e. whether the
+// resource is served by the API server.
+func (c *CRCollector) IsAvailable() bool {
+	if c.informer == nil {
+		log.Warnf("No dynamic informer factory available, cannot collect %s", c.metadata.Name)
+		return false
+	}
+
+	if c.discovery == nil {
+		return true
+	}
+
+	resources, err := c.discovery.ServerResourcesForGroupVersion(c.gvr.GroupVersion().String())
+	if err != nil {
+		log.Warnf("Cannot discover resources for group version %s: %v", c.gvr.GroupVersion().String(), err)
+		return false
+	}
+
+	for _, r := range resources.APIResources {
+		if r.Name == c.gvr.Resource {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Metadata is used to access information about the collector.
+func (c *CRCollector) Metadata() *collectors.CollectorMetadata {
+	return c.metadata
+}
+
+// Run triggers the collection process.
+func (c *CRCollector) Run(rcfg *collectors.CollectorRunConfig) (*collectors.CollectorRunResult, error) {
+	list, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, collectors.NewListingError(err)
+	}
+
+	listed := len(list)
+	if c.maxItems > 0 && listed > c.maxItems {
+		log.Warnf("Collector %s listed %d resources, only the first %d will be sent", c.metadata.Name, listed, c.maxItems)
+		// the lister order is random, sort the resources so that the same
+		// ones are sent from one run to the next
+		sortByNamespaceAndName(list)
+		list = list[:c.maxItems]
+	}
+
+	ctx := &processors.ProcessorContext{
+		APIClient:  rcfg.APIClient,
+		Cfg:        rcfg.Config,
+		ClusterID:  rcfg.ClusterID,
+		MsgGroupID: atomic.AddInt32(rcfg.MsgGroupRef, 1),
+		NodeType:   c.metadata.NodeType,
+	}
+
+	messages, processed := c.processor.Process(ctx, list)
+
+	if processed == -1 {
+		return nil, collectors.ErrProcessingPanic
+	}
+
+	result := &collectors.CollectorRunResult{
+		Messages:           messages,
+		ResourcesListed:    listed,
+		ResourcesProcessed: processed,
+	}
+
+	return result, nil
+}
+
+// sortByNamespaceAndName sorts the resources by namespace, then by name.
+func sortByNamespaceAndName(list []runtime.Object) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return namespacedName(list[i]) < namespacedName(list[j])
+	})
+}
+
+func namespacedName(obj runtime.Object) string {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return m.GetNamespace() + "/" + m.GetName()
+}
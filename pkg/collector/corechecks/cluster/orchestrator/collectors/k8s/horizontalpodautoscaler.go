@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver && orchestrator
+// +build kubeapiserver,orchestrator
+
+package k8s
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/collectors"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/processors"
+	k8sProcessors "github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/processors/k8s"
+	"github.com/DataDog/datadog-agent/pkg/orchestrator"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/retry"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	autoscalingv2beta2Informers "k8s.io/client-go/informers/autoscaling/v2beta2"
+	autoscalingv2beta2Listers "k8s.io/client-go/listers/autoscaling/v2beta2"
+	"k8s.io/client-go/tools/cache"
+)
+
+// HorizontalPodAutoscalerCollector is a collector for Kubernetes HorizontalPodAutoscalers.
+type HorizontalPodAutoscalerCollector struct {
+	informer    autoscalingv2beta2Informers.HorizontalPodAutoscalerInformer
+	lister      autoscalingv2beta2Listers.HorizontalPodAutoscalerLister
+	metadata    *collectors.CollectorMetadata
+	processor   *processors.Processor
+	retryLister func(ctx context.Context, opts metav1.ListOptions) (*autoscalingv2beta2.HorizontalPodAutoscalerList, error)
+}
+
+// NewHorizontalPodAutoscalerCollector creates a new collector for the Kubernetes HorizontalPodAutoscaler
+// resource.
+func NewHorizontalPodAutoscalerCollector() *HorizontalPodAutoscalerCollector {
+	return &HorizontalPodAutoscalerCollector{
+		metadata: &collectors.CollectorMetadata{
+			IsStable: false,
+			Name:     "horizontalpodautoscalers",
+			NodeType: orchestrator.K8sHorizontalPodAutoscaler,
+		},
+		processor: processors.NewProcessor(new(k8sProcessors.HorizontalPodAutoscalerHandlers)),
+	}
+}
+
+// Informer returns the shared informer.
+func (c *HorizontalPodAutoscalerCollector) Informer() cache.SharedInformer {
+	return c.informer.Informer()
+}
+
+// Init is used to initialize the collector.
+func (c *HorizontalPodAutoscalerCollector) Init(rcfg *collectors.CollectorRunConfig) {
+	c.informer = rcfg.APIClient.InformerFactory.Autoscaling().V2beta2().HorizontalPodAutoscalers()
+	c.lister = c.informer.Lister()
+	c.retryLister = rcfg.APIClient.Cl.AutoscalingV2beta2().HorizontalPodAutoscalers("").List
+}
+
+// IsAvailable returns whether the collector is available.
+// Returns false if the autoscaling/v2beta2 API version is not available (kubernetes < 1.12).
+func (c *HorizontalPodAutoscalerCollector) IsAvailable() bool {
+	var retrier retry.Retrier
+	if err := retrier.SetupRetrier(&retry.Config{
+		Name:          "AutoscalingV2beta2Discovery",
+		AttemptMethod: c.list,
+		Strategy:      retry.RetryCount,
+		RetryCount:    3,               // try 3 times
+		RetryDelay:    1 * time.Second, // with 1 sec interval
+	}); err != nil {
+		log.Errorf("Couldn't setup api retrier: %w", err)
+		return false
+	}
+
+	return try(&retrier, "autoscaling/v2beta2") == nil
+}
+
+// Metadata is used to access information about the collector.
+func (c *HorizontalPodAutoscalerCollector) Metadata() *collectors.CollectorMetadata {
+	return c.metadata
+}
+
+// Run triggers the collection process.
+func (c *HorizontalPodAutoscalerCollector) Run(rcfg *collectors.CollectorRunConfig) (*collectors.CollectorRunResult, error) {
+	list, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, collectors.NewListingError(err)
+	}
+
+	ctx := &processors.ProcessorContext{
+		APIClient:  rcfg.APIClient,
+		Cfg:        rcfg.Config,
+		ClusterID:  rcfg.ClusterID,
+		MsgGroupID: atomic.AddInt32(rcfg.MsgGroupRef, 1),
+		NodeType:   c.metadata.NodeType,
+	}
+
+	messages, processed := c.processor.Process(ctx, list)
+
+	if processed == -1 {
+		return nil, collectors.ErrProcessingPanic
+	}
+
+	result := &collectors.CollectorRunResult{
+		Messages:           messages,
+		ResourcesListed:    len(list),
+		ResourcesProcessed: processed,
+	}
+
+	return result, nil
+}
+
+func (c *HorizontalPodAutoscalerCollector) list() error {
+	_, err := c.retryLister(context.TODO(), metav1.ListOptions{})
+	return err
+}
@@ -47,6 +47,25 @@ type OrchestratorInstance struct {
 	//   - services
 	Collectors              []string `yaml:"collectors"`
 	ExtraSyncTimeoutSeconds int      `yaml:"extra_sync_timeout_seconds"`
+	// CustomResources defines the resources collected through the dynamic
+	// client, typically custom resources managed by operators.
+	// Example: Collect at most 500 DatadogMetrics per run.
+	// custom_resources:
+	//   - group: datadoghq.com
+	//     version: v1alpha1
+	//     resource: datadogmetrics
+	//     max_items: 500
+	CustomResources []CustomResourceInstance `yaml:"custom_resources"`
+}
+
+// CustomResourceInstance is the config of a custom resource collector.
+type CustomResourceInstance struct {
+	Group    string `yaml:"group"`
+	Version  string `yaml:"version"`
+	Resource string `yaml:"resource"`
+	// MaxItems is the maximum number of resources sent per check run.
+	// Defaults to 1000 when unset.
+	MaxItems int `yaml:"max_items"`
 }
 
 func (c *OrchestratorInstance) parse(data []byte) error {
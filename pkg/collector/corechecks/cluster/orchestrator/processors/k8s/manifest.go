@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build orchestrator
+// +build orchestrator
+
+package k8s
+
+import (
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/processors"
+)
+
+// buildManifestMessageBody builds a manifest message body out of a list of
+// extracted manifests. It is used by resources which don't have a dedicated
+// protobuf model and are sent as raw manifests.
+func buildManifestMessageBody(ctx *processors.ProcessorContext, resourceModels []interface{}, groupSize int) model.MessageBody {
+	models := make([]*model.Manifest, 0, len(resourceModels))
+
+	for _, m := range resourceModels {
+		models = append(models, m.(*model.Manifest))
+	}
+
+	return &model.CollectorManifest{
+		ClusterName: ctx.Cfg.KubeClusterName,
+		ClusterId:   ctx.ClusterID,
+		GroupId:     ctx.MsgGroupID,
+		GroupSize:   int32(groupSize),
+		Manifests:   models,
+	}
+}
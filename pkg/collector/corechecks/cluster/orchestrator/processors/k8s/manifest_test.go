@@ -0,0 +1,208 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build orchestrator
+// +build orchestrator
+
+package k8s
+
+import (
+	"encoding/json"
+	"testing"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator/processors"
+	"github.com/DataDog/datadog-agent/pkg/orchestrator"
+	"github.com/DataDog/datadog-agent/pkg/orchestrator/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newManifestProcessorContext(nodeType orchestrator.NodeType, maxPerMessage int) *processors.ProcessorContext {
+	cfg := config.NewDefaultOrchestratorConfig()
+	cfg.KubeClusterName = "cluster"
+	cfg.MaxPerMessage = maxPerMessage
+
+	return &processors.ProcessorContext{
+		Cfg:        cfg,
+		ClusterID:  "cluster-id",
+		MsgGroupID: 1,
+		NodeType:   nodeType,
+	}
+}
+
+// redactedAnnotations are the annotations of newObjectMeta once the last
+// applied configuration is scrubbed.
+var redactedAnnotations = map[string]string{
+	"kubectl.kubernetes.io/last-applied-configuration": "-",
+	"annotation": "value",
+}
+
+func newObjectMeta(name, uid string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:            name,
+		Namespace:       "namespace",
+		ResourceVersion: "1234",
+		UID:             types.UID(uid),
+		Annotations: map[string]string{
+			"kubectl.kubernetes.io/last-applied-configuration": `{"kind":"secret"}`,
+			"annotation": "value",
+		},
+	}
+}
+
+// processManifests runs a processor over a list of resources and returns the
+// manifests of the single message it is expected to build.
+func processManifests(t *testing.T, h processors.Handlers, nodeType orchestrator.NodeType, list interface{}) []*model.Manifest {
+	messages, processed := processors.NewProcessor(h).Process(newManifestProcessorContext(nodeType, 100), list)
+	require.Equal(t, 1, processed)
+	require.Len(t, messages, 1)
+
+	collectorManifest, ok := messages[0].(*model.CollectorManifest)
+	require.True(t, ok)
+	assert.Equal(t, "cluster", collectorManifest.ClusterName)
+	assert.Equal(t, "cluster-id", collectorManifest.ClusterId)
+	assert.Equal(t, int32(1), collectorManifest.GroupId)
+	assert.Equal(t, int32(1), collectorManifest.GroupSize)
+	require.Len(t, collectorManifest.Manifests, 1)
+
+	return collectorManifest.Manifests
+}
+
+func TestHorizontalPodAutoscalerManifest(t *testing.T) {
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: newObjectMeta("hpa", "hpa-uid"),
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "web",
+			},
+			MaxReplicas: 10,
+		},
+		Status: autoscalingv2beta2.HorizontalPodAutoscalerStatus{
+			CurrentReplicas: 3,
+			DesiredReplicas: 4,
+		},
+	}
+
+	manifests := processManifests(t, new(HorizontalPodAutoscalerHandlers), orchestrator.K8sHorizontalPodAutoscaler, []*autoscalingv2beta2.HorizontalPodAutoscaler{hpa})
+	assert.Equal(t, "autoscaling/v2beta2/HorizontalPodAutoscaler", manifests[0].Type)
+	assert.Equal(t, "hpa-uid", manifests[0].Uid)
+	assert.Equal(t, "1234", manifests[0].Version)
+
+	var content autoscalingv2beta2.HorizontalPodAutoscaler
+	require.NoError(t, json.Unmarshal(manifests[0].Content, &content))
+	assert.Equal(t, hpa.Spec.ScaleTargetRef, content.Spec.ScaleTargetRef)
+	assert.Equal(t, int32(10), content.Spec.MaxReplicas)
+	assert.Equal(t, int32(3), content.Status.CurrentReplicas)
+	assert.Equal(t, int32(4), content.Status.DesiredReplicas)
+	assert.Equal(t, redactedAnnotations, content.Annotations)
+}
+
+func TestLimitRangeManifest(t *testing.T) {
+	lr := &corev1.LimitRange{
+		ObjectMeta: newObjectMeta("limitrange", "limitrange-uid"),
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{Type: corev1.LimitTypeContainer},
+				{Type: corev1.LimitTypePod},
+			},
+		},
+	}
+
+	manifests := processManifests(t, new(LimitRangeHandlers), orchestrator.K8sLimitRange, []*corev1.LimitRange{lr})
+	assert.Equal(t, "v1/LimitRange", manifests[0].Type)
+	assert.Equal(t, "limitrange-uid", manifests[0].Uid)
+
+	var content corev1.LimitRange
+	require.NoError(t, json.Unmarshal(manifests[0].Content, &content))
+	require.Len(t, content.Spec.Limits, 2)
+	assert.Equal(t, corev1.LimitTypeContainer, content.Spec.Limits[0].Type)
+	assert.Equal(t, corev1.LimitTypePod, content.Spec.Limits[1].Type)
+	assert.Equal(t, redactedAnnotations, content.Annotations)
+}
+
+func TestNamespaceManifest(t *testing.T) {
+	ns := &corev1.Namespace{
+		ObjectMeta: newObjectMeta("namespace", "namespace-uid"),
+		Spec: corev1.NamespaceSpec{
+			Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes},
+		},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespaceTerminating,
+		},
+	}
+
+	manifests := processManifests(t, new(NamespaceHandlers), orchestrator.K8sNamespace, []*corev1.Namespace{ns})
+	assert.Equal(t, "v1/Namespace", manifests[0].Type)
+	assert.Equal(t, "namespace-uid", manifests[0].Uid)
+
+	var content corev1.Namespace
+	require.NoError(t, json.Unmarshal(manifests[0].Content, &content))
+	assert.Equal(t, []corev1.FinalizerName{corev1.FinalizerKubernetes}, content.Spec.Finalizers)
+	assert.Equal(t, corev1.NamespaceTerminating, content.Status.Phase)
+	assert.Equal(t, redactedAnnotations, content.Annotations)
+}
+
+func TestNetworkPolicyManifest(t *testing.T) {
+	np := &netv1.NetworkPolicy{
+		ObjectMeta: newObjectMeta("networkpolicy", "networkpolicy-uid"),
+		Spec: netv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			PolicyTypes: []netv1.PolicyType{netv1.PolicyTypeIngress, netv1.PolicyTypeEgress},
+		},
+	}
+
+	manifests := processManifests(t, new(NetworkPolicyHandlers), orchestrator.K8sNetworkPolicy, []*netv1.NetworkPolicy{np})
+	assert.Equal(t, "networking.k8s.io/v1/NetworkPolicy", manifests[0].Type)
+	assert.Equal(t, "networkpolicy-uid", manifests[0].Uid)
+
+	var content netv1.NetworkPolicy
+	require.NoError(t, json.Unmarshal(manifests[0].Content, &content))
+	assert.Equal(t, map[string]string{"app": "web"}, content.Spec.PodSelector.MatchLabels)
+	assert.Equal(t, []netv1.PolicyType{netv1.PolicyTypeIngress, netv1.PolicyTypeEgress}, content.Spec.PolicyTypes)
+	assert.Equal(t, redactedAnnotations, content.Annotations)
+}
+
+func TestResourceQuotaManifest(t *testing.T) {
+	rq := &corev1.ResourceQuota{
+		ObjectMeta: newObjectMeta("resourcequota", "resourcequota-uid"),
+		Spec: corev1.ResourceQuotaSpec{
+			Scopes: []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort},
+		},
+	}
+
+	manifests := processManifests(t, new(ResourceQuotaHandlers), orchestrator.K8sResourceQuota, []*corev1.ResourceQuota{rq})
+	assert.Equal(t, "v1/ResourceQuota", manifests[0].Type)
+	assert.Equal(t, "resourcequota-uid", manifests[0].Uid)
+
+	var content corev1.ResourceQuota
+	require.NoError(t, json.Unmarshal(manifests[0].Content, &content))
+	assert.Equal(t, []corev1.ResourceQuotaScope{corev1.ResourceQuotaScopeBestEffort}, content.Spec.Scopes)
+	assert.Equal(t, redactedAnnotations, content.Annotations)
+}
+
+func TestBuildManifestMessageBody(t *testing.T) {
+	ctx := newManifestProcessorContext(orchestrator.K8sHorizontalPodAutoscaler, 1)
+	first := &model.Manifest{Uid: "1"}
+	second := &model.Manifest{Uid: "2"}
+
+	body := new(HorizontalPodAutoscalerHandlers).BuildMessageBody(ctx, []interface{}{first, second}, 3)
+
+	assert.Equal(t, &model.CollectorManifest{
+		ClusterName: "cluster",
+		ClusterId:   "cluster-id",
+		GroupId:     1,
+		GroupSize:   3,
+		Manifests:   []*model.Manifest{first, second},
+	}, body)
+}
@@ -49,6 +49,18 @@ func extractMetadata(m *metav1.ObjectMeta) *model.Metadata {
 	return &meta
 }
 
+// extractManifest builds the protobuf manifest model of a resource which
+// doesn't have a dedicated model. The content is set after marshalling.
+func extractManifest(apiVersion, kind string, m *metav1.ObjectMeta) *model.Manifest {
+	return &model.Manifest{
+		Orchestrator: "k8s",
+		Type:         apiVersion + "/" + kind,
+		Uid:          string(m.UID),
+		ContentType:  "json",
+		Version:      m.ResourceVersion,
+	}
+}
+
 func extractLabelSelector(ls *metav1.LabelSelector) []*model.LabelSelectorRequirement {
 	labelSelectors := make([]*model.LabelSelectorRequirement, 0, len(ls.MatchLabels)+len(ls.MatchExpressions))
 	for k, v := range ls.MatchLabels {
@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build orchestrator
+// +build orchestrator
+
+package k8s
+
+import (
+	"fmt"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ExtractCR returns the protobuf manifest model corresponding to a Kubernetes
+// custom resource. The manifest content is set after marshalling.
+func ExtractCR(cr *unstructured.Unstructured) *model.Manifest {
+	return &model.Manifest{
+		Orchestrator: "k8s",
+		Type:         fmt.Sprintf("%s/%s", cr.GetAPIVersion(), cr.GetKind()),
+		Uid:          string(cr.GetUID()),
+		ContentType:  "json",
+		Version:      cr.GetResourceVersion(),
+	}
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build orchestrator
+// +build orchestrator
+
+package k8s
+
+import (
+	"testing"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExtractCR(t *testing.T) {
+	tests := map[string]struct {
+		input    unstructured.Unstructured
+		expected model.Manifest
+	}{
+		"namespaced custom resource": {
+			input: unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "datadoghq.com/v1alpha1",
+					"kind":       "DatadogMetric",
+					"metadata": map[string]interface{}{
+						"name":            "metric",
+						"namespace":       "default",
+						"uid":             "e42e5adc-0749-11e8-a2b8-000c29dea4f6",
+						"resourceVersion": "1234",
+					},
+					"spec": map[string]interface{}{
+						"query": "avg:foo{*}",
+					},
+				},
+			},
+			expected: model.Manifest{
+				Orchestrator: "k8s",
+				Type:         "datadoghq.com/v1alpha1/DatadogMetric",
+				Uid:          "e42e5adc-0749-11e8-a2b8-000c29dea4f6",
+				ContentType:  "json",
+				Version:      "1234",
+			},
+		},
+		"core group resource": {
+			input: unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+					"metadata": map[string]interface{}{
+						"name": "cm",
+						"uid":  "f42e5adc-0749-11e8-a2b8-000c29dea4f6",
+					},
+				},
+			},
+			expected: model.Manifest{
+				Orchestrator: "k8s",
+				Type:         "v1/ConfigMap",
+				Uid:          "f42e5adc-0749-11e8-a2b8-000c29dea4f6",
+				ContentType:  "json",
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, &tc.expected, ExtractCR(&tc.input))
+		})
+	}
+}
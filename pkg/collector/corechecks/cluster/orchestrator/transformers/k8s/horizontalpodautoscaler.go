@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build orchestrator
+// +build orchestrator
+
+package k8s
+
+import (
+	model "github.com/DataDog/agent-payload/v5/process"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+)
+
+// ExtractHorizontalPodAutoscaler returns the protobuf manifest model corresponding to a Kubernetes
+// HorizontalPodAutoscaler resource. The manifest content is set after marshalling.
+func ExtractHorizontalPodAutoscaler(r *autoscalingv2beta2.HorizontalPodAutoscaler) *model.Manifest {
+	return extractManifest("autoscaling/v2beta2", "HorizontalPodAutoscaler", &r.ObjectMeta)
+}
@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build orchestrator
+// +build orchestrator
+
+package k8s
+
+import (
+	model "github.com/DataDog/agent-payload/v5/process"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ExtractLimitRange returns the protobuf manifest model corresponding to a Kubernetes
+// LimitRange resource. The manifest content is set after marshalling.
+func ExtractLimitRange(r *corev1.LimitRange) *model.Manifest {
+	return extractManifest("v1", "LimitRange", &r.ObjectMeta)
+}
@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build orchestrator
+// +build orchestrator
+
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestExtractNetworkPolicy(t *testing.T) {
+	tests := map[string]struct {
+		input           netv1.NetworkPolicy
+		expectedUID     string
+		expectedVersion string
+	}{
+		"standard": {
+			input: netv1.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "networkpolicy",
+					Namespace:       "namespace",
+					ResourceVersion: "1234",
+					UID:             types.UID("e42e5adc-0749-11e8-a2b8-000c29dea4f6"),
+				},
+			},
+			expectedUID:     "e42e5adc-0749-11e8-a2b8-000c29dea4f6",
+			expectedVersion: "1234",
+		},
+		"empty": {
+			input: netv1.NetworkPolicy{},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := ExtractNetworkPolicy(&tc.input)
+			assert.Equal(t, "k8s", m.Orchestrator)
+			assert.Equal(t, "networking.k8s.io/v1/NetworkPolicy", m.Type)
+			assert.Equal(t, tc.expectedUID, m.Uid)
+			assert.Equal(t, tc.expectedVersion, m.Version)
+			assert.Equal(t, "json", m.ContentType)
+			// the content is only set by the processor once the resource is marshalled
+			assert.Empty(t, m.Content)
+		})
+	}
+}
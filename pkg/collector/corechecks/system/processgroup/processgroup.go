@@ -0,0 +1,326 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processgroup
+
+import (
+	"fmt"
+	"os/user"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/v2/metrics/provider"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	processGroupCheckName = "process_group"
+
+	upServiceCheck = "process_group.up"
+
+	containerCacheValidity = time.Minute
+)
+
+// processGroupInstanceConfig selects the processes of a group, a process has
+// to match all the selectors which are set
+type processGroupInstanceConfig struct {
+	Name         string   `yaml:"name"`
+	NameRegex    string   `yaml:"name_regex"`
+	CmdlineRegex string   `yaml:"cmdline_regex"`
+	User         string   `yaml:"user"`
+	ContainerIDs []string `yaml:"container_ids"`
+}
+
+// groupStats are the stats aggregated over the processes of a group
+type groupStats struct {
+	count                  int
+	cpuUserPct             float64
+	cpuSysPct              float64
+	rss                    uint64
+	vms                    uint64
+	openFDs                int64
+	threads                int64
+	readCount              float64
+	writeCount             float64
+	readBytes              float64
+	writeBytes             float64
+	voluntaryCtxSwitches   float64
+	involuntaryCtxSwitches float64
+}
+
+// Check aggregates the stats of a group of processes
+type Check struct {
+	core.CheckBase
+	config processGroupInstanceConfig
+
+	nameRegex    *regexp.Regexp
+	cmdlineRegex *regexp.Regexp
+
+	probe             procutil.Probe
+	containerIDForPID func(pid int32) (string, error)
+	lookupUser        func(uid string) (string, error)
+	lastProcs         map[int32]*procutil.Stats
+	lastRun           time.Time
+	usernamesByUID    map[int32]string
+}
+
+// Configure parses the check configuration and initializes the check
+func (c *Check) Configure(rawInstance integration.Data, rawInitConfig integration.Data, source string) error {
+	// Make sure check id is different for each different config
+	// Must be called before CommonConfigure that uses checkID
+	c.BuildID(rawInstance, rawInitConfig)
+
+	if err := c.CommonConfigure(rawInstance, source); err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(rawInstance, &c.config); err != nil {
+		return err
+	}
+
+	if c.config.Name == "" {
+		return fmt.Errorf("instance config `name` must not be empty")
+	}
+	if c.config.NameRegex == "" && c.config.CmdlineRegex == "" && c.config.User == "" && len(c.config.ContainerIDs) == 0 {
+		return fmt.Errorf("instance config must set at least one of `name_regex`, `cmdline_regex`, `user` or `container_ids`")
+	}
+
+	var err error
+	if c.config.NameRegex != "" {
+		if c.nameRegex, err = regexp.Compile(c.config.NameRegex); err != nil {
+			return fmt.Errorf("invalid `name_regex`: %s", err)
+		}
+	}
+	if c.config.CmdlineRegex != "" {
+		if c.cmdlineRegex, err = regexp.Compile(c.config.CmdlineRegex); err != nil {
+			return fmt.Errorf("invalid `cmdline_regex`: %s", err)
+		}
+	}
+
+	if c.probe == nil {
+		c.probe = procutil.NewProcessProbe(procutil.WithPermission(true))
+	}
+	return nil
+}
+
+// Run executes the check
+func (c *Check) Run() error {
+	sender, err := c.GetSender()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	procs, err := c.probe.ProcessesByPID(now, true)
+	if err != nil {
+		return fmt.Errorf("could not list the processes: %s", err)
+	}
+
+	c.usernamesByUID = make(map[int32]string)
+	stats := groupStats{}
+	current := make(map[int32]*procutil.Stats)
+	// the rates are computed from the stats of the previous run
+	firstRun := c.lastRun.IsZero()
+	elapsed := now.Sub(c.lastRun).Seconds()
+	for pid, proc := range procs {
+		if proc.Stats == nil || !c.matches(proc) {
+			continue
+		}
+		current[pid] = proc.Stats
+		stats.count++
+		stats.add(proc.Stats)
+
+		last, ok := c.lastProcs[pid]
+		if ok && last.CreateTime == proc.Stats.CreateTime && elapsed > 0 {
+			stats.addRates(proc.Stats, last, elapsed)
+		}
+	}
+	c.lastProcs = current
+	c.lastRun = now
+
+	tags := []string{"process_group:" + c.config.Name}
+	sender.Gauge("process_group.count", float64(stats.count), "", tags)
+	sender.Gauge("process_group.mem.rss", float64(stats.rss), "", tags)
+	sender.Gauge("process_group.mem.vms", float64(stats.vms), "", tags)
+	sender.Gauge("process_group.open_file_descriptors", float64(stats.openFDs), "", tags)
+	sender.Gauge("process_group.threads", float64(stats.threads), "", tags)
+	if !firstRun {
+		sender.Gauge("process_group.cpu.pct", stats.cpuUserPct+stats.cpuSysPct, "", tags)
+		sender.Gauge("process_group.cpu.user_pct", stats.cpuUserPct, "", tags)
+		sender.Gauge("process_group.cpu.system_pct", stats.cpuSysPct, "", tags)
+		sender.Gauge("process_group.io.read_count", stats.readCount, "", tags)
+		sender.Gauge("process_group.io.write_count", stats.writeCount, "", tags)
+		sender.Gauge("process_group.io.read_bytes", stats.readBytes, "", tags)
+		sender.Gauge("process_group.io.write_bytes", stats.writeBytes, "", tags)
+		sender.Gauge("process_group.ctx_switches.voluntary", stats.voluntaryCtxSwitches, "", tags)
+		sender.Gauge("process_group.ctx_switches.involuntary", stats.involuntaryCtxSwitches, "", tags)
+	}
+
+	if stats.count > 0 {
+		sender.ServiceCheck(upServiceCheck, metrics.ServiceCheckOK, "", tags, "")
+	} else {
+		sender.ServiceCheck(upServiceCheck, metrics.ServiceCheckCritical, "", tags, "No process matched")
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// Cancel releases the process probe when the check is unscheduled
+func (c *Check) Cancel() {
+	if c.probe != nil {
+		c.probe.Close()
+	}
+	c.CommonCancel()
+}
+
+// add adds the stats of a process which don't depend on its previous stats
+func (s *groupStats) add(stats *procutil.Stats) {
+	if stats.MemInfo != nil {
+		s.rss += stats.MemInfo.RSS
+		s.vms += stats.MemInfo.VMS
+	}
+	// -1 means the agent has no permission to read the file descriptors
+	if stats.OpenFdCount > 0 {
+		s.openFDs += int64(stats.OpenFdCount)
+	}
+	s.threads += int64(stats.NumThreads)
+}
+
+// addRates adds the per-second rates of a process computed from its previous
+// stats. The CPU percentages are relative to a single core.
+func (s *groupStats) addRates(stats, last *procutil.Stats, elapsed float64) {
+	if stats.CPUTime != nil && last.CPUTime != nil {
+		s.cpuUserPct += positiveRate(stats.CPUTime.User, last.CPUTime.User, elapsed) * 100
+		s.cpuSysPct += positiveRate(stats.CPUTime.System, last.CPUTime.System, elapsed) * 100
+	}
+	// -1 counters mean the agent has no permission to read them
+	if io, lastIO := stats.IOStat, last.IOStat; io != nil && lastIO != nil {
+		if io.ReadCount >= 0 && lastIO.ReadCount >= 0 {
+			s.readCount += positiveRate(float64(io.ReadCount), float64(lastIO.ReadCount), elapsed)
+		}
+		if io.WriteCount >= 0 && lastIO.WriteCount >= 0 {
+			s.writeCount += positiveRate(float64(io.WriteCount), float64(lastIO.WriteCount), elapsed)
+		}
+		if io.ReadBytes >= 0 && lastIO.ReadBytes >= 0 {
+			s.readBytes += positiveRate(float64(io.ReadBytes), float64(lastIO.ReadBytes), elapsed)
+		}
+		if io.WriteBytes >= 0 && lastIO.WriteBytes >= 0 {
+			s.writeBytes += positiveRate(float64(io.WriteBytes), float64(lastIO.WriteBytes), elapsed)
+		}
+	}
+	if stats.CtxSwitches != nil && last.CtxSwitches != nil {
+		s.voluntaryCtxSwitches += positiveRate(float64(stats.CtxSwitches.Voluntary), float64(last.CtxSwitches.Voluntary), elapsed)
+		s.involuntaryCtxSwitches += positiveRate(float64(stats.CtxSwitches.Involuntary), float64(last.CtxSwitches.Involuntary), elapsed)
+	}
+}
+
+func positiveRate(current, last, elapsed float64) float64 {
+	if current < last {
+		return 0
+	}
+	return (current - last) / elapsed
+}
+
+// matches returns whether a process belongs to the group
+func (c *Check) matches(proc *procutil.Process) bool {
+	if c.nameRegex != nil && !c.nameRegex.MatchString(proc.Name) {
+		return false
+	}
+	if c.cmdlineRegex != nil && !c.cmdlineRegex.MatchString(strings.Join(proc.Cmdline, " ")) {
+		return false
+	}
+	if c.config.User != "" && !c.matchesUser(proc) {
+		return false
+	}
+	if len(c.config.ContainerIDs) > 0 && !c.matchesContainer(proc) {
+		return false
+	}
+	return true
+}
+
+// matchesUser returns whether the process runs as the configured user, by name
+// or UID
+func (c *Check) matchesUser(proc *procutil.Process) bool {
+	// the username is only set on Windows
+	if proc.Username != "" {
+		return strings.EqualFold(proc.Username, c.config.User)
+	}
+	if len(proc.Uids) == 0 {
+		return false
+	}
+
+	uid := proc.Uids[0]
+	if strconv.Itoa(int(uid)) == c.config.User {
+		return true
+	}
+	name, ok := c.usernamesByUID[uid]
+	if !ok {
+		lookupUser := c.lookupUser
+		if lookupUser == nil {
+			lookupUser = lookupUsername
+		}
+		var err error
+		if name, err = lookupUser(strconv.Itoa(int(uid))); err != nil {
+			log.Debugf("Could not look up the user %d: %s", uid, err)
+		}
+		c.usernamesByUID[uid] = name
+	}
+	return name == c.config.User
+}
+
+// matchesContainer returns whether the process runs in one of the configured
+// containers, which can be set by ID prefix
+func (c *Check) matchesContainer(proc *procutil.Process) bool {
+	containerIDForPID := c.containerIDForPID
+	if containerIDForPID == nil {
+		containerIDForPID = metaContainerIDForPID
+	}
+
+	containerID, err := containerIDForPID(proc.Pid)
+	if err != nil {
+		log.Debugf("Could not get the container of process %d: %s", proc.Pid, err)
+		return false
+	}
+	if containerID == "" {
+		return false
+	}
+	for _, id := range c.config.ContainerIDs {
+		if strings.HasPrefix(containerID, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupUsername(uid string) (string, error) {
+	u, err := user.LookupId(uid)
+	if err != nil {
+		return "", err
+	}
+	return u.Username, nil
+}
+
+func metaContainerIDForPID(pid int32) (string, error) {
+	return provider.GetProvider().GetMetaCollector().GetContainerIDForPID(int(pid), containerCacheValidity)
+}
+
+func processGroupFactory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(processGroupCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(processGroupCheckName, processGroupFactory)
+}
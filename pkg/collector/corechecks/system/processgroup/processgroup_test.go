@@ -0,0 +1,159 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processgroup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+type fakeProbe struct {
+	procs map[int32]*procutil.Process
+}
+
+func (p *fakeProbe) Close() {}
+
+func (p *fakeProbe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*procutil.Stats, error) {
+	return nil, nil
+}
+
+func (p *fakeProbe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*procutil.Process, error) {
+	return p.procs, nil
+}
+
+func (p *fakeProbe) StatsWithPermByPID(pids []int32) (map[int32]*procutil.StatsWithPerm, error) {
+	return nil, nil
+}
+
+func makeProcess(pid int32, name string, uid int32, cpuUser, cpuSystem float64, readBytes int64, rss uint64) *procutil.Process {
+	return &procutil.Process{
+		Pid:     pid,
+		Name:    name,
+		Cmdline: []string{"/usr/sbin/" + name, "-c", "/etc/" + name + ".conf"},
+		Uids:    []int32{uid},
+		Stats: &procutil.Stats{
+			CreateTime:  int64(pid) * 1000,
+			OpenFdCount: 10,
+			NumThreads:  2,
+			CPUTime:     &procutil.CPUTimesStat{User: cpuUser, System: cpuSystem},
+			MemInfo:     &procutil.MemoryInfoStat{RSS: rss, VMS: 2 * rss},
+			IOStat:      &procutil.IOCountersStat{ReadCount: 1, WriteCount: 1, ReadBytes: readBytes, WriteBytes: -1},
+			CtxSwitches: &procutil.NumCtxSwitchesStat{Voluntary: 100, Involuntary: 10},
+		},
+	}
+}
+
+func newTestCheck(t *testing.T, probe procutil.Probe, instance string) (*Check, *mocksender.MockSender) {
+	c := processGroupFactory().(*Check)
+	c.probe = probe
+	c.lookupUser = func(uid string) (string, error) {
+		if uid == "33" {
+			return "www-data", nil
+		}
+		return "", errors.New("unknown user")
+	}
+	c.containerIDForPID = func(pid int32) (string, error) {
+		if pid == 3 {
+			return "0123456789abcdef", nil
+		}
+		return "", nil
+	}
+	require.NoError(t, c.Configure([]byte(instance), nil, "test"))
+
+	sender := mocksender.NewMockSender(c.ID())
+	sender.SetupAcceptAll()
+	return c, sender
+}
+
+func TestConfigure(t *testing.T) {
+	for _, instance := range []string{
+		"name_regex: nginx",
+		"name: nginx",
+		"name: nginx\nname_regex: '['",
+		"name: nginx\ncmdline_regex: '('",
+	} {
+		c := processGroupFactory().(*Check)
+		c.probe = &fakeProbe{}
+		assert.Error(t, c.Configure([]byte(instance), nil, "test"), instance)
+	}
+}
+
+func TestProcessGroupCheck(t *testing.T) {
+	probe := &fakeProbe{procs: map[int32]*procutil.Process{
+		1: makeProcess(1, "nginx", 0, 10, 5, 1000, 100),
+		2: makeProcess(2, "nginx", 33, 20, 5, 1000, 200),
+		3: makeProcess(3, "redis", 999, 0, 0, 0, 1000),
+	}}
+	c, sender := newTestCheck(t, probe, "name: web\nname_regex: ^nginx$")
+	tags := []string{"process_group:web"}
+
+	require.NoError(t, c.Run())
+	sender.AssertMetric(t, "Gauge", "process_group.count", 2, "", tags)
+	sender.AssertMetric(t, "Gauge", "process_group.mem.rss", 300, "", tags)
+	sender.AssertMetric(t, "Gauge", "process_group.mem.vms", 600, "", tags)
+	sender.AssertMetric(t, "Gauge", "process_group.open_file_descriptors", 20, "", tags)
+	sender.AssertMetric(t, "Gauge", "process_group.threads", 4, "", tags)
+	sender.AssertServiceCheck(t, upServiceCheck, metrics.ServiceCheckOK, "", tags, "")
+	// the rates need two runs
+	sender.AssertNotCalled(t, "Gauge", "process_group.cpu.pct", mock.Anything, "", mock.Anything)
+
+	// nginx 2 was restarted and is not part of the rates
+	c.lastRun = c.lastRun.Add(-10 * time.Second)
+	probe.procs = map[int32]*procutil.Process{
+		1: makeProcess(1, "nginx", 0, 12, 6, 2000, 100),
+		2: makeProcess(2, "nginx", 33, 30, 10, 5000, 200),
+		3: makeProcess(3, "redis", 999, 0, 0, 0, 1000),
+	}
+	probe.procs[2].Stats.CreateTime = 5000
+	sender.ResetCalls()
+	require.NoError(t, c.Run())
+	sender.AssertMetricInRange(t, "Gauge", "process_group.cpu.pct", 29.9, 30.1, "", tags)
+	sender.AssertMetricInRange(t, "Gauge", "process_group.cpu.user_pct", 19.9, 20.1, "", tags)
+	sender.AssertMetricInRange(t, "Gauge", "process_group.io.read_bytes", 99.9, 100.1, "", tags)
+	sender.AssertMetric(t, "Gauge", "process_group.io.write_bytes", 0, "", tags)
+	sender.AssertMetric(t, "Gauge", "process_group.ctx_switches.voluntary", 0, "", tags)
+}
+
+func TestProcessGroupSelectors(t *testing.T) {
+	probe := &fakeProbe{procs: map[int32]*procutil.Process{
+		1: makeProcess(1, "nginx", 0, 0, 0, 0, 100),
+		2: makeProcess(2, "nginx", 33, 0, 0, 0, 200),
+		3: makeProcess(3, "redis", 999, 0, 0, 0, 1000),
+	}}
+
+	tests := []struct {
+		name     string
+		instance string
+		rss      float64
+	}{
+		{name: "cmdline", instance: "cmdline_regex: redis\\.conf", rss: 1000},
+		{name: "username", instance: "user: www-data", rss: 200},
+		{name: "uid", instance: "user: '0'", rss: 100},
+		{name: "container prefix", instance: "container_ids: [01234]", rss: 1000},
+		{name: "combined", instance: "name_regex: nginx\nuser: www-data", rss: 200},
+		{name: "no match", instance: "name_regex: nginx\ncontainer_ids: [01234]", rss: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, sender := newTestCheck(t, probe, "name: group\n"+tt.instance)
+			require.NoError(t, c.Run())
+			sender.AssertMetric(t, "Gauge", "process_group.mem.rss", tt.rss, "", []string{"process_group:group"})
+		})
+	}
+
+	c, sender := newTestCheck(t, probe, "name: none\nname_regex: postgres")
+	require.NoError(t, c.Run())
+	sender.AssertServiceCheck(t, upServiceCheck, metrics.ServiceCheckCritical, "", []string{"process_group:none"}, "No process matched")
+}
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -90,6 +91,52 @@ func WithHostRootMount(hostRootMount string) BuilderOption {
 	}
 }
 
+// WithRootFilesystem evaluates the rules against the root filesystem at rest
+// mounted at the given path, instead of the running host. Only the rules
+// whose resources can be read from the filesystem are evaluated.
+func WithRootFilesystem(root string) BuilderOption {
+	return func(b *builder) error {
+		fi, err := os.Stat(root)
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			return fmt.Errorf("root filesystem %s is not a directory", root)
+		}
+
+		log.Infof("Rules will be evaluated against the root filesystem at %s", root)
+		b.pathMapper = &pathMapper{
+			hostMountPath:   root,
+			resolveSymlinks: true,
+		}
+		b.etcGroupPath = b.NormalizeToHostRoot("/etc/group")
+		b.atRest = true
+		return nil
+	}
+}
+
+// WithImageTarball evaluates the rules against the root filesystem of the
+// container image exported in the given tarball, in the OCI layout or docker
+// save format. The layers are extracted to a temporary directory removed when
+// the builder is closed.
+func WithImageTarball(tarball string) BuilderOption {
+	return func(b *builder) error {
+		root, err := os.MkdirTemp("", "compliance-image-rootfs")
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Extracting image %s to %s", tarball, root)
+		if err := extractImage(tarball, root); err != nil {
+			os.RemoveAll(root)
+			return err
+		}
+		b.extractedRoot = root
+
+		return WithRootFilesystem(root)(b)
+	}
+}
+
 // WithDocker configures using docker
 func WithDocker() BuilderOption {
 	return func(b *builder) error {
@@ -128,6 +175,25 @@ func WithAuditClient(cli env.AuditClient) BuilderOption {
 	}
 }
 
+// WithSystemd configures using systemd unit checks
+func WithSystemd() BuilderOption {
+	return func(b *builder) error {
+		cli, err := newSystemdClient(b.NormalizeToHostRoot(systemdPrivateSocket))
+		if err == nil {
+			b.systemdClient = cli
+		}
+		return err
+	}
+}
+
+// WithSystemdClient configures using specific systemd client
+func WithSystemdClient(cli env.SystemdClient) BuilderOption {
+	return func(b *builder) error {
+		b.systemdClient = cli
+		return nil
+	}
+}
+
 type kubeClient struct {
 	dynamic.Interface
 	clusterID string
@@ -281,18 +347,21 @@ type builder struct {
 	reporter   event.Reporter
 	valueCache *cache.Cache
 
-	hostname     string
-	pathMapper   *pathMapper
-	etcGroupPath string
-	nodeLabels   map[string]string
+	hostname      string
+	pathMapper    *pathMapper
+	etcGroupPath  string
+	nodeLabels    map[string]string
+	atRest        bool
+	extractedRoot string
 
 	suiteMatcher SuiteMatcher
 	ruleMatcher  RuleMatcher
 
-	dockerClient env.DockerClient
-	auditClient  env.AuditClient
-	kubeClient   *kubeClient
-	isLeaderFunc func() bool
+	dockerClient  env.DockerClient
+	auditClient   env.AuditClient
+	systemdClient env.SystemdClient
+	kubeClient    *kubeClient
+	isLeaderFunc  func() bool
 
 	regoInputOverride map[string]eval.RegoInputMap
 	regoInputDumpPath string
@@ -311,6 +380,16 @@ func (b *builder) Close() error {
 			return err
 		}
 	}
+	if b.systemdClient != nil {
+		if err := b.systemdClient.Close(); err != nil {
+			return err
+		}
+	}
+	if b.extractedRoot != "" {
+		if err := os.RemoveAll(b.extractedRoot); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -444,6 +523,11 @@ func (b *builder) checkFromRule(meta *compliance.SuiteMeta, rule *compliance.Con
 		return nil, ErrRuleDoesNotApply
 	}
 
+	if b.atRest && !appliesAtRest(conditionResources(rule.Resources)) {
+		log.Debugf("rule %s/%s discarded - resources not available at rest", meta.Framework, rule.ID)
+		return nil, ErrRuleDoesNotApply
+	}
+
 	resourceReporter := b.getRuleResourceReporter(ruleScope, *rule)
 	return b.newCheck(meta, ruleScope, rule, resourceReporter)
 }
@@ -467,6 +551,11 @@ func (b *builder) checkFromRegoRule(meta *compliance.SuiteMeta, rule *compliance
 		}
 	}
 
+	if b.atRest && !appliesAtRest(regoInputResources(rule.Inputs)) {
+		log.Debugf("rule %s/%s discarded - resources not available at rest", meta.Framework, rule.ID)
+		return nil, ErrRuleDoesNotApply
+	}
+
 	return b.newRegoCheck(meta, ruleScope, rule, fallthroughReporter)
 }
 
@@ -562,7 +651,8 @@ func (b *builder) getRuleResourceReporter(scope compliance.RuleScope, rule compl
 func (b *builder) hostMatcher(scope compliance.RuleScope, ruleID string, hostSelector string) (bool, error) {
 	switch scope {
 	case compliance.DockerScope:
-		if b.dockerClient == nil {
+		// the docker rules are evaluated against the images at rest
+		if b.dockerClient == nil && !b.atRest {
 			log.Infof("rule %s skipped - not running in a docker environment", ruleID)
 			return false, nil
 		}
@@ -730,6 +820,10 @@ func (b *builder) KubeClient() env.KubeClient {
 	return b.kubeClient
 }
 
+func (b *builder) SystemdClient() env.SystemdClient {
+	return b.systemdClient
+}
+
 func (b *builder) ProvidedInput(ruleID string) eval.RegoInputMap {
 	return b.regoInputOverride[ruleID]
 }
@@ -757,6 +851,13 @@ func (b *builder) NormalizeToHostRoot(path string) string {
 	return b.pathMapper.normalizeToHostRoot(path)
 }
 
+func (b *builder) GlobHostRoot(pattern string) ([]string, error) {
+	if b.pathMapper == nil {
+		return filepath.Glob(pattern)
+	}
+	return b.pathMapper.glob(pattern)
+}
+
 func (b *builder) RelativeToHostRoot(path string) string {
 	if b.pathMapper == nil {
 		return path
@@ -23,6 +23,7 @@ type Clients interface {
 	DockerClient() DockerClient
 	AuditClient() AuditClient
 	KubeClient() KubeClient
+	SystemdClient() SystemdClient
 }
 
 // RegoConfiguration provides the rego specific configuration
@@ -37,6 +38,7 @@ type Configuration interface {
 	MaxEventsPerRun() int
 	EtcGroupPath() string
 	NormalizeToHostRoot(path string) string
+	GlobHostRoot(pattern string) ([]string, error)
 	RelativeToHostRoot(path string) string
 	EvaluateFromCache(e eval.Evaluatable) (interface{}, error)
 	IsLeader() bool
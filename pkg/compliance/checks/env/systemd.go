@@ -0,0 +1,12 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package env
+
+// SystemdClient defines the interface for querying the systemd units over D-Bus
+type SystemdClient interface {
+	GetUnitProperties(unit string) (map[string]interface{}, error)
+	Close() error
+}
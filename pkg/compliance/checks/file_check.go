@@ -9,7 +9,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/DataDog/datadog-agent/pkg/compliance"
 	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
@@ -45,7 +44,7 @@ func resolveFile(_ context.Context, e env.Env, ruleID string, res compliance.Res
 	}
 
 	initialGlob := path
-	paths, err := filepath.Glob(e.NormalizeToHostRoot(path))
+	paths, err := e.GlobHostRoot(path)
 	if err != nil {
 		return nil, err
 	}
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -32,7 +33,7 @@ func TestFileCheck(t *testing.T) {
 	normalizePath := func(t *testing.T, env *mocks.Env, file *compliance.File) {
 		t.Helper()
 		env.On("MaxEventsPerRun").Return(30).Maybe()
-		env.On("NormalizeToHostRoot", file.Path).Return(file.Path)
+		env.On("GlobHostRoot", file.Path).Return(filepath.Glob(file.Path))
 		env.On("RelativeToHostRoot", file.Path).Return(file.Path)
 	}
 
@@ -77,7 +78,7 @@ func TestFileCheck(t *testing.T) {
 				_, filePaths := createTempFiles(t, 1)
 
 				env.On("MaxEventsPerRun").Return(30).Maybe()
-				env.On("NormalizeToHostRoot", file.Path).Return(filePaths[0])
+				env.On("GlobHostRoot", file.Path).Return(filepath.Glob(filePaths[0]))
 				env.On("RelativeToHostRoot", filePaths[0]).Return(file.Path)
 			},
 			validate: func(t *testing.T, file *compliance.File, report *compliance.Report) {
@@ -104,7 +105,7 @@ func TestFileCheck(t *testing.T) {
 					env.On("RelativeToHostRoot", filePath).Return(path.Join("/etc/", path.Base(filePath)))
 				}
 
-				env.On("NormalizeToHostRoot", file.Path).Return(path.Join(tempDir, "/*.dat"))
+				env.On("GlobHostRoot", file.Path).Return(filepath.Glob(path.Join(tempDir, "/*.dat")))
 			},
 			validate: func(t *testing.T, file *compliance.File, report *compliance.Report) {
 				assert.True(report.Passed)
@@ -142,7 +143,7 @@ func TestFileCheck(t *testing.T) {
 			},
 			setup: func(t *testing.T, env *mocks.Env, file *compliance.File) {
 				env.On("MaxEventsPerRun").Return(30).Maybe()
-				env.On("NormalizeToHostRoot", file.Path).Return("./testdata/file/daemon.json")
+				env.On("GlobHostRoot", file.Path).Return(filepath.Glob("./testdata/file/daemon.json"))
 				env.On("RelativeToHostRoot", "./testdata/file/daemon.json").Return(file.Path)
 			},
 			validate: func(t *testing.T, file *compliance.File, report *compliance.Report) {
@@ -164,7 +165,7 @@ func TestFileCheck(t *testing.T) {
 			},
 			setup: func(t *testing.T, env *mocks.Env, file *compliance.File) {
 				env.On("MaxEventsPerRun").Return(30).Maybe()
-				env.On("NormalizeToHostRoot", file.Path).Return("./testdata/file/daemon.json")
+				env.On("GlobHostRoot", file.Path).Return(filepath.Glob("./testdata/file/daemon.json"))
 				env.On("RelativeToHostRoot", "./testdata/file/daemon.json").Return(file.Path)
 			},
 			validate: func(t *testing.T, file *compliance.File, report *compliance.Report) {
@@ -188,7 +189,7 @@ func TestFileCheck(t *testing.T) {
 				path := "/etc/docker/daemon.json"
 				env.On("MaxEventsPerRun").Return(30).Maybe()
 				env.On("EvaluateFromCache", mock.Anything).Return(path, nil)
-				env.On("NormalizeToHostRoot", path).Return("./testdata/file/daemon.json")
+				env.On("GlobHostRoot", path).Return(filepath.Glob("./testdata/file/daemon.json"))
 				env.On("RelativeToHostRoot", "./testdata/file/daemon.json").Return(path)
 			},
 			validate: func(t *testing.T, file *compliance.File, report *compliance.Report) {
@@ -258,7 +259,7 @@ func TestFileCheck(t *testing.T) {
 			},
 			setup: func(t *testing.T, env *mocks.Env, file *compliance.File) {
 				env.On("MaxEventsPerRun").Return(30).Maybe()
-				env.On("NormalizeToHostRoot", file.Path).Return("./testdata/file/daemon.json")
+				env.On("GlobHostRoot", file.Path).Return(filepath.Glob("./testdata/file/daemon.json"))
 				env.On("RelativeToHostRoot", "./testdata/file/daemon.json").Return(file.Path)
 			},
 			validate: func(t *testing.T, file *compliance.File, report *compliance.Report) {
@@ -280,7 +281,7 @@ func TestFileCheck(t *testing.T) {
 			},
 			setup: func(t *testing.T, env *mocks.Env, file *compliance.File) {
 				env.On("MaxEventsPerRun").Return(30).Maybe()
-				env.On("NormalizeToHostRoot", file.Path).Return("./testdata/file/pod.yaml")
+				env.On("GlobHostRoot", file.Path).Return(filepath.Glob("./testdata/file/pod.yaml"))
 				env.On("RelativeToHostRoot", "./testdata/file/pod.yaml").Return(file.Path)
 			},
 			validate: func(t *testing.T, file *compliance.File, report *compliance.Report) {
@@ -302,7 +303,7 @@ func TestFileCheck(t *testing.T) {
 			},
 			setup: func(t *testing.T, env *mocks.Env, file *compliance.File) {
 				env.On("MaxEventsPerRun").Return(30).Maybe()
-				env.On("NormalizeToHostRoot", file.Path).Return("./testdata/file/mounts")
+				env.On("GlobHostRoot", file.Path).Return(filepath.Glob("./testdata/file/mounts"))
 				env.On("RelativeToHostRoot", "./testdata/file/mounts").Return(file.Path)
 			},
 			validate: func(t *testing.T, file *compliance.File, report *compliance.Report) {
@@ -9,6 +9,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
 
 	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
 	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
@@ -17,10 +20,60 @@ import (
 
 type pathMapper struct {
 	hostMountPath string
+	// resolveSymlinks resolves the symlinks inside the mount, as the symlinks
+	// of a root filesystem at rest must not point to the running host
+	resolveSymlinks bool
 }
 
 func (m pathMapper) normalizeToHostRoot(path string) string {
-	return filepath.Join(m.hostMountPath, path)
+	if !m.resolveSymlinks {
+		return filepath.Join(m.hostMountPath, path)
+	}
+
+	// the glob patterns are resolved up to their first wildcard, the
+	// symlinks they match are resolved by glob
+	dir, pattern := splitGlobPattern(path)
+	resolved, err := securejoin.SecureJoin(m.hostMountPath, dir)
+	if err != nil {
+		log.Warnf("Unable to resolve path %s in %s: %v", path, m.hostMountPath, err)
+		return filepath.Join(m.hostMountPath, path)
+	}
+	return filepath.Join(resolved, pattern)
+}
+
+// glob returns the paths matching the pattern in the host root. For a root
+// filesystem at rest, each match is resolved inside the root, and the matches
+// that leave it are dropped.
+func (m pathMapper) glob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(m.normalizeToHostRoot(pattern))
+	if err != nil || !m.resolveSymlinks {
+		return matches, err
+	}
+
+	paths := make([]string, 0, len(matches))
+	seen := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		rel, err := filepath.Rel(m.hostMountPath, match)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			log.Debugf("Ignoring path %s outside of %s", match, m.hostMountPath)
+			continue
+		}
+
+		resolved, err := securejoin.SecureJoin(m.hostMountPath, rel)
+		if err != nil {
+			log.Warnf("Unable to resolve path %s in %s: %v", match, m.hostMountPath, err)
+			continue
+		}
+
+		// the match may have been found by following a symlink out of the
+		// root, in which case the resolved path doesn't exist
+		if _, err := os.Lstat(resolved); err != nil || seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		paths = append(paths, resolved)
+	}
+	return paths, nil
 }
 
 func (m pathMapper) relativeToHostRoot(path string) string {
@@ -37,6 +90,17 @@ func (m pathMapper) relativeToHostRoot(path string) string {
 	return path
 }
 
+// splitGlobPattern splits a path before its first element with wildcards
+func splitGlobPattern(path string) (string, string) {
+	elems := strings.Split(filepath.ToSlash(path), "/")
+	for i, elem := range elems {
+		if strings.ContainsAny(elem, `*?[\`) {
+			return filepath.FromSlash(strings.Join(elems[:i], "/")), filepath.FromSlash(strings.Join(elems[i:], "/"))
+		}
+	}
+	return path, ""
+}
+
 func resolvePath(e env.Env, path string) (string, error) {
 	pathExpr, err := eval.Cache.ParsePath(path)
 	if err != nil {
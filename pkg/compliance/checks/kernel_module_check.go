@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const procModulesPath = "/proc/modules"
+
+// modprobeConfigDirs are the directories of the modprobe configuration, in
+// their order of precedence
+var modprobeConfigDirs = []string{
+	"/etc/modprobe.d",
+	"/run/modprobe.d",
+	"/usr/local/lib/modprobe.d",
+	"/lib/modprobe.d",
+	"/usr/lib/modprobe.d",
+}
+
+var kernelModuleReportedFields = []string{
+	compliance.KernelModuleFieldName,
+	compliance.KernelModuleFieldLoaded,
+	compliance.KernelModuleFieldBlacklisted,
+	compliance.KernelModuleFieldInstallCommand,
+}
+
+func resolveKernelModule(_ context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.KernelModule == nil {
+		return nil, fmt.Errorf("%s: expecting kernel module resource in kernel module check", ruleID)
+	}
+
+	name := normalizeModuleName(res.KernelModule.Name)
+
+	log.Debugf("%s: running kernel module check for %q", ruleID, name)
+
+	f, err := os.Open(e.NormalizeToHostRoot(procModulesPath))
+	if err != nil {
+		return nil, wrapErrorWithID(ruleID, err)
+	}
+	defer f.Close()
+
+	loaded, err := isModuleLoaded(f, name)
+	if err != nil {
+		return nil, wrapErrorWithID(ruleID, err)
+	}
+
+	config := readModprobeConfig(e, name)
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.KernelModuleFieldName:           name,
+			compliance.KernelModuleFieldLoaded:         loaded,
+			compliance.KernelModuleFieldBlacklisted:    config.blacklisted,
+			compliance.KernelModuleFieldInstallCommand: config.installCommand,
+		},
+		nil,
+		eval.RegoInputMap{
+			"name":           name,
+			"loaded":         loaded,
+			"blacklisted":    config.blacklisted,
+			"installCommand": config.installCommand,
+		},
+	)
+
+	return newResolvedInstance(instance, name, "kernel_module"), nil
+}
+
+// normalizeModuleName replaces the dashes of a module name by underscores,
+// which modprobe considers equivalent
+func normalizeModuleName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// isModuleLoaded reads /proc/modules, whose first field is the module name
+func isModuleLoaded(r io.Reader, name string) (bool, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && normalizeModuleName(fields[0]) == name {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+type modprobeConfig struct {
+	blacklisted    bool
+	installCommand string
+}
+
+// readModprobeConfig reads the blacklist and install commands of a module from
+// the modprobe configuration. The files of the same name are overridden by the
+// directories of higher precedence, and the files are read in lexical order.
+func readModprobeConfig(e env.Env, name string) *modprobeConfig {
+	files := make(map[string]string)
+	for i := len(modprobeConfigDirs) - 1; i >= 0; i-- {
+		paths, err := filepath.Glob(filepath.Join(e.NormalizeToHostRoot(modprobeConfigDirs[i]), "*.conf"))
+		if err != nil {
+			continue
+		}
+		for _, path := range paths {
+			files[filepath.Base(path)] = path
+		}
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	config := &modprobeConfig{}
+	for _, file := range names {
+		f, err := os.Open(files[file])
+		if err != nil {
+			log.Debugf("failed to open modprobe configuration %s: %v", files[file], err)
+			continue
+		}
+		readModprobeConfigFile(f, name, config)
+		f.Close()
+	}
+	return config
+}
+
+func readModprobeConfigFile(r io.Reader, name string, config *modprobeConfig) {
+	scanner := bufio.NewScanner(r)
+	var line string
+	for scanner.Scan() {
+		// the lines ending with a backslash continue on the next line
+		text := scanner.Text()
+		if strings.HasSuffix(text, `\`) {
+			line += strings.TrimSuffix(text, `\`) + " "
+			continue
+		}
+		line += text
+
+		fields := strings.Fields(line)
+		line = ""
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || normalizeModuleName(fields[1]) != name {
+			continue
+		}
+
+		switch fields[0] {
+		case "blacklist":
+			config.blacklisted = true
+		case "install":
+			// the first install command is used
+			if config.installCommand == "" {
+				config.installCommand = strings.Join(fields[2:], " ")
+			}
+		}
+	}
+}
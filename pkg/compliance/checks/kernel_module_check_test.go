@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestKernelModuleCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		module    string
+		condition string
+
+		expectPassed bool
+		expectData   event.Data
+	}{
+		{
+			name:         "disabled with install command",
+			module:       "cramfs",
+			condition:    `!module.loaded && module.installCommand == "/bin/true"`,
+			expectPassed: true,
+			expectData: event.Data{
+				"module.name":           "cramfs",
+				"module.loaded":         false,
+				"module.blacklisted":    false,
+				"module.installCommand": "/bin/true",
+			},
+		},
+		{
+			name:         "install command on several lines",
+			module:       "freevxfs",
+			condition:    `module.installCommand == "/bin/false"`,
+			expectPassed: true,
+			expectData: event.Data{
+				"module.name":           "freevxfs",
+				"module.loaded":         false,
+				"module.blacklisted":    false,
+				"module.installCommand": "/bin/false",
+			},
+		},
+		{
+			name:         "blacklisted but loaded",
+			module:       "usb-storage",
+			condition:    `module.blacklisted && !module.loaded`,
+			expectPassed: false,
+			expectData: event.Data{
+				"module.name":           "usb_storage",
+				"module.loaded":         true,
+				"module.blacklisted":    true,
+				"module.installCommand": "",
+			},
+		},
+		{
+			name:         "configuration overridden by /etc",
+			module:       "overlay",
+			condition:    `module.blacklisted`,
+			expectPassed: false,
+			expectData: event.Data{
+				"module.name":           "overlay",
+				"module.loaded":         true,
+				"module.blacklisted":    false,
+				"module.installCommand": "",
+			},
+		},
+		{
+			name:         "configuration in /lib",
+			module:       "dccp",
+			condition:    `module.blacklisted && module.installCommand == "/bin/false"`,
+			expectPassed: true,
+			expectData: event.Data{
+				"module.name":           "dccp",
+				"module.loaded":         false,
+				"module.blacklisted":    true,
+				"module.installCommand": "/bin/false",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := newHostRootEnv("./testdata/kernel_module")
+
+			moduleCheck, err := newResourceCheck(env, "rule-id", compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					KernelModule: &compliance.KernelModule{
+						Name: test.module,
+					},
+				},
+				Condition: test.condition,
+			})
+			assert.NoError(err)
+
+			reports := moduleCheck.check(env)
+			assert.NoError(reports[0].Error)
+			assert.Equal(&compliance.Report{
+				Passed: test.expectPassed,
+				Data:   test.expectData,
+				Resource: compliance.ReportResource{
+					ID:   test.expectData["module.name"].(string),
+					Type: "kernel_module",
+				},
+			}, reports[0])
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var packageReportedFields = []string{
+	compliance.PackageFieldName,
+	compliance.PackageFieldVersion,
+	compliance.PackageFieldArch,
+	compliance.PackageFieldInstalled,
+}
+
+func resolvePackage(ctx context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.Package == nil {
+		return nil, fmt.Errorf("%s: expecting package resource in package check", ruleID)
+	}
+
+	pkg := res.Package
+	if err := pkg.Validate(); err != nil {
+		return nil, wrapErrorWithID(ruleID, err)
+	}
+
+	log.Debugf("%s: running package check for %q", ruleID, pkg.Name)
+
+	db, err := findPackageDatabase(e, pkg.Manager)
+	if err != nil {
+		return nil, wrapErrorWithID(ruleID, err)
+	}
+
+	installed, err := db.find(ctx, pkg.Name)
+	if err != nil {
+		return nil, wrapErrorWithID(ruleID, err)
+	}
+
+	// a package which is not installed is still resolved since the rules
+	// commonly check that a package is absent
+	var version, arch string
+	if installed != nil {
+		version, arch = installed.version, installed.arch
+	}
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.PackageFieldName:      pkg.Name,
+			compliance.PackageFieldVersion:   version,
+			compliance.PackageFieldArch:      arch,
+			compliance.PackageFieldManager:   db.manager,
+			compliance.PackageFieldInstalled: installed != nil,
+		},
+		eval.FunctionMap{
+			compliance.PackageFuncVersionCompare: packageVersionCompare(db.manager, pkg.Name, installed),
+			compliance.PackageFuncVersionAtLeast: packageVersionAtLeast(db.manager, installed),
+		},
+		eval.RegoInputMap{
+			"name":      pkg.Name,
+			"version":   version,
+			"arch":      arch,
+			"manager":   db.manager,
+			"installed": installed != nil,
+		},
+	)
+
+	return newResolvedInstance(instance, pkg.Name, "package"), nil
+}
+
+func versionArgument(args []interface{}) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf(`invalid number of arguments, expecting 1 got %d`, len(args))
+	}
+	version, ok := args[0].(string)
+	if !ok {
+		return "", fmt.Errorf(`expecting string value for version argument`)
+	}
+	return version, nil
+}
+
+// packageVersionCompare returns -1, 0 or 1 when the installed version is
+// lower than, equal to or greater than the version argument
+func packageVersionCompare(manager, name string, installed *installedPackage) eval.Function {
+	return func(_ eval.Instance, args ...interface{}) (interface{}, error) {
+		version, err := versionArgument(args)
+		if err != nil {
+			return nil, err
+		}
+		if installed == nil {
+			return nil, fmt.Errorf("package %s is not installed", name)
+		}
+		return comparePackageVersions(manager, installed.version, version)
+	}
+}
+
+// packageVersionAtLeast returns whether the installed version is greater than
+// or equal to the version argument, it is false when the package is not
+// installed
+func packageVersionAtLeast(manager string, installed *installedPackage) eval.Function {
+	return func(_ eval.Instance, args ...interface{}) (interface{}, error) {
+		version, err := versionArgument(args)
+		if err != nil {
+			return nil, err
+		}
+		if installed == nil {
+			return false, nil
+		}
+		c, err := comparePackageVersions(manager, installed.version, version)
+		if err != nil {
+			return nil, err
+		}
+		return c >= 0, nil
+	}
+}
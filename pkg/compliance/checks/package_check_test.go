@@ -0,0 +1,299 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestPackageCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     string
+		resource compliance.Resource
+
+		expectReport *compliance.Report
+	}{
+		{
+			name: "dpkg package installed with epoch",
+			root: "./testdata/package/debian",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "openssh-server",
+					},
+				},
+				Condition: `package.installed && package.versionAtLeast("1:8.4p1-5") && package.versionCompare("1:8.4p1-6") < 0`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "openssh-server",
+					"package.version":   "1:8.4p1-5+deb11u1",
+					"package.arch":      "amd64",
+					"package.installed": true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "openssh-server",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name: "dpkg package removed with config files left",
+			root: "./testdata/package/debian",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "telnet",
+					},
+				},
+				Condition: `!package.installed`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "telnet",
+					"package.version":   "",
+					"package.arch":      "",
+					"package.installed": false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnet",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name: "apk package too old",
+			root: "./testdata/package/alpine",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "openssl",
+					},
+				},
+				Condition: `package.versionAtLeast("1.1.1n-r1")`,
+			},
+			expectReport: &compliance.Report{
+				Passed: false,
+				Data: event.Data{
+					"package.name":      "openssl",
+					"package.version":   "1.1.1n-r0",
+					"package.arch":      "x86_64",
+					"package.installed": true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "openssl",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name: "rpm package with several versions",
+			root: "./testdata/package/rhel",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "kernel",
+					},
+				},
+				Condition: `package.versionAtLeast("4.18.0-348.el8")`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "kernel",
+					"package.version":   "4.18.0-348.7.1.el8_5",
+					"package.arch":      "x86_64",
+					"package.installed": true,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "kernel",
+					Type: "package",
+				},
+			},
+		},
+		{
+			name: "rpm package not installed",
+			root: "./testdata/package/rhel",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name:    "telnet-server",
+						Manager: compliance.PackageManagerRPM,
+					},
+				},
+				Condition: `!package.installed`,
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"package.name":      "telnet-server",
+					"package.version":   "",
+					"package.arch":      "",
+					"package.installed": false,
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnet-server",
+					Type: "package",
+				},
+			},
+		},
+	}
+
+	commandRunner = func(ctx context.Context, name string, args []string, captureStdout bool) (int, []byte, error) {
+		if name != "rpm" {
+			return 0, nil, errors.New("unexpected command")
+		}
+		switch args[len(args)-1] {
+		case "kernel":
+			return 0, []byte("kernel\t4.18.0-348.7.1.el8_5\tx86_64\nkernel\t4.18.0-305.el8\tx86_64\n"), nil
+		default:
+			return 1, []byte("package telnet-server is not installed\n"), errors.New("exit status 1")
+		}
+	}
+	defer func() {
+		commandRunner = runCommand
+	}()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := &mocks.Env{}
+			env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string {
+				return filepath.Join(test.root, path)
+			})
+
+			packageCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			reports := packageCheck.check(env)
+			assert.NoError(reports[0].Error)
+			assert.Equal(test.expectReport, reports[0])
+		})
+	}
+}
+
+func TestPackageCheckErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string {
+		return filepath.Join("./testdata/package/debian", path)
+	})
+
+	check, err := newResourceCheck(env, "rule-id", compliance.Resource{
+		ResourceCommon: compliance.ResourceCommon{
+			Package: &compliance.Package{Name: "telnet"},
+		},
+		Condition: `package.versionCompare("0.17") == 0`,
+	})
+	assert.NoError(err)
+	reports := check.check(env)
+	assert.EqualError(reports[0].Error, `1:1: call to "package.versionCompare()" failed: package telnet is not installed`)
+
+	check, err = newResourceCheck(env, "rule-id", compliance.Resource{
+		ResourceCommon: compliance.ResourceCommon{
+			Package: &compliance.Package{Name: "musl", Manager: compliance.PackageManagerAPK},
+		},
+		Condition: `package.installed`,
+	})
+	assert.NoError(err)
+	reports = check.check(env)
+	assert.EqualError(reports[0].Error, "rule-id: no apk package database found")
+}
+
+func TestFindRPMPackageErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	var stdout string
+	commandRunner = func(ctx context.Context, name string, args []string, captureStdout bool) (int, []byte, error) {
+		return 1, []byte(stdout), errors.New("exit status 1")
+	}
+	defer func() {
+		commandRunner = runCommand
+	}()
+
+	stdout = "package telnet-server is not installed\n"
+	pkg, err := findRPMPackage(context.Background(), "/var/lib/rpm", "telnet-server")
+	assert.NoError(err)
+	assert.Nil(pkg)
+
+	// the database can't be opened, rpm reports the error on stderr
+	stdout = ""
+	pkg, err = findRPMPackage(context.Background(), "/var/lib/rpm", "telnet-server")
+	assert.EqualError(err, "failed to query the rpm database: exit status 1")
+	assert.Nil(pkg)
+}
+
+func TestPackageRegoCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	f := &regoFixture{
+		inputs: []compliance.RegoInput{
+			{
+				ResourceCommon: compliance.ResourceCommon{
+					Package: &compliance.Package{
+						Name: "musl",
+					},
+				},
+				TagName: "musl",
+				Type:    "object",
+			},
+		},
+		module: `
+			package test
+
+			import data.datadog as dd
+
+			findings[f] {
+				input.musl.installed
+				package_version_compare(input.musl.manager, input.musl.version, "1.2.2-r8") < 0
+				f := dd.failing_finding("package", input.musl.name, {"package.version": input.musl.version})
+			}
+		`,
+		findings: "data.test.findings",
+	}
+
+	env := &mocks.Env{}
+	env.On("MaxEventsPerRun").Return(30).Maybe()
+	env.On("ProvidedInput", mock.Anything).Return(nil).Once()
+	env.On("Hostname").Return("hostname_test").Once()
+	env.On("DumpInputPath").Return("").Once()
+	env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string {
+		return filepath.Join("./testdata/package/alpine", path)
+	})
+
+	regoCheck, err := f.newRegoCheck()
+	assert.NoError(err)
+
+	reports := regoCheck.check(env)
+	assert.Equal([]*compliance.Report{
+		{
+			Passed: false,
+			Data: event.Data{
+				"package.version": "1.2.2-r7",
+			},
+			Resource: compliance.ReportResource{
+				ID:   "musl",
+				Type: "package",
+			},
+			Evaluator: "rego",
+		},
+	}, reports)
+}
@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+)
+
+const (
+	dpkgStatusPath   = "/var/lib/dpkg/status"
+	apkInstalledPath = "/lib/apk/db/installed"
+)
+
+// rpmDBPaths are the locations of the rpm database, the newer distributions
+// moved it to /usr/lib/sysimage/rpm
+var rpmDBPaths = []string{
+	"/var/lib/rpm",
+	"/usr/lib/sysimage/rpm",
+}
+
+// rpmQueryFormat prints the installed package as name, [epoch:]version-release
+// and arch
+const rpmQueryFormat = `%{NAME}\t%|EPOCH?{%{EPOCH}:}:{}|%{VERSION}-%{RELEASE}\t%{ARCH}\n`
+
+type installedPackage struct {
+	name    string
+	version string
+	arch    string
+}
+
+// packageDatabase is the database of the packages installed by a package
+// manager
+type packageDatabase struct {
+	manager string
+	// path is the path of the database, normalized to the host root
+	path string
+}
+
+// findPackageDatabase returns the database of the package manager, which is
+// detected from the databases found on the host when it is empty
+func findPackageDatabase(e env.Env, manager string) (*packageDatabase, error) {
+	candidates := []packageDatabase{
+		{manager: compliance.PackageManagerDpkg, path: dpkgStatusPath},
+		{manager: compliance.PackageManagerAPK, path: apkInstalledPath},
+	}
+	for _, path := range rpmDBPaths {
+		candidates = append(candidates, packageDatabase{manager: compliance.PackageManagerRPM, path: path})
+	}
+
+	for _, db := range candidates {
+		if manager != "" && db.manager != manager {
+			continue
+		}
+		path := e.NormalizeToHostRoot(db.path)
+		if _, err := os.Stat(path); err == nil {
+			return &packageDatabase{manager: db.manager, path: path}, nil
+		}
+	}
+
+	if manager != "" {
+		return nil, fmt.Errorf("no %s package database found", manager)
+	}
+	return nil, fmt.Errorf("no package database found")
+}
+
+// find returns the installed package of the given name, or nil when it is not
+// installed
+func (db *packageDatabase) find(ctx context.Context, name string) (*installedPackage, error) {
+	switch db.manager {
+	case compliance.PackageManagerDpkg:
+		return findDpkgPackage(db.path, name)
+	case compliance.PackageManagerAPK:
+		return findAPKPackage(db.path, name)
+	case compliance.PackageManagerRPM:
+		return findRPMPackage(ctx, db.path, name)
+	default:
+		return nil, fmt.Errorf("unsupported package manager `%s`", db.manager)
+	}
+}
+
+// readStanzas calls fn with the fields of each stanza of a file made of
+// blank-line-separated "key: value" stanzas, until fn returns true
+func readStanzas(r io.Reader, fn func(fields map[string]string) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	fields := make(map[string]string)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(fields) > 0 && fn(fields) {
+				return nil
+			}
+			fields = make(map[string]string)
+			continue
+		}
+		// continuation lines of multi-line fields
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		fields[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		fn(fields)
+	}
+	return nil
+}
+
+// findDpkgPackage reads the dpkg status file, a package is installed when its
+// status is "install ok installed"
+func findDpkgPackage(path, name string) (*installedPackage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pkg *installedPackage
+	err = readStanzas(f, func(fields map[string]string) bool {
+		if fields["Package"] != name {
+			return false
+		}
+		status := strings.Fields(fields["Status"])
+		if len(status) != 3 || status[2] != "installed" {
+			return false
+		}
+		pkg = &installedPackage{
+			name:    name,
+			version: fields["Version"],
+			arch:    fields["Architecture"],
+		}
+		return true
+	})
+	return pkg, err
+}
+
+// findAPKPackage reads the apk installed database, which only lists the
+// installed packages
+func findAPKPackage(path, name string) (*installedPackage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var pkg *installedPackage
+	err = readStanzas(f, func(fields map[string]string) bool {
+		if fields["P"] != name {
+			return false
+		}
+		pkg = &installedPackage{
+			name:    name,
+			version: fields["V"],
+			arch:    fields["A"],
+		}
+		return true
+	})
+	return pkg, err
+}
+
+// findRPMPackage queries the rpm database with the rpm binary, which supports
+// all its formats (Berkeley DB, NDB and SQLite)
+func findRPMPackage(ctx context.Context, dbPath, name string) (*installedPackage, error) {
+	args := []string{"--dbpath", dbPath, "--query", "--queryformat", rpmQueryFormat, name}
+	exitCode, stdout, err := commandRunner(ctx, "rpm", args, true)
+	if exitCode == 1 && strings.Contains(string(stdout), fmt.Sprintf("package %s is not installed", name)) {
+		// rpm exits with 1 when the package is not installed, but also when
+		// the database can't be read
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the rpm database: %w", err)
+	}
+
+	// several versions of a package can be installed, such as the kernel, the
+	// greatest one is reported
+	var pkg *installedPackage
+	for _, line := range strings.Split(strings.TrimSpace(string(stdout)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("unexpected rpm output %q", line)
+		}
+		if pkg == nil || compareRPMVersions(fields[1], pkg.version) > 0 {
+			pkg = &installedPackage{
+				name:    fields[0],
+				version: fields[1],
+				arch:    fields[2],
+			}
+		}
+	}
+	return pkg, nil
+}
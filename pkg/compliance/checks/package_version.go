@@ -0,0 +1,341 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+)
+
+// comparePackageVersions compares two versions with the ordering of a package
+// manager, it returns -1, 0 or 1 when a is lower than, equal to or greater
+// than b
+func comparePackageVersions(manager, a, b string) (int, error) {
+	switch manager {
+	case compliance.PackageManagerDpkg:
+		return compareDebianVersions(a, b), nil
+	case compliance.PackageManagerRPM:
+		return compareRPMVersions(a, b), nil
+	case compliance.PackageManagerAPK:
+		return compareAPKVersions(a, b), nil
+	default:
+		return 0, fmt.Errorf("unsupported package manager `%s`", manager)
+	}
+}
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// splitEpoch splits the epoch of a [epoch:]version string, which defaults to 0
+func splitEpoch(version string) (int, string) {
+	idx := strings.IndexByte(version, ':')
+	if idx < 0 {
+		return 0, version
+	}
+	epoch, err := strconv.Atoi(version[:idx])
+	if err != nil {
+		return 0, version
+	}
+	return epoch, version[idx+1:]
+}
+
+// compareDebianVersions compares two [epoch:]upstream[-revision] versions as
+// dpkg does
+func compareDebianVersions(a, b string) int {
+	epochA, a := splitEpoch(a)
+	epochB, b := splitEpoch(b)
+	if epochA != epochB {
+		return sign(epochA - epochB)
+	}
+
+	upstreamA, revisionA := splitRevision(a)
+	upstreamB, revisionB := splitRevision(b)
+	if c := debianVerRevCmp(upstreamA, upstreamB); c != 0 {
+		return c
+	}
+	return debianVerRevCmp(revisionA, revisionB)
+}
+
+func splitRevision(version string) (string, string) {
+	idx := strings.LastIndexByte(version, '-')
+	if idx < 0 {
+		return version, ""
+	}
+	return version[:idx], version[idx+1:]
+}
+
+// debianOrder is the weight of a character in a dpkg version, the letters
+// sort before the other characters and the tilde sorts before anything, even
+// the end of the version
+func debianOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case isDigit(c):
+		return 0
+	case isLetter(c):
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+// debianVerRevCmp is the verrevcmp function of dpkg, comparing alternately the
+// non-digit and the digit parts of two versions
+func debianVerRevCmp(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		firstDiff := 0
+		for (i < len(a) && !isDigit(a[i])) || (j < len(b) && !isDigit(b[j])) {
+			ac, bc := debianOrder(a, i), debianOrder(b, j)
+			if ac != bc {
+				return sign(ac - bc)
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		for i < len(a) && isDigit(a[i]) && j < len(b) && isDigit(b[j]) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if i < len(a) && isDigit(a[i]) {
+			return 1
+		}
+		if j < len(b) && isDigit(b[j]) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return sign(firstDiff)
+		}
+	}
+	return 0
+}
+
+// compareRPMVersions compares two [epoch:]version[-release] versions as rpm
+// does
+func compareRPMVersions(a, b string) int {
+	epochA, a := splitEpoch(a)
+	epochB, b := splitEpoch(b)
+	if epochA != epochB {
+		return sign(epochA - epochB)
+	}
+
+	versionA, releaseA := splitRevision(a)
+	versionB, releaseB := splitRevision(b)
+	if c := rpmVerCmp(versionA, versionB); c != 0 {
+		return c
+	}
+	// a version without release matches all the releases
+	if releaseA == "" || releaseB == "" {
+		return 0
+	}
+	return rpmVerCmp(releaseA, releaseB)
+}
+
+// rpmVerCmp is the rpmvercmp function of rpm, comparing the alphanumeric
+// segments of two versions
+func rpmVerCmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for i < len(a) && !isAlnum(a[i]) && a[i] != '~' && a[i] != '^' {
+			i++
+		}
+		for j < len(b) && !isAlnum(b[j]) && b[j] != '~' && b[j] != '^' {
+			j++
+		}
+
+		// the tilde sorts before anything, even the end of the version
+		tildeA, tildeB := i < len(a) && a[i] == '~', j < len(b) && b[j] == '~'
+		if tildeA || tildeB {
+			if !tildeA {
+				return 1
+			}
+			if !tildeB {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		// the caret sorts after the end of the version only
+		caretA, caretB := i < len(a) && a[i] == '^', j < len(b) && b[j] == '^'
+		if caretA || caretB {
+			if i >= len(a) {
+				return -1
+			}
+			if j >= len(b) {
+				return 1
+			}
+			if !caretA {
+				return 1
+			}
+			if !caretB {
+				return -1
+			}
+			i++
+			j++
+			continue
+		}
+
+		if i >= len(a) || j >= len(b) {
+			break
+		}
+
+		startA, startB := i, j
+		isNum := isDigit(a[i])
+		if isNum {
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+		} else {
+			for i < len(a) && isLetter(a[i]) {
+				i++
+			}
+			for j < len(b) && isLetter(b[j]) {
+				j++
+			}
+		}
+
+		segA, segB := a[startA:i], b[startB:j]
+		if segB == "" {
+			// the numeric segments are newer than the alphabetic ones
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+
+		if isNum {
+			segA, segB = strings.TrimLeft(segA, "0"), strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				return sign(len(segA) - len(segB))
+			}
+		}
+		if c := strings.Compare(segA, segB); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case i >= len(a) && j >= len(b):
+		return 0
+	case i >= len(a):
+		return -1
+	default:
+		return 1
+	}
+}
+
+// apkSuffixes are the version suffixes of apk, in their order. The pre-release
+// ones sort before the version without suffix.
+var apkSuffixes = map[string]int{
+	"alpha": -4,
+	"beta":  -3,
+	"pre":   -2,
+	"rc":    -1,
+	"cvs":   1,
+	"svn":   2,
+	"git":   3,
+	"hg":    4,
+	"p":     5,
+}
+
+// compareAPKVersions compares two number[letter][_suffix[number]...][-rrevision]
+// versions as apk does
+func compareAPKVersions(a, b string) int {
+	baseA, suffixesA, revisionA := splitAPKVersion(a)
+	baseB, suffixesB, revisionB := splitAPKVersion(b)
+
+	if c := rpmVerCmp(baseA, baseB); c != 0 {
+		return c
+	}
+
+	for k := 0; k < len(suffixesA) || k < len(suffixesB); k++ {
+		var sa, sb apkSuffix
+		if k < len(suffixesA) {
+			sa = suffixesA[k]
+		}
+		if k < len(suffixesB) {
+			sb = suffixesB[k]
+		}
+		if sa.order != sb.order {
+			return sign(sa.order - sb.order)
+		}
+		if sa.number != sb.number {
+			return sign(sa.number - sb.number)
+		}
+	}
+
+	return sign(revisionA - revisionB)
+}
+
+type apkSuffix struct {
+	order  int
+	number int
+}
+
+func splitAPKVersion(version string) (string, []apkSuffix, int) {
+	var revision int
+	if idx := strings.LastIndex(version, "-r"); idx >= 0 {
+		if r, err := strconv.Atoi(version[idx+2:]); err == nil {
+			revision = r
+			version = version[:idx]
+		}
+	}
+
+	parts := strings.Split(version, "_")
+	var suffixes []apkSuffix
+	for _, part := range parts[1:] {
+		name := strings.TrimRightFunc(part, func(r rune) bool { return r >= '0' && r <= '9' })
+		number, _ := strconv.Atoi(part[len(name):])
+		suffixes = append(suffixes, apkSuffix{order: apkSuffixes[name], number: number})
+	}
+	return parts[0], suffixes, revision
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || isLetter(c)
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestComparePackageVersions(t *testing.T) {
+	tests := []struct {
+		manager  string
+		a        string
+		b        string
+		expected int
+	}{
+		{manager: compliance.PackageManagerDpkg, a: "1.0", b: "1.0", expected: 0},
+		{manager: compliance.PackageManagerDpkg, a: "1.0", b: "1.1", expected: -1},
+		{manager: compliance.PackageManagerDpkg, a: "1.10", b: "1.9", expected: 1},
+		{manager: compliance.PackageManagerDpkg, a: "1:1.0", b: "2.0", expected: 1},
+		{manager: compliance.PackageManagerDpkg, a: "1.0~rc1", b: "1.0", expected: -1},
+		{manager: compliance.PackageManagerDpkg, a: "1.0~rc1", b: "1.0~rc2", expected: -1},
+		{manager: compliance.PackageManagerDpkg, a: "1.0+deb1", b: "1.0", expected: 1},
+		{manager: compliance.PackageManagerDpkg, a: "1.0a", b: "1.0+", expected: -1},
+		{manager: compliance.PackageManagerDpkg, a: "2.31-13+deb11u3", b: "2.31-13", expected: 1},
+		{manager: compliance.PackageManagerDpkg, a: "1:8.4p1-5+deb11u1", b: "1:8.4p1-5", expected: 1},
+		{manager: compliance.PackageManagerDpkg, a: "1.001", b: "1.1", expected: 0},
+
+		{manager: compliance.PackageManagerRPM, a: "1.0", b: "1.0", expected: 0},
+		{manager: compliance.PackageManagerRPM, a: "1.0", b: "1.0.1", expected: -1},
+		{manager: compliance.PackageManagerRPM, a: "2.10", b: "2.9", expected: 1},
+		{manager: compliance.PackageManagerRPM, a: "1.0a", b: "1.0", expected: 1},
+		{manager: compliance.PackageManagerRPM, a: "1.0a", b: "1.01", expected: -1},
+		{manager: compliance.PackageManagerRPM, a: "1.0~rc1", b: "1.0", expected: -1},
+		{manager: compliance.PackageManagerRPM, a: "1.0^git1", b: "1.0", expected: 1},
+		{manager: compliance.PackageManagerRPM, a: "1.0^git1", b: "1.0.1", expected: -1},
+		{manager: compliance.PackageManagerRPM, a: "4.18.0-348.7.1.el8_5", b: "4.18.0-348.el8", expected: 1},
+		{manager: compliance.PackageManagerRPM, a: "1:1.0-1", b: "2.0-1", expected: 1},
+		{manager: compliance.PackageManagerRPM, a: "1.0-5.el8", b: "1.0", expected: 0},
+
+		{manager: compliance.PackageManagerAPK, a: "1.2.2-r7", b: "1.2.2-r7", expected: 0},
+		{manager: compliance.PackageManagerAPK, a: "1.2.2-r7", b: "1.2.2-r10", expected: -1},
+		{manager: compliance.PackageManagerAPK, a: "1.1.1n-r0", b: "1.1.1m-r3", expected: 1},
+		{manager: compliance.PackageManagerAPK, a: "2.0_rc1", b: "2.0", expected: -1},
+		{manager: compliance.PackageManagerAPK, a: "2.0_alpha2", b: "2.0_beta1", expected: -1},
+		{manager: compliance.PackageManagerAPK, a: "2.0_p1", b: "2.0", expected: 1},
+		{manager: compliance.PackageManagerAPK, a: "1.10", b: "1.9-r3", expected: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.manager+" "+test.a+" "+test.b, func(t *testing.T) {
+			assert := assert.New(t)
+
+			c, err := comparePackageVersions(test.manager, test.a, test.b)
+			assert.NoError(err)
+			assert.Equal(test.expected, c)
+
+			c, err = comparePackageVersions(test.manager, test.b, test.a)
+			assert.NoError(err)
+			assert.Equal(-test.expected, c)
+		})
+	}
+
+	_, err := comparePackageVersions("pacman", "1.0", "1.0")
+	assert.Error(t, err)
+}
@@ -21,6 +21,7 @@ var helpers string
 
 var regoBuiltins = []func(*rego.Rego){
 	octalLiteralFunc,
+	packageVersionCompareFunc,
 }
 
 var octalLiteralFunc = rego.Function1(
@@ -42,3 +43,27 @@ var octalLiteralFunc = rego.Function1(
 		return ast.IntNumberTerm(int(value)), err
 	},
 )
+
+var packageVersionCompareFunc = rego.Function3(
+	&rego.Function{
+		Name: "package_version_compare",
+		Decl: types.NewFunction(types.Args(types.S, types.S, types.S), types.N),
+	},
+	func(_ rego.BuiltinContext, manager, a, b *ast.Term) (*ast.Term, error) {
+		var args [3]string
+		for i, term := range []*ast.Term{manager, a, b} {
+			str, ok := term.Value.(ast.String)
+			if !ok {
+				return nil, errors.New("failed to compare package versions")
+			}
+			args[i] = string(str)
+		}
+
+		c, err := comparePackageVersions(args[0], args[1], args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.IntNumberTerm(c), nil
+	},
+)
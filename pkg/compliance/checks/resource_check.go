@@ -198,6 +198,17 @@ func resourceKindToResolverAndFields(env env.Env, ruleID string, kind compliance
 		return resolveCommand, commandReportedFields, nil
 	case compliance.KindProcess:
 		return resolveProcess, processReportedFields, nil
+	case compliance.KindPackage:
+		return resolvePackage, packageReportedFields, nil
+	case compliance.KindSysctl:
+		return resolveSysctl, sysctlReportedFields, nil
+	case compliance.KindKernelModule:
+		return resolveKernelModule, kernelModuleReportedFields, nil
+	case compliance.KindSystemdUnit:
+		if env.SystemdClient() == nil {
+			return nil, nil, log.Errorf("%s: systemd client not initialized", ruleID)
+		}
+		return resolveSystemdUnit, systemdUnitReportedFields, nil
 	case compliance.KindDocker:
 		if env.DockerClient() == nil {
 			return nil, nil, log.Errorf("%s: docker client not initialized", ruleID)
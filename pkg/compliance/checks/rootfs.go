@@ -0,0 +1,505 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+
+	ociIndexMediaType        = "application/vnd.oci.image.index.v1+json"
+	dockerManifestListMedia  = "application/vnd.docker.distribution.manifest.list.v2+json"
+	ociImageManifestFile     = "index.json"
+	dockerImageManifestFile  = "manifest.json"
+	maxImageMetadataFileSize = 10 * 1024 * 1024
+)
+
+// restResourceKinds are the resource kinds which can be evaluated against a
+// root filesystem at rest, the others need the running host
+var restResourceKinds = map[compliance.ResourceKind]struct{}{
+	compliance.KindFile:      {},
+	compliance.KindGroup:     {},
+	compliance.KindPackage:   {},
+	compliance.KindConstants: {},
+}
+
+// appliesAtRest returns whether all the resources of a rule can be evaluated
+// against a root filesystem at rest
+func appliesAtRest(resources []compliance.ResourceCommon) bool {
+	for _, resource := range resources {
+		if _, ok := restResourceKinds[resource.Kind()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// conditionResources returns the resources of a condition rule, including
+// their fallbacks
+func conditionResources(resources []compliance.Resource) []compliance.ResourceCommon {
+	var common []compliance.ResourceCommon
+	for _, resource := range resources {
+		common = append(common, resource.ResourceCommon)
+		if resource.Fallback != nil {
+			common = append(common, conditionResources([]compliance.Resource{resource.Fallback.Resource})...)
+		}
+	}
+	return common
+}
+
+// regoInputResources returns the resources of the inputs of a rego rule
+func regoInputResources(inputs []compliance.RegoInput) []compliance.ResourceCommon {
+	var common []compliance.ResourceCommon
+	for _, input := range inputs {
+		common = append(common, input.ResourceCommon)
+	}
+	return common
+}
+
+// descriptor is the subset of the OCI content descriptor used to find the
+// manifest and the layers of an image
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+}
+
+type ociManifest struct {
+	MediaType string       `json:"mediaType"`
+	Manifests []descriptor `json:"manifests"`
+	Layers    []descriptor `json:"layers"`
+}
+
+type dockerManifest struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+// extractImage extracts the layers of the image exported in the given tarball,
+// in the OCI layout or docker save format, to the root directory
+func extractImage(tarball string, root string) error {
+	archiveDir, err := os.MkdirTemp("", "compliance-image-archive")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(archiveDir)
+
+	f, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := extractArchive(f, archiveDir); err != nil {
+		return fmt.Errorf("failed to extract image archive %s: %w", tarball, err)
+	}
+
+	layers, err := imageLayers(archiveDir)
+	if err != nil {
+		return fmt.Errorf("failed to read image manifest of %s: %w", tarball, err)
+	}
+
+	// the directories stay writable until all the layers are extracted
+	dirModes := make(map[string]os.FileMode)
+	for _, layer := range layers {
+		log.Debugf("Extracting image layer %s", layer)
+		if err := extractLayerFile(layer, root, dirModes); err != nil {
+			return fmt.Errorf("failed to extract image layer %s: %w", layer, err)
+		}
+	}
+
+	return applyDirModes(dirModes)
+}
+
+// imageLayers returns the paths of the layers of an extracted image archive,
+// from the lowest to the topmost
+func imageLayers(archiveDir string) ([]string, error) {
+	// docker save writes the manifest.json file, also written alongside the
+	// OCI layout by the recent versions
+	var manifests []dockerManifest
+	if err := readImageMetadata(archiveDir, dockerImageManifestFile, &manifests); err == nil {
+		if len(manifests) != 1 {
+			return nil, fmt.Errorf("expecting one image in archive, found %d", len(manifests))
+		}
+		var layers []string
+		for _, layer := range manifests[0].Layers {
+			path, err := securejoin.SecureJoin(archiveDir, layer)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, path)
+		}
+		return layers, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var index ociManifest
+	if err := readImageMetadata(archiveDir, ociImageManifestFile, &index); err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("neither manifest.json nor index.json found in archive")
+		}
+		return nil, err
+	}
+
+	manifest, err := resolveImageManifest(archiveDir, index)
+	if err != nil {
+		return nil, err
+	}
+
+	var layers []string
+	for _, layer := range manifest.Layers {
+		path, err := blobPath(archiveDir, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, path)
+	}
+	return layers, nil
+}
+
+// resolveImageManifest follows the image indexes down to the manifest of the
+// image matching the current platform, or of the first image
+func resolveImageManifest(archiveDir string, index ociManifest) (*ociManifest, error) {
+	for {
+		if len(index.Manifests) == 0 {
+			return nil, errors.New("no image manifest found in index")
+		}
+
+		selected := index.Manifests[0]
+		for _, desc := range index.Manifests {
+			if desc.Platform != nil && desc.Platform.OS == "linux" && desc.Platform.Architecture == runtime.GOARCH {
+				selected = desc
+				break
+			}
+		}
+
+		path, err := blobPath(archiveDir, selected.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		var manifest ociManifest
+		if err := readImageMetadata(filepath.Dir(path), filepath.Base(path), &manifest); err != nil {
+			return nil, err
+		}
+
+		switch selected.MediaType {
+		case ociIndexMediaType, dockerManifestListMedia:
+			index = manifest
+		default:
+			return &manifest, nil
+		}
+	}
+}
+
+// blobPath returns the path of a blob of the OCI layout from its digest
+func blobPath(archiveDir string, digest string) (string, error) {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid digest `%s`", digest)
+	}
+	return securejoin.SecureJoin(archiveDir, filepath.Join("blobs", parts[0], parts[1]))
+}
+
+func readImageMetadata(dir string, name string, v interface{}) error {
+	path, err := securejoin.SecureJoin(dir, name)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewDecoder(io.LimitReader(f, maxImageMetadataFileSize)).Decode(v)
+}
+
+func extractLayerFile(path string, root string, dirModes map[string]os.FileMode) error {
+	// the whiteouts only hide the files of the lower layers, they are applied
+	// before the files of the layer are extracted
+	if err := readLayerFile(path, func(r io.Reader) error { return applyWhiteouts(r, root) }); err != nil {
+		return err
+	}
+	return readLayerFile(path, func(r io.Reader) error { return extractLayer(r, root, dirModes) })
+}
+
+func readLayerFile(path string, fn func(r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := decompressedReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return fn(r)
+}
+
+// decompressedReader detects the compression of the layers, which are
+// uncompressed, gzip or zstd compressed tarballs
+func decompressedReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		d, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	default:
+		return ioutil.NopCloser(br), nil
+	}
+}
+
+// extractArchive extracts the regular files and directories of the image
+// archive, which only contains the metadata and layer blobs
+func extractArchive(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path, err := securejoin.SecureJoin(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return err
+			}
+			if err := writeFile(path, tr, 0600); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// applyWhiteouts removes the files of the lower layers hidden by the
+// whiteouts of a layer, as the overlay filesystem would
+func applyWhiteouts(r io.Reader, root string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name, base := layerEntryName(hdr)
+		if !strings.HasPrefix(base, whiteoutPrefix) {
+			continue
+		}
+
+		parent, err := securejoin.SecureJoin(root, filepath.Dir(name))
+		if err != nil {
+			return err
+		}
+
+		if base == whiteoutOpaque {
+			if err := removeChildren(parent); err != nil {
+				return err
+			}
+		} else if err := os.RemoveAll(filepath.Join(parent, strings.TrimPrefix(base, whiteoutPrefix))); err != nil {
+			return err
+		}
+	}
+}
+
+// extractLayer extracts the files of a layer to the root directory, and
+// records the modes of its directories
+func extractLayer(r io.Reader, root string, dirModes map[string]os.FileMode) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name, base := layerEntryName(hdr)
+		if name == string(os.PathSeparator) || strings.HasPrefix(base, whiteoutPrefix) {
+			continue
+		}
+
+		// the parent directories are resolved inside the root so that the
+		// symlinks of the image never point to the host
+		parent, err := securejoin.SecureJoin(root, filepath.Dir(name))
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(parent, 0755); err != nil {
+			return err
+		}
+		path := filepath.Join(parent, base)
+		mode := hdr.FileInfo().Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+
+		// a file of an upper layer replaces the one of the lower layers,
+		// unless both are directories
+		if fi, err := os.Lstat(path); err == nil && !(fi.IsDir() && hdr.Typeflag == tar.TypeDir) {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+			delete(dirModes, path)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0700); err != nil {
+				return err
+			}
+			dirModes[path] = mode
+		case tar.TypeReg:
+			if err := writeFile(path, tr, 0600); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.Symlink(hdr.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			target, err := securejoin.SecureJoin(root, hdr.Linkname)
+			if err != nil {
+				return err
+			}
+			if err := os.Link(target, path); err != nil {
+				return err
+			}
+		default:
+			// devices and fifos are irrelevant to the checks
+			log.Tracef("Skipping image file %s of type %c", name, hdr.Typeflag)
+			continue
+		}
+
+		// the ownership can only be restored when running as root, the hard
+		// links share the ownership of their target
+		if os.Geteuid() == 0 && hdr.Typeflag != tar.TypeLink {
+			if err := os.Lchown(path, hdr.Uid, hdr.Gid); err != nil {
+				return err
+			}
+		}
+
+		// the mode is set after the ownership, whose change clears the
+		// setuid and setgid bits
+		if hdr.Typeflag == tar.TypeReg {
+			if err := os.Chmod(path, mode); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyDirModes sets the modes of the extracted directories, from the deepest
+// ones
+func applyDirModes(dirModes map[string]os.FileMode) error {
+	dirs := make([]string, 0, len(dirModes))
+	for dir := range dirModes {
+		dirs = append(dirs, dir)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+
+	for _, dir := range dirs {
+		// the directories may have been removed by the upper layers
+		if fi, err := os.Lstat(dir); err != nil || !fi.IsDir() {
+			continue
+		}
+		if err := os.Chmod(dir, dirModes[dir]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// layerEntryName returns the absolute name of a layer entry and its base name
+func layerEntryName(hdr *tar.Header) (string, string) {
+	name := filepath.Clean(string(os.PathSeparator) + hdr.Name)
+	return name, filepath.Base(name)
+}
+
+func removeChildren(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, r io.Reader, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,339 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+// +build !windows
+
+package checks
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	mode     int64
+	content  string
+	linkname string
+}
+
+func buildTar(t *testing.T, entries []tarEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		hdr := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Mode:     entry.mode,
+			Size:     int64(len(entry.content)),
+			Linkname: entry.linkname,
+		}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0644
+		}
+		assert.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(entry.content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func zstdCompressed(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w, err := zstd.NewWriter(&buf)
+	assert.NoError(t, err)
+	_, err = w.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func testImageLayers(t *testing.T) [][]byte {
+	lower := buildTar(t, []tarEntry{
+		{name: "etc/", typeflag: tar.TypeDir, mode: 0755},
+		{name: "etc/passwd", content: "root:x:0:0:root:/root:/bin/sh\n"},
+		{name: "etc/os-release", content: "ID=alpine\n"},
+		{name: "etc/shadow-link", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"},
+		{name: "escape", typeflag: tar.TypeSymlink, linkname: "/compliance-rootfs-escape"},
+		{name: "tmp/", typeflag: tar.TypeDir, mode: 01777},
+		{name: "usr/bin/tool", mode: 04755, content: "#!/bin/sh\n"},
+		{name: "var/lib/app/a", content: "a"},
+		{name: "var/lib/app/b", content: "b"},
+		{name: "readonly/", typeflag: tar.TypeDir, mode: 0555},
+	})
+	upper := buildTar(t, []tarEntry{
+		{name: "etc/passwd", content: "root:x:0:0:root:/root:/bin/ash\n"},
+		{name: "etc/.wh.os-release", content: ""},
+		{name: "var/lib/app/c", content: "c"},
+		{name: "var/lib/app/.wh..wh..opq", content: ""},
+		{name: "escape/evil", content: "evil"},
+		{name: "readonly/file", content: "ro"},
+		{name: "usr/bin/tool-link", typeflag: tar.TypeLink, linkname: "usr/bin/tool"},
+	})
+	return [][]byte{gzipped(t, lower), zstdCompressed(t, upper)}
+}
+
+func writeOCIImage(t *testing.T, path string, layers [][]byte) {
+	var entries []tarEntry
+	addBlob := func(data []byte) string {
+		digest := fmt.Sprintf("%x", sha256.Sum256(data))
+		entries = append(entries, tarEntry{name: "blobs/sha256/" + digest, content: string(data)})
+		return "sha256:" + digest
+	}
+
+	manifest := ociManifest{MediaType: "application/vnd.oci.image.manifest.v1+json"}
+	for _, layer := range layers {
+		manifest.Layers = append(manifest.Layers, descriptor{
+			MediaType: "application/vnd.oci.image.layer.v1.tar+gzip",
+			Digest:    addBlob(layer),
+		})
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	assert.NoError(t, err)
+
+	index, err := json.Marshal(ociManifest{
+		Manifests: []descriptor{{
+			MediaType: "application/vnd.oci.image.manifest.v1+json",
+			Digest:    addBlob(manifestJSON),
+		}},
+	})
+	assert.NoError(t, err)
+
+	entries = append(entries,
+		tarEntry{name: "oci-layout", content: `{"imageLayoutVersion":"1.0.0"}`},
+		tarEntry{name: "index.json", content: string(index)},
+	)
+	assert.NoError(t, os.WriteFile(path, buildTar(t, entries), 0644))
+}
+
+func writeDockerSaveImage(t *testing.T, path string, layers [][]byte) {
+	var entries []tarEntry
+	manifest := dockerManifest{
+		Config:   "config.json",
+		RepoTags: []string{"app:latest"},
+	}
+	for i, layer := range layers {
+		name := fmt.Sprintf("layer%d/layer.tar", i)
+		entries = append(entries, tarEntry{name: name, content: string(layer)})
+		manifest.Layers = append(manifest.Layers, name)
+	}
+	manifestJSON, err := json.Marshal([]dockerManifest{manifest})
+	assert.NoError(t, err)
+
+	entries = append(entries,
+		tarEntry{name: "config.json", content: "{}"},
+		tarEntry{name: "manifest.json", content: string(manifestJSON)},
+	)
+	assert.NoError(t, os.WriteFile(path, buildTar(t, entries), 0644))
+}
+
+func TestExtractImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(t *testing.T, path string, layers [][]byte)
+	}{
+		{name: "oci layout", write: writeOCIImage},
+		{name: "docker save", write: writeDockerSaveImage},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			dir := t.TempDir()
+			tarball := filepath.Join(dir, "image.tar")
+			test.write(t, tarball, testImageLayers(t))
+
+			root := filepath.Join(dir, "rootfs")
+			assert.NoError(os.Mkdir(root, 0755))
+			assert.NoError(extractImage(tarball, root))
+
+			content, err := os.ReadFile(filepath.Join(root, "etc/passwd"))
+			assert.NoError(err)
+			assert.Equal("root:x:0:0:root:/root:/bin/ash\n", string(content))
+
+			assert.NoFileExists(filepath.Join(root, "etc/os-release"))
+			assert.NoFileExists(filepath.Join(root, "var/lib/app/a"))
+			assert.NoFileExists(filepath.Join(root, "var/lib/app/b"))
+			assert.FileExists(filepath.Join(root, "var/lib/app/c"))
+
+			link, err := os.Readlink(filepath.Join(root, "etc/shadow-link"))
+			assert.NoError(err)
+			assert.Equal("/etc/passwd", link)
+
+			// the absolute symlinks are resolved inside the root
+			assert.FileExists(filepath.Join(root, "compliance-rootfs-escape/evil"))
+			assert.NoDirExists("/compliance-rootfs-escape")
+
+			fi, err := os.Stat(filepath.Join(root, "usr/bin/tool"))
+			assert.NoError(err)
+			assert.Equal(os.FileMode(0755)|os.ModeSetuid, fi.Mode())
+
+			linked, err := os.Stat(filepath.Join(root, "usr/bin/tool-link"))
+			assert.NoError(err)
+			assert.True(os.SameFile(fi, linked))
+
+			fi, err = os.Stat(filepath.Join(root, "tmp"))
+			assert.NoError(err)
+			assert.Equal(os.ModeDir|os.ModeSticky|0777, fi.Mode())
+
+			fi, err = os.Stat(filepath.Join(root, "readonly"))
+			assert.NoError(err)
+			assert.Equal(os.ModeDir|0555, fi.Mode())
+			assert.FileExists(filepath.Join(root, "readonly/file"))
+
+			// the read-only directories are removable by the tests
+			assert.NoError(os.Chmod(filepath.Join(root, "readonly"), 0755))
+		})
+	}
+}
+
+func TestExtractImageErrors(t *testing.T) {
+	dir := t.TempDir()
+	tarball := filepath.Join(dir, "image.tar")
+	assert.NoError(t, os.WriteFile(tarball, buildTar(t, []tarEntry{{name: "layer.tar", content: "layer"}}), 0644))
+
+	err := extractImage(tarball, dir)
+	assert.EqualError(t, err, fmt.Sprintf("failed to read image manifest of %s: neither manifest.json nor index.json found in archive", tarball))
+}
+
+func TestMapperResolveSymlinks(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(root, "etc/ssh"), 0755))
+	assert.NoError(os.MkdirAll(filepath.Join(root, "usr/etc"), 0755))
+	assert.NoError(os.Symlink("/usr/etc", filepath.Join(root, "etc/alternatives")))
+	assert.NoError(os.Symlink("/", filepath.Join(root, "host")))
+
+	m := pathMapper{
+		hostMountPath:   root,
+		resolveSymlinks: true,
+	}
+	assert.Equal(filepath.Join(root, "etc/ssh/sshd_config"), m.normalizeToHostRoot("/etc/ssh/sshd_config"))
+	assert.Equal(filepath.Join(root, "usr/etc/java"), m.normalizeToHostRoot("/etc/alternatives/java"))
+	assert.Equal(filepath.Join(root, "usr/etc/*.conf"), m.normalizeToHostRoot("/etc/alternatives/*.conf"))
+	assert.Equal(filepath.Join(root, "etc/shadow"), m.normalizeToHostRoot("/host/etc/shadow"))
+	assert.Equal("/etc/ssh/sshd_config", m.relativeToHostRoot(filepath.Join(root, "etc/ssh/sshd_config")))
+
+	// the glob matches are resolved inside the root, the ones leaving it are
+	// dropped
+	host := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(host, "sshd_host"), nil, 0644))
+	assert.NoError(os.WriteFile(filepath.Join(root, "usr/etc/sshd_config"), nil, 0644))
+	assert.NoError(os.MkdirAll(filepath.Join(root, "etc/ssh/sshd_config.d"), 0755))
+	assert.NoError(os.Symlink("/usr/etc/sshd_config", filepath.Join(root, "etc/ssh/sshd_config.d/10-link.conf")))
+	assert.NoError(os.Symlink(filepath.Join(host, "sshd_host"), filepath.Join(root, "etc/ssh/sshd_config.d/20-host.conf")))
+	assert.NoError(os.Symlink(host, filepath.Join(root, "etc/hostdir")))
+	paths, err := m.glob("/etc/ssh/sshd_config.d/*.conf")
+	assert.NoError(err)
+	assert.Equal([]string{filepath.Join(root, "usr/etc/sshd_config")}, paths)
+	paths, err = m.glob("/etc/*/sshd_host")
+	assert.NoError(err)
+	assert.Empty(paths)
+}
+
+const rootFilesystemSuite = `
+schema:
+  version: 1.0.0
+name: CIS Docker Generic
+framework: cis-docker
+version: 1.2.0
+rules:
+- id: daemon-icc
+  scope:
+    - docker
+  resources:
+    - file:
+        path: /etc/docker/daemon.json
+        parser: json
+      condition: file.jq(".icc") == "false"
+    - group:
+        name: docker
+      condition: group.id == 999
+- id: privileged-containers
+  scope:
+    - docker
+  resources:
+    - docker:
+        kind: container
+      condition: docker.template("{{ .HostConfig.Privileged }}") != "true"
+- id: dockerd-flags
+  scope:
+    - docker
+  input:
+    - process:
+        name: dockerd
+  module: package datadog
+`
+
+func TestRootFilesystemRules(t *testing.T) {
+	assert := assert.New(t)
+
+	root := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(root, "etc/docker"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(root, "etc/docker/daemon.json"), []byte(`{"icc": false}`), 0644))
+	assert.NoError(os.WriteFile(filepath.Join(root, "etc/group"), []byte("docker:x:999:\n"), 0644))
+
+	suite := filepath.Join(t.TempDir(), "cis-docker.yaml")
+	assert.NoError(os.WriteFile(suite, []byte(rootFilesystemSuite), 0644))
+
+	reporter := &mocks.Reporter{}
+	defer reporter.AssertExpectations(t)
+	reporter.On("Report", mock.MatchedBy(func(e *event.Event) bool {
+		return e.AgentRuleID == "daemon-icc" && e.Result == event.Passed
+	})).Once()
+
+	b, err := NewBuilder(reporter, WithRootFilesystem(root), WithHostname("image"))
+	assert.NoError(err)
+	defer b.Close()
+
+	checks := make(map[string]compliance.Check)
+	errs := make(map[string]error)
+	err = b.ChecksFromFile(suite, func(rule *compliance.RuleCommon, check compliance.Check, err error) bool {
+		checks[rule.ID] = check
+		errs[rule.ID] = err
+		return true
+	})
+	assert.NoError(err)
+
+	assert.NoError(errs["daemon-icc"])
+	assert.NoError(checks["daemon-icc"].Run())
+
+	assert.Equal(ErrRuleDoesNotApply, errs["privileged-containers"])
+	assert.Equal(ErrRuleDoesNotApply, errs["dockerd-flags"])
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const procSysPath = "/proc/sys"
+
+var sysctlReportedFields = []string{
+	compliance.SysctlFieldName,
+	compliance.SysctlFieldValue,
+}
+
+func resolveSysctl(_ context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.Sysctl == nil {
+		return nil, fmt.Errorf("%s: expecting sysctl resource in sysctl check", ruleID)
+	}
+
+	sysctl := res.Sysctl
+
+	log.Debugf("%s: running sysctl check for %q", ruleID, sysctl.Name)
+
+	paths, err := filepath.Glob(e.NormalizeToHostRoot(sysctlPath(sysctl.Name)))
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []resolvedInstance
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			// some parameters are write-only
+			log.Debugf("%s: failed to read sysctl %s: %v", ruleID, path, err)
+			continue
+		}
+
+		name := sysctlName(e.RelativeToHostRoot(path))
+		// the values of the parameters with several fields are separated by tabs
+		value := strings.Join(strings.Fields(string(content)), " ")
+
+		instance := eval.NewInstance(
+			eval.VarMap{
+				compliance.SysctlFieldName:  name,
+				compliance.SysctlFieldValue: value,
+			},
+			nil,
+			eval.RegoInputMap{
+				"name":  name,
+				"value": value,
+			},
+		)
+		instances = append(instances, newResolvedInstance(instance, name, "sysctl"))
+	}
+
+	if len(instances) == 0 {
+		if rego {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("no sysctl found for sysctl check %q", sysctl.Name)
+	}
+
+	if len(instances) == 1 {
+		return instances[0].(*_resolvedInstance), nil
+	}
+
+	return newResolvedInstances(instances), nil
+}
+
+// sysctlPath returns the path of a kernel parameter under /proc/sys, the
+// parameters can be written with dots or slashes as sysctl does
+func sysctlPath(name string) string {
+	if !strings.Contains(name, "/") {
+		name = strings.ReplaceAll(name, ".", "/")
+	}
+	return filepath.Join(procSysPath, name)
+}
+
+// sysctlName returns the name of a kernel parameter from its path
+func sysctlName(path string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(path, procSysPath), "/")
+	return strings.ReplaceAll(name, "/", ".")
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	"github.com/stretchr/testify/mock"
+	assert "github.com/stretchr/testify/require"
+)
+
+// newHostRootEnv returns an env whose host root is mounted on a testdata
+// directory
+func newHostRootEnv(root string) *mocks.Env {
+	env := &mocks.Env{}
+	env.On("NormalizeToHostRoot", mock.Anything).Return(func(path string) string {
+		return filepath.Join(root, path)
+	})
+	env.On("RelativeToHostRoot", mock.Anything).Return(func(path string) string {
+		return strings.TrimPrefix(path, filepath.Clean(root))
+	})
+	return env
+}
+
+func sysctlReport(name, value string, passed bool) *compliance.Report {
+	return &compliance.Report{
+		Passed: passed,
+		Data: event.Data{
+			"sysctl.name":  name,
+			"sysctl.value": value,
+		},
+		Resource: compliance.ReportResource{
+			ID:   name,
+			Type: "sysctl",
+		},
+	}
+}
+
+func TestSysctlCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource compliance.Resource
+
+		expectReports []*compliance.Report
+		expectError   string
+	}{
+		{
+			name: "parameter",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Name: "net.ipv4.ip_forward",
+					},
+				},
+				Condition: `sysctl.value == "0"`,
+			},
+			expectReports: []*compliance.Report{
+				sysctlReport("net.ipv4.ip_forward", "0", true),
+			},
+		},
+		{
+			name: "parameter with slashes and several values",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Name: "net/ipv4/tcp_rmem",
+					},
+				},
+				Condition: `sysctl.value == "4096 131072 6291456"`,
+			},
+			expectReports: []*compliance.Report{
+				sysctlReport("net.ipv4.tcp_rmem", "4096 131072 6291456", true),
+			},
+		},
+		{
+			name: "glob",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Name: "net.ipv4.conf.*.accept_redirects",
+					},
+				},
+				Condition: `sysctl.value == "0"`,
+			},
+			expectReports: []*compliance.Report{
+				sysctlReport("net.ipv4.conf.all.accept_redirects", "0", true),
+				sysctlReport("net.ipv4.conf.default.accept_redirects", "1", false),
+			},
+		},
+		{
+			name: "unknown parameter",
+			resource: compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					Sysctl: &compliance.Sysctl{
+						Name: "kernel.unknown",
+					},
+				},
+				Condition: `sysctl.value == "0"`,
+			},
+			expectError: `no sysctl found for sysctl check "kernel.unknown"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			env := newHostRootEnv("./testdata/sysctl")
+
+			sysctlCheck, err := newResourceCheck(env, "rule-id", test.resource)
+			assert.NoError(err)
+
+			reports := sysctlCheck.check(env)
+			if test.expectError != "" {
+				assert.EqualError(reports[0].Error, test.expectError)
+				return
+			}
+			assert.Equal(test.expectReports, reports)
+		})
+	}
+}
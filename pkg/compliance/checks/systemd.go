@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package checks
+
+import (
+	"github.com/coreos/go-systemd/dbus"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/systemd"
+)
+
+// newSystemdClient connects to systemd with its private socket, which is
+// reachable from a container with the host root mounted, and falls back to the
+// system bus
+func newSystemdClient(privateSocket string) (env.SystemdClient, error) {
+	conn, err := systemd.NewSystemdConnection(privateSocket)
+	if err != nil {
+		log.Debugf("Failed to connect to systemd with the private socket %s: %v", privateSocket, err)
+		if conn, err = dbus.NewSystemConnection(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &systemdClient{
+		conn: conn,
+	}, nil
+}
+
+type systemdClient struct {
+	conn *dbus.Conn
+}
+
+func (c *systemdClient) GetUnitProperties(unit string) (map[string]interface{}, error) {
+	return c.conn.GetUnitProperties(unit)
+}
+
+func (c *systemdClient) Close() error {
+	c.conn.Close()
+	return nil
+}
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/checks/env"
+	"github.com/DataDog/datadog-agent/pkg/compliance/eval"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const systemdPrivateSocket = "/run/systemd/private"
+
+var systemdUnitReportedFields = []string{
+	compliance.SystemdUnitFieldName,
+	compliance.SystemdUnitFieldLoadState,
+	compliance.SystemdUnitFieldActiveState,
+	compliance.SystemdUnitFieldUnitFileState,
+}
+
+// systemdUnitTypes are the suffixes of the unit names
+var systemdUnitTypes = map[string]struct{}{
+	".service":   {},
+	".socket":    {},
+	".device":    {},
+	".mount":     {},
+	".automount": {},
+	".swap":      {},
+	".target":    {},
+	".path":      {},
+	".timer":     {},
+	".slice":     {},
+	".scope":     {},
+}
+
+func resolveSystemdUnit(_ context.Context, e env.Env, ruleID string, res compliance.ResourceCommon, rego bool) (resolved, error) {
+	if res.SystemdUnit == nil {
+		return nil, fmt.Errorf("%s: expecting systemd unit resource in systemd unit check", ruleID)
+	}
+
+	name := systemdUnitName(res.SystemdUnit.Name)
+
+	log.Debugf("%s: running systemd unit check for %q", ruleID, name)
+
+	// systemd loads the unit files on demand, the properties of the units
+	// which are not installed have the not-found load state
+	properties, err := e.SystemdClient().GetUnitProperties(name)
+	if err != nil {
+		return nil, wrapErrorWithID(ruleID, err)
+	}
+
+	loadState := stringProperty(properties, "LoadState")
+	activeState := stringProperty(properties, "ActiveState")
+	subState := stringProperty(properties, "SubState")
+	unitFileState := stringProperty(properties, "UnitFileState")
+	active := activeState == "active"
+	enabled := unitFileState == "enabled" || unitFileState == "enabled-runtime"
+
+	instance := eval.NewInstance(
+		eval.VarMap{
+			compliance.SystemdUnitFieldName:          name,
+			compliance.SystemdUnitFieldLoadState:     loadState,
+			compliance.SystemdUnitFieldActiveState:   activeState,
+			compliance.SystemdUnitFieldSubState:      subState,
+			compliance.SystemdUnitFieldUnitFileState: unitFileState,
+			compliance.SystemdUnitFieldActive:        active,
+			compliance.SystemdUnitFieldEnabled:       enabled,
+		},
+		nil,
+		eval.RegoInputMap{
+			"name":          name,
+			"loadState":     loadState,
+			"activeState":   activeState,
+			"subState":      subState,
+			"unitFileState": unitFileState,
+			"active":        active,
+			"enabled":       enabled,
+		},
+	)
+
+	return newResolvedInstance(instance, name, "systemd_unit"), nil
+}
+
+// systemdUnitName adds the .service suffix to the unit names without type, as
+// systemctl does
+func systemdUnitName(name string) string {
+	if _, ok := systemdUnitTypes[filepath.Ext(name)]; ok {
+		return name
+	}
+	return name + ".service"
+}
+
+func stringProperty(properties map[string]interface{}, name string) string {
+	value, _ := properties[name].(string)
+	return value
+}
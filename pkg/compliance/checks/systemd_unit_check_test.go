@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance"
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestSystemdUnitCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		unit       string
+		condition  string
+		properties map[string]interface{}
+		err        error
+
+		expectReport *compliance.Report
+		expectError  string
+	}{
+		{
+			name:      "enabled service",
+			unit:      "auditd",
+			condition: `unit.enabled && unit.active`,
+			properties: map[string]interface{}{
+				"Id":            "auditd.service",
+				"LoadState":     "loaded",
+				"ActiveState":   "active",
+				"SubState":      "running",
+				"UnitFileState": "enabled",
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"unit.name":          "auditd.service",
+					"unit.loadState":     "loaded",
+					"unit.activeState":   "active",
+					"unit.unitFileState": "enabled",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "auditd.service",
+					Type: "systemd_unit",
+				},
+			},
+		},
+		{
+			name:      "masked socket",
+			unit:      "rpcbind.socket",
+			condition: `unit.unitFileState == "masked" || unit.loadState == "not-found"`,
+			properties: map[string]interface{}{
+				"LoadState":     "masked",
+				"ActiveState":   "inactive",
+				"SubState":      "dead",
+				"UnitFileState": "masked",
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"unit.name":          "rpcbind.socket",
+					"unit.loadState":     "masked",
+					"unit.activeState":   "inactive",
+					"unit.unitFileState": "masked",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "rpcbind.socket",
+					Type: "systemd_unit",
+				},
+			},
+		},
+		{
+			name:      "not installed",
+			unit:      "telnet.socket",
+			condition: `!unit.enabled`,
+			properties: map[string]interface{}{
+				"LoadState":     "not-found",
+				"ActiveState":   "inactive",
+				"SubState":      "dead",
+				"UnitFileState": "",
+			},
+			expectReport: &compliance.Report{
+				Passed: true,
+				Data: event.Data{
+					"unit.name":          "telnet.socket",
+					"unit.loadState":     "not-found",
+					"unit.activeState":   "inactive",
+					"unit.unitFileState": "",
+				},
+				Resource: compliance.ReportResource{
+					ID:   "telnet.socket",
+					Type: "systemd_unit",
+				},
+			},
+		},
+		{
+			name:        "D-Bus error",
+			unit:        "sshd",
+			condition:   `unit.active`,
+			err:         errors.New("connection closed"),
+			expectError: "rule-id: connection closed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			client := &mocks.SystemdClient{}
+			client.On("GetUnitProperties", systemdUnitName(test.unit)).Return(test.properties, test.err)
+			defer client.AssertExpectations(t)
+
+			env := &mocks.Env{}
+			env.On("SystemdClient").Return(client)
+
+			unitCheck, err := newResourceCheck(env, "rule-id", compliance.Resource{
+				ResourceCommon: compliance.ResourceCommon{
+					SystemdUnit: &compliance.SystemdUnit{
+						Name: test.unit,
+					},
+				},
+				Condition: test.condition,
+			})
+			assert.NoError(err)
+
+			reports := unitCheck.check(env)
+			if test.expectError != "" {
+				assert.EqualError(reports[0].Error, test.expectError)
+				return
+			}
+			assert.Equal(test.expectReport, reports[0])
+		})
+	}
+}
+
+func TestSystemdUnitCheckWithoutClient(t *testing.T) {
+	env := &mocks.Env{}
+	env.On("SystemdClient").Return(nil)
+
+	_, err := newResourceCheck(env, "rule-id", compliance.Resource{
+		ResourceCommon: compliance.ResourceCommon{
+			SystemdUnit: &compliance.SystemdUnit{
+				Name: "sshd",
+			},
+		},
+		Condition: `unit.active`,
+	})
+	assert.Error(t, err)
+}
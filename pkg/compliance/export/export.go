@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package export converts the compliance rule events to the report formats
+// consumed by CI systems and auditors
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+// Format is a report format
+type Format string
+
+const (
+	// FormatJSON is the JSON dump of the events, grouped by rule ID
+	FormatJSON = Format("json")
+	// FormatSARIF is the Static Analysis Results Interchange Format 2.1.0
+	FormatSARIF = Format("sarif")
+	// FormatJUnit is the JUnit XML format
+	FormatJUnit = Format("junit")
+	// FormatXCCDF is the XCCDF 1.2 test result format
+	FormatXCCDF = Format("xccdf")
+)
+
+// Formats lists the supported report formats
+var Formats = []Format{FormatJSON, FormatSARIF, FormatJUnit, FormatXCCDF}
+
+// ParseFormat returns the report format of the given name
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported report format `%s`, expecting one of %v", name, Formats)
+}
+
+// Metadata describes the run of the checks which produced the events
+type Metadata struct {
+	Hostname     string
+	AgentVersion string
+	StartTime    time.Time
+	EndTime      time.Time
+}
+
+// WriteReport writes the events in the given format
+func WriteReport(w io.Writer, format Format, events []*event.Event, meta Metadata) error {
+	events = sortEvents(events)
+
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, events)
+	case FormatSARIF:
+		return writeSARIF(w, events, meta)
+	case FormatJUnit:
+		return writeJUnit(w, events, meta)
+	case FormatXCCDF:
+		return writeXCCDF(w, events, meta)
+	default:
+		return fmt.Errorf("unsupported report format `%s`", format)
+	}
+}
+
+// sortEvents returns the events sorted by framework, rule and resource, so
+// that the reports of the same results are identical
+func sortEvents(events []*event.Event) []*event.Event {
+	sorted := make([]*event.Event, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.AgentFrameworkID != b.AgentFrameworkID {
+			return a.AgentFrameworkID < b.AgentFrameworkID
+		}
+		if a.AgentRuleID != b.AgentRuleID {
+			return a.AgentRuleID < b.AgentRuleID
+		}
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		return a.ResourceID < b.ResourceID
+	})
+	return sorted
+}
+
+func writeJSON(w io.Writer, events []*event.Event) error {
+	byRule := make(map[string][]*event.Event)
+	for _, e := range events {
+		byRule[e.AgentRuleID] = append(byRule[e.AgentRuleID], e)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(byRule)
+}
+
+// evidence returns the data of an event as JSON
+func evidence(e *event.Event) string {
+	if e.Data == nil {
+		return ""
+	}
+	b, err := json.Marshal(e.Data)
+	if err != nil {
+		return fmt.Sprintf("%v", e.Data)
+	}
+	return string(b)
+}
+
+// resourceName returns the resource of an event as type:id
+func resourceName(e *event.Event) string {
+	switch {
+	case e.ResourceType == "":
+		return e.ResourceID
+	case e.ResourceID == "":
+		return e.ResourceType
+	default:
+		return e.ResourceType + ":" + e.ResourceID
+	}
+}
+
+// frameworkName returns the framework of an event, which is empty for the
+// rules of custom suites
+func frameworkName(e *event.Event) string {
+	if e.AgentFrameworkID == "" {
+		return "default"
+	}
+	return e.AgentFrameworkID
+}
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+var testMeta = Metadata{
+	Hostname:     "builder",
+	AgentVersion: "7.35.0",
+	StartTime:    time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+	EndTime:      time.Date(2022, 3, 1, 10, 0, 5, 0, time.UTC),
+}
+
+func testEvents() []*event.Event {
+	return []*event.Event{
+		{
+			AgentRuleID:      "cis-docker-1.2.1-5.4",
+			AgentFrameworkID: "cis-docker",
+			Result:           event.Failed,
+			ResourceType:     "docker_container",
+			ResourceID:       "c2",
+			Data:             event.Data{"container.privileged": true},
+		},
+		{
+			AgentRuleID:      "cis-docker-1.2.1-2.1",
+			AgentFrameworkID: "cis-docker",
+			Result:           event.Passed,
+			ResourceType:     "docker_daemon",
+			ResourceID:       "builder",
+			Data:             event.Data{"process.name": "dockerd"},
+		},
+		{
+			AgentRuleID:      "cis-docker-1.2.1-5.4",
+			AgentFrameworkID: "cis-docker",
+			Result:           event.Passed,
+			ResourceType:     "docker_container",
+			ResourceID:       "c1",
+			Data:             event.Data{"container.privileged": false},
+		},
+		{
+			AgentRuleID:      "cis-debian-1.1.1",
+			AgentFrameworkID: "cis-debian",
+			Result:           event.Error,
+			ResourceType:     "kernel_module",
+			ResourceID:       "cramfs",
+			Data:             event.Data{"error": "open /proc/modules: permission denied"},
+		},
+	}
+}
+
+func writeReport(t *testing.T, format Format, events []*event.Event) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(&buf, format, events, testMeta))
+	return buf.Bytes()
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("SARIF")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatSARIF, format)
+
+	_, err = ParseFormat("csv")
+	assert.Error(t, err)
+}
+
+func TestSARIF(t *testing.T) {
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(writeReport(t, FormatSARIF, testEvents()), &log))
+
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	assert.Equal(t, "7.35.0", run.Tool.Driver.Version)
+	assert.Equal(t, "builder", run.Invocations[0].Machine)
+	assert.Equal(t, "2022-03-01T10:00:00Z", run.Invocations[0].StartTimeUTC)
+
+	var ruleIDs []string
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	assert.Equal(t, []string{"cis-debian-1.1.1", "cis-docker-1.2.1-2.1", "cis-docker-1.2.1-5.4"}, ruleIDs)
+
+	require.Len(t, run.Results, 4)
+	assert.Equal(t, "cis-debian-1.1.1", run.Results[0].RuleID)
+	assert.Equal(t, "open", run.Results[0].Kind)
+	assert.Equal(t, "none", run.Results[0].Level)
+
+	failure := run.Results[3]
+	assert.Equal(t, "cis-docker-1.2.1-5.4", failure.RuleID)
+	assert.Equal(t, 2, failure.RuleIndex)
+	assert.Equal(t, "fail", failure.Kind)
+	assert.Equal(t, "error", failure.Level)
+	assert.Equal(t, "docker_container:c2", failure.Locations[0].LogicalLocations[0].FullyQualifiedName)
+	assert.Equal(t, `Rule cis-docker-1.2.1-5.4 failed on docker_container:c2: {"container.privileged":true}`, failure.Message.Text)
+	assert.Equal(t, map[string]interface{}{"container.privileged": true}, failure.Properties.Evidence)
+}
+
+func TestJUnit(t *testing.T) {
+	var suites junitTestSuites
+	require.NoError(t, xml.Unmarshal(writeReport(t, FormatJUnit, testEvents()), &suites))
+
+	assert.Equal(t, 4, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 1, suites.Errors)
+	assert.Equal(t, "5.000", suites.Time)
+
+	require.Len(t, suites.Suites, 2)
+	assert.Equal(t, "cis-debian", suites.Suites[0].Name)
+	assert.Equal(t, 1, suites.Suites[0].Errors)
+
+	docker := suites.Suites[1]
+	assert.Equal(t, "cis-docker", docker.Name)
+	assert.Equal(t, "builder", docker.Hostname)
+	require.Len(t, docker.Cases, 3)
+	assert.Equal(t, "cis-docker-1.2.1-5.4 docker_container:c1", docker.Cases[1].Name)
+	assert.Nil(t, docker.Cases[1].Failure)
+	assert.Equal(t, "cis-docker.cis-docker-1.2.1-5.4", docker.Cases[2].ClassName)
+	require.NotNil(t, docker.Cases[2].Failure)
+	assert.Equal(t, `{"container.privileged":true}`, docker.Cases[2].Failure.Content)
+}
+
+func TestXCCDF(t *testing.T) {
+	var benchmark xccdfBenchmark
+	require.NoError(t, xml.Unmarshal(writeReport(t, FormatXCCDF, testEvents()), &benchmark))
+
+	assert.Equal(t, "xccdf_com.datadoghq_benchmark_datadog-security-agent", benchmark.ID)
+	require.Len(t, benchmark.Groups, 2)
+	assert.Equal(t, "xccdf_com.datadoghq_group_cis-docker", benchmark.Groups[1].ID)
+	require.Len(t, benchmark.Groups[1].Rules, 2)
+	assert.Equal(t, "xccdf_com.datadoghq_rule_cis-docker_cis-docker-1.2.1-5.4", benchmark.Groups[1].Rules[1].ID)
+
+	result := benchmark.TestResult
+	assert.Equal(t, "builder", result.Target)
+	require.Len(t, result.RuleResults, 4)
+	assert.Equal(t, "error", result.RuleResults[0].Result)
+	assert.Equal(t, "fail", result.RuleResults[3].Result)
+	assert.Equal(t, "docker_container:c2", result.RuleResults[3].Instance.Value)
+	assert.Equal(t, "xccdf_com.datadoghq_rule_cis-docker_cis-docker-1.2.1-5.4", result.RuleResults[3].IDRef)
+	assert.Equal(t, "2", result.Score.Value)
+	assert.Equal(t, "3", result.Score.Maximum)
+}
+
+func TestReportsAreStable(t *testing.T) {
+	events := testEvents()
+	reversed := make([]*event.Event, len(events))
+	for i, e := range events {
+		reversed[len(events)-1-i] = e
+	}
+
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			assert.Equal(t, string(writeReport(t, format, events)), string(writeReport(t, format, reversed)))
+		})
+	}
+}
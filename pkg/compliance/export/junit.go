@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr,omitempty"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Hostname  string          `xml:"hostname,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// writeJUnit writes a test suite per framework with a test case per rule and
+// resource
+func writeJUnit(w io.Writer, events []*event.Event, meta Metadata) error {
+	suites := junitTestSuites{
+		Name: toolName,
+	}
+	if !meta.StartTime.IsZero() && !meta.EndTime.IsZero() {
+		suites.Time = fmt.Sprintf("%.3f", meta.EndTime.Sub(meta.StartTime).Seconds())
+	}
+
+	var suite *junitTestSuite
+	for _, e := range events {
+		framework := frameworkName(e)
+		if suite == nil || suite.Name != framework {
+			suites.Suites = append(suites.Suites, junitTestSuite{
+				Name:     framework,
+				Hostname: meta.Hostname,
+			})
+			suite = &suites.Suites[len(suites.Suites)-1]
+			if !meta.StartTime.IsZero() {
+				suite.Timestamp = meta.StartTime.UTC().Format(time.RFC3339)
+			}
+		}
+
+		name := e.AgentRuleID
+		if resource := resourceName(e); resource != "" {
+			name += " " + resource
+		}
+		testCase := junitTestCase{
+			Name:      name,
+			ClassName: framework + "." + e.AgentRuleID,
+		}
+		switch e.Result {
+		case event.Passed:
+			testCase.SystemOut = evidence(e)
+		case event.Failed:
+			testCase.Failure = &junitProblem{Message: resultMessage(e), Type: e.Result, Content: evidence(e)}
+			suite.Failures++
+		default:
+			testCase.Error = &junitProblem{Message: resultMessage(e), Type: e.Result, Content: evidence(e)}
+			suite.Errors++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	for _, s := range suites.Suites {
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Errors += s.Errors
+	}
+
+	return writeXML(w, &suites)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
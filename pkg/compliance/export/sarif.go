@@ -0,0 +1,194 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "datadog-security-agent"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string              `json:"id"`
+	ShortDescription sarifMessage        `json:"shortDescription"`
+	Properties       sarifRuleProperties `json:"properties"`
+}
+
+type sarifRuleProperties struct {
+	Framework string `json:"framework"`
+	Version   int    `json:"version,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool   `json:"executionSuccessful"`
+	StartTimeUTC        string `json:"startTimeUtc,omitempty"`
+	EndTimeUTC          string `json:"endTimeUtc,omitempty"`
+	Machine             string `json:"machine,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string                `json:"ruleId"`
+	RuleIndex  int                   `json:"ruleIndex"`
+	Kind       string                `json:"kind"`
+	Level      string                `json:"level"`
+	Message    sarifMessage          `json:"message"`
+	Locations  []sarifLocation       `json:"locations,omitempty"`
+	Properties sarifResultProperties `json:"properties"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+type sarifResultProperties struct {
+	Result    string      `json:"result"`
+	Evidence  interface{} `json:"evidence,omitempty"`
+	Evaluator string      `json:"evaluator,omitempty"`
+}
+
+// sarifKindAndLevel maps the result of a rule to the kind and level of a SARIF
+// result, the level of the results which are not failures must be none
+func sarifKindAndLevel(result string) (string, string) {
+	switch result {
+	case event.Passed:
+		return "pass", "none"
+	case event.Failed:
+		return "fail", "error"
+	default:
+		// the rule could not be evaluated
+		return "open", "none"
+	}
+}
+
+func writeSARIF(w io.Writer, events []*event.Event, meta Metadata) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:    toolName,
+				Version: meta.AgentVersion,
+				Rules:   []sarifRule{},
+			},
+		},
+		Invocations: []sarifInvocation{
+			{
+				ExecutionSuccessful: true,
+				StartTimeUTC:        formatTime(meta.StartTime),
+				EndTimeUTC:          formatTime(meta.EndTime),
+				Machine:             meta.Hostname,
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	ruleIndexes := make(map[string]int)
+	for _, e := range events {
+		index, ok := ruleIndexes[e.AgentRuleID]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			ruleIndexes[e.AgentRuleID] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               e.AgentRuleID,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("%s rule %s", frameworkName(e), e.AgentRuleID)},
+				Properties: sarifRuleProperties{
+					Framework: frameworkName(e),
+					Version:   e.AgentRuleVersion,
+				},
+			})
+		}
+
+		kind, level := sarifKindAndLevel(e.Result)
+		result := sarifResult{
+			RuleID:    e.AgentRuleID,
+			RuleIndex: index,
+			Kind:      kind,
+			Level:     level,
+			Message:   sarifMessage{Text: resultMessage(e)},
+			Properties: sarifResultProperties{
+				Result:    e.Result,
+				Evidence:  e.Data,
+				Evaluator: e.Evaluator,
+			},
+		}
+		if name := resourceName(e); name != "" {
+			result.Locations = []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{
+					Name:               e.ResourceID,
+					FullyQualifiedName: name,
+					Kind:               e.ResourceType,
+				}},
+			}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// resultMessage describes the result of a rule on a resource
+func resultMessage(e *event.Event) string {
+	message := fmt.Sprintf("Rule %s %s", e.AgentRuleID, e.Result)
+	if name := resourceName(e); name != "" {
+		message += " on " + name
+	}
+	if ev := evidence(e); ev != "" {
+		message += ": " + ev
+	}
+	return message
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
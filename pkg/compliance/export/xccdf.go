@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+const (
+	xccdfNamespace = "http://checklists.nist.gov/xccdf/1.2"
+	// xccdfIDPrefix is the reverse DNS name used in the XCCDF 1.2 identifiers
+	xccdfIDPrefix = "xccdf_com.datadoghq_"
+	// xccdfScoringSystem counts the rules which passed, out of those which
+	// passed or failed
+	xccdfScoringSystem = "urn:xccdf:scoring:flat-unweighted"
+)
+
+type xccdfBenchmark struct {
+	XMLName    xml.Name        `xml:"Benchmark"`
+	Namespace  string          `xml:"xmlns,attr"`
+	ID         string          `xml:"id,attr"`
+	Resolved   string          `xml:"resolved,attr"`
+	Status     string          `xml:"status"`
+	Title      string          `xml:"title"`
+	Version    string          `xml:"version"`
+	Groups     []xccdfGroup    `xml:"Group"`
+	TestResult xccdfTestResult `xml:"TestResult"`
+}
+
+type xccdfGroup struct {
+	ID    string      `xml:"id,attr"`
+	Title string      `xml:"title"`
+	Rules []xccdfRule `xml:"Rule"`
+}
+
+type xccdfRule struct {
+	ID       string `xml:"id,attr"`
+	Selected bool   `xml:"selected,attr"`
+	Title    string `xml:"title"`
+}
+
+type xccdfTestResult struct {
+	ID          string            `xml:"id,attr"`
+	StartTime   string            `xml:"start-time,attr,omitempty"`
+	EndTime     string            `xml:"end-time,attr"`
+	Title       string            `xml:"title"`
+	Target      string            `xml:"target"`
+	RuleResults []xccdfRuleResult `xml:"rule-result"`
+	Score       xccdfScore        `xml:"score"`
+}
+
+type xccdfRuleResult struct {
+	IDRef    string         `xml:"idref,attr"`
+	Time     string         `xml:"time,attr,omitempty"`
+	Result   string         `xml:"result"`
+	Instance *xccdfInstance `xml:"instance,omitempty"`
+	Message  *xccdfMessage  `xml:"message,omitempty"`
+}
+
+type xccdfInstance struct {
+	Context string `xml:"context,attr"`
+	Value   string `xml:",chardata"`
+}
+
+type xccdfMessage struct {
+	Severity string `xml:"severity,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type xccdfScore struct {
+	System  string `xml:"system,attr"`
+	Maximum string `xml:"maximum,attr"`
+	Value   string `xml:",chardata"`
+}
+
+// xccdfResult maps the result of a rule to an XCCDF rule result
+func xccdfResult(result string) string {
+	switch result {
+	case event.Passed:
+		return "pass"
+	case event.Failed:
+		return "fail"
+	default:
+		return "error"
+	}
+}
+
+// xccdfID returns an XCCDF 1.2 identifier of the given type, the characters
+// which are not allowed in the identifiers are replaced with underscores
+func xccdfID(kind, name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+	return xccdfIDPrefix + kind + "_" + name
+}
+
+// writeXCCDF writes a benchmark with a group of rules per framework and the
+// test result of the run
+func writeXCCDF(w io.Writer, events []*event.Event, meta Metadata) error {
+	endTime := meta.EndTime
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+	target := meta.Hostname
+	if target == "" {
+		target = "unknown"
+	}
+
+	benchmark := xccdfBenchmark{
+		Namespace: xccdfNamespace,
+		ID:        xccdfID("benchmark", toolName),
+		Resolved:  "1",
+		Status:    "accepted",
+		Title:     "Datadog compliance rules",
+		Version:   meta.AgentVersion,
+		TestResult: xccdfTestResult{
+			ID:        xccdfID("testresult", target),
+			StartTime: formatTime(meta.StartTime),
+			EndTime:   formatTime(endTime),
+			Title:     "Datadog compliance check results",
+			Target:    target,
+		},
+	}
+	if benchmark.Version == "" {
+		benchmark.Version = "0"
+	}
+
+	var group *xccdfGroup
+	seenRules := make(map[string]bool)
+	var passed, scored int
+	for _, e := range events {
+		framework := frameworkName(e)
+		if group == nil || group.Title != framework {
+			benchmark.Groups = append(benchmark.Groups, xccdfGroup{
+				ID:    xccdfID("group", framework),
+				Title: framework,
+			})
+			group = &benchmark.Groups[len(benchmark.Groups)-1]
+		}
+
+		ruleID := xccdfID("rule", framework+"_"+e.AgentRuleID)
+		if !seenRules[ruleID] {
+			seenRules[ruleID] = true
+			group.Rules = append(group.Rules, xccdfRule{
+				ID:       ruleID,
+				Selected: true,
+				Title:    e.AgentRuleID,
+			})
+		}
+
+		ruleResult := xccdfRuleResult{
+			IDRef:   ruleID,
+			Time:    formatTime(meta.EndTime),
+			Result:  xccdfResult(e.Result),
+			Message: &xccdfMessage{Severity: "info", Value: resultMessage(e)},
+		}
+		if name := resourceName(e); name != "" {
+			ruleResult.Instance = &xccdfInstance{Context: "resource", Value: name}
+		}
+		benchmark.TestResult.RuleResults = append(benchmark.TestResult.RuleResults, ruleResult)
+
+		switch e.Result {
+		case event.Passed:
+			passed++
+			scored++
+		case event.Failed:
+			scored++
+		}
+	}
+
+	benchmark.TestResult.Score = xccdfScore{
+		System:  xccdfScoringSystem,
+		Maximum: strconv.Itoa(scored),
+		Value:   strconv.Itoa(passed),
+	}
+
+	return writeXML(w, &benchmark)
+}
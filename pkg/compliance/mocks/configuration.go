@@ -49,6 +49,29 @@ func (_m *Configuration) EvaluateFromCache(e eval.Evaluatable) (interface{}, err
 	return r0, r1
 }
 
+// GlobHostRoot provides a mock function with given fields: pattern
+func (_m *Configuration) GlobHostRoot(pattern string) ([]string, error) {
+	ret := _m.Called(pattern)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(pattern)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(pattern)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Hostname provides a mock function with given fields:
 func (_m *Configuration) Hostname() string {
 	ret := _m.Called()
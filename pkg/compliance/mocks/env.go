@@ -99,6 +99,29 @@ func (_m *Env) EvaluateFromCache(e eval.Evaluatable) (interface{}, error) {
 	return r0, r1
 }
 
+// GlobHostRoot provides a mock function with given fields: pattern
+func (_m *Env) GlobHostRoot(pattern string) ([]string, error) {
+	ret := _m.Called(pattern)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(pattern)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(pattern)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Hostname provides a mock function with given fields:
 func (_m *Env) Hostname() string {
 	ret := _m.Called()
@@ -232,3 +255,19 @@ func (_m *Env) Reporter() event.Reporter {
 
 	return r0
 }
+
+// SystemdClient provides a mock function with given fields:
+func (_m *Env) SystemdClient() env.SystemdClient {
+	ret := _m.Called()
+
+	var r0 env.SystemdClient
+	if rf, ok := ret.Get(0).(func() env.SystemdClient); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(env.SystemdClient)
+		}
+	}
+
+	return r0
+}
@@ -30,6 +30,14 @@ const (
 	KindAudit = ResourceKind("audit")
 	// KindKubernetes is used for a KubernetesResource
 	KindKubernetes = ResourceKind("kubernetes")
+	// KindPackage is used for a Package resource
+	KindPackage = ResourceKind("package")
+	// KindSysctl is used for a Sysctl resource
+	KindSysctl = ResourceKind("sysctl")
+	// KindKernelModule is used for a KernelModule resource
+	KindKernelModule = ResourceKind("kernel_module")
+	// KindSystemdUnit is used for a SystemdUnit resource
+	KindSystemdUnit = ResourceKind("systemd_unit")
 	// KindConstants is used for Constants check
 	KindConstants = ResourceKind("constants")
 	// KindCustom is used for a Custom check
@@ -45,6 +53,10 @@ type ResourceCommon struct {
 	Audit         *Audit              `yaml:"audit,omitempty"`
 	Docker        *DockerResource     `yaml:"docker,omitempty"`
 	KubeApiserver *KubernetesResource `yaml:"kubeApiserver,omitempty"`
+	Package       *Package            `yaml:"package,omitempty"`
+	Sysctl        *Sysctl             `yaml:"sysctl,omitempty"`
+	KernelModule  *KernelModule       `yaml:"kernelModule,omitempty"`
+	SystemdUnit   *SystemdUnit        `yaml:"systemdUnit,omitempty"`
 	Constants     *ConstantsResource  `yaml:"constants,omitempty"`
 	Custom        *Custom             `yaml:"custom,omitempty"`
 }
@@ -92,6 +104,14 @@ func (r *ResourceCommon) Kind() ResourceKind {
 		return KindDocker
 	case r.KubeApiserver != nil:
 		return KindKubernetes
+	case r.Package != nil:
+		return KindPackage
+	case r.Sysctl != nil:
+		return KindSysctl
+	case r.KernelModule != nil:
+		return KindKernelModule
+	case r.SystemdUnit != nil:
+		return KindSystemdUnit
 	case r.Constants != nil:
 		return KindConstants
 	case r.Custom != nil:
@@ -195,6 +215,91 @@ type Group struct {
 	Name string `yaml:"name"`
 }
 
+// Package managers supported by the Package resource
+const (
+	PackageManagerDpkg = "dpkg"
+	PackageManagerRPM  = "rpm"
+	PackageManagerAPK  = "apk"
+)
+
+// Fields & functions available for Package
+const (
+	PackageFieldName      = "package.name"
+	PackageFieldVersion   = "package.version"
+	PackageFieldArch      = "package.arch"
+	PackageFieldManager   = "package.manager"
+	PackageFieldInstalled = "package.installed"
+
+	PackageFuncVersionCompare = "package.versionCompare"
+	PackageFuncVersionAtLeast = "package.versionAtLeast"
+)
+
+// Package describes an OS package resource, which is resolved whether the
+// package is installed or not
+type Package struct {
+	Name string `yaml:"name"`
+	// Manager is the package manager whose database is read, it is detected
+	// from the databases found on the host when empty
+	Manager string `yaml:"manager,omitempty"`
+}
+
+// Validate validates package resource
+func (p *Package) Validate() error {
+	if len(p.Name) == 0 {
+		return errors.New("package resource is missing name")
+	}
+	switch p.Manager {
+	case "", PackageManagerDpkg, PackageManagerRPM, PackageManagerAPK:
+		return nil
+	default:
+		return fmt.Errorf("package resource has unsupported manager `%s`", p.Manager)
+	}
+}
+
+// Fields available for Sysctl
+const (
+	SysctlFieldName  = "sysctl.name"
+	SysctlFieldValue = "sysctl.value"
+)
+
+// Sysctl describes a kernel parameter resource, read from /proc/sys
+type Sysctl struct {
+	// Name is the name of the parameter, such as net.ipv4.ip_forward. It can
+	// be a glob pattern, such as net.ipv4.conf.*.accept_redirects
+	Name string `yaml:"name"`
+}
+
+// Fields available for KernelModule
+const (
+	KernelModuleFieldName           = "module.name"
+	KernelModuleFieldLoaded         = "module.loaded"
+	KernelModuleFieldBlacklisted    = "module.blacklisted"
+	KernelModuleFieldInstallCommand = "module.installCommand"
+)
+
+// KernelModule describes a kernel module resource, with its loaded state and
+// its modprobe configuration
+type KernelModule struct {
+	Name string `yaml:"name"`
+}
+
+// Fields available for SystemdUnit
+const (
+	SystemdUnitFieldName          = "unit.name"
+	SystemdUnitFieldLoadState     = "unit.loadState"
+	SystemdUnitFieldActiveState   = "unit.activeState"
+	SystemdUnitFieldSubState      = "unit.subState"
+	SystemdUnitFieldUnitFileState = "unit.unitFileState"
+	SystemdUnitFieldActive        = "unit.active"
+	SystemdUnitFieldEnabled       = "unit.enabled"
+)
+
+// SystemdUnit describes a systemd unit resource
+type SystemdUnit struct {
+	// Name is the name of the unit, the .service suffix can be omitted
+	Name string `yaml:"name"`
+}
+
 // BinaryCmd describes a command in form of a name + args
 type BinaryCmd struct {
 	Name string   `yaml:"name"`
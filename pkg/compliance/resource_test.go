@@ -56,6 +56,32 @@ condition: >-
   "root" in group.users
 `
 
+const testResourcePackage = `
+package:
+  name: telnet
+condition: >-
+  !package.installed
+`
+
+const testResourceSysctl = `
+sysctl:
+  name: net.ipv4.ip_forward
+condition: sysctl.value == "0"
+`
+
+const testResourceKernelModule = `
+kernelModule:
+  name: cramfs
+condition: >-
+  !module.loaded
+`
+
+const testResourceSystemdUnit = `
+systemdUnit:
+  name: auditd
+condition: unit.enabled
+`
+
 const testResourceDockerImage = `
 docker:
   kind: image
@@ -153,6 +179,54 @@ func TestResources(t *testing.T) {
 				Condition: `"root" in group.users`,
 			},
 		},
+		{
+			name:  "package",
+			input: testResourcePackage,
+			expected: Resource{
+				ResourceCommon: ResourceCommon{
+					Package: &Package{
+						Name: "telnet",
+					},
+				},
+				Condition: `!package.installed`,
+			},
+		},
+		{
+			name:  "sysctl",
+			input: testResourceSysctl,
+			expected: Resource{
+				ResourceCommon: ResourceCommon{
+					Sysctl: &Sysctl{
+						Name: "net.ipv4.ip_forward",
+					},
+				},
+				Condition: `sysctl.value == "0"`,
+			},
+		},
+		{
+			name:  "kernel module",
+			input: testResourceKernelModule,
+			expected: Resource{
+				ResourceCommon: ResourceCommon{
+					KernelModule: &KernelModule{
+						Name: "cramfs",
+					},
+				},
+				Condition: `!module.loaded`,
+			},
+		},
+		{
+			name:  "systemd unit",
+			input: testResourceSystemdUnit,
+			expected: Resource{
+				ResourceCommon: ResourceCommon{
+					SystemdUnit: &SystemdUnit{
+						Name: "auditd",
+					},
+				},
+				Condition: `unit.enabled`,
+			},
+		},
 		{
 
 			name:  "docker image",
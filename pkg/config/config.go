@@ -360,6 +360,23 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("secret_backend_timeout", 30)
 	config.BindEnvAndSetDefault("secret_backend_command_allow_group_exec_perm", false)
 	config.BindEnvAndSetDefault("secret_backend_skip_checks", false)
+	config.BindEnvAndSetDefault("secret_backend_type", "")
+	config.BindEnvAndSetDefault("secret_backend_refresh_interval", 0)
+	config.BindEnvAndSetDefault("secret_backend_file.enabled", false)
+	config.BindEnvAndSetDefault("secret_backend_file.directory", "")
+	config.BindEnvAndSetDefault("secret_backend_env.enabled", false)
+	config.BindEnvAndSetDefault("secret_backend_env.prefix", "")
+	config.BindEnvAndSetDefault("secret_backend_vault.enabled", false)
+	config.BindEnvAndSetDefault("secret_backend_vault.address", "")
+	config.BindEnvAndSetDefault("secret_backend_vault.namespace", "")
+	config.BindEnvAndSetDefault("secret_backend_vault.auth_method", "token")
+	config.BindEnvAndSetDefault("secret_backend_vault.token", "")
+	config.BindEnvAndSetDefault("secret_backend_vault.approle_role_id", "")
+	config.BindEnvAndSetDefault("secret_backend_vault.approle_secret_id", "")
+	config.BindEnvAndSetDefault("secret_backend_vault.approle_mount", "approle")
+	config.BindEnvAndSetDefault("secret_backend_vault.mount", "secret")
+	config.BindEnvAndSetDefault("secret_backend_vault.kv_version", 2)
+	config.BindEnvAndSetDefault("secret_backend_vault.tls_skip_verify", false)
 
 	// Use to output logs in JSON format
 	config.BindEnvAndSetDefault("log_format_json", false)
@@ -438,6 +455,9 @@ func InitConfig(config Config) {
 	// Warning: do not change the two following values. Your payloads will get dropped by Datadog's intake.
 	config.BindEnvAndSetDefault("serializer_max_payload_size", 2*megaByte+megaByte/2)
 	config.BindEnvAndSetDefault("serializer_max_uncompressed_payload_size", 4*megaByte)
+	// Compression of the metric payloads: "zlib" or "zstd"
+	config.BindEnvAndSetDefault("serializer_compressor_kind", "zlib")
+	config.BindEnvAndSetDefault("serializer_zstd_compressor_level", 1)
 	config.BindEnvAndSetDefault("serializer_max_series_points_per_payload", 10000)
 
 	config.BindEnvAndSetDefault("use_v2_api.series", false)
@@ -472,6 +492,22 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("forwarder_storage_max_disk_ratio", 0.80)                // Do not store transactions on disk when the disk usage exceeds 80% of the disk capacity. Use 80% as some applications do not behave well when the disk space is very small.
 	config.BindEnvAndSetDefault("forwarder_retry_queue_capacity_time_interval_sec", 900) // 15 mins
 
+	// Prometheus remote-write exporter, dual-shipping the aggregated series and sketches
+	config.BindEnvAndSetDefault("prometheus_remote_write.enabled", false)
+	config.BindEnvAndSetDefault("prometheus_remote_write.url", "")
+	config.BindEnvAndSetDefault("prometheus_remote_write.bearer_token", "")
+	config.BindEnvAndSetDefault("prometheus_remote_write.headers", map[string]string{})
+	config.BindEnvAndSetDefault("prometheus_remote_write.sketches_as", "summary") // "summary" or "native_histogram"
+	config.BindEnvAndSetDefault("prometheus_remote_write.summary_quantiles", []string{"0.5", "0.75", "0.95", "0.99"})
+	config.BindEnvAndSetDefault("prometheus_remote_write.max_series_per_payload", 2000)
+	config.BindEnvAndSetDefault("prometheus_remote_write.num_workers", 1)
+	config.BindEnvAndSetDefault("prometheus_remote_write.retry_queue_payloads_max_size", 15*megaByte)
+
+	// Local OpenMetrics endpoint exposing the last flushed series and sketches
+	config.BindEnvAndSetDefault("openmetrics_endpoint.enabled", false)
+	config.BindEnvAndSetDefault("openmetrics_endpoint.port", 5020)
+	config.BindEnvAndSetDefault("openmetrics_endpoint.summary_quantiles", []string{"0.5", "0.75", "0.95", "0.99"})
+
 	// Forwarder channels buffer size
 	config.BindEnvAndSetDefault("forwarder_high_prio_buffer_size", 100)
 	config.BindEnvAndSetDefault("forwarder_low_prio_buffer_size", 100)
@@ -565,6 +601,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("autoconfig_exclude_features", []string{})
 	config.BindEnvAndSetDefault("autoconfig_include_features", []string{})
 
+	// Workloadmeta
+	config.BindEnvAndSetDefault("workloadmeta.process_collector.enabled", false)
+
 	// Docker
 	config.BindEnvAndSetDefault("docker_query_timeout", int64(5))
 	config.BindEnvAndSetDefault("docker_labels_as_tags", map[string]string{})
@@ -807,6 +846,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("logs_config.dd_url_443", "agent-443-intake.logs.datadoghq.com")
 	config.BindEnvAndSetDefault("logs_config.stop_grace_period", 30)
 	config.BindEnvAndSetDefault("logs_config.close_timeout", 60)
+	// Read the compressed archive of a rotated file to collect the lines its tailer missed
+	config.BindEnvAndSetDefault("logs_config.drain_rotated_archives", false)
+	// Number of bytes at the beginning of a file used to fingerprint its content, 0 disables fingerprinting
+	config.BindEnvAndSetDefault("logs_config.fingerprint_size", 0)
 	config.BindEnvAndSetDefault("logs_config.auto_multi_line_detection", false)
 	config.BindEnvAndSetDefault("logs_config.auto_multi_line_extra_patterns", []string{})
 	// The following auto_multi_line settings are experimental and may change
@@ -886,8 +929,14 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("admission_controller.webhook_name", "datadog-webhook")
 	config.BindEnvAndSetDefault("admission_controller.inject_config.enabled", true)
 	config.BindEnvAndSetDefault("admission_controller.inject_config.endpoint", "/injectconfig")
+	config.BindEnvAndSetDefault("admission_controller.inject_config.mode", "hostip") // possible values: hostip / socket
+	config.BindEnvAndSetDefault("admission_controller.inject_config.trace_agent_socket", "unix:///var/run/datadog/apm.socket")
+	config.BindEnvAndSetDefault("admission_controller.inject_config.dogstatsd_socket", "unix:///var/run/datadog/dsd.socket")
 	config.BindEnvAndSetDefault("admission_controller.inject_tags.enabled", true)
 	config.BindEnvAndSetDefault("admission_controller.inject_tags.endpoint", "/injecttags")
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.enabled", false)
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.endpoint", "/injectlib")
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.container_registry", "gcr.io/datadoghq")
 	config.BindEnvAndSetDefault("admission_controller.pod_owners_cache_validity", 10) // in minutes
 	config.BindEnvAndSetDefault("admission_controller.namespace_selector_fallback", false)
 
@@ -1257,8 +1306,37 @@ func ResolveSecrets(config Config, origin string) error {
 		config.GetInt("secret_backend_output_max_size"),
 		config.GetBool("secret_backend_command_allow_group_exec_perm"),
 	)
+	err := secrets.InitBackends(secrets.BackendsConfig{
+		Type:            config.GetString("secret_backend_type"),
+		RefreshInterval: time.Duration(config.GetInt("secret_backend_refresh_interval")) * time.Second,
+		File: secrets.FileBackendConfig{
+			Enabled:   config.GetBool("secret_backend_file.enabled"),
+			Directory: config.GetString("secret_backend_file.directory"),
+		},
+		Env: secrets.EnvBackendConfig{
+			Enabled: config.GetBool("secret_backend_env.enabled"),
+			Prefix:  config.GetString("secret_backend_env.prefix"),
+		},
+		Vault: secrets.VaultBackendConfig{
+			Enabled:       config.GetBool("secret_backend_vault.enabled"),
+			Address:       config.GetString("secret_backend_vault.address"),
+			Namespace:     config.GetString("secret_backend_vault.namespace"),
+			AuthMethod:    config.GetString("secret_backend_vault.auth_method"),
+			Token:         config.GetString("secret_backend_vault.token"),
+			RoleID:        config.GetString("secret_backend_vault.approle_role_id"),
+			SecretID:      config.GetString("secret_backend_vault.approle_secret_id"),
+			AppRoleMount:  config.GetString("secret_backend_vault.approle_mount"),
+			Mount:         config.GetString("secret_backend_vault.mount"),
+			KVVersion:     config.GetInt("secret_backend_vault.kv_version"),
+			TLSSkipVerify: config.GetBool("secret_backend_vault.tls_skip_verify"),
+			Timeout:       time.Duration(config.GetInt("secret_backend_timeout")) * time.Second,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to initialize the secret backends: %v", err)
+	}
 
-	if config.GetString("secret_backend_command") != "" {
+	if secrets.Enabled() {
 		// Viper doesn't expose the final location of the file it
 		// loads. Since we are searching for 'datadog.yaml' in multiple
 		// locations we let viper determine the one to use before
@@ -1323,7 +1401,8 @@ func bindEnvAndSetLogsConfigKeys(config Config, prefix string) {
 	config.BindEnv(prefix + "dd_url")
 	config.BindEnv(prefix + "additional_endpoints")
 	config.BindEnvAndSetDefault(prefix+"use_compression", true)
-	config.BindEnvAndSetDefault(prefix+"compression_level", 6) // Default level for the gzip/deflate algorithm
+	config.BindEnvAndSetDefault(prefix+"compression_level", 6)     // Default level for the gzip/deflate algorithm
+	config.BindEnvAndSetDefault(prefix+"compression_kind", "gzip") // "gzip" or "zstd", compression_level applies to both
 	config.BindEnvAndSetDefault(prefix+"batch_wait", DefaultBatchWait)
 	config.BindEnvAndSetDefault(prefix+"connection_reset_interval", 0) // in seconds, 0 means disabled
 	config.BindEnvAndSetDefault(prefix+"logs_no_ssl", false)
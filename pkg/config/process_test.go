@@ -46,6 +46,14 @@ func TestProcessDefaultConfig(t *testing.T) {
 			key:          "process_config.process_discovery.interval",
 			defaultValue: 4 * time.Hour,
 		},
+		{
+			key:          "process_config.event_collection.enabled",
+			defaultValue: false,
+		},
+		{
+			key:          "process_config.event_collection.interval",
+			defaultValue: 10 * time.Second,
+		},
 		{
 			key:          "process_config.process_collection.enabled",
 			defaultValue: false,
@@ -201,6 +209,18 @@ func TestEnvVarOverride(t *testing.T) {
 			value:    "1h",
 			expected: time.Hour,
 		},
+		{
+			key:      "process_config.event_collection.enabled",
+			env:      "DD_PROCESS_CONFIG_EVENT_COLLECTION_ENABLED",
+			value:    "true",
+			expected: true,
+		},
+		{
+			key:      "process_config.event_collection.interval",
+			env:      "DD_PROCESS_AGENT_EVENT_COLLECTION_INTERVAL",
+			value:    "30s",
+			expected: 30 * time.Second,
+		},
 		{
 			key:      "process_config.disable_realtime_checks",
 			env:      "DD_PROCESS_CONFIG_DISABLE_REALTIME_CHECKS",
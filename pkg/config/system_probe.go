@@ -41,6 +41,11 @@ const (
 	defaultZypperReposDirSuffix = "/zypp/repos.d"
 
 	defaultOffsetThreshold = 400
+
+	// defaultMaxHTTPPathsPerHost is the default maximum number of distinct HTTP paths of a host
+	defaultMaxHTTPPathsPerHost = 500
+	// defaultHTTPPathTemplateThreshold is the default number of distinct path segments turning a path prefix into a template
+	defaultHTTPPathTemplateThreshold = 50
 )
 
 func isSystemProbeConfigInit(cfg Config) bool {
@@ -136,6 +141,9 @@ func InitSystemProbeConfig(cfg Config) {
 	cfg.BindEnv(join(netNS, "enable_http_monitoring"), "DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP_MONITORING")
 	cfg.BindEnv(join(netNS, "enable_https_monitoring"), "DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTPS_MONITORING")
 	cfg.BindEnvAndSetDefault(join(netNS, "enable_gateway_lookup"), false, "DD_SYSTEM_PROBE_NETWORK_ENABLE_GATEWAY_LOOKUP")
+	cfg.BindEnvAndSetDefault(join(netNS, "enable_http_path_normalization"), true, "DD_SYSTEM_PROBE_NETWORK_ENABLE_HTTP_PATH_NORMALIZATION")
+	cfg.BindEnvAndSetDefault(join(netNS, "max_http_paths_per_host"), defaultMaxHTTPPathsPerHost, "DD_SYSTEM_PROBE_NETWORK_MAX_HTTP_PATHS_PER_HOST")
+	cfg.BindEnvAndSetDefault(join(netNS, "http_path_template_threshold"), defaultHTTPPathTemplateThreshold, "DD_SYSTEM_PROBE_NETWORK_HTTP_PATH_TEMPLATE_THRESHOLD")
 	httpRules := join(netNS, "http_replace_rules")
 	cfg.BindEnv(httpRules, "DD_SYSTEM_PROBE_NETWORK_HTTP_REPLACE_RULES")
 	cfg.SetEnvKeyTransformer(httpRules, func(in string) interface{} {
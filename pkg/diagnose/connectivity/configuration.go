@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package connectivity
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+)
+
+func init() {
+	diagnosis.Register("DogStatsD socket", diagnosis.CategoryConfiguration, func() []diagnosis.Result {
+		return []diagnosis.Result{diagnoseDogStatsDSocket(config.Datadog)}
+	})
+	diagnosis.Register("Configuration conflicts", diagnosis.CategoryConfiguration, func() []diagnosis.Result {
+		return diagnoseConfigConflicts(config.Datadog)
+	})
+}
+
+// diagnoseDogStatsDSocket checks that the DogStatsD Unix socket can be created
+// by the Agent and written by the clients
+func diagnoseDogStatsDSocket(cfg config.Config) diagnosis.Result {
+	socketPath := cfg.GetString("dogstatsd_socket")
+	if socketPath == "" {
+		return diagnosis.OK("the DogStatsD Unix socket is disabled")
+	}
+	details := map[string]interface{}{"path": socketPath}
+
+	info, err := os.Stat(socketPath)
+	if os.IsNotExist(err) {
+		dir := filepath.Dir(socketPath)
+		dirInfo, err := os.Stat(dir)
+		if err != nil || !dirInfo.IsDir() {
+			return diagnosis.Fail(
+				fmt.Sprintf("the folder %s of the DogStatsD socket does not exist", dir),
+				"Create the folder, or mount it when the Agent runs in a container, or change dogstatsd_socket.",
+			).WithDetails(details)
+		}
+		return diagnosis.OK(fmt.Sprintf("%s does not exist, the Agent creates it when it starts", socketPath)).WithDetails(details)
+	}
+	if err != nil {
+		return diagnosis.Fail(fmt.Sprintf("cannot check the DogStatsD socket: %v", err),
+			"Check that the Agent user can access the folder of the socket.",
+		).WithDetails(details)
+	}
+
+	details["mode"] = info.Mode().String()
+	if info.Mode()&os.ModeSocket == 0 {
+		return diagnosis.Fail(fmt.Sprintf("%s is not a Unix socket", socketPath),
+			"Remove the file, the Agent creates the socket when it starts, or change dogstatsd_socket.",
+		).WithDetails(details)
+	}
+	// the Agent makes the socket writable by every user
+	if info.Mode().Perm()&0022 == 0 {
+		return diagnosis.Warning(fmt.Sprintf("%s is only writable by its owner, the clients running as another user cannot send metrics", socketPath),
+			"Restart the Agent to recreate the socket, or allow the clients to write it.",
+		).WithDetails(details)
+	}
+	return diagnosis.OK(fmt.Sprintf("%s is writable by the clients", socketPath)).WithDetails(details)
+}
+
+// configConflict describes settings which conflict with each other
+type configConflict struct {
+	keys        []string
+	conflicts   func(cfg config.Config) bool
+	status      diagnosis.Status
+	message     string
+	remediation string
+}
+
+func isSetAndNotEmpty(cfg config.Config, key string) bool {
+	return cfg.IsSet(key) && cfg.GetString(key) != ""
+}
+
+// siteOverride returns the conflict between `site` and a setting overriding it
+func siteOverride(ddURLKey string) configConflict {
+	return configConflict{
+		keys: []string{"site", ddURLKey},
+		conflicts: func(cfg config.Config) bool {
+			return isSetAndNotEmpty(cfg, "site") && isSetAndNotEmpty(cfg, ddURLKey)
+		},
+		status:      diagnosis.StatusWarning,
+		message:     fmt.Sprintf("%s takes precedence over site", ddURLKey),
+		remediation: fmt.Sprintf("Remove %s unless the data must be sent to a proxy or to another site than the site setting.", ddURLKey),
+	}
+}
+
+var configConflicts = []configConflict{
+	{
+		keys: []string{"api_key"},
+		conflicts: func(cfg config.Config) bool {
+			return strings.TrimSpace(cfg.GetString("api_key")) == ""
+		},
+		status:      diagnosis.StatusFail,
+		message:     "no API key is configured",
+		remediation: "Set api_key, or the DD_API_KEY environment variable.",
+	},
+	siteOverride("dd_url"),
+	siteOverride("logs_config.logs_dd_url"),
+	siteOverride("apm_config.apm_dd_url"),
+	siteOverride("process_config.process_dd_url"),
+	{
+		keys: []string{"logs_config.use_http", "logs_config.use_tcp"},
+		conflicts: func(cfg config.Config) bool {
+			return cfg.GetBool("logs_config.use_http") && cfg.GetBool("logs_config.use_tcp")
+		},
+		status:      diagnosis.StatusWarning,
+		message:     "logs_config.use_http takes precedence over logs_config.use_tcp",
+		remediation: "Only enable one of logs_config.use_http and logs_config.use_tcp.",
+	},
+	{
+		keys: []string{"use_dogstatsd", "dogstatsd_socket"},
+		conflicts: func(cfg config.Config) bool {
+			return !cfg.GetBool("use_dogstatsd") && cfg.GetString("dogstatsd_socket") != ""
+		},
+		status:      diagnosis.StatusWarning,
+		message:     "dogstatsd_socket is ignored as use_dogstatsd is disabled",
+		remediation: "Enable use_dogstatsd to receive metrics on the socket, or remove dogstatsd_socket.",
+	},
+}
+
+// diagnoseConfigConflicts reports the settings which conflict with each other
+func diagnoseConfigConflicts(cfg config.Config) []diagnosis.Result {
+	var results []diagnosis.Result
+	for _, conflict := range configConflicts {
+		if !conflict.conflicts(cfg) {
+			continue
+		}
+		result := diagnosis.Result{
+			Name:        "Configuration conflict on " + strings.Join(conflict.keys, ", "),
+			Status:      conflict.status,
+			Message:     conflict.message,
+			Remediation: conflict.remediation,
+		}
+		results = append(results, result.WithDetails(map[string]interface{}{"keys": conflict.keys}))
+	}
+	if len(results) == 0 {
+		return []diagnosis.Result{diagnosis.OK("no conflicting settings")}
+	}
+	return results
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package connectivity
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+)
+
+func TestDiagnoseDogStatsDSocket(t *testing.T) {
+	mockConfig := config.Mock()
+	assert.Equal(t, diagnosis.StatusOK, diagnoseDogStatsDSocket(mockConfig).Status)
+
+	dir := t.TempDir()
+	mockConfig.Set("dogstatsd_socket", filepath.Join(dir, "missing", "dsd.socket"))
+	assert.Equal(t, diagnosis.StatusFail, diagnoseDogStatsDSocket(mockConfig).Status)
+
+	socketPath := filepath.Join(dir, "dsd.socket")
+	mockConfig.Set("dogstatsd_socket", socketPath)
+	assert.Equal(t, diagnosis.StatusOK, diagnoseDogStatsDSocket(mockConfig).Status)
+
+	require.NoError(t, os.WriteFile(socketPath, nil, 0600))
+	assert.Equal(t, diagnosis.StatusFail, diagnoseDogStatsDSocket(mockConfig).Status)
+	require.NoError(t, os.Remove(socketPath))
+
+	listener, err := net.Listen("unixgram", socketPath)
+	if err != nil {
+		t.Skipf("cannot create a Unix socket: %v", err)
+	}
+	defer listener.Close()
+	require.NoError(t, os.Chmod(socketPath, 0700))
+	assert.Equal(t, diagnosis.StatusWarning, diagnoseDogStatsDSocket(mockConfig).Status)
+	require.NoError(t, os.Chmod(socketPath, 0722))
+	assert.Equal(t, diagnosis.StatusOK, diagnoseDogStatsDSocket(mockConfig).Status)
+}
+
+func TestDiagnoseConfigConflicts(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("api_key", "api_key")
+	assert.Equal(t, []diagnosis.Result{diagnosis.OK("no conflicting settings")}, diagnoseConfigConflicts(mockConfig))
+
+	mockConfig.Set("api_key", "")
+	mockConfig.Set("site", "datadoghq.eu")
+	mockConfig.Set("dd_url", "https://proxy.example.com")
+	mockConfig.Set("logs_config.use_http", true)
+	mockConfig.Set("logs_config.use_tcp", true)
+
+	results := diagnoseConfigConflicts(mockConfig)
+	require.Len(t, results, 3)
+	assert.Equal(t, "Configuration conflict on api_key", results[0].Name)
+	assert.Equal(t, diagnosis.StatusFail, results[0].Status)
+	assert.Equal(t, "Configuration conflict on site, dd_url", results[1].Name)
+	assert.Equal(t, diagnosis.StatusWarning, results[1].Status)
+	assert.Equal(t, "Configuration conflict on logs_config.use_http, logs_config.use_tcp", results[2].Name)
+}
@@ -0,0 +1,322 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package connectivity registers the diagnoses of the connectivity to the
+// Datadog intakes and of the local configuration of the Agent.
+package connectivity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/endpoints"
+	logsconfig "github.com/DataDog/datadog-agent/pkg/logs/config"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// requestTimeout is the timeout of the DNS resolutions and HTTP requests
+var requestTimeout = 10 * time.Second
+
+func init() {
+	diagnosis.Register("Metrics intake connectivity", diagnosis.CategoryConnectivity, func() []diagnosis.Result {
+		return diagnoseIntakes(metricsIntakes)
+	})
+	diagnosis.Register("Logs intake connectivity", diagnosis.CategoryConnectivity, func() []diagnosis.Result {
+		return diagnoseIntakes(logsIntakes)
+	})
+	diagnosis.Register("APM intake connectivity", diagnosis.CategoryConnectivity, func() []diagnosis.Result {
+		return diagnoseIntakes(apmIntakes)
+	})
+	diagnosis.Register("Process intake connectivity", diagnosis.CategoryConnectivity, func() []diagnosis.Result {
+		return diagnoseIntakes(processIntakes)
+	})
+}
+
+// intake is an endpoint of a Datadog intake
+type intake struct {
+	url *url.URL
+	// apiKeys are validated against validationDomain when it is set
+	apiKeys          []string
+	validationDomain string
+}
+
+// intakesGetter returns the intakes configured for a product, a nil slice and
+// a message when the product is disabled
+type intakesGetter func() ([]intake, string, error)
+
+func metricsIntakes() ([]intake, string, error) {
+	keysPerDomain, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return nil, "", err
+	}
+	domains := make([]string, 0, len(keysPerDomain))
+	for domain := range keysPerDomain {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	intakes := make([]intake, 0, len(domains))
+	for _, domain := range domains {
+		// the forwarder sends the payloads to the versioned domain
+		versionedDomain, err := config.AddAgentVersionToDomain(domain, "app")
+		if err != nil {
+			return nil, "", err
+		}
+		u, err := url.Parse(versionedDomain)
+		if err != nil {
+			return nil, "", err
+		}
+		intakes = append(intakes, intake{
+			url:              u,
+			apiKeys:          keysPerDomain[domain],
+			validationDomain: forwarder.APIKeyValidationDomain(domain),
+		})
+	}
+	return intakes, "", nil
+}
+
+func logsIntakes() ([]intake, string, error) {
+	if !config.Datadog.GetBool("logs_enabled") && !config.Datadog.GetBool("log_enabled") {
+		return nil, "logs collection is disabled", nil
+	}
+	logsEndpoints, err := logsconfig.BuildHTTPEndpoints("", logsconfig.DefaultIntakeProtocol, logsconfig.DefaultIntakeOrigin)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var intakes []intake
+	// the endpoints include the main endpoint
+	for _, e := range logsEndpoints.Endpoints {
+		scheme := "http"
+		if e.UseSSL {
+			scheme = "https"
+		}
+		host := e.Host
+		if e.Port != 0 {
+			host = net.JoinHostPort(e.Host, fmt.Sprint(e.Port))
+		}
+		intakes = append(intakes, intake{url: &url.URL{Scheme: scheme, Host: host}})
+	}
+	return intakes, "", nil
+}
+
+func apmIntakes() ([]intake, string, error) {
+	if !config.Datadog.GetBool("apm_config.enabled") {
+		return nil, "APM is disabled", nil
+	}
+	return mainEndpointIntakes("https://trace.agent.", "apm_config.apm_dd_url")
+}
+
+func processIntakes() ([]intake, string, error) {
+	return mainEndpointIntakes("https://process.", "process_config.process_dd_url")
+}
+
+func mainEndpointIntakes(prefix string, ddURLKey string) ([]intake, string, error) {
+	u, err := url.Parse(config.GetMainEndpoint(prefix, ddURLKey))
+	if err != nil {
+		return nil, "", fmt.Errorf("could not parse %s: %v", ddURLKey, err)
+	}
+	return []intake{{url: u}}, "", nil
+}
+
+func diagnoseIntakes(getIntakes intakesGetter) []diagnosis.Result {
+	intakes, disabledMessage, err := getIntakes()
+	if err != nil {
+		return []diagnosis.Result{diagnosis.Fail(
+			fmt.Sprintf("invalid endpoint configuration: %v", err),
+			"Check the site, the URL and the additional endpoints settings of the configuration.",
+		)}
+	}
+	if intakes == nil {
+		return []diagnosis.Result{diagnosis.OK(disabledMessage)}
+	}
+
+	var results []diagnosis.Result
+	for _, in := range intakes {
+		results = append(results, diagnoseIntake(in)...)
+	}
+	return results
+}
+
+// diagnoseIntake checks the proxy used to reach an intake, the resolution of
+// its host, the TLS handshake with it and the validity of its API keys
+func diagnoseIntake(in intake) []diagnosis.Result {
+	host := in.url.Hostname()
+	transport := httputils.CreateHTTPTransport()
+	client := &http.Client{Transport: transport, Timeout: requestTimeout}
+
+	results := make([]diagnosis.Result, 0, 3+len(in.apiKeys))
+	proxyURL, result := diagnoseProxy(transport, in.url)
+	results = append(results, result.WithName("Proxy for "+host))
+	results = append(results, diagnoseDNS(host, proxyURL).WithName("DNS resolution of "+host))
+	result = diagnoseTLS(client, in.url, proxyURL)
+	results = append(results, result.WithName("Connection to "+host))
+	if result.Status == diagnosis.StatusFail || in.validationDomain == "" {
+		return results
+	}
+
+	for _, apiKey := range in.apiKeys {
+		name := fmt.Sprintf("API key ending with %s on %s", lastChars(apiKey), host)
+		results = append(results, diagnoseAPIKey(client, in.validationDomain, apiKey).WithName(name))
+	}
+	return results
+}
+
+func diagnoseProxy(transport *http.Transport, u *url.URL) (*url.URL, diagnosis.Result) {
+	if transport.Proxy == nil {
+		return nil, diagnosis.OK("no proxy configured, connecting directly")
+	}
+	proxyURL, err := transport.Proxy(&http.Request{URL: u})
+	if err != nil {
+		return nil, diagnosis.Fail(
+			fmt.Sprintf("invalid proxy configuration: %v", err),
+			"Check the proxy settings of the configuration and the DD_PROXY_HTTP, DD_PROXY_HTTPS and DD_PROXY_NO_PROXY environment variables.",
+		)
+	}
+	if proxyURL == nil {
+		return nil, diagnosis.OK("the host matches no_proxy, connecting directly")
+	}
+	return proxyURL, diagnosis.OK("connecting through the proxy " + proxyURL.Redacted()).
+		WithDetails(map[string]interface{}{"proxy": proxyURL.Redacted()})
+}
+
+func diagnoseDNS(host string, proxyURL *url.URL) diagnosis.Result {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	addresses, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		if proxyURL != nil {
+			return diagnosis.Warning(
+				fmt.Sprintf("cannot resolve %s locally: %v", host, err),
+				"The proxy resolves the host, ignore this warning if the connection through the proxy succeeds.",
+			)
+		}
+		return diagnosis.Fail(
+			fmt.Sprintf("cannot resolve %s: %v", host, err),
+			"Check the DNS configuration of the host and that the site setting is correct.",
+		)
+	}
+	return diagnosis.OK(fmt.Sprintf("%s resolves to %d address(es)", host, len(addresses))).
+		WithDetails(map[string]interface{}{"addresses": addresses})
+}
+
+func diagnoseTLS(client *http.Client, u *url.URL, proxyURL *url.URL) diagnosis.Result {
+	resp, err := doRequest(client, u.String(), nil)
+	if err != nil {
+		return diagnosis.Fail(fmt.Sprintf("cannot connect to %s: %v", u.Host, err), connectionRemediation(err, u, proxyURL))
+	}
+	io.Copy(ioutil.Discard, resp.Body) //nolint:errcheck
+	resp.Body.Close()
+
+	details := map[string]interface{}{"status_code": resp.StatusCode}
+	if resp.TLS == nil {
+		return diagnosis.Warning(
+			fmt.Sprintf("connected to %s without encryption", u.Host),
+			"Use an https URL, unless the endpoint is a local proxy forwarding the payloads over TLS.",
+		).WithDetails(details)
+	}
+
+	details["tls_version"] = tlsVersionName(resp.TLS.Version)
+	details["cipher_suite"] = tls.CipherSuiteName(resp.TLS.CipherSuite)
+	if len(resp.TLS.PeerCertificates) > 0 {
+		cert := resp.TLS.PeerCertificates[0]
+		details["certificate_subject"] = cert.Subject.String()
+		details["certificate_issuer"] = cert.Issuer.String()
+		details["certificate_expiration"] = cert.NotAfter
+	}
+	return diagnosis.OK(fmt.Sprintf("TLS handshake with %s succeeded", u.Host)).WithDetails(details)
+}
+
+func connectionRemediation(err error, u *url.URL, proxyURL *url.URL) string {
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certificateErr x509.CertificateInvalidError
+	if errors.As(err, &unknownAuthorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &certificateErr) {
+		return "The certificate presented to the Agent is not trusted. If a proxy or a firewall inspects the TLS traffic, add its certificate authority to the certificate authorities of the host."
+	}
+	if proxyURL != nil {
+		return fmt.Sprintf("Check that the proxy %s is reachable and allows connections to %s.", proxyURL.Redacted(), u.Host)
+	}
+	return fmt.Sprintf("Check that the firewall allows outbound connections to %s, or configure a proxy.", u.Host)
+}
+
+func diagnoseAPIKey(client *http.Client, validationDomain string, apiKey string) diagnosis.Result {
+	// the API key is sent in a header so that it does not appear in the errors
+	resp, err := doRequest(client, validationDomain+endpoints.V1ValidateEndpoint.Route, map[string]string{"DD-API-KEY": apiKey})
+	if err != nil {
+		return diagnosis.Warning(
+			fmt.Sprintf("cannot validate the API key: %v", err),
+			fmt.Sprintf("Check that %s is reachable from the Agent.", validationDomain),
+		)
+	}
+	io.Copy(ioutil.Discard, resp.Body) //nolint:errcheck
+	resp.Body.Close()
+
+	// Server will respond 200 if the key is valid or 403 if invalid
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return diagnosis.OK("the API key is valid")
+	case http.StatusForbidden:
+		return diagnosis.Fail(
+			"the API key is invalid",
+			"Check the api_key setting, or the API keys of additional_endpoints, and that the API key belongs to the organization of the configured site.",
+		)
+	default:
+		return diagnosis.Warning(
+			fmt.Sprintf("unexpected status code %d from the API key validation endpoint", resp.StatusCode),
+			fmt.Sprintf("Check that %s is a Datadog site or a proxy forwarding the requests to one.", validationDomain),
+		)
+	}
+}
+
+func doRequest(client *http.Client, url string, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return client.Do(req)
+}
+
+func tlsVersionName(v uint16) string {
+	switch v {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("0x%04x", v)
+	}
+}
+
+// lastChars returns the last characters of an API key, which can be printed
+func lastChars(apiKey string) string {
+	if len(apiKey) > 5 {
+		return apiKey[len(apiKey)-5:]
+	}
+	return apiKey
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package connectivity
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+)
+
+func newIntakeServer(t *testing.T) *httptest.Server {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/validate" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("DD-API-KEY") == "valid_api_key" {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func statusesByName(results []diagnosis.Result) map[string]diagnosis.Status {
+	statuses := make(map[string]diagnosis.Status, len(results))
+	for _, result := range results {
+		statuses[result.Name] = result.Status
+	}
+	return statuses
+}
+
+func TestMetricsIntakes(t *testing.T) {
+	ts := newIntakeServer(t)
+
+	mockConfig := config.Mock()
+	mockConfig.Set("skip_ssl_validation", true)
+	mockConfig.Set("dd_url", ts.URL)
+	mockConfig.Set("api_key", "valid_api_key")
+	mockConfig.Set("additional_endpoints", map[string][]string{ts.URL: {"invalid_api_key"}})
+
+	results := diagnoseIntakes(metricsIntakes)
+	require.Len(t, results, 5)
+
+	statuses := statusesByName(results)
+	assert.Equal(t, diagnosis.StatusOK, statuses["Proxy for 127.0.0.1"])
+	assert.Equal(t, diagnosis.StatusOK, statuses["DNS resolution of 127.0.0.1"])
+	assert.Equal(t, diagnosis.StatusOK, statuses["Connection to 127.0.0.1"])
+	assert.Equal(t, "TLS 1.3", results[2].Details["tls_version"])
+	assert.Equal(t, http.StatusNotFound, results[2].Details["status_code"])
+
+	// both API keys end with "i_key", their results are ordered as the keys
+	assert.Equal(t, "API key ending with i_key on 127.0.0.1", results[3].Name)
+	assert.Equal(t, diagnosis.StatusOK, results[3].Status)
+	assert.Equal(t, diagnosis.StatusFail, results[4].Status)
+	assert.Equal(t, "the API key is invalid", results[4].Message)
+}
+
+func TestUnreachableIntake(t *testing.T) {
+	ts := newIntakeServer(t)
+	ts.Close()
+
+	mockConfig := config.Mock()
+	mockConfig.Set("process_config.process_dd_url", ts.URL)
+
+	results := diagnoseIntakes(processIntakes)
+	require.Len(t, results, 3)
+	assert.Equal(t, diagnosis.StatusOK, results[1].Status)
+	assert.Equal(t, "Connection to 127.0.0.1", results[2].Name)
+	assert.Equal(t, diagnosis.StatusFail, results[2].Status)
+	assert.Contains(t, results[2].Remediation, "firewall")
+}
+
+func TestUntrustedCertificate(t *testing.T) {
+	ts := newIntakeServer(t)
+
+	mockConfig := config.Mock()
+	mockConfig.Set("apm_config.enabled", true)
+	mockConfig.Set("apm_config.apm_dd_url", ts.URL)
+
+	results := diagnoseIntakes(apmIntakes)
+	require.Len(t, results, 3)
+	assert.Equal(t, diagnosis.StatusFail, results[2].Status)
+	assert.Contains(t, results[2].Remediation, "certificate")
+}
+
+func TestUnencryptedIntake(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	mockConfig := config.Mock()
+	mockConfig.Set("process_config.process_dd_url", ts.URL)
+
+	results := diagnoseIntakes(processIntakes)
+	require.Len(t, results, 3)
+	assert.Equal(t, diagnosis.StatusWarning, results[2].Status)
+}
+
+func TestDisabledIntake(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("logs_enabled", false)
+
+	results := diagnoseIntakes(logsIntakes)
+	assert.Equal(t, []diagnosis.Result{diagnosis.OK("logs collection is disabled")}, results)
+}
+
+func TestLogsIntake(t *testing.T) {
+	ts := newIntakeServer(t)
+
+	mockConfig := config.Mock()
+	mockConfig.Set("logs_enabled", true)
+	mockConfig.Set("skip_ssl_validation", true)
+	mockConfig.Set("logs_config.logs_dd_url", ts.Listener.Addr().String())
+
+	results := diagnoseIntakes(logsIntakes)
+	require.Len(t, results, 3)
+	assert.Equal(t, "Connection to 127.0.0.1", results[2].Name)
+	assert.Equal(t, diagnosis.StatusOK, results[2].Status)
+}
@@ -7,19 +7,103 @@ package diagnosis
 
 import "github.com/DataDog/datadog-agent/pkg/util/log"
 
+// Category groups the diagnoses checking the same kind of component
+type Category string
+
+const (
+	// CategoryCloudProvider is the category of the cloud provider metadata APIs
+	CategoryCloudProvider Category = "cloud-provider"
+	// CategoryContainerRuntime is the category of the container runtimes
+	CategoryContainerRuntime Category = "container-runtime"
+	// CategoryOrchestrator is the category of the orchestrators and of the Cluster Agent
+	CategoryOrchestrator Category = "orchestrator"
+	// CategoryConnectivity is the category of the connectivity to the Datadog intakes
+	CategoryConnectivity Category = "connectivity"
+	// CategoryConfiguration is the category of the local configuration of the Agent
+	CategoryConfiguration Category = "configuration"
+)
+
+// Status is the outcome of a diagnosis
+type Status string
+
+const (
+	// StatusOK means the component works as expected
+	StatusOK Status = "ok"
+	// StatusWarning means the component is not available, which is expected in some environments
+	StatusWarning Status = "warning"
+	// StatusFail means the component does not work as expected
+	StatusFail Status = "fail"
+)
+
+// Result is a structured result of a diagnosis
+type Result struct {
+	// Name defaults to the name of the diagnosis, diagnoses returning several
+	// results name each of them
+	Name     string   `json:"name"`
+	Category Category `json:"category"`
+	Status   Status   `json:"status"`
+	Message  string   `json:"message"`
+	// Remediation explains how to fix a warning or a failure
+	Remediation string `json:"remediation,omitempty"`
+	// Details holds the raw data collected by the diagnosis
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// OK returns a successful result
+func OK(message string) Result {
+	return Result{Status: StatusOK, Message: message}
+}
+
+// Warning returns a warning result
+func Warning(message string, remediation string) Result {
+	return Result{Status: StatusWarning, Message: message, Remediation: remediation}
+}
+
+// Fail returns a failed result
+func Fail(message string, remediation string) Result {
+	return Result{Status: StatusFail, Message: message, Remediation: remediation}
+}
+
+// FromError returns a failed result reporting err, or a successful result when
+// err is nil
+func FromError(err error, okMessage string, remediation string) Result {
+	if err != nil {
+		return Fail(err.Error(), remediation)
+	}
+	return OK(okMessage)
+}
+
+// WithDetails returns the result with the given raw details
+func (r Result) WithDetails(details map[string]interface{}) Result {
+	r.Details = details
+	return r
+}
+
+// WithName returns the result with the given name
+func (r Result) WithName(name string) Result {
+	r.Name = name
+	return r
+}
+
+// Diagnosis checks a component and reports its health with one or more results
+type Diagnosis func() []Result
+
+// Entry is a registered diagnosis
+type Entry struct {
+	Category  Category
+	Diagnosis Diagnosis
+}
+
 // Catalog holds available diagnosis for detection and usage
-type Catalog map[string]Diagnosis
+type Catalog map[string]Entry
 
 // DefaultCatalog holds every compiled-in diagnosis
 var DefaultCatalog = make(Catalog)
 
 // Register a diagnosis that will be called on diagnose
-func Register(name string, d Diagnosis) {
+func Register(name string, category Category, d Diagnosis) {
 	if _, ok := DefaultCatalog[name]; ok {
 		log.Warnf("Diagnosis %s already registered, overriding it", name)
 	}
-	DefaultCatalog[name] = d
+	DefaultCatalog[name] = Entry{Category: category, Diagnosis: d}
 }
-
-// Diagnosis should return an error to report its health
-type Diagnosis func() error
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -19,6 +20,19 @@ import (
 
 // RunAll runs all registered connectivity checks, output it in writer
 func RunAll(w io.Writer) error {
+	_, err := Run(w, nil)
+	return err
+}
+
+// Run runs the registered diagnoses of the given categories, or all of them
+// when no category is given. The logs and the results of the diagnoses are
+// written as text to w, and the results are returned.
+func Run(w io.Writer, categories []diagnosis.Category) ([]diagnosis.Result, error) {
+	selected, err := selectDiagnoses(categories)
+	if err != nil {
+		return nil, err
+	}
+
 	if w != color.Output {
 		color.NoColor = true
 	}
@@ -26,27 +40,94 @@ func RunAll(w io.Writer) error {
 	// Use temporarily a custom logger to our Writer
 	customLogger, err := seelog.LoggerFromWriterWithMinLevelAndFormat(w, seelog.DebugLvl, "[%LEVEL] %FuncShort: %Msg - %Ns%n")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	log.RegisterAdditionalLogger("diagnose", customLogger)
 	defer log.UnregisterAdditionalLogger("diagnose")
 
+	var results []diagnosis.Result
+	for _, name := range selected {
+		fmt.Fprintln(w, fmt.Sprintf("=== Running %s diagnosis ===", color.BlueString(name)))
+		entry := diagnosis.DefaultCatalog[name]
+		for _, result := range entry.Diagnosis() {
+			if result.Name == "" {
+				result.Name = name
+			}
+			result.Category = entry.Category
+			if result.Status == diagnosis.StatusFail {
+				log.Infof("diagnosis error for %s: %s", result.Name, result.Message)
+			}
+			printResult(w, name, result)
+			results = append(results, result)
+		}
+		fmt.Fprintln(w)
+	}
+	printSummary(w, results)
+
+	return results, nil
+}
+
+// selectDiagnoses returns the sorted names of the diagnoses of the categories
+func selectDiagnoses(categories []diagnosis.Category) ([]string, error) {
+	known := make(map[diagnosis.Category]bool)
+	for _, entry := range diagnosis.DefaultCatalog {
+		known[entry.Category] = true
+	}
+	wanted := make(map[diagnosis.Category]bool, len(categories))
+	for _, category := range categories {
+		if !known[category] {
+			return nil, fmt.Errorf("no diagnosis of the category %q, the available categories are %s", category, joinCategories(known))
+		}
+		wanted[category] = true
+	}
+
 	var sortedDiagnosis []string
-	for name := range diagnosis.DefaultCatalog {
-		sortedDiagnosis = append(sortedDiagnosis, name)
+	for name, entry := range diagnosis.DefaultCatalog {
+		if len(wanted) == 0 || wanted[entry.Category] {
+			sortedDiagnosis = append(sortedDiagnosis, name)
+		}
 	}
 	sort.Strings(sortedDiagnosis)
+	return sortedDiagnosis, nil
+}
 
-	for _, name := range sortedDiagnosis {
-		fmt.Fprintln(w, fmt.Sprintf("=== Running %s diagnosis ===", color.BlueString(name)))
-		err := diagnosis.DefaultCatalog[name]()
-		statusString := color.GreenString("PASS")
-		if err != nil {
-			statusString = color.RedString("FAIL")
-			log.Infof("diagnosis error for %s: %v", name, err)
-		}
-		fmt.Fprintln(w, fmt.Sprintf("===> %s\n", statusString))
+func joinCategories(categories map[diagnosis.Category]bool) string {
+	var names []string
+	for category := range categories {
+		names = append(names, string(category))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func printResult(w io.Writer, diagnosisName string, result diagnosis.Result) {
+	var status string
+	switch result.Status {
+	case diagnosis.StatusOK:
+		status = color.GreenString("PASS")
+	case diagnosis.StatusWarning:
+		status = color.YellowString("WARNING")
+	default:
+		status = color.RedString("FAIL")
 	}
 
-	return nil
+	line := "===> " + status
+	if result.Name != diagnosisName {
+		line += " " + result.Name
+	}
+	if result.Message != "" {
+		line += ": " + result.Message
+	}
+	fmt.Fprintln(w, line)
+	if result.Remediation != "" && result.Status != diagnosis.StatusOK {
+		fmt.Fprintf(w, "     Remediation: %s\n", result.Remediation)
+	}
+}
+
+func printSummary(w io.Writer, results []diagnosis.Result) {
+	counts := make(map[diagnosis.Status]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	fmt.Fprintf(w, "%d passed, %d warning(s), %d failed\n", counts[diagnosis.StatusOK], counts[diagnosis.StatusWarning], counts[diagnosis.StatusFail])
 }
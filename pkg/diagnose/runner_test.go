@@ -13,17 +13,52 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRunAll(t *testing.T) {
 
-	diagnosis.Register("failing", func() error { return errors.New("fail") })
-	diagnosis.Register("succeeding", func() error { return nil })
+	diagnosis.Register("failing", diagnosis.CategoryContainerRuntime, func() []diagnosis.Result {
+		return []diagnosis.Result{diagnosis.FromError(errors.New("fail"), "", "restart it")}
+	})
+	diagnosis.Register("succeeding", diagnosis.CategoryContainerRuntime, func() []diagnosis.Result {
+		return []diagnosis.Result{diagnosis.OK("works")}
+	})
+	defer delete(diagnosis.DefaultCatalog, "failing")
+	defer delete(diagnosis.DefaultCatalog, "succeeding")
 
 	w := &bytes.Buffer{}
 	RunAll(w)
 
 	result := w.String()
-	assert.Contains(t, result, "=== Running failing diagnosis ===\n===> FAIL")
-	assert.Contains(t, result, "=== Running succeeding diagnosis ===\n===> PASS")
+	assert.Contains(t, result, "=== Running failing diagnosis ===\n===> FAIL: fail\n     Remediation: restart it\n")
+	assert.Contains(t, result, "=== Running succeeding diagnosis ===\n===> PASS: works\n")
+	assert.Contains(t, result, "1 passed, 0 warning(s), 1 failed\n")
+}
+
+func TestRunCategories(t *testing.T) {
+	diagnosis.Register("cloud", diagnosis.CategoryCloudProvider, func() []diagnosis.Result {
+		return []diagnosis.Result{
+			diagnosis.OK("first").WithName("cloud first"),
+			diagnosis.Warning("second", "ignore it").WithName("cloud second").WithDetails(map[string]interface{}{"key": "value"}),
+		}
+	})
+	diagnosis.Register("runtime", diagnosis.CategoryContainerRuntime, func() []diagnosis.Result {
+		return []diagnosis.Result{diagnosis.OK("runtime")}
+	})
+	defer delete(diagnosis.DefaultCatalog, "cloud")
+	defer delete(diagnosis.DefaultCatalog, "runtime")
+
+	w := &bytes.Buffer{}
+	results, err := Run(w, []diagnosis.Category{diagnosis.CategoryCloudProvider})
+	require.NoError(t, err)
+	assert.Equal(t, []diagnosis.Result{
+		{Name: "cloud first", Category: diagnosis.CategoryCloudProvider, Status: diagnosis.StatusOK, Message: "first"},
+		{Name: "cloud second", Category: diagnosis.CategoryCloudProvider, Status: diagnosis.StatusWarning, Message: "second", Remediation: "ignore it", Details: map[string]interface{}{"key": "value"}},
+	}, results)
+	assert.Contains(t, w.String(), "===> WARNING cloud second: second\n     Remediation: ignore it\n")
+	assert.NotContains(t, w.String(), "runtime")
+
+	_, err = Run(w, []diagnosis.Category{"unknown"})
+	assert.Error(t, err)
 }
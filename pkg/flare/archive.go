@@ -29,6 +29,7 @@ import (
 	apiutil "github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/diagnose"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -625,7 +626,7 @@ func zipDiagnose(tempDir, hostname string) error {
 	var b bytes.Buffer
 
 	writer := bufio.NewWriter(&b)
-	diagnose.RunAll(writer) //nolint:errcheck
+	results, _ := diagnose.Run(writer, nil)
 	writer.Flush()
 
 	f := filepath.Join(tempDir, hostname, "diagnose.log")
@@ -634,7 +635,25 @@ func zipDiagnose(tempDir, hostname string) error {
 		return err
 	}
 
-	return writeScrubbedFile(f, b.Bytes())
+	err = writeScrubbedFile(f, b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return writeDiagnoseResults(tempDir, hostname, results)
+}
+
+// writeDiagnoseResults writes the structured results of the diagnoses
+func writeDiagnoseResults(tempDir, hostname string, results []diagnosis.Result) error {
+	if results == nil {
+		results = []diagnosis.Result{}
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeScrubbedFile(filepath.Join(tempDir, hostname, "diagnose.json"), data)
 }
 
 func zipFile(sourceDir, targetDir, filename string) error {
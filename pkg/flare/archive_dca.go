@@ -271,7 +271,7 @@ func zipClusterAgentDiagnose(tempDir, hostname string) error {
 	var b bytes.Buffer
 
 	writer := bufio.NewWriter(&b)
-	GetClusterAgentDiagnose(writer) //nolint:errcheck
+	results, _ := GetClusterAgentDiagnose(writer)
 	writer.Flush()
 
 	f := filepath.Join(tempDir, hostname, "diagnose.log")
@@ -280,7 +280,12 @@ func zipClusterAgentDiagnose(tempDir, hostname string) error {
 		return err
 	}
 
-	return writeScrubbedFile(f, b.Bytes())
+	err = writeScrubbedFile(f, b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return writeDiagnoseResults(tempDir, hostname, results)
 }
 
 func zipClusterAgentTelemetry(tempDir, hostname string) error {
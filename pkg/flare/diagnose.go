@@ -9,9 +9,11 @@ import (
 	"io"
 
 	"github.com/DataDog/datadog-agent/pkg/diagnose"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 )
 
 // GetClusterAgentDiagnose dumps the connectivity checks diagnose to the writer
-func GetClusterAgentDiagnose(w io.Writer) error {
-	return diagnose.RunAll(w)
+// and returns their structured results
+func GetClusterAgentDiagnose(w io.Writer) ([]diagnosis.Result, error) {
+	return diagnose.Run(w, nil)
 }
@@ -94,6 +94,10 @@ const (
 	SysProbeFeatures
 )
 
+// coreAgentName is the name of the core Agent, it names the folder of its
+// transactions stored on disk
+const coreAgentName = "core"
+
 // Options contain the configuration options for the DefaultForwarder
 type Options struct {
 	NumberOfWorkers                int
@@ -232,14 +236,10 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 	if storageMaxSize == 0 {
 		log.Infof("Retry queue storage on disk is disabled")
 	} else if agentName != "" {
-		storagePath := config.Datadog.GetString("forwarder_storage_path")
-		if storagePath == "" {
-			storagePath = path.Join(config.Datadog.GetString("run_path"), "transactions_to_retry")
-		}
+		storagePath := retryQueueStoragePath(agentName)
 		outdatedFileInDays := config.Datadog.GetInt("forwarder_outdated_file_in_days")
 		var err error
 
-		storagePath = path.Join(storagePath, agentName)
 		optionalRemovalPolicy, err = retry.NewFileRemovalPolicy(storagePath, outdatedFileInDays, retry.FileRemovalPolicyTelemetry{})
 		if err != nil {
 			log.Errorf("Error when initializing the removal policy: %v", err)
@@ -322,7 +322,7 @@ func NewDefaultForwarder(options *Options) *DefaultForwarder {
 
 func getAgentName(options *Options) string {
 	if HasFeature(options.EnabledFeatures, CoreFeatures) {
-		return "core"
+		return coreAgentName
 	}
 	// If a new Agent is supported by this function, the implementation of
 	// QueueDurationCapacity.ComputeCapacity must be updated.
@@ -330,6 +330,16 @@ func getAgentName(options *Options) string {
 	return ""
 }
 
+// retryQueueStoragePath returns the folder where the retry queues of an Agent
+// store their transactions, one subfolder per domain.
+func retryQueueStoragePath(agentName string) string {
+	storagePath := config.Datadog.GetString("forwarder_storage_path")
+	if storagePath == "" {
+		storagePath = path.Join(config.Datadog.GetString("run_path"), "transactions_to_retry")
+	}
+	return path.Join(storagePath, agentName)
+}
+
 // Start initialize and runs the forwarder.
 func (f *DefaultForwarder) Start() error {
 	// Lock so we can't stop a Forwarder while is starting
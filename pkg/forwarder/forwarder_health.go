@@ -133,17 +133,23 @@ func (fh *forwarderHealth) healthCheckLoop() {
 // computeDomainsURL populates a map containing API Endpoints per API keys that belongs to the forwarderHealth struct
 func (fh *forwarderHealth) computeDomainsURL() {
 	for domain, dr := range fh.domainResolvers {
-		apiDomain := ""
-		re := regexp.MustCompile(`((us|eu)\d\.)?(datadoghq\.[a-z]+|ddog-gov\.com)$`)
-		if re.MatchString(domain) {
-			apiDomain = "https://api." + re.FindString(domain)
-		} else {
-			apiDomain = domain
-		}
+		apiDomain := APIKeyValidationDomain(domain)
 		fh.keysPerAPIEndpoint[apiDomain] = append(fh.keysPerAPIEndpoint[apiDomain], dr.GetAPIKeys()...)
 	}
 }
 
+var datadogDomainRegexp = regexp.MustCompile(`((us|eu)\d\.)?(datadoghq\.[a-z]+|ddog-gov\.com)$`)
+
+// APIKeyValidationDomain returns the domain validating the API keys of a
+// forwarder domain: the API domain of the Datadog sites, the domain itself
+// otherwise (e.g. a proxy).
+func APIKeyValidationDomain(domain string) string {
+	if datadogDomainRegexp.MatchString(domain) {
+		return "https://api." + datadogDomainRegexp.FindString(domain)
+	}
+	return domain
+}
+
 func (fh *forwarderHealth) setAPIKeyStatus(apiKey string, domain string, status expvar.Var) {
 	if len(apiKey) > 5 {
 		apiKey = apiKey[len(apiKey)-5:]
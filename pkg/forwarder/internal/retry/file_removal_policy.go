@@ -6,13 +6,9 @@
 package retry
 
 import (
-	"crypto/md5"
-	"fmt"
-	"io"
 	"io/ioutil"
 	"os"
 	"path"
-	"path/filepath"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util"
@@ -109,14 +105,7 @@ func (p *FileRemovalPolicy) forEachDomainPath(callback func(folderPath string) (
 }
 
 func (p *FileRemovalPolicy) getFolderPathForDomain(domainName string) (string, error) {
-	// Use md5 for the folder name as the domainName is an url which can contain invalid charaters for a file path.
-	h := md5.New()
-	if _, err := io.WriteString(h, domainName); err != nil {
-		return "", err
-	}
-	folder := fmt.Sprintf("%x", h.Sum(nil))
-
-	return path.Join(p.rootPath, folder), nil
+	return DomainFolderPath(p.rootPath, domainName)
 }
 
 func (p *FileRemovalPolicy) removeUnknownDomain(folderPath string) ([]string, error) {
@@ -158,15 +147,5 @@ func (p *FileRemovalPolicy) removeRetryFiles(folderPath string, shouldRemove fun
 }
 
 func (p *FileRemovalPolicy) getRetryFiles(folder string) ([]string, error) {
-	entries, err := ioutil.ReadDir(folder)
-	if err != nil {
-		return nil, err
-	}
-	var files []string
-	for _, entry := range entries {
-		if entry.Mode().IsRegular() && filepath.Ext(entry.Name()) == retryTransactionsExtension {
-			files = append(files, path.Join(folder, entry.Name()))
-		}
-	}
-	return files, nil
+	return GetRetryFiles(folder)
 }
@@ -109,41 +109,49 @@ func (s *HTTPTransactionsSerializer) Deserialize(bytes []byte) ([]transaction.Tr
 	var httpTransactions []transaction.Transaction
 	errorCount := 0
 	for _, tr := range collection.Values {
-		var route string
-		var proto http.Header
-		e := tr.Endpoint
-
-		priority, err := fromTransactionPriorityProto(tr.Priority)
-		if err == nil {
-			route, err = s.restoreAPIKeys(e.Route)
-			if err == nil {
-				proto, err = s.fromHeaderProto(tr.Headers)
-			}
-		}
-
+		httpTransaction, err := s.DeserializeTransaction(tr)
 		if err != nil {
 			log.Errorf("Error when deserializing a transaction: %v", err)
 			errorCount++
 			continue
 		}
+		httpTransactions = append(httpTransactions, httpTransaction)
+	}
+	return httpTransactions, errorCount, nil
+}
 
-		endpoint := transaction.Endpoint{Route: route, Name: e.Name}
-		domain, _ := s.resolver.Resolve(endpoint)
-		tr := transaction.HTTPTransaction{
-			Domain:         domain,
-			Endpoint:       endpoint,
-			Headers:        proto,
-			Payload:        &tr.Payload,
-			ErrorCount:     int(tr.ErrorCount),
-			CreatedAt:      time.Unix(tr.CreatedAt, 0),
-			Retryable:      tr.Retryable,
-			StorableOnDisk: true,
-			Priority:       priority,
+// DeserializeTransaction restores a single transaction read from the disk.
+func (s *HTTPTransactionsSerializer) DeserializeTransaction(tr *HttpTransactionProto) (*transaction.HTTPTransaction, error) {
+	var route string
+	var proto http.Header
+	e := tr.Endpoint
+
+	priority, err := fromTransactionPriorityProto(tr.Priority)
+	if err == nil {
+		route, err = s.restoreAPIKeys(e.Route)
+		if err == nil {
+			proto, err = s.fromHeaderProto(tr.Headers)
 		}
-		tr.SetDefaultHandlers()
-		httpTransactions = append(httpTransactions, &tr)
 	}
-	return httpTransactions, errorCount, nil
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := transaction.Endpoint{Route: route, Name: e.Name}
+	domain, _ := s.resolver.Resolve(endpoint)
+	httpTransaction := transaction.HTTPTransaction{
+		Domain:         domain,
+		Endpoint:       endpoint,
+		Headers:        proto,
+		Payload:        &tr.Payload,
+		ErrorCount:     int(tr.ErrorCount),
+		CreatedAt:      time.Unix(tr.CreatedAt, 0),
+		Retryable:      tr.Retryable,
+		StorableOnDisk: true,
+		Priority:       priority,
+	}
+	httpTransaction.SetDefaultHandlers()
+	return &httpTransaction, nil
 }
 
 func (s *HTTPTransactionsSerializer) replaceAPIKeys(str string) string {
@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	proto "github.com/golang/protobuf/proto"
+)
+
+// DomainFolderPath returns the folder storing the retry files of a domain.
+func DomainFolderPath(rootPath string, domainName string) (string, error) {
+	// Use md5 for the folder name as the domainName is an url which can contain invalid charaters for a file path.
+	h := md5.New()
+	if _, err := io.WriteString(h, domainName); err != nil {
+		return "", err
+	}
+	folder := fmt.Sprintf("%x", h.Sum(nil))
+
+	return path.Join(rootPath, folder), nil
+}
+
+// GetRetryFiles returns the paths of the retry files stored in a folder.
+func GetRetryFiles(folder string) ([]string, error) {
+	entries, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() && filepath.Ext(entry.Name()) == retryTransactionsExtension {
+			files = append(files, path.Join(folder, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// ReadRetryFile reads the transactions of a retry file. The API keys are not
+// restored, they stay replaced by placeholders.
+func ReadRetryFile(path string) (*HttpTransactionProtoCollection, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	collection := &HttpTransactionProtoCollection{}
+	if err := proto.Unmarshal(bytes, collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// WriteRetryFile replaces the transactions of a retry file, or removes the
+// file when there is no transaction left. The modification time of the file
+// is kept as it orders the files of the retry queue.
+func WriteRetryFile(path string, collection *HttpTransactionProtoCollection) error {
+	if len(collection.Values) == 0 {
+		return os.Remove(path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	bytes, err := proto.Marshal(collection)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(bytes)
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Chtimes(file.Name(), info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+	return err
+}
+
+// FormatAPIKeyPlaceholders makes the API key placeholders of a stored route or
+// header readable, `<API_KEY_0>` for the first API key.
+func FormatAPIKeyPlaceholders(str string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(str, placeHolderPrefix)
+		if start < 0 {
+			break
+		}
+		rest := str[start+len(placeHolderPrefix):]
+		end := strings.Index(rest, squareChar)
+		if end < 0 {
+			break
+		}
+		b.WriteString(str[:start])
+		b.WriteString("<API_KEY_" + rest[:end] + ">")
+		str = rest[end+len(squareChar):]
+	}
+	b.WriteString(str)
+	return b.String()
+}
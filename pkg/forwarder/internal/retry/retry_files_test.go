@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWriteRetryFile(t *testing.T) {
+	folder := t.TempDir()
+	serializer := NewHTTPTransactionsSerializer(resolver.NewSingleDomainResolver(domain, []string{apiKey1, apiKey2}))
+	require.NoError(t, serializer.Add(createHTTPTransactionTests(domain)))
+	require.NoError(t, serializer.Add(createHTTPTransactionTests(domain)))
+	bytes, err := serializer.GetBytesAndReset()
+	require.NoError(t, err)
+
+	filename := path.Join(folder, "2022_01_01__00_00_00_1"+retryTransactionsExtension)
+	require.NoError(t, os.WriteFile(filename, bytes, 0600))
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(filename, modTime, modTime))
+	require.NoError(t, os.WriteFile(path.Join(folder, "other.txt"), nil, 0600))
+
+	files, err := GetRetryFiles(folder)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filename}, files)
+
+	collection, err := ReadRetryFile(filename)
+	require.NoError(t, err)
+	require.Len(t, collection.Values, 2)
+	assert.Equal(t, "route<API_KEY_0>", FormatAPIKeyPlaceholders(collection.Values[0].Endpoint.Route))
+
+	// the API keys are restored when the transaction is deserialized
+	tr, err := serializer.DeserializeTransaction(collection.Values[0])
+	require.NoError(t, err)
+	assert.Equal(t, "route"+apiKey1, tr.Endpoint.Route)
+
+	collection.Values = collection.Values[1:]
+	require.NoError(t, WriteRetryFile(filename, collection))
+	collection, err = ReadRetryFile(filename)
+	require.NoError(t, err)
+	assert.Len(t, collection.Values, 1)
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	assert.Equal(t, modTime, info.ModTime())
+
+	collection.Values = nil
+	require.NoError(t, WriteRetryFile(filename, collection))
+	files, err = GetRetryFiles(folder)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestFormatAPIKeyPlaceholders(t *testing.T) {
+	assert.Equal(t, "no placeholder", FormatAPIKeyPlaceholders("no placeholder"))
+	assert.Equal(t, "/api?key=<API_KEY_0>&other=<API_KEY_12>",
+		FormatAPIKeyPlaceholders("/api?key="+placeHolderPrefix+"0"+squareChar+"&other="+placeHolderPrefix+"12"+squareChar))
+	// a truncated placeholder is kept as is
+	assert.Equal(t, "a"+placeHolderPrefix+"1", FormatAPIKeyPlaceholders("a"+placeHolderPrefix+"1"))
+}
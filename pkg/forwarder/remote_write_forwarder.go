@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/internal/retry"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// remoteWriteEndpointName is the name of the remote-write endpoint in the
+// forwarder telemetry.
+const remoteWriteEndpointName = "prometheus_remote_write"
+
+// RemoteWriteOptions contain the configuration options for the RemoteWriteForwarder
+type RemoteWriteOptions struct {
+	// URL is the full URL of the remote-write endpoint, e.g. https://prometheus:9090/api/v1/write
+	URL string
+	// Headers are added to every request, typically for authentication.
+	Headers                        http.Header
+	NumberOfWorkers                int
+	RetryQueuePayloadsTotalMaxSize int
+	ConnectionResetInterval        time.Duration
+}
+
+// NewRemoteWriteOptions creates new RemoteWriteOptions from the
+// `prometheus_remote_write` configuration.
+func NewRemoteWriteOptions() *RemoteWriteOptions {
+	headers := http.Header{}
+	for key, value := range config.Datadog.GetStringMapString("prometheus_remote_write.headers") {
+		headers.Set(key, value)
+	}
+	if token := config.Datadog.GetString("prometheus_remote_write.bearer_token"); token != "" {
+		headers.Set("Authorization", "Bearer "+token)
+	}
+
+	return &RemoteWriteOptions{
+		URL:                            config.Datadog.GetString("prometheus_remote_write.url"),
+		Headers:                        headers,
+		NumberOfWorkers:                config.Datadog.GetInt("prometheus_remote_write.num_workers"),
+		RetryQueuePayloadsTotalMaxSize: config.Datadog.GetInt("prometheus_remote_write.retry_queue_payloads_max_size"),
+		ConnectionResetInterval:        time.Duration(config.Datadog.GetInt("forwarder_connection_reset_interval")) * time.Second,
+	}
+}
+
+// RemoteWriteForwarder sends payloads to a Prometheus remote-write endpoint.
+// It has its own workers and retry queue, so an unavailable remote-write
+// endpoint doesn't affect the payloads sent to Datadog.
+type RemoteWriteForwarder struct {
+	domain          string
+	endpoint        transaction.Endpoint
+	headers         http.Header
+	domainForwarder *domainForwarder
+	internalState   uint32
+	m               sync.Mutex // To control Start/Stop races
+}
+
+// NewRemoteWriteForwarder returns a new RemoteWriteForwarder.
+func NewRemoteWriteForwarder(options *RemoteWriteOptions) (*RemoteWriteForwarder, error) {
+	u, err := url.Parse(options.URL)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the remote-write URL: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid remote-write URL %q: an absolute http or https URL is expected", options.URL)
+	}
+
+	domain := fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+	route := u.EscapedPath()
+	if u.RawQuery != "" {
+		route += "?" + u.RawQuery
+	}
+
+	numberOfWorkers := options.NumberOfWorkers
+	if numberOfWorkers <= 0 {
+		numberOfWorkers = 1
+	}
+
+	retryQueue := retry.BuildTransactionRetryQueue(
+		options.RetryQueuePayloadsTotalMaxSize,
+		config.Datadog.GetFloat64("forwarder_flush_to_disk_mem_ratio"),
+		"",
+		nil,
+		transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: false},
+		resolver.NewSingleDomainResolver(domain, nil))
+
+	return &RemoteWriteForwarder{
+		domain:   domain,
+		endpoint: transaction.Endpoint{Route: route, Name: remoteWriteEndpointName},
+		headers:  options.Headers,
+		domainForwarder: newDomainForwarder(
+			domain,
+			retryQueue,
+			numberOfWorkers,
+			options.ConnectionResetInterval,
+			transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: true}),
+		internalState: Stopped,
+	}, nil
+}
+
+// Start starts the RemoteWriteForwarder.
+func (f *RemoteWriteForwarder) Start() error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.internalState == Started {
+		return fmt.Errorf("the remote-write forwarder is already started")
+	}
+	if err := f.domainForwarder.Start(); err != nil {
+		return err
+	}
+	log.Infof("Remote-write forwarder started, sending to %q", f.domain+f.endpoint.Route)
+	f.internalState = Started
+	return nil
+}
+
+// Stop stops the RemoteWriteForwarder, the transactions not yet sent are lost.
+func (f *RemoteWriteForwarder) Stop() {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.internalState == Stopped {
+		log.Warnf("the remote-write forwarder is already stopped")
+		return
+	}
+	f.domainForwarder.Stop(false)
+	f.internalState = Stopped
+}
+
+// SubmitRemoteWrite sends snappy compressed remote-write protobuf payloads.
+func (f *RemoteWriteForwarder) SubmitRemoteWrite(payload Payloads, extra http.Header) error {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.internalState == Stopped {
+		return fmt.Errorf("the remote-write forwarder is not started")
+	}
+
+	for _, p := range payload {
+		t := transaction.NewHTTPTransaction()
+		t.Domain = f.domain
+		t.Endpoint = f.endpoint
+		t.Payload = p
+		// the headers may contain credentials
+		t.StorableOnDisk = false
+		t.Headers.Set(useragentHTTPHeaderKey, fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
+		for key := range f.headers {
+			t.Headers.Set(key, f.headers.Get(key))
+		}
+		for key := range extra {
+			t.Headers.Set(key, extra.Get(key))
+		}
+
+		tlmTxInputCount.Inc(f.domain, f.endpoint.Name)
+		tlmTxInputBytes.Add(float64(t.GetPayloadSize()), f.domain, f.endpoint.Name)
+		transactionsInputCountByEndpoint.Add(f.endpoint.Name, 1)
+		transactionsInputBytesByEndpoint.Add(f.endpoint.Name, int64(t.GetPayloadSize()))
+
+		f.domainForwarder.sendHTTPTransactions(t)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestNewRemoteWriteForwarderInvalidURL(t *testing.T) {
+	for _, u := range []string{"", "prometheus:9090/api/v1/write", "ftp://prometheus/api/v1/write"} {
+		_, err := NewRemoteWriteForwarder(&RemoteWriteOptions{URL: u})
+		assert.Error(t, err, u)
+	}
+}
+
+func TestRemoteWriteForwarderSubmit(t *testing.T) {
+	type request struct {
+		path    string
+		query   string
+		headers http.Header
+		body    []byte
+	}
+	requests := make(chan request, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- request{path: r.URL.Path, query: r.URL.RawQuery, headers: r.Header, body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer secret")
+	f, err := NewRemoteWriteForwarder(&RemoteWriteOptions{
+		URL:                            ts.URL + "/api/v1/write?tenant=a",
+		Headers:                        headers,
+		RetryQueuePayloadsTotalMaxSize: 1024,
+	})
+	require.NoError(t, err)
+
+	data := []byte("payload")
+	assert.Error(t, f.SubmitRemoteWrite(Payloads{&data}, nil))
+
+	require.NoError(t, f.Start())
+	defer f.Stop()
+
+	extra := http.Header{}
+	extra.Set("Content-Encoding", "snappy")
+	require.NoError(t, f.SubmitRemoteWrite(Payloads{&data}, extra))
+
+	select {
+	case r := <-requests:
+		assert.Equal(t, "/api/v1/write", r.path)
+		assert.Equal(t, "tenant=a", r.query)
+		assert.Equal(t, "Bearer secret", r.headers.Get("Authorization"))
+		assert.Equal(t, "snappy", r.headers.Get("Content-Encoding"))
+		assert.Empty(t, r.headers.Get(apiHTTPHeaderKey))
+		assert.Equal(t, data, r.body)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the payload was not sent")
+	}
+}
+
+func TestNewRemoteWriteOptions(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("prometheus_remote_write.url", "https://prometheus/api/v1/write")
+	mockConfig.Set("prometheus_remote_write.bearer_token", "secret")
+	mockConfig.Set("prometheus_remote_write.headers", map[string]string{"X-Scope-OrgID": "tenant"})
+	defer mockConfig.Set("prometheus_remote_write.url", "")
+	defer mockConfig.Set("prometheus_remote_write.bearer_token", "")
+	defer mockConfig.Set("prometheus_remote_write.headers", map[string]string{})
+
+	options := NewRemoteWriteOptions()
+	assert.Equal(t, "https://prometheus/api/v1/write", options.URL)
+	assert.Equal(t, "Bearer secret", options.Headers.Get("Authorization"))
+	assert.Equal(t, "tenant", options.Headers.Get("X-Scope-OrgID"))
+}
@@ -0,0 +1,360 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/agent-payload/v5/gogen"
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/endpoints"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/internal/retry"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+// RetryTransaction describes a transaction stored on disk by the retry queue
+// of the core Agent. The API keys of the route and of the headers are replaced
+// by placeholders.
+type RetryTransaction struct {
+	// ID identifies the transaction, `<file name>:<index in the file>`
+	ID          string      `json:"id"`
+	File        string      `json:"file"`
+	Index       int         `json:"index"`
+	Domain      string      `json:"domain"`
+	Endpoint    string      `json:"endpoint"`
+	Route       string      `json:"route"`
+	Priority    string      `json:"priority"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ErrorCount  int         `json:"error_count"`
+	PayloadSize int         `json:"payload_size"`
+	Headers     http.Header `json:"headers"`
+
+	proto *retry.HttpTransactionProto
+}
+
+// DecodedRetryTransaction is a RetryTransaction with its decompressed and
+// decoded payload.
+type DecodedRetryTransaction struct {
+	RetryTransaction
+	// Payload is the decoded series or sketches, the JSON payload, or the raw
+	// bytes of the payloads of other formats
+	Payload interface{} `json:"payload"`
+}
+
+// ResendResult is the result of sending again a RetryTransaction.
+type ResendResult struct {
+	Transaction RetryTransaction
+	StatusCode  int
+	// Removed is set when the transaction was sent or rejected by the intake,
+	// and removed from the disk
+	Removed bool
+	// Err is set when the transaction could not be sent or was rejected
+	Err error
+}
+
+// retryDomain is a domain whose transactions are stored on disk
+type retryDomain struct {
+	name     string
+	resolver resolver.DomainResolver
+}
+
+// RetryQueueInspector lists, decodes, purges and sends again the transactions
+// stored on disk by the retry queue of the core Agent. The domain of the
+// transactions is not stored on disk, it is found from the domains of the
+// configuration.
+type RetryQueueInspector struct {
+	storagePath string
+	// domains by folder name
+	domains   map[string]retryDomain
+	resolvers map[string]resolver.DomainResolver
+}
+
+// NewRetryQueueInspector returns a RetryQueueInspector for the domains and
+// API keys of the configuration.
+func NewRetryQueueInspector(keysPerDomain map[string][]string) (*RetryQueueInspector, error) {
+	storagePath := retryQueueStoragePath(coreAgentName)
+	resolvers := NewOptions(keysPerDomain).DomainResolvers
+
+	domains := make(map[string]retryDomain, len(resolvers))
+	for domain, r := range resolvers {
+		domain, _ := config.AddAgentVersionToDomain(domain, "app")
+		r.SetBaseDomain(domain)
+		folder, err := retry.DomainFolderPath(storagePath, domain)
+		if err != nil {
+			return nil, err
+		}
+		domains[filepath.Base(folder)] = retryDomain{name: domain, resolver: r}
+	}
+
+	return &RetryQueueInspector{
+		storagePath: storagePath,
+		domains:     domains,
+		resolvers:   resolvers,
+	}, nil
+}
+
+// StoragePath returns the folder of the transactions stored on disk.
+func (i *RetryQueueInspector) StoragePath() string {
+	return i.storagePath
+}
+
+// List returns the transactions stored on disk, from the oldest file to the
+// newest. The files which cannot be read are skipped and reported in the
+// returned error.
+func (i *RetryQueueInspector) List() ([]RetryTransaction, error) {
+	entries, err := ioutil.ReadDir(i.storagePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type retryFile struct {
+		path    string
+		domain  string
+		modTime time.Time
+	}
+	var files []retryFile
+	var errs error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		paths, err := retry.GetRetryFiles(filepath.Join(i.storagePath, entry.Name()))
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+			files = append(files, retryFile{path: path, domain: i.domains[entry.Name()].name, modTime: info.ModTime()})
+		}
+	}
+	sort.Slice(files, func(a, b int) bool {
+		return files[a].modTime.Before(files[b].modTime)
+	})
+
+	var transactions []RetryTransaction
+	for _, file := range files {
+		collection, err := retry.ReadRetryFile(file.path)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("cannot read %s: %v", file.path, err))
+			continue
+		}
+		for index, tr := range collection.Values {
+			transactions = append(transactions, newRetryTransaction(file.path, file.domain, index, tr))
+		}
+	}
+	return transactions, errs
+}
+
+func newRetryTransaction(path string, domain string, index int, tr *retry.HttpTransactionProto) RetryTransaction {
+	headers := make(http.Header, len(tr.Headers))
+	for key, values := range tr.Headers {
+		for _, v := range values.Values {
+			headers.Add(key, retry.FormatAPIKeyPlaceholders(v))
+		}
+	}
+	var endpoint, route string
+	if tr.Endpoint != nil {
+		endpoint = tr.Endpoint.Name
+		route = retry.FormatAPIKeyPlaceholders(tr.Endpoint.Route)
+	}
+	return RetryTransaction{
+		ID:          filepath.Base(path) + ":" + strconv.Itoa(index),
+		File:        path,
+		Index:       index,
+		Domain:      domain,
+		Endpoint:    endpoint,
+		Route:       route,
+		Priority:    strings.ToLower(tr.Priority.String()),
+		CreatedAt:   time.Unix(tr.CreatedAt, 0),
+		ErrorCount:  int(tr.ErrorCount),
+		PayloadSize: len(tr.Payload),
+		Headers:     headers,
+		proto:       tr,
+	}
+}
+
+// SelectRetryTransactions returns the transactions matching the selectors: a transaction ID,
+// or a file name selecting all the transactions of the file.
+func SelectRetryTransactions(transactions []RetryTransaction, selectors []string) ([]RetryTransaction, error) {
+	var selected []RetryTransaction
+	for _, selector := range selectors {
+		found := false
+		for _, tr := range transactions {
+			if tr.ID == selector || filepath.Base(tr.File) == selector {
+				selected = append(selected, tr)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no transaction matches %q", selector)
+		}
+	}
+	return selected, nil
+}
+
+// Decode decompresses and decodes the payload of a transaction.
+func (i *RetryQueueInspector) Decode(tr RetryTransaction) (*DecodedRetryTransaction, error) {
+	payload, err := decompressPayload(tr.Headers.Get("Content-Encoding"), tr.proto.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress the payload of %s: %v", tr.ID, err)
+	}
+
+	decoded := &DecodedRetryTransaction{RetryTransaction: tr}
+	switch tr.Endpoint {
+	case endpoints.SeriesEndpoint.Name:
+		series := &gogen.MetricPayload{}
+		if err := series.Unmarshal(payload); err != nil {
+			return nil, fmt.Errorf("cannot decode the series of %s: %v", tr.ID, err)
+		}
+		decoded.Payload = series
+	case endpoints.SketchSeriesEndpoint.Name:
+		sketches := &gogen.SketchPayload{}
+		if err := sketches.Unmarshal(payload); err != nil {
+			return nil, fmt.Errorf("cannot decode the sketches of %s: %v", tr.ID, err)
+		}
+		decoded.Payload = sketches
+	default:
+		if json.Valid(payload) {
+			decoded.Payload = json.RawMessage(payload)
+		} else {
+			decoded.Payload = payload
+		}
+	}
+	return decoded, nil
+}
+
+func decompressPayload(contentEncoding string, payload []byte) ([]byte, error) {
+	switch contentEncoding {
+	case "":
+		return payload, nil
+	case "deflate":
+		reader, err := zlib.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return ioutil.ReadAll(reader)
+	case "gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return ioutil.ReadAll(reader)
+	case "zstd":
+		return compression.NewZstdCompressor(0).Decompress(payload)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", contentEncoding)
+	}
+}
+
+// Purge removes the transactions from the disk.
+func (i *RetryQueueInspector) Purge(transactions []RetryTransaction) error {
+	byFile := make(map[string]map[int]struct{})
+	for _, tr := range transactions {
+		if byFile[tr.File] == nil {
+			byFile[tr.File] = make(map[int]struct{})
+		}
+		byFile[tr.File][tr.Index] = struct{}{}
+	}
+
+	var errs error
+	for file, indexes := range byFile {
+		collection, err := retry.ReadRetryFile(file)
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		kept := collection.Values[:0]
+		for index, tr := range collection.Values {
+			if _, found := indexes[index]; !found {
+				kept = append(kept, tr)
+			}
+		}
+		collection.Values = kept
+		if err := retry.WriteRetryFile(file, collection); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Resend sends the transactions again through a new SyncForwarder, and
+// removes from the disk the ones which were sent or rejected by the intake.
+// The transactions which could not be sent are kept.
+func (i *RetryQueueInspector) Resend(transactions []RetryTransaction, timeout time.Duration) ([]ResendResult, error) {
+	forwarder := NewSyncForwarder(i.resolvers, timeout)
+
+	results := make([]ResendResult, 0, len(transactions))
+	var handled []RetryTransaction
+	for _, tr := range transactions {
+		result := i.resend(forwarder, tr)
+		if result.Removed {
+			handled = append(handled, tr)
+		}
+		results = append(results, result)
+	}
+	return results, i.Purge(handled)
+}
+
+func (i *RetryQueueInspector) resend(forwarder *SyncForwarder, tr RetryTransaction) ResendResult {
+	result := ResendResult{Transaction: tr}
+	domain, found := i.domains[filepath.Base(filepath.Dir(tr.File))]
+	if !found {
+		result.Err = fmt.Errorf("unknown domain, the API keys of the transaction cannot be restored")
+		return result
+	}
+
+	httpTransaction, err := retry.NewHTTPTransactionsSerializer(domain.resolver).DeserializeTransaction(tr.proto)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	var completionErr error
+	httpTransaction.CompletionHandler = func(_ *transaction.HTTPTransaction, statusCode int, _ []byte, err error) {
+		result.StatusCode = statusCode
+		completionErr = err
+	}
+
+	if err := forwarder.sendHTTPTransaction(httpTransaction); err != nil {
+		result.Err = err
+	} else if completionErr != nil {
+		// a transaction which is not retryable reports its error to the completion handler only
+		result.Err = completionErr
+	} else {
+		// the intake dropped the transaction when the status code is an error,
+		// the Agent would not retry it either
+		result.Removed = true
+		if result.StatusCode >= 400 {
+			result.Err = fmt.Errorf("rejected by the intake with the status code %d", result.StatusCode)
+		}
+	}
+	return result
+}
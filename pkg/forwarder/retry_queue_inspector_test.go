@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package forwarder
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/agent-payload/v5/gogen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/config/resolver"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/endpoints"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/internal/retry"
+	"github.com/DataDog/datadog-agent/pkg/forwarder/transaction"
+)
+
+func writeRetryFile(t *testing.T, folder string, domain string, apiKey string) {
+	series := &gogen.MetricPayload{Series: []*gogen.MetricPayload_MetricSeries{{Metric: "my.metric"}}}
+	seriesPayload, err := series.Marshal()
+	require.NoError(t, err)
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	_, err = writer.Write(seriesPayload)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	payloads := [][]byte{compressed.Bytes(), []byte(`{"key":"value"}`)}
+
+	serializer := retry.NewHTTPTransactionsSerializer(resolver.NewSingleDomainResolver(domain, []string{apiKey}))
+	for i, endpoint := range []transaction.Endpoint{endpoints.SeriesEndpoint, endpoints.V1IntakeEndpoint} {
+		tr := transaction.NewHTTPTransaction()
+		tr.Domain = domain
+		tr.Endpoint = endpoint
+		tr.Payload = &payloads[i]
+		tr.Headers.Set("DD-Api-Key", apiKey)
+		if endpoint == endpoints.SeriesEndpoint {
+			tr.Headers.Set("Content-Encoding", "deflate")
+		}
+		require.NoError(t, serializer.Add(tr))
+	}
+	data, err := serializer.GetBytesAndReset()
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(folder, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "2022_01_01__00_00_00_1.retry"), data, 0600))
+}
+
+func TestRetryQueueInspector(t *testing.T) {
+	var receivedAPIKeys []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAPIKeys = append(receivedAPIKeys, r.Header.Get("DD-Api-Key"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	storagePath := t.TempDir()
+	config.Datadog.Set("forwarder_storage_path", storagePath)
+	defer config.Datadog.Set("forwarder_storage_path", "")
+
+	inspector, err := NewRetryQueueInspector(map[string][]string{ts.URL: {"api_key1"}})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(storagePath, coreAgentName), inspector.StoragePath())
+
+	folder, err := retry.DomainFolderPath(inspector.StoragePath(), ts.URL)
+	require.NoError(t, err)
+	writeRetryFile(t, folder, ts.URL, "api_key1")
+
+	transactions, err := inspector.List()
+	require.NoError(t, err)
+	require.Len(t, transactions, 2)
+	assert.Equal(t, "2022_01_01__00_00_00_1.retry:0", transactions[0].ID)
+	assert.Equal(t, ts.URL, transactions[0].Domain)
+	assert.Equal(t, endpoints.SeriesEndpoint.Name, transactions[0].Endpoint)
+	assert.Equal(t, "normal", transactions[0].Priority)
+	assert.Equal(t, "<API_KEY_0>", transactions[0].Headers.Get("DD-Api-Key"))
+
+	decoded, err := inspector.Decode(transactions[0])
+	require.NoError(t, err)
+	require.IsType(t, &gogen.MetricPayload{}, decoded.Payload)
+	assert.Equal(t, "my.metric", decoded.Payload.(*gogen.MetricPayload).Series[0].Metric)
+	decoded, err = inspector.Decode(transactions[1])
+	require.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`{"key":"value"}`), decoded.Payload)
+
+	selected, err := SelectRetryTransactions(transactions, []string{"2022_01_01__00_00_00_1.retry:1"})
+	require.NoError(t, err)
+	results, err := inspector.Resend(selected, 5*time.Second)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+	assert.True(t, results[0].Removed)
+	assert.Equal(t, http.StatusAccepted, results[0].StatusCode)
+	assert.Equal(t, []string{"api_key1"}, receivedAPIKeys)
+
+	transactions, err = inspector.List()
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, endpoints.SeriesEndpoint.Name, transactions[0].Endpoint)
+
+	_, err = SelectRetryTransactions(transactions, []string{"unknown.retry"})
+	assert.Error(t, err)
+	selected, err = SelectRetryTransactions(transactions, []string{"2022_01_01__00_00_00_1.retry"})
+	require.NoError(t, err)
+	require.NoError(t, inspector.Purge(selected))
+	transactions, err = inspector.List()
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+}
@@ -46,21 +46,29 @@ func (f *SyncForwarder) Stop() {
 
 func (f *SyncForwarder) sendHTTPTransactions(transactions []*transaction.HTTPTransaction) error {
 	for _, t := range transactions {
-		if err := t.Process(context.Background(), f.client); err != nil {
-			log.Debugf("SyncForwarder.sendHTTPTransactions first attempt: %s", err)
-			// Retry once after error
-			// The intake may have closed the connection between Lambda invocations.
-			// If so, the first attempt will fail because the closed connection will still be cached.
-			log.Debug("Retrying transaction")
-			if err := t.Process(context.Background(), f.client); err != nil {
-				log.Warnf("SyncForwarder.sendHTTPTransactions failed to send: %s", err)
-			}
+		if err := f.sendHTTPTransaction(t); err != nil {
+			log.Warnf("SyncForwarder.sendHTTPTransactions failed to send: %s", err)
 		}
 	}
 	log.Debugf("SyncForwarder has flushed %d transactions", len(transactions))
 	return nil
 }
 
+// sendHTTPTransaction sends a transaction, retrying once on error, and returns
+// the error of the last attempt.
+func (f *SyncForwarder) sendHTTPTransaction(t *transaction.HTTPTransaction) error {
+	err := t.Process(context.Background(), f.client)
+	if err != nil {
+		log.Debugf("SyncForwarder.sendHTTPTransactions first attempt: %s", err)
+		// Retry once after error
+		// The intake may have closed the connection between Lambda invocations.
+		// If so, the first attempt will fail because the closed connection will still be cached.
+		log.Debug("Retrying transaction")
+		err = t.Process(context.Background(), f.client)
+	}
+	return err
+}
+
 // SubmitV1Series will send timeserie to v1 endpoint (this will be remove once
 // the backend handles v2 endpoints).
 func (f *SyncForwarder) SubmitV1Series(payload Payloads, extra http.Header) error {
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package transaction
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const contentEncodingHTTPHeader = "Content-Encoding"
+
+var (
+	// zstdUnsupportedDomains holds the domains which rejected a zstd payload.
+	// The zstd payloads sent to these domains are transcoded with
+	// fallbackCompressor.
+	zstdUnsupportedDomains sync.Map
+
+	zstdCompressor     = compression.NewZstdCompressor(0)
+	fallbackCompressor = compression.NewCompressor(compression.ZlibKind, 0)
+)
+
+// isZstdRejected returns true when the response status code means that the
+// domain doesn't accept the zstd payload of the transaction
+func (t *HTTPTransaction) isZstdRejected(statusCode int) bool {
+	return statusCode == 415 && t.Headers.Get(contentEncodingHTTPHeader) == zstdCompressor.ContentEncoding()
+}
+
+// disableZstd records that the domain of the transaction doesn't accept zstd
+// payloads
+func (t *HTTPTransaction) disableZstd() {
+	if _, loaded := zstdUnsupportedDomains.LoadOrStore(t.Domain, struct{}{}); !loaded {
+		log.Warnf("%s does not support zstd compression, falling back to %q content encoding", t.Domain, fallbackCompressor.ContentEncoding())
+	}
+}
+
+// fallbackFromZstd transcodes the payload with fallbackCompressor when it is
+// compressed with zstd and its domain doesn't accept zstd payloads. The
+// payload is shared with the transactions of the other domains, so it is
+// replaced instead of being modified in place.
+func (t *HTTPTransaction) fallbackFromZstd() error {
+	if t.Headers.Get(contentEncodingHTTPHeader) != zstdCompressor.ContentEncoding() {
+		return nil
+	}
+	if _, ok := zstdUnsupportedDomains.Load(t.Domain); !ok {
+		return nil
+	}
+
+	decompressed, err := zstdCompressor.Decompress(*t.Payload)
+	if err != nil {
+		return err
+	}
+	payload, err := fallbackCompressor.Compress(decompressed)
+	if err != nil {
+		return err
+	}
+
+	t.Payload = &payload
+	if encoding := fallbackCompressor.ContentEncoding(); encoding != "" {
+		t.Headers.Set(contentEncodingHTTPHeader, encoding)
+	} else {
+		t.Headers.Del(contentEncodingHTTPHeader)
+	}
+	return nil
+}
@@ -286,11 +286,18 @@ func (t *HTTPTransaction) Process(ctx context.Context, client *http.Client) erro
 // internalProcess does the  work of actually sending the http request to the specified domain
 // This will return  (http status code, response body, error).
 func (t *HTTPTransaction) internalProcess(ctx context.Context, client *http.Client) (int, []byte, error) {
-	reader := bytes.NewReader(*t.Payload)
 	url := t.Domain + t.Endpoint.Route
 	transactionEndpointName := t.GetEndpointName()
 	logURL := scrubber.ScrubLine(url) // sanitized url that can be logged
 
+	if err := t.fallbackFromZstd(); err != nil {
+		log.Errorf("Could not transcode the zstd payload of the transaction to %q (dropping transaction): %s", logURL, err)
+		transactionsErrors.Add(1)
+		tlmTxErrors.Inc(t.Domain, transactionEndpointName, "invalid_payload")
+		return 0, nil, nil
+	}
+	reader := bytes.NewReader(*t.Payload)
+
 	req, err := http.NewRequest("POST", url, reader)
 	if err != nil {
 		log.Errorf("Could not create request for transaction to invalid URL %q (dropping transaction): %s", logURL, err)
@@ -335,6 +342,12 @@ func (t *HTTPTransaction) internalProcess(ctx context.Context, client *http.Clie
 		tlmTxHTTPErrors.Inc(t.Domain, transactionEndpointName, statusCode)
 	}
 
+	// The transaction is retried with the fallback compression when the
+	// domain doesn't accept zstd
+	if t.isZstdRejected(resp.StatusCode) {
+		t.disableZstd()
+	}
+
 	// We want to retry 404s even if that means that the agent would retry
 	// payloads on endpoints that don’t exist at the intake it’s sending data
 	// to (example: a specific DD region, or a http proxy)
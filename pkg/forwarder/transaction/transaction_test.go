@@ -7,6 +7,7 @@ package transaction
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -114,6 +115,49 @@ func TestProcessHTTPError(t *testing.T) {
 	assert.Equal(t, transaction.ErrorCount, 1)
 }
 
+func TestProcessZstdFallback(t *testing.T) {
+	content := []byte("test payload")
+	var received []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") == "zstd" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	defer zstdUnsupportedDomains.Delete(ts.URL)
+
+	payload, err := zstdCompressor.Compress(content)
+	assert.NoError(t, err)
+
+	transaction := NewHTTPTransaction()
+	transaction.Domain = ts.URL
+	transaction.Endpoint.Route = "/endpoint/test"
+	transaction.Headers.Set("Content-Encoding", "zstd")
+	transaction.Payload = &payload
+
+	client := &http.Client{}
+
+	// the zstd payload is rejected, the transaction is retried
+	err = transaction.Process(context.Background(), client)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, transaction.ErrorCount)
+
+	err = transaction.Process(context.Background(), client)
+	assert.Nil(t, err)
+	assert.Equal(t, fallbackCompressor.ContentEncoding(), transaction.Headers.Get("Content-Encoding"))
+	decompressed, err := fallbackCompressor.Decompress(received)
+	assert.NoError(t, err)
+	assert.Equal(t, content, decompressed)
+
+	// the payload shared with the other domains is left as is
+	decompressed, err = zstdCompressor.Decompress(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, content, decompressed)
+}
+
 func TestProcessCancel(t *testing.T) {
 	transaction := NewHTTPTransaction()
 	transaction.Domain = "example.com"
@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package auditor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditorUnmarshalRegistryV3(t *testing.T) {
+	input := `{
+	    "Registry": {
+	        "file:/var/log/path1.log": {
+	            "Offset": "1",
+	            "LastUpdated": "2006-01-12T01:01:01.000000001Z",
+	            "Fingerprint": "8c9a3d1e2f4b5a67"
+	        },
+	        "file:/var/log/path2.log": {
+	            "Offset": "2",
+	            "LastUpdated": "2006-01-12T01:01:02.000000001Z"
+	        }
+	    },
+	    "Version": 3
+	}`
+	r, err := unmarshalRegistryV3([]byte(input))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "1", r["file:/var/log/path1.log"].Offset)
+	assert.Equal(t, 1, r["file:/var/log/path1.log"].LastUpdated.Second())
+	assert.Equal(t, "8c9a3d1e2f4b5a67", r["file:/var/log/path1.log"].Fingerprint)
+
+	assert.Equal(t, "2", r["file:/var/log/path2.log"].Offset)
+	assert.Equal(t, 2, r["file:/var/log/path2.log"].LastUpdated.Second())
+	assert.Equal(t, "", r["file:/var/log/path2.log"].Fingerprint)
+}
@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,16 +25,22 @@ import (
 // DefaultRegistryFilename is the default registry filename
 const DefaultRegistryFilename = "registry.json"
 
+// ArchiveIdentifierPrefix prefixes the registry identifiers of the archives
+// read by the file launcher.
+const ArchiveIdentifierPrefix = "archive:"
+
 const defaultFlushPeriod = 1 * time.Second
 const defaultCleanupPeriod = 300 * time.Second
 
 // latest version of the API used by the auditor to retrieve the registry from disk.
-const registryAPIVersion = 2
+const registryAPIVersion = 3
 
 // Registry holds a list of offsets.
 type Registry interface {
 	GetOffset(identifier string) string
 	GetTailingMode(identifier string) string
+	GetFingerprint(identifier string) string
+	GetIdentifierForFingerprint(fingerprint string) string
 }
 
 // A RegistryEntry represents an entry in the registry where we keep track
@@ -43,6 +50,7 @@ type RegistryEntry struct {
 	Offset             string
 	TailingMode        string
 	IngestionTimestamp int64
+	Fingerprint        string
 }
 
 // JSONRegistry represents the registry that will be written on disk
@@ -149,6 +157,34 @@ func (a *RegistryAuditor) GetTailingMode(identifier string) string {
 	return entry.TailingMode
 }
 
+// GetFingerprint returns the fingerprint of the content the offset of a given identifier
+// has been committed for, returns an empty string if it does not exist.
+func (a *RegistryAuditor) GetFingerprint(identifier string) string {
+	r := a.readOnlyRegistryCopy()
+	entry, exists := r[identifier]
+	if !exists {
+		return ""
+	}
+	return entry.Fingerprint
+}
+
+// GetIdentifierForFingerprint returns the identifier of the most recently updated entry
+// with the given fingerprint, returns an empty string if it does not exist.
+func (a *RegistryAuditor) GetIdentifierForFingerprint(fingerprint string) string {
+	if fingerprint == "" {
+		return ""
+	}
+	var identifier string
+	var lastUpdated time.Time
+	for id, entry := range a.readOnlyRegistryCopy() {
+		if entry.Fingerprint == fingerprint && entry.LastUpdated.After(lastUpdated) {
+			identifier = id
+			lastUpdated = entry.LastUpdated
+		}
+	}
+	return identifier
+}
+
 // run keeps up to date the registry depending on different events
 func (a *RegistryAuditor) run() {
 	cleanUpTicker := time.NewTicker(defaultCleanupPeriod)
@@ -171,7 +207,7 @@ func (a *RegistryAuditor) run() {
 			}
 			// update the registry with new entry
 			for _, msg := range payload.Messages {
-				a.updateRegistry(msg.Origin.Identifier, msg.Origin.Offset, msg.Origin.LogSource.Config.TailingMode, msg.IngestionTimestamp)
+				a.updateRegistry(msg.Origin.Identifier, msg.Origin.Offset, msg.Origin.LogSource.Config.TailingMode, msg.Origin.Fingerprint, msg.IngestionTimestamp)
 			}
 		case <-cleanUpTicker.C:
 			// remove expired offsets from registry
@@ -217,14 +253,26 @@ func (a *RegistryAuditor) cleanupRegistry() {
 	defer a.registryMutex.Unlock()
 	expireBefore := time.Now().UTC().Add(-a.entryTTL)
 	for path, entry := range a.registry {
-		if entry.LastUpdated.Before(expireBefore) {
+		if entry.LastUpdated.Before(expireBefore) && !archiveExists(path) {
 			delete(a.registry, path)
 		}
 	}
 }
 
+// archiveExists returns true if identifier is the identifier of an archive
+// which still exists. An archive is read only once, so its entry isn't updated
+// anymore once it has been read, and is kept as long as the archive exists to
+// not read it again after a restart.
+func archiveExists(identifier string) bool {
+	if !strings.HasPrefix(identifier, ArchiveIdentifierPrefix) {
+		return false
+	}
+	_, err := os.Stat(strings.TrimPrefix(identifier, ArchiveIdentifierPrefix))
+	return err == nil
+}
+
 // updateRegistry updates the registry entry matching identifier with new the offset and timestamp
-func (a *RegistryAuditor) updateRegistry(identifier string, offset string, tailingMode string, ingestionTimestamp int64) {
+func (a *RegistryAuditor) updateRegistry(identifier string, offset string, tailingMode string, fingerprint string, ingestionTimestamp int64) {
 	a.registryMutex.Lock()
 	defer a.registryMutex.Unlock()
 	if identifier == "" {
@@ -247,6 +295,7 @@ func (a *RegistryAuditor) updateRegistry(identifier string, offset string, taili
 		Offset:             offset,
 		TailingMode:        tailingMode,
 		IngestionTimestamp: ingestionTimestamp,
+		Fingerprint:        fingerprint,
 	}
 }
 
@@ -293,6 +342,8 @@ func (a *RegistryAuditor) unmarshalRegistry(b []byte) (map[string]*RegistryEntry
 	}
 	// ensure backward compatibility
 	switch int(version) {
+	case 3:
+		return unmarshalRegistryV3(b)
 	case 2:
 		return unmarshalRegistryV2(b)
 	case 1:
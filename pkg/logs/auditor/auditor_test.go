@@ -62,14 +62,15 @@ func (suite *AuditorTestSuite) TestAuditorStartStop() {
 func (suite *AuditorTestSuite) TestAuditorUpdatesRegistry() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.Equal(0, len(suite.a.registry))
-	suite.a.updateRegistry(suite.source.Config.Path, "42", "end", 0)
+	suite.a.updateRegistry(suite.source.Config.Path, "42", "end", "", 0)
 	suite.Equal(1, len(suite.a.registry))
 	suite.Equal("42", suite.a.registry[suite.source.Config.Path].Offset)
 	suite.Equal("end", suite.a.registry[suite.source.Config.Path].TailingMode)
-	suite.a.updateRegistry(suite.source.Config.Path, "43", "beginning", 1)
+	suite.a.updateRegistry(suite.source.Config.Path, "43", "beginning", "8c9a3d1e2f4b5a67", 1)
 	suite.Equal(1, len(suite.a.registry))
 	suite.Equal("43", suite.a.registry[suite.source.Config.Path].Offset)
 	suite.Equal("beginning", suite.a.registry[suite.source.Config.Path].TailingMode)
+	suite.Equal("8c9a3d1e2f4b5a67", suite.a.registry[suite.source.Config.Path].Fingerprint)
 }
 
 func (suite *AuditorTestSuite) TestAuditorFlushesAndRecoversRegistry() {
@@ -82,7 +83,7 @@ func (suite *AuditorTestSuite) TestAuditorFlushesAndRecoversRegistry() {
 	suite.a.flushRegistry()
 	r, err := ioutil.ReadFile(suite.testPath)
 	suite.Nil(err)
-	suite.Equal("{\"Version\":2,\"Registry\":{\"testpath\":{\"LastUpdated\":\"2006-01-12T01:01:01.000000001Z\",\"Offset\":\"42\",\"TailingMode\":\"end\",\"IngestionTimestamp\":0}}}", string(r))
+	suite.Equal("{\"Version\":3,\"Registry\":{\"testpath\":{\"LastUpdated\":\"2006-01-12T01:01:01.000000001Z\",\"Offset\":\"42\",\"TailingMode\":\"end\",\"IngestionTimestamp\":0,\"Fingerprint\":\"\"}}}", string(r))
 
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.a.registry = suite.a.recoverRegistry()
@@ -103,6 +104,26 @@ func (suite *AuditorTestSuite) TestAuditorRecoversRegistryForOffset() {
 	suite.Equal("", offset)
 }
 
+func (suite *AuditorTestSuite) TestAuditorRecoversRegistryForFingerprint() {
+	suite.a.registry = make(map[string]*RegistryEntry)
+	suite.a.registry["file:/var/log/old.log"] = &RegistryEntry{
+		LastUpdated: time.Date(2006, time.January, 12, 1, 1, 1, 1, time.UTC),
+		Offset:      "42",
+		Fingerprint: "8c9a3d1e2f4b5a67",
+	}
+	suite.a.registry["file:/var/log/new.log"] = &RegistryEntry{
+		LastUpdated: time.Date(2006, time.January, 12, 1, 1, 2, 1, time.UTC),
+		Offset:      "43",
+		Fingerprint: "8c9a3d1e2f4b5a67",
+	}
+
+	suite.Equal("8c9a3d1e2f4b5a67", suite.a.GetFingerprint("file:/var/log/old.log"))
+	suite.Equal("", suite.a.GetFingerprint("file:/var/log/other.log"))
+	suite.Equal("file:/var/log/new.log", suite.a.GetIdentifierForFingerprint("8c9a3d1e2f4b5a67"))
+	suite.Equal("", suite.a.GetIdentifierForFingerprint("0123456789abcdef"))
+	suite.Equal("", suite.a.GetIdentifierForFingerprint(""))
+}
+
 func (suite *AuditorTestSuite) TestAuditorCleansupRegistry() {
 	suite.a.registry = make(map[string]*RegistryEntry)
 	suite.a.registry[suite.source.Config.Path] = &RegistryEntry{
@@ -123,6 +144,28 @@ func (suite *AuditorTestSuite) TestAuditorCleansupRegistry() {
 	suite.Equal("43", suite.a.registry[otherpath].Offset)
 }
 
+func (suite *AuditorTestSuite) TestAuditorKeepsExistingArchives() {
+	archivePath := fmt.Sprintf("%s/app.log.1.gz", suite.testDir)
+	_, err := os.Create(archivePath)
+	suite.Nil(err)
+	defer os.Remove(archivePath)
+
+	expired := time.Now().UTC().Add(-2 * time.Hour)
+	suite.a.registry = map[string]*RegistryEntry{
+		ArchiveIdentifierPrefix + archivePath:                     {LastUpdated: expired, Offset: "42"},
+		ArchiveIdentifierPrefix + suite.testDir + "/app.log.2.gz": {LastUpdated: expired, Offset: "43"},
+	}
+
+	// the entry of an archive is kept past its TTL while the archive exists
+	suite.a.cleanupRegistry()
+	suite.Equal(1, len(suite.a.registry))
+	suite.Equal("42", suite.a.GetOffset(ArchiveIdentifierPrefix+archivePath))
+
+	suite.Nil(os.Remove(archivePath))
+	suite.a.cleanupRegistry()
+	suite.Equal(0, len(suite.a.registry))
+}
+
 func TestScannerTestSuite(t *testing.T) {
 	suite.Run(t, new(AuditorTestSuite))
 }
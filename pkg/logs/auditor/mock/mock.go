@@ -7,13 +7,16 @@ package mock
 
 // Registry does nothing
 type Registry struct {
-	offset      string
-	tailingMode string
+	offset       string
+	tailingMode  string
+	fingerprints map[string]string
 }
 
 // NewRegistry returns a new registry.
 func NewRegistry() *Registry {
-	return &Registry{}
+	return &Registry{
+		fingerprints: make(map[string]string),
+	}
 }
 
 // GetOffset returns the offset.
@@ -35,3 +38,23 @@ func (r *Registry) GetTailingMode(identifier string) string {
 func (r *Registry) SetTailingMode(tailingMode string) {
 	r.tailingMode = tailingMode
 }
+
+// GetFingerprint returns the fingerprint set for identifier.
+func (r *Registry) GetFingerprint(identifier string) string {
+	return r.fingerprints[identifier]
+}
+
+// GetIdentifierForFingerprint returns the identifier the fingerprint has been set for.
+func (r *Registry) GetIdentifierForFingerprint(fingerprint string) string {
+	for identifier, f := range r.fingerprints {
+		if f == fingerprint {
+			return identifier
+		}
+	}
+	return ""
+}
+
+// SetFingerprint sets the fingerprint of identifier.
+func (r *Registry) SetFingerprint(identifier string, fingerprint string) {
+	r.fingerprints[identifier] = fingerprint
+}
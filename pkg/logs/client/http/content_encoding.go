@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
+)
+
+// isCompressed returns true if the content encoding designates a compression
+// algorithm the destination knows how to transcode.
+func isCompressed(encoding string) bool {
+	return encoding == config.GzipCompressionKind || encoding == config.ZstdCompressionKind
+}
+
+// transcode decompresses content encoded with the from algorithm and compresses
+// it again with the to algorithm.
+func transcode(content []byte, from string, to string, level int) ([]byte, error) {
+	decoded, err := decode(content, from)
+	if err != nil {
+		return nil, err
+	}
+	return encode(decoded, to, level)
+}
+
+func decode(content []byte, encoding string) ([]byte, error) {
+	switch encoding {
+	case config.GzipCompressionKind:
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return ioutil.ReadAll(reader)
+	case config.ZstdCompressionKind:
+		return compression.NewZstdCompressor(0).Decompress(content)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+}
+
+func encode(content []byte, encoding string, level int) ([]byte, error) {
+	switch encoding {
+	case config.GzipCompressionKind:
+		if level < gzip.NoCompression || level > gzip.BestCompression {
+			level = gzip.DefaultCompression
+		}
+		var buffer bytes.Buffer
+		writer, err := gzip.NewWriterLevel(&buffer, level)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(content); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	case config.ZstdCompressionKind:
+		return compression.NewZstdCompressor(level).Compress(content)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+}
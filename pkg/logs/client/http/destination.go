@@ -15,6 +15,7 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
@@ -36,11 +37,12 @@ const (
 
 // HTTP errors.
 var (
-	errClient = errors.New("client error")
-	errServer = errors.New("server error")
-	tlmSend   = telemetry.NewCounter("logs_client_http_destination", "send", []string{"endpoint_host", "error"}, "Payloads sent")
-	tlmInUse  = telemetry.NewCounter("logs_client_http_destination", "in_use_ms", []string{"sender"}, "Time spent sending payloads in ms")
-	tlmIdle   = telemetry.NewCounter("logs_client_http_destination", "idle_ms", []string{"sender"}, "Time spent idle while not sending payloads in ms")
+	errClient              = errors.New("client error")
+	errServer              = errors.New("server error")
+	errUnsupportedEncoding = errors.New("unsupported content encoding")
+	tlmSend                = telemetry.NewCounter("logs_client_http_destination", "send", []string{"endpoint_host", "error"}, "Payloads sent")
+	tlmInUse               = telemetry.NewCounter("logs_client_http_destination", "in_use_ms", []string{"sender"}, "Time spent sending payloads in ms")
+	tlmIdle                = telemetry.NewCounter("logs_client_http_destination", "idle_ms", []string{"sender"}, "Time spent idle while not sending payloads in ms")
 
 	expVarIdleMsMapKey  = "idleMs"
 	expVarInUseMsMapKey = "inUseMs"
@@ -61,6 +63,13 @@ type Destination struct {
 	protocol            config.IntakeProtocol
 	origin              config.IntakeOrigin
 
+	// Compression
+	compressionKind  string
+	compressionLevel int
+	// zstdUnsupported is an atomic value, set to 1 once the intake rejected a
+	// zstd payload. Later payloads are then sent with gzip.
+	zstdUnsupported int32
+
 	// Concurrency
 	climit chan struct{} // semaphore for limiting concurrent background sends
 	wg     sync.WaitGroup
@@ -129,6 +138,11 @@ func newDestination(endpoint config.Endpoint,
 		endpoint.RecoveryReset,
 	)
 
+	var compressionKind string
+	if endpoint.UseCompression {
+		compressionKind = endpoint.CompressionKind
+	}
+
 	return &Destination{
 		host:                endpoint.Host,
 		url:                 buildURL(endpoint),
@@ -141,6 +155,8 @@ func newDestination(endpoint config.Endpoint,
 		backoff:             policy,
 		protocol:            endpoint.Protocol,
 		origin:              endpoint.Origin,
+		compressionKind:     compressionKind,
+		compressionLevel:    endpoint.CompressionLevel,
 		lastRetryError:      nil,
 		retryLock:           sync.Mutex{},
 		shouldRetry:         shouldRetry,
@@ -250,10 +266,15 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 	if err != nil {
 		return err
 	}
+	content, encoding, err := d.encodePayload(payload)
+	if err != nil {
+		log.Warnf("Could not encode payload for %s: %v", d.host, err)
+		return err
+	}
 	metrics.BytesSent.Add(int64(payload.UnencodedSize))
-	metrics.EncodedBytesSent.Add(int64(len(payload.Encoded)))
+	metrics.EncodedBytesSent.Add(int64(len(content)))
 
-	req, err := http.NewRequest("POST", d.url, bytes.NewReader(payload.Encoded))
+	req, err := http.NewRequest("POST", d.url, bytes.NewReader(content))
 	if err != nil {
 		// the request could not be built,
 		// this can happen when the method or the url are valid.
@@ -261,8 +282,8 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 	}
 	req.Header.Set("DD-API-KEY", d.apiKey)
 	req.Header.Set("Content-Type", d.contentType)
-	if payload.Encoding != "" {
-		req.Header.Set("Content-Encoding", payload.Encoding)
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
 	}
 	if d.protocol != "" {
 		req.Header.Set("DD-PROTOCOL", string(d.protocol))
@@ -298,6 +319,14 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 	if resp.StatusCode >= http.StatusBadRequest {
 		log.Warnf("failed to post http payload. code=%d host=%s response=%s", resp.StatusCode, d.host, string(response))
 	}
+	if resp.StatusCode == http.StatusUnsupportedMediaType && encoding == config.ZstdCompressionKind {
+		// the intake doesn't accept zstd, fall back to gzip for this endpoint
+		// and send the payload again.
+		if atomic.CompareAndSwapInt32(&d.zstdUnsupported, 0, 1) {
+			log.Warnf("%s does not support zstd compression, falling back to gzip", d.host)
+		}
+		return client.NewRetryableError(errUnsupportedEncoding)
+	}
 	if resp.StatusCode == http.StatusBadRequest ||
 		resp.StatusCode == http.StatusUnauthorized ||
 		resp.StatusCode == http.StatusForbidden ||
@@ -314,6 +343,25 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 	}
 }
 
+// encodePayload returns the content to send for payload along with its content
+// encoding. The payloads are compressed once for all the destinations, so when
+// this endpoint uses a different compression algorithm, the content is
+// transcoded.
+func (d *Destination) encodePayload(payload *message.Payload) ([]byte, string, error) {
+	kind := d.compressionKind
+	if kind == config.ZstdCompressionKind && atomic.LoadInt32(&d.zstdUnsupported) != 0 {
+		kind = config.GzipCompressionKind
+	}
+	if kind == "" || kind == payload.Encoding || !isCompressed(payload.Encoding) {
+		return payload.Encoded, payload.Encoding, nil
+	}
+	content, err := transcode(payload.Encoded, payload.Encoding, kind, d.compressionLevel)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, kind, nil
+}
+
 func (d *Destination) updateRetryState(err error, isRetrying chan bool) {
 	d.retryLock.Lock()
 	defer d.retryLock.Unlock()
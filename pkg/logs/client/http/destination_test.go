@@ -7,6 +7,7 @@ package http
 
 import (
 	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
@@ -330,3 +331,50 @@ func TestBackoffDelayDisabledServerless(t *testing.T) {
 
 	assert.False(t, dest.shouldRetry)
 }
+
+func TestDestinationTranscodesPayload(t *testing.T) {
+	server := NewTestServer(200)
+	defer server.httpServer.Close()
+
+	gzipped, err := encode([]byte("payload"), config.GzipCompressionKind, 6)
+	assert.Nil(t, err)
+	payload := &message.Payload{Encoded: gzipped, Encoding: config.GzipCompressionKind}
+
+	// same compression as the payload, no transcoding
+	server.Destination.compressionKind = config.GzipCompressionKind
+	content, encoding, err := server.Destination.encodePayload(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, config.GzipCompressionKind, encoding)
+	assert.Equal(t, gzipped, content)
+
+	server.Destination.compressionKind = config.ZstdCompressionKind
+	content, encoding, err = server.Destination.encodePayload(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, config.ZstdCompressionKind, encoding)
+	decoded, err := decode(content, config.ZstdCompressionKind)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("payload"), decoded)
+
+	err = server.Destination.unconditionalSend(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, config.ZstdCompressionKind, server.request.Header.Get("Content-Encoding"))
+}
+
+func TestDestinationFallsBackToGzip(t *testing.T) {
+	server := NewTestServer(http.StatusUnsupportedMediaType)
+	defer server.httpServer.Close()
+	server.Destination.compressionKind = config.ZstdCompressionKind
+
+	zstded, err := encode([]byte("payload"), config.ZstdCompressionKind, 1)
+	assert.Nil(t, err)
+	payload := &message.Payload{Encoded: zstded, Encoding: config.ZstdCompressionKind}
+
+	err = server.Destination.unconditionalSend(payload)
+	assert.IsType(t, &client.RetryableError{}, err)
+	assert.Equal(t, config.ZstdCompressionKind, server.request.Header.Get("Content-Encoding"))
+
+	server.ChangeStatus(200)
+	err = server.Destination.unconditionalSend(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, config.GzipCompressionKind, server.request.Header.Get("Content-Encoding"))
+}
@@ -204,6 +204,7 @@ func BuildHTTPEndpointsWithConfig(logsConfig *LogsConfigKeys, endpointPrefix str
 		APIKey:                  logsConfig.getLogsAPIKey(),
 		UseCompression:          logsConfig.useCompression(),
 		CompressionLevel:        logsConfig.compressionLevel(),
+		CompressionKind:         logsConfig.compressionKind(),
 		ConnectionResetInterval: logsConfig.connectionResetInterval(),
 		BackoffBase:             logsConfig.senderBackoffBase(),
 		BackoffMax:              logsConfig.senderBackoffMax(),
@@ -271,6 +272,9 @@ func BuildHTTPEndpointsWithConfig(logsConfig *LogsConfigKeys, endpointPrefix str
 		additionals[i].APIKey = coreConfig.SanitizeAPIKey(additionals[i].APIKey)
 		additionals[i].UseCompression = main.UseCompression
 		additionals[i].CompressionLevel = main.CompressionLevel
+		if additionals[i].CompressionKind != GzipCompressionKind && additionals[i].CompressionKind != ZstdCompressionKind {
+			additionals[i].CompressionKind = main.CompressionKind
+		}
 		additionals[i].BackoffBase = main.BackoffBase
 		additionals[i].BackoffMax = main.BackoffMax
 		additionals[i].BackoffFactor = main.BackoffFactor
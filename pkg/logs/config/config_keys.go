@@ -108,6 +108,19 @@ func (l *LogsConfigKeys) compressionLevel() int {
 	return l.getConfig().GetInt(l.getConfigKey("compression_level"))
 }
 
+func (l *LogsConfigKeys) compressionKind() string {
+	kind := l.getConfig().GetString(l.getConfigKey("compression_kind"))
+	switch kind {
+	case GzipCompressionKind, ZstdCompressionKind:
+		return kind
+	case "":
+		return GzipCompressionKind
+	default:
+		log.Warnf("Invalid %s: %q, falling back to %s", l.getConfigKey("compression_kind"), kind, GzipCompressionKind)
+		return GzipCompressionKind
+	}
+}
+
 func (l *LogsConfigKeys) useCompression() bool {
 	return l.getConfig().GetBool(l.getConfigKey("use_compression"))
 }
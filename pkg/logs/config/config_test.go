@@ -142,7 +142,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsEnvVar() {
 
 	os.Setenv("DD_LOGS_CONFIG_ADDITIONAL_ENDPOINTS", `[
 	{"api_key": "456", "host": "additional.endpoint.1", "port": 1234, "use_compression": true, "compression_level": 2},
-	{"api_key": "789", "host": "additional.endpoint.2", "port": 1234, "use_compression": true, "compression_level": 2}]`)
+	{"api_key": "789", "host": "additional.endpoint.2", "port": 1234, "use_compression": true, "compression_level": 2, "compression_kind": "zstd"}]`)
 	defer os.Unsetenv("DD_LOGS_CONFIG_ADDITIONAL_ENDPOINTS")
 
 	expectedMainEndpoint := Endpoint{
@@ -152,6 +152,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsEnvVar() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    3,
 		BackoffBase:      1.0,
 		BackoffMax:       2.0,
@@ -167,6 +168,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsEnvVar() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    3,
 		BackoffBase:      1.0,
 		BackoffMax:       2.0,
@@ -181,6 +183,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsEnvVar() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "zstd",
 		BackoffFactor:    3,
 		BackoffBase:      1.0,
 		BackoffMax:       2.0,
@@ -264,6 +267,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -278,6 +282,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -291,6 +296,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -338,6 +344,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig2() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -355,6 +362,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig2() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -368,6 +376,7 @@ func (suite *ConfigTestSuite) TestMultipleHttpEndpointsInConfig2() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -443,6 +452,7 @@ func (suite *ConfigTestSuite) TestEndpointsSetLogsDDUrl() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -489,6 +499,7 @@ func (suite *ConfigTestSuite) TestEndpointsSetDDSite() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -525,6 +536,7 @@ func (suite *ConfigTestSuite) TestBuildServerlessEndpoints() {
 		UseSSL:           true,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -560,6 +572,7 @@ func getTestEndpoint(host string, port int, ssl bool) Endpoint {
 		UseSSL:           ssl,
 		UseCompression:   true,
 		CompressionLevel: 6,
+		CompressionKind:  "gzip",
 		BackoffFactor:    coreConfig.DefaultLogsSenderBackoffFactor,
 		BackoffBase:      coreConfig.DefaultLogsSenderBackoffBase,
 		BackoffMax:       coreConfig.DefaultLogsSenderBackoffMax,
@@ -639,3 +652,16 @@ func (suite *ConfigTestSuite) TestBuildEndpointsWithoutVector() {
 	suite.Nil(err)
 	suite.Equal(expectedEndpoints, endpoints)
 }
+
+func (suite *ConfigTestSuite) TestEndpointsCompressionKind() {
+	suite.config.Set("api_key", "123")
+	suite.config.Set("logs_config.compression_kind", "zstd")
+	endpoints, err := BuildHTTPEndpoints("test-track", "test-proto", "test-source")
+	suite.Nil(err)
+	suite.Equal(ZstdCompressionKind, endpoints.Main.CompressionKind)
+
+	suite.config.Set("logs_config.compression_kind", "brotli")
+	endpoints, err = BuildHTTPEndpoints("test-track", "test-proto", "test-source")
+	suite.Nil(err)
+	suite.Equal(GzipCompressionKind, endpoints.Main.CompressionKind)
+}
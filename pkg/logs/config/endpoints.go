@@ -32,14 +32,23 @@ const (
 	EPIntakeVersion2
 )
 
+// Compression algorithms supported by the HTTP endpoints.
+const (
+	// GzipCompressionKind compresses payloads with gzip
+	GzipCompressionKind = "gzip"
+	// ZstdCompressionKind compresses payloads with zstd
+	ZstdCompressionKind = "zstd"
+)
+
 // Endpoint holds all the organization and network parameters to send logs to Datadog.
 type Endpoint struct {
 	APIKey                  string `mapstructure:"api_key" json:"api_key"`
 	Host                    string
 	Port                    int
 	UseSSL                  bool
-	UseCompression          bool `mapstructure:"use_compression" json:"use_compression"`
-	CompressionLevel        int  `mapstructure:"compression_level" json:"compression_level"`
+	UseCompression          bool   `mapstructure:"use_compression" json:"use_compression"`
+	CompressionLevel        int    `mapstructure:"compression_level" json:"compression_level"`
+	CompressionKind         string `mapstructure:"compression_kind" json:"compression_kind"`
 	ProxyAddress            string
 	IsReliable              bool `mapstructure:"is_reliable" json:"is_reliable"`
 	ConnectionResetInterval time.Duration
@@ -7,6 +7,7 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -29,6 +30,9 @@ const (
 	UTF16LE string = "utf-16-le"
 	// SHIFTJIS for Shift JIS (Japanese) encoding
 	SHIFTJIS string = "shift-jis"
+
+	// SyslogFormat for syslog messages (RFC 3164 and RFC 5424)
+	SyslogFormat string = "syslog"
 )
 
 // LogsConfig represents a log source config, which can be for instance
@@ -38,11 +42,13 @@ type LogsConfig struct {
 
 	Port        int    // Network
 	IdleTimeout string `mapstructure:"idle_timeout" json:"idle_timeout"` // Network
+	Format      string // Network
 	Path        string // File, Journald
 
-	Encoding     string   `mapstructure:"encoding" json:"encoding"`             // File
-	ExcludePaths []string `mapstructure:"exclude_paths" json:"exclude_paths"`   // File
-	TailingMode  string   `mapstructure:"start_position" json:"start_position"` // File
+	Encoding         string   `mapstructure:"encoding" json:"encoding"`                   // File
+	ExcludePaths     []string `mapstructure:"exclude_paths" json:"exclude_paths"`         // File
+	TailingMode      string   `mapstructure:"start_position" json:"start_position"`       // File
+	BackfillArchives string   `mapstructure:"backfill_archives" json:"backfill_archives"` // File
 
 	IncludeUnits  []string `mapstructure:"include_units" json:"include_units"`   // Journald
 	ExcludeUnits  []string `mapstructure:"exclude_units" json:"exclude_units"`   // Journald
@@ -135,10 +141,15 @@ func (c *LogsConfig) Validate() error {
 		if err != nil {
 			return err
 		}
+		if _, err := filepath.Match(c.BackfillArchives, ""); err != nil {
+			return fmt.Errorf("invalid backfill_archives pattern '%v': %v", c.BackfillArchives, err)
+		}
 	case c.Type == TCPType && c.Port == 0:
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case (c.Type == TCPType || c.Type == UDPType) && c.Format != "" && c.Format != SyslogFormat:
+		return fmt.Errorf("invalid format '%v' for %v source, supported formats are: %v", c.Format, c.Type, SyslogFormat)
 	}
 	err := ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
@@ -38,6 +38,8 @@ type Message struct {
 	Status             string
 	RawDataLen         int
 	Timestamp          string
+	Hostname           string
+	Service            string
 	IngestionTimestamp int64
 }
 
@@ -26,6 +26,8 @@ type MultiLineHandler struct {
 	linesLen       int
 	status         string
 	timestamp      string
+	hostname       string
+	service        string
 	countInfo      *config.CountInfo
 }
 
@@ -80,6 +82,8 @@ func (h *MultiLineHandler) process(message *Message) {
 	h.linesLen += message.RawDataLen
 	h.timestamp = message.Timestamp
 	h.status = message.Status
+	h.hostname = message.Hostname
+	h.service = message.Service
 
 	if h.buffer.Len() > 0 {
 		// the buffer already contains some data which means that
@@ -128,6 +132,9 @@ func (h *MultiLineHandler) sendBuffer() {
 	copy(content, data)
 
 	if len(content) > 0 || h.linesLen > 0 {
-		h.outputFn(NewMessage(content, h.status, h.linesLen, h.timestamp))
+		output := NewMessage(content, h.status, h.linesLen, h.timestamp)
+		output.Hostname = h.hostname
+		output.Service = h.service
+		h.outputFn(output)
 	}
 }
@@ -37,6 +37,12 @@ const (
 	// headers are included in the log frame.  The size in those headers is not
 	// consulted.  The result does not include the trailing newlines.
 	DockerStream
+
+	// Syslog messages framed with octet counting (RFC 6587, section 3.4.1),
+	// where each message is prefixed by its length and a space.  Messages
+	// that are not prefixed by a length are treated as newline-terminated
+	// UTF-8 text (non-transparent framing).
+	Syslog
 )
 
 // Framer gets chunks of bytes (via Process(..)) and uses an
@@ -95,6 +101,8 @@ func NewFramer(
 		matcher = &oneByteNewLineMatcher{contentLenLimit}
 	case DockerStream:
 		matcher = &dockerStreamMatcher{contentLenLimit}
+	case Syslog:
+		matcher = &syslogMatcher{oneByteNewLineMatcher{contentLenLimit}}
 	default:
 		panic(fmt.Sprintf("unknown framing %d", framing))
 	}
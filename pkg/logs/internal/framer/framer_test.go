@@ -113,6 +113,26 @@ func TestLineBreaking(t *testing.T) {
 		t.Run("one-byte chunks", test(framing, chunk(utf16, 1), lines, lens))
 	})
 
+	t.Run("Syslog(octet-counted)", func(t *testing.T) {
+		input := []byte("11 <34>1 line112 <34>1 line1012 <34>1 line11\n")
+		lines := []string{"<34>1 line1", "<34>1 line10", "<34>1 line11", ""}
+		lens := []int{14, 15, 15, 1}
+		framing := Syslog
+		t.Run("one chunk", test(framing, chunk(input, len(input)), lines, lens))
+		for size := 1; size < 20; size++ {
+			t.Run(fmt.Sprintf("%d-byte chunks", size), test(framing, chunk(input, size), lines, lens))
+		}
+	})
+
+	t.Run("Syslog(non-transparent)", func(t *testing.T) {
+		input := []byte("<34>1 line1\n<13>Oct 11 22:14:15 host app: line2\n")
+		lines := []string{"<34>1 line1", "<13>Oct 11 22:14:15 host app: line2"}
+		lens := []int{12, 36}
+		framing := Syslog
+		t.Run("one chunk", test(framing, chunk(input, len(input)), lines, lens))
+		t.Run("one-byte chunks", test(framing, chunk(input, 1), lines, lens))
+	})
+
 	dockerChunk := func(stream byte, data []byte) []byte {
 		header := [8]byte{stream}
 		binary.BigEndian.PutUint32(header[4:8], uint32(len(data)))
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package framer
+
+import "strconv"
+
+// maxMsgLenDigits is the maximum number of digits of an octet-counted frame
+// length, anything longer is considered as the beginning of a message.
+const maxMsgLenDigits = 9
+
+// syslogMatcher implements FrameMatcher for syslog messages, sent either
+// with octet counting (`MSG-LEN SP SYSLOG-MSG`) or with non-transparent
+// framing, where messages are terminated by a newline.
+type syslogMatcher struct {
+	newline oneByteNewLineMatcher
+}
+
+// FindFrame implements FrameMatcher#FindFrame.
+func (s *syslogMatcher) FindFrame(buf []byte, seen int) ([]byte, int) {
+	i := 0
+	for i < len(buf) && i <= maxMsgLenDigits && buf[i] >= '0' && buf[i] <= '9' {
+		i++
+	}
+
+	if i > 0 && i == len(buf) {
+		// the length may not be complete yet
+		return nil, 0
+	}
+
+	if i > 0 && i <= maxMsgLenDigits && buf[i] == ' ' {
+		msgLen, err := strconv.Atoi(string(buf[:i]))
+		if err == nil {
+			end := i + 1 + msgLen
+			if end > len(buf) {
+				return nil, 0
+			}
+			return buf[i+1 : end], end
+		}
+	}
+
+	return s.newline.FindFrame(buf, seen)
+}
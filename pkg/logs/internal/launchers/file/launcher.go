@@ -6,12 +6,15 @@
 package file
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
@@ -37,6 +40,39 @@ var ContainersLogsDir = "/var/log/containers"
 // DefaultSleepDuration represents the amount of time the tailer waits before reading new data when no data is received
 const DefaultSleepDuration = 1 * time.Second
 
+// archiveTailingLimit is the maximum number of archives read at the same time.
+const archiveTailingLimit = 2
+
+// archiveMaxAttempts is the maximum number of attempts to read an archive, one
+// per scan, when it can't be opened or its end has not been written yet.
+const archiveMaxAttempts = 30
+
+// rotatedArchiveTimeout is how long the archive of a rotated file is looked
+// for once its tailer is done, as it may be compressed after the rotation
+// with `delaycompress` or by an asynchronous compression.
+const rotatedArchiveTimeout = 10 * time.Minute
+
+// archiveTask describes an archive waiting to be read by an ArchiveTailer.
+type archiveTask struct {
+	file        *tailer.File
+	archivePath string
+	offset      int64
+	tracked     bool
+	// attempts is the number of times the archive could not be read
+	attempts int
+	// tailer is the tailer reading the archive once the task is started
+	tailer *tailer.ArchiveTailer
+}
+
+// rotatedTailer is the tailer of a rotated file whose archive is drained once
+// it is done.
+type rotatedTailer struct {
+	tailer *tailer.Tailer
+	// deadline is set once the tailer is done, the archive is not looked for
+	// anymore after it
+	deadline time.Time
+}
+
 // Launcher checks all files provided by fileProvider and create new tailers
 // or update the old ones if needed
 type Launcher struct {
@@ -55,6 +91,13 @@ type Launcher struct {
 	// Feature flag defaulting to false, use `logs_config.validate_pod_container_id`.
 	validatePodContainerID bool
 	scanPeriod             time.Duration
+	// set to true to read the compressed archive of a rotated file once its tailer
+	// is done, to collect the lines it could not read before the file got compressed.
+	// Feature flag defaulting to false, use `logs_config.drain_rotated_archives`.
+	drainRotatedArchives bool
+	rotatedTailers       []*rotatedTailer
+	archiveTailers       map[string]*archiveTask
+	pendingArchives      []*archiveTask
 }
 
 // NewLauncher returns a new launcher.
@@ -72,6 +115,8 @@ func NewLauncher(sources *config.LogSources, tailingLimit int, pipelineProvider
 		stop:                   make(chan struct{}),
 		validatePodContainerID: validatePodContainerID,
 		scanPeriod:             scanPeriod,
+		drainRotatedArchives:   coreConfig.Datadog.GetBool("logs_config.drain_rotated_archives"),
+		archiveTailers:         make(map[string]*archiveTask),
 	}
 }
 
@@ -114,6 +159,12 @@ func (s *Launcher) cleanup() {
 		stopper.Add(tailer)
 		delete(s.tailers, scanKey)
 	}
+	for archivePath, task := range s.archiveTailers {
+		stopper.Add(task.tailer)
+		delete(s.archiveTailers, archivePath)
+	}
+	s.rotatedTailers = nil
+	s.pendingArchives = nil
 	stopper.Stop()
 }
 
@@ -182,12 +233,132 @@ func (s *Launcher) scan() {
 			s.stopTailer(scanKey, tailer)
 		}
 	}
+
+	s.scanArchives()
+}
+
+// scanArchives queues the archives of the rotated files whose tailer is done,
+// and starts archive tailers for the pending archives.
+func (s *Launcher) scanArchives() {
+	now := time.Now()
+	rotatedTailers := s.rotatedTailers[:0]
+	for _, rotated := range s.rotatedTailers {
+		if !rotated.tailer.IsFinished() {
+			rotatedTailers = append(rotatedTailers, rotated)
+			continue
+		}
+		path := rotated.tailer.GetFile().Path
+		archivePath := rotated.tailer.FindRotatedArchive()
+		if archivePath == "" {
+			// the rotated file may not be compressed yet
+			if rotated.deadline.IsZero() {
+				rotated.deadline = now.Add(rotatedArchiveTimeout)
+			}
+			if now.Before(rotated.deadline) {
+				rotatedTailers = append(rotatedTailers, rotated)
+			} else {
+				log.Infof("No rotated archive found for %s after %s, the lines it could not read are lost", path, rotatedArchiveTimeout)
+			}
+			continue
+		}
+		log.Infof("Found rotated archive %s for %s, draining it from offset %d", archivePath, path, rotated.tailer.GetLastReadOffset())
+		s.pendingArchives = append(s.pendingArchives, &archiveTask{
+			file:        rotated.tailer.GetFile(),
+			archivePath: archivePath,
+			offset:      rotated.tailer.GetLastReadOffset(),
+		})
+	}
+	s.rotatedTailers = rotatedTailers
+
+	// the archives which can't be read yet are retried in the next scan
+	var retries []*archiveTask
+	for archivePath, task := range s.archiveTailers {
+		if !task.tailer.IsFinished() {
+			continue
+		}
+		delete(s.archiveTailers, archivePath)
+		if task.tailer.NotReady() {
+			task.offset = task.tailer.GetDecodedOffset()
+			if s.shouldRetryArchive(task, fmt.Errorf("the end of the archive has not been written yet")) {
+				retries = append(retries, task)
+			}
+		}
+	}
+
+	for len(s.pendingArchives) > 0 && len(s.archiveTailers) < archiveTailingLimit {
+		task := s.pendingArchives[0]
+		s.pendingArchives = s.pendingArchives[1:]
+		if _, isTailed := s.archiveTailers[task.archivePath]; isTailed {
+			continue
+		}
+		if err := s.startArchiveTailer(task); err != nil && s.shouldRetryArchive(task, err) {
+			retries = append(retries, task)
+		}
+	}
+	s.pendingArchives = append(s.pendingArchives, retries...)
+}
+
+// startArchiveTailer creates a new archive tailer and starts it from the offset of the task.
+func (s *Launcher) startArchiveTailer(task *archiveTask) error {
+	archiveTailer := tailer.NewArchiveTailer(s.pipelineProvider.NextPipelineChan(), task.file, task.archivePath, task.tracked, decoder.NewDecoderFromSource(task.file.Source))
+	if err := archiveTailer.Start(task.offset); err != nil {
+		return err
+	}
+	task.tailer = archiveTailer
+	s.archiveTailers[task.archivePath] = task
+	return nil
+}
+
+// shouldRetryArchive records a failed attempt to read the archive of the task,
+// and returns true if it should be read again.
+func (s *Launcher) shouldRetryArchive(task *archiveTask, err error) bool {
+	task.attempts++
+	if task.attempts >= archiveMaxAttempts {
+		log.Warnf("Could not read archive %s after %d attempts: %v", task.archivePath, task.attempts, err)
+		return false
+	}
+	log.Debugf("Could not read archive %s, retrying in the next scan: %v", task.archivePath, err)
+	return true
 }
 
 // addSource keeps track of the new source and launch new tailers for this source.
 func (s *Launcher) addSource(source *config.LogSource) {
 	s.activeSources = append(s.activeSources, source)
 	s.launchTailers(source)
+	s.queueArchiveBackfill(source)
+}
+
+// queueArchiveBackfill queues the archives matching the `backfill_archives` pattern
+// of the source, to be read once from the offset recorded in the registry.
+func (s *Launcher) queueArchiveBackfill(source *config.LogSource) {
+	if source.Config.BackfillArchives == "" {
+		return
+	}
+	paths, err := filepath.Glob(source.Config.BackfillArchives)
+	if err != nil {
+		log.Warnf("Could not collect archives to backfill: %v", err)
+		return
+	}
+	isWildcardPath := config.ContainsWildcard(source.Config.BackfillArchives)
+	for _, path := range paths {
+		if !tailer.IsArchive(path) {
+			continue
+		}
+		var offset int64
+		if recorded := s.registry.GetOffset(tailer.ArchiveIdentifier(path)); recorded != "" {
+			offset, err = strconv.ParseInt(recorded, 10, 64)
+			if err != nil {
+				log.Warnf("Could not recover offset for archive %s: %v", path, err)
+				offset = 0
+			}
+		}
+		s.pendingArchives = append(s.pendingArchives, &archiveTask{
+			file:        tailer.NewFile(path, source, isWildcardPath),
+			archivePath: path,
+			offset:      offset,
+			tracked:     true,
+		})
+	}
 }
 
 // removeSource removes the source from cache.
@@ -199,6 +370,29 @@ func (s *Launcher) removeSource(source *config.LogSource) {
 			break
 		}
 	}
+
+	pendingArchives := s.pendingArchives[:0]
+	for _, task := range s.pendingArchives {
+		if task.file.Source != source {
+			pendingArchives = append(pendingArchives, task)
+		}
+	}
+	s.pendingArchives = pendingArchives
+
+	rotatedTailers := s.rotatedTailers[:0]
+	for _, rotated := range s.rotatedTailers {
+		if rotated.tailer.GetFile().Source != source {
+			rotatedTailers = append(rotatedTailers, rotated)
+		}
+	}
+	s.rotatedTailers = rotatedTailers
+
+	for archivePath, task := range s.archiveTailers {
+		if task.file.Source == source {
+			go task.tailer.Stop()
+			delete(s.archiveTailers, archivePath)
+		}
+	}
 }
 
 // launch launches new tailers for a new source.
@@ -261,7 +455,7 @@ func (s *Launcher) startNewTailer(file *tailer.File, m config.TailingMode) bool
 	var whence int
 	mode := s.handleTailingModeChange(tailer.Identifier(), m)
 
-	offset, whence, err := Position(s.registry, tailer.Identifier(), mode)
+	offset, whence, err := Position(s.registry, tailer.Identifier(), mode, tailer.Fingerprint())
 	if err != nil {
 		log.Warnf("Could not recover offset for file with path %v: %v", file.Path, err)
 	}
@@ -373,6 +567,9 @@ func (s *Launcher) stopTailer(scanKey string, tailer *tailer.Tailer) {
 func (s *Launcher) restartTailerAfterFileRotation(tailer *tailer.Tailer, file *tailer.File) bool {
 	log.Info("Log rotation happened to ", file.Path)
 	tailer.StopAfterFileRotation()
+	if s.drainRotatedArchives {
+		s.rotatedTailers = append(s.rotatedTailers, &rotatedTailer{tailer: tailer})
+	}
 	tailer = s.createRotatedTailer(tailer, file, tailer.GetDetectedPattern())
 	// force reading file from beginning since it has been log-rotated
 	err := tailer.StartFromBeginning()
@@ -9,6 +9,7 @@
 package file
 
 import (
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -448,3 +449,150 @@ func TestContainerIDInContainerLogFile(t *testing.T) {
 func getScanKey(path string, source *config.LogSource) string {
 	return filetailer.NewFile(path, source, false).GetScanKey()
 }
+
+func TestLauncherBackfillsArchives(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-launcher-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+
+	for i, content := range []string{"first\nsecond\n", "third\n"} {
+		f, err := os.Create(fmt.Sprintf("%s/app.log.%d.gz", testDir, i+1))
+		assert.Nil(t, err)
+		w := gzip.NewWriter(f)
+		_, err = w.Write([]byte(content))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+		assert.Nil(t, f.Close())
+	}
+
+	sleepDuration := 20 * time.Millisecond
+	registry := auditor.NewRegistry()
+	registry.SetOffset("6")
+	provider := mock.NewMockProvider()
+	outputChan := provider.NextPipelineChan()
+	launcher := NewLauncher(config.NewLogSources(), 10, provider, registry, sleepDuration, false, 10*time.Second)
+	source := config.NewLogSource("", &config.LogsConfig{
+		Type:             config.FileType,
+		Path:             fmt.Sprintf("%s/app.log", testDir),
+		BackfillArchives: fmt.Sprintf("%s/app.log.*.gz", testDir),
+	})
+	status.Clear()
+	status.InitStatus(config.CreateSources([]*config.LogSource{source}))
+	defer status.Clear()
+
+	launcher.addSource(source)
+	assert.Equal(t, 2, len(launcher.pendingArchives))
+
+	launcher.scanArchives()
+	assert.Equal(t, 0, len(launcher.pendingArchives))
+	assert.Equal(t, 2, len(launcher.archiveTailers))
+
+	// the registry offset skips "first\n" in the first archive, and the
+	// whole content of the second one.
+	msg := <-outputChan
+	assert.Equal(t, "second", string(msg.Content))
+	assert.Equal(t, filetailer.ArchiveIdentifier(fmt.Sprintf("%s/app.log.1.gz", testDir)), msg.Origin.Identifier)
+	assert.Equal(t, "13", msg.Origin.Offset)
+
+	launcher.cleanup()
+	assert.Equal(t, 0, len(launcher.archiveTailers))
+}
+
+func writeGzipArchive(t *testing.T, path string, content string, complete bool) {
+	f, err := os.Create(path)
+	assert.Nil(t, err)
+	defer f.Close()
+	w := gzip.NewWriter(f)
+	_, err = w.Write([]byte(content))
+	assert.Nil(t, err)
+	if complete {
+		assert.Nil(t, w.Close())
+	} else {
+		assert.Nil(t, w.Flush())
+	}
+}
+
+func TestLauncherWaitsForRotatedArchive(t *testing.T) {
+	testDir := t.TempDir()
+	path := fmt.Sprintf("%s/app.log", testDir)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("first\n"), 0644))
+
+	provider := mock.NewMockProvider()
+	outputChan := provider.NextPipelineChan()
+	launcher := NewLauncher(config.NewLogSources(), 10, provider, auditor.NewRegistry(), 20*time.Millisecond, false, 10*time.Second)
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
+
+	// the tailer of the rotated file is done before the file is compressed
+	tailer := launcher.createTailer(filetailer.NewFile(path, source, false), outputChan)
+	assert.Nil(t, tailer.StartFromBeginning())
+	assert.Equal(t, "first", string((<-outputChan).Content))
+	tailer.Stop()
+	launcher.rotatedTailers = []*rotatedTailer{{tailer: tailer}}
+
+	launcher.scanArchives()
+	assert.Equal(t, 1, len(launcher.rotatedTailers))
+	assert.False(t, launcher.rotatedTailers[0].deadline.IsZero())
+	assert.Equal(t, 0, len(launcher.pendingArchives))
+
+	// the archive is drained once it is found
+	writeGzipArchive(t, path+".1.gz", "first\nsecond\n", true)
+	launcher.scanArchives()
+	assert.Equal(t, 0, len(launcher.rotatedTailers))
+	assert.Equal(t, 1, len(launcher.archiveTailers))
+	assert.Equal(t, "second", string((<-outputChan).Content))
+	launcher.cleanup()
+
+	// the archive isn't looked for anymore after the deadline
+	assert.Nil(t, os.Remove(path+".1.gz"))
+	launcher.rotatedTailers = []*rotatedTailer{{tailer: tailer, deadline: time.Now().Add(-time.Second)}}
+	launcher.scanArchives()
+	assert.Equal(t, 0, len(launcher.rotatedTailers))
+	assert.Equal(t, 0, len(launcher.pendingArchives))
+}
+
+func TestLauncherRetriesArchives(t *testing.T) {
+	testDir := t.TempDir()
+	path := fmt.Sprintf("%s/app.log", testDir)
+	archivePath := path + ".1.gz"
+	assert.Nil(t, ioutil.WriteFile(archivePath, nil, 0644))
+
+	provider := mock.NewMockProvider()
+	outputChan := provider.NextPipelineChan()
+	launcher := NewLauncher(config.NewLogSources(), 10, provider, auditor.NewRegistry(), 20*time.Millisecond, false, 10*time.Second)
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
+	launcher.pendingArchives = []*archiveTask{{file: filetailer.NewFile(path, source, false), archivePath: archivePath}}
+
+	// the archive can't be opened before its header is written
+	launcher.scanArchives()
+	assert.Equal(t, 0, len(launcher.archiveTailers))
+	assert.Equal(t, 1, len(launcher.pendingArchives))
+	assert.Equal(t, 1, launcher.pendingArchives[0].attempts)
+
+	// the archive is read up to the end written so far
+	writeGzipArchive(t, archivePath, "first\nsec", false)
+	launcher.scanArchives()
+	assert.Equal(t, 1, len(launcher.archiveTailers))
+	assert.Equal(t, "first", string((<-outputChan).Content))
+	task := launcher.archiveTailers[archivePath]
+	assert.Eventually(t, task.tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+
+	launcher.scanArchives()
+	assert.Equal(t, 0, len(launcher.archiveTailers))
+	assert.Equal(t, []*archiveTask{task}, launcher.pendingArchives)
+	assert.Equal(t, int64(len("first\n")), task.offset)
+	assert.Equal(t, 2, task.attempts)
+
+	// and read again from there once it is complete
+	writeGzipArchive(t, archivePath, "first\nsecond\n", true)
+	launcher.scanArchives()
+	assert.Equal(t, "second", string((<-outputChan).Content))
+	assert.Equal(t, 0, len(launcher.pendingArchives))
+
+	// the archive is dropped after too many attempts
+	launcher.cleanup()
+	assert.Nil(t, ioutil.WriteFile(archivePath, nil, 0644))
+	launcher.pendingArchives = []*archiveTask{{file: filetailer.NewFile(path, source, false), archivePath: archivePath, attempts: archiveMaxAttempts - 1}}
+	launcher.scanArchives()
+	assert.Equal(t, 0, len(launcher.pendingArchives))
+	assert.False(t, source.Status.IsError())
+}
@@ -7,19 +7,29 @@ package file
 
 import (
 	"io"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 )
 
+// fileIdentifierPrefix prefixes the registry identifiers of the tailed files.
+const fileIdentifierPrefix = "file:"
+
 // Position returns the position from where logs should be collected.
-func Position(registry auditor.Registry, identifier string, mode config.TailingMode) (int64, int, error) {
+// When fingerprint is not empty, it is used to check that the offset registered
+// for identifier belongs to the same content.
+func Position(registry auditor.Registry, identifier string, mode config.TailingMode, fingerprint string) (int64, int, error) {
 	var offset int64
 	var whence int
 	var err error
 
 	value := registry.GetOffset(identifier)
+	if fingerprint != "" {
+		value = fingerprintedOffset(registry, identifier, value, fingerprint)
+	}
 
 	switch {
 	case mode == config.ForceBeginning:
@@ -47,3 +57,49 @@ func Position(registry auditor.Registry, identifier string, mode config.TailingM
 	}
 	return offset, whence, err
 }
+
+// fingerprintedOffset uses the fingerprint of a file to decide whether it is the same
+// file as the one the offset of identifier has been registered for, a file that has
+// been moved from another path, or a new file. It returns the offset to resume from,
+// or an empty string if none applies.
+func fingerprintedOffset(registry auditor.Registry, identifier string, offset string, fingerprint string) string {
+	registered := registry.GetFingerprint(identifier)
+	switch {
+	case registered == fingerprint:
+		// same file
+		return offset
+	case registered != "":
+		// the content at this path changed (copy-truncate rotation, symlink swap...),
+		// resume from where the file was left if it has been moved here, otherwise
+		// the whole file is new.
+		if moved := movedFrom(registry, identifier, fingerprint); moved != "" {
+			return registry.GetOffset(moved)
+		}
+		return "0"
+	case offset == "":
+		// nothing was registered at this path, the file may have been moved here
+		if moved := movedFrom(registry, identifier, fingerprint); moved != "" {
+			return registry.GetOffset(moved)
+		}
+		return ""
+	default:
+		// the offset was registered without a fingerprint, rely on the path only
+		return offset
+	}
+}
+
+// movedFrom returns the identifier of the file the file of identifier has been
+// moved from, or an empty string if it is not a moved file. Files sharing a
+// common header (CSV header, banner...) have the same fingerprint, so a file
+// registered with the same fingerprint is only considered moved once it no
+// longer exists at its path.
+func movedFrom(registry auditor.Registry, identifier string, fingerprint string) string {
+	moved := registry.GetIdentifierForFingerprint(fingerprint)
+	if moved == "" || moved == identifier || !strings.HasPrefix(moved, fileIdentifierPrefix) {
+		return ""
+	}
+	if _, err := os.Stat(strings.TrimPrefix(moved, fileIdentifierPrefix)); !os.IsNotExist(err) {
+		return ""
+	}
+	return moved
+}
@@ -7,6 +7,9 @@ package file
 
 import (
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,49 +25,117 @@ func TestPosition(t *testing.T) {
 	var offset int64
 	var whence int
 
-	offset, whence, err = Position(registry, "", config.End)
+	offset, whence, err = Position(registry, "", config.End, "")
 	assert.Nil(t, err)
 	assert.Equal(t, int64(0), offset)
 	assert.Equal(t, io.SeekEnd, whence)
 
-	offset, whence, err = Position(registry, "", config.Beginning)
+	offset, whence, err = Position(registry, "", config.Beginning, "")
 	assert.Nil(t, err)
 	assert.Equal(t, int64(0), offset)
 	assert.Equal(t, io.SeekStart, whence)
 
 	registry.SetOffset("123456789")
-	offset, whence, err = Position(registry, "", config.End)
+	offset, whence, err = Position(registry, "", config.End, "")
 	assert.Nil(t, err)
 	assert.Equal(t, int64(123456789), offset)
 	assert.Equal(t, io.SeekStart, whence)
 
 	registry.SetOffset("987654321")
-	offset, whence, err = Position(registry, "", config.Beginning)
+	offset, whence, err = Position(registry, "", config.Beginning, "")
 	assert.Nil(t, err)
 	assert.Equal(t, int64(987654321), offset)
 	assert.Equal(t, io.SeekStart, whence)
 
 	registry.SetOffset("foo")
-	offset, whence, err = Position(registry, "", config.End)
+	offset, whence, err = Position(registry, "", config.End, "")
 	assert.NotNil(t, err)
 	assert.Equal(t, int64(0), offset)
 	assert.Equal(t, io.SeekEnd, whence)
 
 	registry.SetOffset("bar")
-	offset, whence, err = Position(registry, "", config.Beginning)
+	offset, whence, err = Position(registry, "", config.Beginning, "")
 	assert.NotNil(t, err)
 	assert.Equal(t, int64(0), offset)
 	assert.Equal(t, io.SeekStart, whence)
 
 	registry.SetOffset("123456789")
-	offset, whence, err = Position(registry, "", config.ForceBeginning)
+	offset, whence, err = Position(registry, "", config.ForceBeginning, "")
 	assert.Nil(t, err)
 	assert.Equal(t, int64(0), offset)
 	assert.Equal(t, io.SeekStart, whence)
 
 	registry.SetOffset("987654321")
-	offset, whence, err = Position(registry, "", config.ForceEnd)
+	offset, whence, err = Position(registry, "", config.ForceEnd, "")
 	assert.Nil(t, err)
 	assert.Equal(t, int64(0), offset)
 	assert.Equal(t, io.SeekEnd, whence)
 }
+
+func TestPositionWithFingerprint(t *testing.T) {
+	registry := mock.NewRegistry()
+
+	var err error
+	var offset int64
+	var whence int
+
+	// no fingerprint registered, the path is used
+	registry.SetOffset("42")
+	offset, whence, err = Position(registry, "file:/var/log/app.log", config.End, "8c9a3d1e2f4b5a67")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// same file
+	registry.SetFingerprint("file:/var/log/app.log", "8c9a3d1e2f4b5a67")
+	offset, whence, err = Position(registry, "file:/var/log/app.log", config.End, "8c9a3d1e2f4b5a67")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// the file has been replaced, it is read from the beginning
+	offset, whence, err = Position(registry, "file:/var/log/app.log", config.End, "0123456789abcdef")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// the file has been moved, its offset is resumed
+	offset, whence, err = Position(registry, "file:/var/log/moved.log", config.End, "8c9a3d1e2f4b5a67")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// forced modes take precedence
+	offset, whence, err = Position(registry, "file:/var/log/app.log", config.ForceEnd, "8c9a3d1e2f4b5a67")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+	assert.Equal(t, io.SeekEnd, whence)
+}
+
+func TestPositionWithSharedHeader(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.csv")
+	second := filepath.Join(dir, "second.csv")
+	for _, path := range []string{first, second} {
+		assert.Nil(t, ioutil.WriteFile(path, []byte("timestamp,level,message\n"), 0644))
+	}
+
+	registry := mock.NewRegistry()
+	registry.SetOffset("42")
+	registry.SetFingerprint("file:"+first, "8c9a3d1e2f4b5a67")
+	registry.SetFingerprint("file:"+second, "0123456789abcdef")
+
+	// the content of the second file changed and now starts with the same
+	// header as the first file, which still exists: it is a new file
+	offset, whence, err := Position(registry, "file:"+second, config.End, "8c9a3d1e2f4b5a67")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), offset)
+	assert.Equal(t, io.SeekStart, whence)
+
+	// once the first file doesn't exist anymore, it has been moved
+	assert.Nil(t, os.Remove(first))
+	offset, whence, err = Position(registry, "file:"+second, config.End, "8c9a3d1e2f4b5a67")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), offset)
+	assert.Equal(t, io.SeekStart, whence)
+}
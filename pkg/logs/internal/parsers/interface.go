@@ -24,6 +24,15 @@ type Message struct {
 	// which do not contain a timestamp (such as files) leave this set to "".
 	Timestamp string
 
+	// Hostname is the hostname of the host that emitted the message, if any.
+	// Log sources forwarding messages from other hosts (such as syslog)
+	// set it to override the agent hostname.
+	Hostname string
+
+	// Service is the service parsed from the message, if any.  The service
+	// of the log source, if configured, takes precedence.
+	Service string
+
 	// IsPartial indicates that this is a partial message.  If the parser
 	// supports partial lines, then this is true only for the message returned
 	// from the last parsed line in a multi-line message.
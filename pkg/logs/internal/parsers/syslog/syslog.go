@@ -0,0 +1,358 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const (
+	// nilValue is used by RFC 5424 for fields without a value
+	nilValue = "-"
+
+	// rfc3164TimestampLen is the length of a `Mmm dd hh:mm:ss` timestamp
+	rfc3164TimestampLen = len(time.Stamp)
+)
+
+var (
+	// utf8BOM may prefix the MSG part of RFC 5424 messages
+	utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+	errInvalidPriority = errors.New("cannot parse the syslog priority")
+)
+
+// severityStatusMapping maps syslog severities, used as index, to statuses.
+var severityStatusMapping = []string{
+	message.StatusEmergency,
+	message.StatusAlert,
+	message.StatusCritical,
+	message.StatusError,
+	message.StatusWarning,
+	message.StatusNotice,
+	message.StatusInfo,
+	message.StatusDebug,
+}
+
+// New creates a new parser that parses syslog messages, formatted following
+// either RFC 5424 or the BSD syslog format described in RFC 3164.
+//
+// For example:
+// `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3"] An application event`
+// `<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8`
+//
+// The content of the parsed messages is a JSON object holding the MSG part of
+// the syslog message under the "message" key and all the other fields under
+// the "syslog" key.
+func New() parsers.Parser {
+	return &syslogFormat{}
+}
+
+type syslogFormat struct{}
+
+// Parse implements Parser#Parse
+func (p *syslogFormat) Parse(msg []byte) (parsers.Message, error) {
+	return parseSyslog(msg, time.Now())
+}
+
+// SupportsPartialLine implements Parser#SupportsPartialLine
+func (p *syslogFormat) SupportsPartialLine() bool {
+	return false
+}
+
+// syslogMessage holds the fields of a syslog message, except MSG.
+type syslogMessage struct {
+	Facility       int                          `json:"facility"`
+	Severity       int                          `json:"severity"`
+	Version        int                          `json:"version,omitempty"`
+	Timestamp      string                       `json:"timestamp,omitempty"`
+	Hostname       string                       `json:"hostname,omitempty"`
+	AppName        string                       `json:"appname,omitempty"`
+	ProcID         string                       `json:"procid,omitempty"`
+	MsgID          string                       `json:"msgid,omitempty"`
+	StructuredData map[string]map[string]string `json:"structured_data,omitempty"`
+}
+
+type payload struct {
+	Message string        `json:"message"`
+	Syslog  syslogMessage `json:"syslog"`
+}
+
+func parseSyslog(msg []byte, now time.Time) (parsers.Message, error) {
+	pri, rest, err := parsePriority(msg)
+	if err != nil {
+		return parsers.Message{
+			Content: msg,
+			Status:  message.StatusInfo,
+		}, err
+	}
+
+	sm := syslogMessage{
+		Facility: pri / 8,
+		Severity: pri % 8,
+	}
+
+	var ts time.Time
+	var content []byte
+	if version, afterVersion, ok := parseVersion(rest); ok {
+		sm.Version = version
+		ts, content, err = parseRFC5424(afterVersion, &sm)
+	} else {
+		ts, content = parseRFC3164(rest, &sm, now)
+	}
+	if err != nil {
+		return parsers.Message{
+			Content: msg,
+			Status:  severityStatusMapping[sm.Severity],
+		}, err
+	}
+
+	var timestamp string
+	if !ts.IsZero() {
+		sm.Timestamp = ts.Format(time.RFC3339Nano)
+		timestamp = ts.UTC().Format(config.DateFormat)
+	}
+
+	encoded, err := json.Marshal(payload{
+		Message: string(content),
+		Syslog:  sm,
+	})
+	if err != nil {
+		// ensure the message has some content if the json encoding failed
+		encoded = content
+	}
+
+	return parsers.Message{
+		Content:   encoded,
+		Status:    severityStatusMapping[sm.Severity],
+		Timestamp: timestamp,
+		Hostname:  sm.Hostname,
+		Service:   sm.AppName,
+	}, nil
+}
+
+// parsePriority parses the `<PRI>` header of a syslog message.
+func parsePriority(msg []byte) (int, []byte, error) {
+	if len(msg) < 3 || msg[0] != '<' {
+		return 0, nil, errInvalidPriority
+	}
+
+	end := bytes.IndexByte(msg[:min(len(msg), 5)], '>')
+	if end < 2 {
+		return 0, nil, errInvalidPriority
+	}
+
+	pri, err := strconv.Atoi(string(msg[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return 0, nil, errInvalidPriority
+	}
+
+	return pri, msg[end+1:], nil
+}
+
+// parseVersion parses the `VERSION SP` part of RFC 5424 messages, RFC 3164
+// messages have no version and start with a timestamp.
+func parseVersion(msg []byte) (int, []byte, bool) {
+	sp := bytes.IndexByte(msg, ' ')
+	if sp < 1 || sp > 2 {
+		return 0, nil, false
+	}
+
+	version, err := strconv.Atoi(string(msg[:sp]))
+	if err != nil || version < 1 {
+		return 0, nil, false
+	}
+
+	return version, msg[sp+1:], true
+}
+
+// parseRFC5424 parses `TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA [SP MSG]`.
+func parseRFC5424(msg []byte, sm *syslogMessage) (time.Time, []byte, error) {
+	var ts time.Time
+	var field string
+
+	field, msg = nextField(msg)
+	if field != "" {
+		var err error
+		ts, err = time.Parse(time.RFC3339Nano, field)
+		if err != nil {
+			return ts, nil, errors.New("cannot parse the syslog timestamp")
+		}
+	}
+
+	sm.Hostname, msg = nextField(msg)
+	sm.AppName, msg = nextField(msg)
+	sm.ProcID, msg = nextField(msg)
+	sm.MsgID, msg = nextField(msg)
+
+	sd, msg, err := parseStructuredData(msg)
+	if err != nil {
+		return ts, nil, err
+	}
+	sm.StructuredData = sd
+
+	if len(msg) > 0 && msg[0] == ' ' {
+		msg = msg[1:]
+	}
+
+	return ts, bytes.TrimPrefix(msg, utf8BOM), nil
+}
+
+// parseStructuredData parses the `STRUCTURED-DATA` part of RFC 5424 messages,
+// that is either the nil value or a list of `[SD-ID *(SP PARAM-NAME="PARAM-VALUE")]`.
+func parseStructuredData(msg []byte) (map[string]map[string]string, []byte, error) {
+	if len(msg) == 0 {
+		return nil, msg, nil
+	}
+
+	if msg[0] == nilValue[0] {
+		return nil, msg[1:], nil
+	}
+
+	if msg[0] != '[' {
+		return nil, nil, errors.New("cannot parse the syslog structured data")
+	}
+
+	sd := make(map[string]map[string]string)
+	for len(msg) > 0 && msg[0] == '[' {
+		msg = msg[1:]
+
+		end := bytes.IndexAny(msg, " ]")
+		if end < 1 {
+			return nil, nil, errors.New("cannot parse the syslog structured data")
+		}
+
+		params := make(map[string]string)
+		sd[string(msg[:end])] = params
+		msg = msg[end:]
+
+		for len(msg) > 0 && msg[0] == ' ' {
+			msg = msg[1:]
+
+			eq := bytes.IndexByte(msg, '=')
+			if eq < 1 || len(msg) < eq+2 || msg[eq+1] != '"' {
+				return nil, nil, errors.New("cannot parse the syslog structured data")
+			}
+
+			name := string(msg[:eq])
+			value, rest, ok := parseParamValue(msg[eq+2:])
+			if !ok {
+				return nil, nil, errors.New("cannot parse the syslog structured data")
+			}
+
+			params[name] = value
+			msg = rest
+		}
+
+		if len(msg) == 0 || msg[0] != ']' {
+			return nil, nil, errors.New("cannot parse the syslog structured data")
+		}
+		msg = msg[1:]
+	}
+
+	return sd, msg, nil
+}
+
+// parseParamValue parses a PARAM-VALUE up to its closing quote, unescaping
+// the `"`, `\` and `]` characters.
+func parseParamValue(msg []byte) (string, []byte, bool) {
+	var value []byte
+	for i := 0; i < len(msg); i++ {
+		switch msg[i] {
+		case '\\':
+			if i+1 < len(msg) && (msg[i+1] == '"' || msg[i+1] == '\\' || msg[i+1] == ']') {
+				i++
+			}
+			value = append(value, msg[i])
+		case '"':
+			return string(value), msg[i+1:], true
+		default:
+			value = append(value, msg[i])
+		}
+	}
+
+	return "", nil, false
+}
+
+// parseRFC3164 parses `TIMESTAMP SP HOSTNAME SP TAG[PID]: MSG`. The format
+// is loosely defined, so any part that can't be parsed is kept in the
+// message content.
+func parseRFC3164(msg []byte, sm *syslogMessage, now time.Time) (time.Time, []byte) {
+	var ts time.Time
+	if len(msg) <= rfc3164TimestampLen || msg[rfc3164TimestampLen] != ' ' {
+		return ts, msg
+	}
+
+	parsed, err := time.ParseInLocation(time.Stamp, string(msg[:rfc3164TimestampLen]), now.Location())
+	if err != nil {
+		return ts, msg
+	}
+
+	// the timestamp has no year, messages from the future were sent last year
+	ts = parsed.AddDate(now.Year(), 0, 0)
+	if ts.After(now.AddDate(0, 0, 1)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	msg = msg[rfc3164TimestampLen+1:]
+
+	sm.Hostname, msg = nextField(msg)
+
+	sp := bytes.IndexByte(msg, ' ')
+	if sp < 0 {
+		sp = len(msg)
+	}
+	if sp < 2 || msg[sp-1] != ':' {
+		// no tag
+		return ts, msg
+	}
+
+	tag := msg[:sp-1]
+	if open := bytes.IndexByte(tag, '['); open > 0 && tag[len(tag)-1] == ']' {
+		sm.ProcID = string(tag[open+1 : len(tag)-1])
+		tag = tag[:open]
+	}
+	sm.AppName = string(tag)
+
+	if sp < len(msg) {
+		sp++
+	}
+
+	return ts, msg[sp:]
+}
+
+// nextField returns the next space-separated field of msg, or an empty string
+// for the nil value, and the remaining bytes after the separator.
+func nextField(msg []byte) (string, []byte) {
+	sp := bytes.IndexByte(msg, ' ')
+	if sp < 0 {
+		sp = len(msg)
+	}
+
+	field := string(msg[:sp])
+	if field == nilValue {
+		field = ""
+	}
+
+	if sp < len(msg) {
+		sp++
+	}
+
+	return field, msg[sp:]
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
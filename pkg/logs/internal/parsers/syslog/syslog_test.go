@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestSyslogParserRFC5424(t *testing.T) {
+	msg, err := New().Parse([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high \"q\" \]"] ` + "\xef\xbb\xbf" + `An application event`))
+	require.NoError(t, err)
+	assert.False(t, msg.IsPartial)
+	assert.Equal(t, message.StatusNotice, msg.Status)
+	assert.Equal(t, "2003-10-11T22:14:15.003000000Z", msg.Timestamp)
+	assert.Equal(t, "mymachine.example.com", msg.Hostname)
+	assert.Equal(t, "evntslog", msg.Service)
+	assert.JSONEq(t, `{
+		"message": "An application event",
+		"syslog": {
+			"facility": 20,
+			"severity": 5,
+			"version": 1,
+			"timestamp": "2003-10-11T22:14:15.003Z",
+			"hostname": "mymachine.example.com",
+			"appname": "evntslog",
+			"procid": "1234",
+			"msgid": "ID47",
+			"structured_data": {
+				"exampleSDID@32473": {"iut": "3", "eventSource": "Application", "eventID": "1011"},
+				"examplePriority@32473": {"class": "high \"q\" ]"}
+			}
+		}
+	}`, string(msg.Content))
+}
+
+func TestSyslogParserRFC5424NilValues(t *testing.T) {
+	msg, err := New().Parse([]byte(`<11>1 - - - - - -`))
+	require.NoError(t, err)
+	assert.Equal(t, message.StatusError, msg.Status)
+	assert.Equal(t, "", msg.Timestamp)
+	assert.Equal(t, "", msg.Hostname)
+	assert.Equal(t, "", msg.Service)
+	assert.JSONEq(t, `{"message":"","syslog":{"facility":1,"severity":3,"version":1}}`, string(msg.Content))
+}
+
+func TestSyslogParserRFC3164(t *testing.T) {
+	now := time.Date(2021, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		input           string
+		expectedStatus  string
+		expectedTime    string
+		expectedHost    string
+		expectedService string
+		expectedContent string
+	}{
+		{
+			name:            "tag with pid",
+			input:           "<34>Mar  9 22:14:15 mymachine su[230]: 'su root' failed",
+			expectedStatus:  message.StatusCritical,
+			expectedTime:    "2021-03-09T22:14:15.000000000Z",
+			expectedHost:    "mymachine",
+			expectedService: "su",
+			expectedContent: `{"message":"'su root' failed","syslog":{"facility":4,"severity":2,"timestamp":"2021-03-09T22:14:15Z","hostname":"mymachine","appname":"su","procid":"230"}}`,
+		},
+		{
+			name:            "tag without pid",
+			input:           "<13>Mar 10 11:00:00 router sshd: accepted",
+			expectedStatus:  message.StatusNotice,
+			expectedTime:    "2021-03-10T11:00:00.000000000Z",
+			expectedHost:    "router",
+			expectedService: "sshd",
+			expectedContent: `{"message":"accepted","syslog":{"facility":1,"severity":5,"timestamp":"2021-03-10T11:00:00Z","hostname":"router","appname":"sshd"}}`,
+		},
+		{
+			name:            "no tag",
+			input:           "<15>Mar 10 11:00:00 switch link is up",
+			expectedStatus:  message.StatusDebug,
+			expectedTime:    "2021-03-10T11:00:00.000000000Z",
+			expectedHost:    "switch",
+			expectedContent: `{"message":"link is up","syslog":{"facility":1,"severity":7,"timestamp":"2021-03-10T11:00:00Z","hostname":"switch"}}`,
+		},
+		{
+			name:            "message from last year",
+			input:           "<14>Dec 31 23:59:59 host app: bye",
+			expectedStatus:  message.StatusInfo,
+			expectedTime:    "2020-12-31T23:59:59.000000000Z",
+			expectedHost:    "host",
+			expectedService: "app",
+			expectedContent: `{"message":"bye","syslog":{"facility":1,"severity":6,"timestamp":"2020-12-31T23:59:59Z","hostname":"host","appname":"app"}}`,
+		},
+		{
+			name:            "no timestamp",
+			input:           "<14>just a message",
+			expectedStatus:  message.StatusInfo,
+			expectedContent: `{"message":"just a message","syslog":{"facility":1,"severity":6}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseSyslog([]byte(tt.input), now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, msg.Status)
+			assert.Equal(t, tt.expectedTime, msg.Timestamp)
+			assert.Equal(t, tt.expectedHost, msg.Hostname)
+			assert.Equal(t, tt.expectedService, msg.Service)
+			assert.JSONEq(t, tt.expectedContent, string(msg.Content))
+		})
+	}
+}
+
+func TestSyslogParserShouldFailWithInvalidInput(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"no priority",
+		"<>1 - - - - - -",
+		"<192>1 - - - - - -",
+		"<1a>1 - - - - - -",
+		"<13>1 not-a-timestamp - - - - -",
+		"<13>1 - - - - - [unterminated",
+		"<13>1 - - - - - [id param=noquotes]",
+		"<13>1 - - - - - garbage",
+	} {
+		t.Run(input, func(t *testing.T) {
+			msg, err := New().Parse([]byte(input))
+			assert.Error(t, err)
+			assert.Equal(t, []byte(input), msg.Content)
+		})
+	}
+}
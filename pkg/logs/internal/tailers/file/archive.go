@@ -0,0 +1,397 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/tag"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// headerSize is the number of bytes captured at the beginning of a tailed file,
+// used to recognize that file once it has been compressed by a log rotation.
+const headerSize = 256
+
+// archiveExtensions lists the compressed file extensions supported by the
+// ArchiveTailer.
+var archiveExtensions = []string{".gz", ".zst"}
+
+// IsArchive returns true if the path designates a compressed file the
+// ArchiveTailer knows how to read.
+func IsArchive(path string) bool {
+	ext := filepath.Ext(path)
+	for _, archiveExt := range archiveExtensions {
+		if ext == archiveExt {
+			return true
+		}
+	}
+	return false
+}
+
+// openArchive opens a compressed file and returns a reader on its
+// decompressed content.
+func openArchive(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	switch filepath.Ext(path) {
+	case ".gz":
+		r, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &archiveReader{Reader: r, closers: []func() error{r.Close, f.Close}}, nil
+	case ".zst":
+		r, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &archiveReader{Reader: r, closers: []func() error{func() error { r.Close(); return nil }, f.Close}}, nil
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unsupported archive format: %s", path)
+	}
+}
+
+// isArchiveNotReady returns true if err means that the end of the archive
+// has not been written yet, because it's still being compressed by a log
+// rotation for instance, so that it can be read again later.
+func isArchiveNotReady(err error) bool {
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// archiveReader closes both the decompressor and the underlying file.
+type archiveReader struct {
+	io.Reader
+	closers []func() error
+}
+
+// Close closes the decompressor then the underlying file.
+func (r *archiveReader) Close() error {
+	var err error
+	for _, closer := range r.closers {
+		if cerr := closer(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// readFileHeader returns up to headerSize bytes from the beginning of the file.
+func readFileHeader(path string) []byte {
+	f, err := openFile(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	header := make([]byte, headerSize)
+	n, _ := io.ReadFull(f, header)
+	return header[:n]
+}
+
+// FindRotatedArchive looks for a compressed sibling of path whose decompressed
+// content starts with header, which happens when logrotate compresses the file
+// we were tailing. Candidates are checked from the most recently modified one.
+// Returns an empty string if no archive matches.
+func FindRotatedArchive(path string, header []byte) string {
+	if len(header) == 0 {
+		return ""
+	}
+	candidates, err := filepath.Glob(path + "*")
+	if err != nil {
+		return ""
+	}
+
+	type candidate struct {
+		path    string
+		modTime int64
+	}
+	var archives []candidate
+	for _, c := range candidates {
+		if !IsArchive(c) {
+			continue
+		}
+		info, err := os.Stat(c)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		archives = append(archives, candidate{path: c, modTime: info.ModTime().UnixNano()})
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].modTime > archives[j].modTime
+	})
+
+	for _, archive := range archives {
+		if archiveStartsWith(archive.path, header) {
+			return archive.path
+		}
+	}
+	return ""
+}
+
+// archiveStartsWith returns true if the decompressed content of the archive
+// starts with prefix.
+func archiveStartsWith(path string, prefix []byte) bool {
+	r, err := openArchive(path)
+	if err != nil {
+		log.Debugf("Could not open archive %s: %v", path, err)
+		return false
+	}
+	defer r.Close()
+	buf := make([]byte, len(prefix))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return false
+	}
+	return bytes.Equal(buf, prefix)
+}
+
+// ArchiveTailer reads a compressed file once, from a given offset in its
+// decompressed content up to its end, and forwards the decoded messages to
+// the output channel.
+//
+// It is used both to drain the remaining lines of a file that has been
+// compressed by a log rotation before its tailer could finish reading it,
+// and to backfill existing archives.
+type ArchiveTailer struct {
+	// file is the file the messages are attributed to. When draining a rotated
+	// file, this is the original file and not the archive.
+	file *File
+
+	// archivePath is the path of the compressed file to read.
+	archivePath string
+
+	// tracked is true if the offset of this tailer is recorded in the registry.
+	tracked bool
+
+	// decodedOffset is the offset in the decompressed content at which the
+	// latest decoded message ends.
+	decodedOffset int64
+
+	tags        []string
+	tagProvider tag.Provider
+	outputChan  chan *message.Message
+	decoder     *decoder.Decoder
+	reader      io.ReadCloser
+
+	// isFinished is an atomic value, set to 1 when the tailer has read the whole
+	// archive, or has been stopped, and flushed all messages.
+	isFinished int32
+
+	// notReady is an atomic value, set to 1 when the tailer reached the end of
+	// the archive before the end of its compressed stream.
+	notReady int32
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewArchiveTailer returns an initialized ArchiveTailer reading archivePath
+// on behalf of file. When tracked is true, the progress of the tailer is
+// recorded in the registry under its Identifier.
+func NewArchiveTailer(outputChan chan *message.Message, file *File, archivePath string, tracked bool, decoder *decoder.Decoder) *ArchiveTailer {
+	var tagProvider tag.Provider
+	if file.Source.Config.Identifier != "" {
+		tagProvider = tag.NewProvider(containers.BuildTaggerEntityName(file.Source.Config.Identifier))
+	} else {
+		tagProvider = tag.NewLocalProvider([]string{})
+	}
+
+	return &ArchiveTailer{
+		file:        file,
+		archivePath: archivePath,
+		tracked:     tracked,
+		tagProvider: tagProvider,
+		outputChan:  outputChan,
+		decoder:     decoder,
+		stop:        make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+}
+
+// ArchiveIdentifier returns the registry identifier of an archive.
+func ArchiveIdentifier(archivePath string) string {
+	return auditor.ArchiveIdentifierPrefix + archivePath
+}
+
+// Identifier returns a string that identifies this tailer in the registry, or an
+// empty string if its progress is not tracked.
+func (t *ArchiveTailer) Identifier() string {
+	if !t.tracked {
+		return ""
+	}
+	return ArchiveIdentifier(t.archivePath)
+}
+
+// ArchivePath returns the path of the compressed file read by this tailer.
+func (t *ArchiveTailer) ArchivePath() string {
+	return t.archivePath
+}
+
+// File returns the file this tailer reads on behalf of.
+func (t *ArchiveTailer) File() *File {
+	return t.file
+}
+
+// Start opens the archive, skips offset bytes of decompressed content and
+// begins the tailer's operation in a dedicated goroutine. An archive whose end
+// has not been written yet isn't reported as an error in the status of the
+// source, as it can be read again later.
+func (t *ArchiveTailer) Start(offset int64) error {
+	r, err := openArchive(t.archivePath)
+	if err != nil {
+		if !isArchiveNotReady(err) {
+			t.file.Source.Status.Error(err)
+		}
+		return err
+	}
+	if offset > 0 {
+		skipped, err := io.CopyN(io.Discard, r, offset)
+		if err != nil && err != io.EOF {
+			r.Close()
+			if !isArchiveNotReady(err) {
+				t.file.Source.Status.Error(err)
+			}
+			return err
+		}
+		offset = skipped
+	}
+	t.reader = r
+	t.decodedOffset = offset
+	t.tags = t.buildTailerTags()
+
+	log.Infof("Reading archive %s for %s from offset %d", t.archivePath, t.file.Path, offset)
+	t.file.Source.AddInput(t.archivePath)
+
+	go t.forwardMessages()
+	t.decoder.Start()
+	go t.readUntilEOF()
+
+	return nil
+}
+
+// Stop stops the tailer and returns only after all in-flight messages have
+// been flushed to the output channel.
+func (t *ArchiveTailer) Stop() {
+	t.stop <- struct{}{}
+	<-t.done
+}
+
+// IsFinished returns true if the tailer has read the whole archive, or has
+// been stopped, and flushed all messages to the output channel.
+func (t *ArchiveTailer) IsFinished() bool {
+	return atomic.LoadInt32(&t.isFinished) != 0
+}
+
+// NotReady returns true if the tailer stopped because the end of the archive
+// has not been written yet. The archive can then be read again from
+// GetDecodedOffset once the tailer is finished.
+func (t *ArchiveTailer) NotReady() bool {
+	return atomic.LoadInt32(&t.notReady) != 0
+}
+
+// GetDecodedOffset returns the offset in the decompressed content at which the
+// latest decoded message ends. It must only be called once the tailer is
+// finished.
+func (t *ArchiveTailer) GetDecodedOffset() int64 {
+	return t.decodedOffset
+}
+
+// readUntilEOF passes the decompressed content of the archive to the decoder
+// until the end of the archive is reached or the tailer is stopped.
+func (t *ArchiveTailer) readUntilEOF() {
+	defer func() {
+		t.reader.Close()
+		t.decoder.Stop()
+		t.file.Source.RemoveInput(t.archivePath)
+		log.Info("Closed archive", t.archivePath, "read", t.decoder.GetLineCount(), "lines")
+	}()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		default:
+		}
+
+		inBuf := make([]byte, 4096)
+		n, err := t.reader.Read(inBuf)
+		if n > 0 {
+			t.decoder.InputChan <- decoder.NewInput(inBuf[:n])
+			t.recordBytes(int64(n))
+		}
+		if err == io.EOF {
+			return
+		}
+		if isArchiveNotReady(err) {
+			atomic.StoreInt32(&t.notReady, 1)
+			log.Debugf("The end of archive %s has not been written yet: %v", t.archivePath, err)
+			return
+		}
+		if err != nil {
+			t.file.Source.Status.Error(err)
+			log.Warnf("Unexpected error occurred while reading archive %s: %v", t.archivePath, err)
+			return
+		}
+	}
+}
+
+// forwardMessages forwards the decoded messages to the output channel.
+func (t *ArchiveTailer) forwardMessages() {
+	defer func() {
+		atomic.StoreInt32(&t.isFinished, 1)
+		close(t.done)
+	}()
+	identifier := t.Identifier()
+	for output := range t.decoder.OutputChan {
+		t.decodedOffset += int64(output.RawDataLen)
+		origin := message.NewOrigin(t.file.Source)
+		origin.Identifier = identifier
+		if identifier != "" {
+			origin.Offset = strconv.FormatInt(t.decodedOffset, 10)
+		}
+		origin.SetTags(append(t.tags, t.tagProvider.GetTags()...))
+		// Ignore empty lines once the registry offset is updated
+		if len(output.Content) == 0 {
+			continue
+		}
+		t.outputChan <- message.NewMessage(output.Content, origin, output.Status, output.IngestionTimestamp)
+	}
+}
+
+// buildTailerTags groups the file tag and directory (if wildcard path)
+func (t *ArchiveTailer) buildTailerTags() []string {
+	tags := []string{fmt.Sprintf("filename:%s", filepath.Base(t.file.Path))}
+	if t.file.IsWildcardPath {
+		tags = append(tags, fmt.Sprintf("dirname:%s", filepath.Dir(t.file.Path)))
+	}
+	return tags
+}
+
+func (t *ArchiveTailer) recordBytes(n int64) {
+	t.file.Source.BytesRead.Add(n)
+	if t.file.Source.ParentSource != nil {
+		t.file.Source.ParentSource.BytesRead.Add(n)
+	}
+}
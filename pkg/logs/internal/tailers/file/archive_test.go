@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+// +build !windows
+
+package file
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func writeArchive(t *testing.T, path string, content string) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var w io.WriteCloser
+	switch filepath.Ext(path) {
+	case ".gz":
+		w = gzip.NewWriter(f)
+	case ".zst":
+		w, err = zstd.NewWriter(f)
+		require.NoError(t, err)
+	default:
+		t.Fatalf("unsupported archive %s", path)
+	}
+	_, err = w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func TestIsArchive(t *testing.T) {
+	assert.True(t, IsArchive("/var/log/foo.log.1.gz"))
+	assert.True(t, IsArchive("/var/log/foo.log.zst"))
+	assert.False(t, IsArchive("/var/log/foo.log"))
+	assert.False(t, IsArchive("/var/log/foo.log.1"))
+}
+
+func TestFindRotatedArchive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	writeArchive(t, path+".2.gz", "older content\n")
+	writeArchive(t, path+".1.zst", "first line\nsecond line\n")
+	require.NoError(t, os.WriteFile(path+".1", []byte("first line\n"), 0644))
+
+	assert.Equal(t, path+".1.zst", FindRotatedArchive(path, []byte("first line\n")))
+	assert.Equal(t, path+".2.gz", FindRotatedArchive(path, []byte("older")))
+	assert.Equal(t, "", FindRotatedArchive(path, []byte("unknown")))
+	assert.Equal(t, "", FindRotatedArchive(path, nil))
+}
+
+func TestArchiveTailerReadsFromOffset(t *testing.T) {
+	for _, ext := range []string{".gz", ".zst"} {
+		t.Run(ext, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app.log")
+			archivePath := path + ".1" + ext
+			writeArchive(t, archivePath, "first line\nsecond line\nthird line\n")
+
+			source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
+			outputChan := make(chan *message.Message, 10)
+			tailer := NewArchiveTailer(outputChan, NewFile(path, source, false), archivePath, true, decoder.NewDecoderFromSource(source))
+
+			require.NoError(t, tailer.Start(int64(len("first line\n"))))
+
+			msg := <-outputChan
+			assert.Equal(t, "second line", string(msg.Content))
+			assert.Equal(t, "archive:"+archivePath, msg.Origin.Identifier)
+			assert.Equal(t, "23", msg.Origin.Offset)
+			assert.Contains(t, msg.Origin.Tags(), "filename:app.log")
+
+			msg = <-outputChan
+			assert.Equal(t, "third line", string(msg.Content))
+			assert.Equal(t, "34", msg.Origin.Offset)
+
+			assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+			tailer.Stop()
+		})
+	}
+}
+
+func TestArchiveTailerUntracked(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	archivePath := path + ".1.gz"
+	writeArchive(t, archivePath, "only line\n")
+
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
+	outputChan := make(chan *message.Message, 10)
+	tailer := NewArchiveTailer(outputChan, NewFile(path, source, false), archivePath, false, decoder.NewDecoderFromSource(source))
+
+	require.NoError(t, tailer.Start(0))
+	msg := <-outputChan
+	assert.Equal(t, "only line", string(msg.Content))
+	assert.Equal(t, "", msg.Origin.Identifier)
+	assert.Equal(t, "", msg.Origin.Offset)
+	tailer.Stop()
+}
+
+// writePartialArchive writes a gzip archive whose compressed stream is not
+// terminated, as if it was still being written
+func writePartialArchive(t *testing.T, path string, content string) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	w := gzip.NewWriter(f)
+	_, err = w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+}
+
+func TestArchiveTailerNotReady(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	archivePath := path + ".1.gz"
+	writePartialArchive(t, archivePath, "first line\nsecond")
+
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: path})
+	outputChan := make(chan *message.Message, 10)
+	tailer := NewArchiveTailer(outputChan, NewFile(path, source, false), archivePath, true, decoder.NewDecoderFromSource(source))
+
+	require.NoError(t, tailer.Start(0))
+	msg := <-outputChan
+	assert.Equal(t, "first line", string(msg.Content))
+	assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, tailer.NotReady())
+	assert.Equal(t, int64(len("first line\n")), tailer.GetDecodedOffset())
+	assert.False(t, source.Status.IsError())
+
+	// the archive can't be opened before its header is written
+	require.NoError(t, os.WriteFile(archivePath, nil, 0644))
+	tailer = NewArchiveTailer(outputChan, NewFile(path, source, false), archivePath, true, decoder.NewDecoderFromSource(source))
+	assert.Error(t, tailer.Start(0))
+	assert.False(t, source.Status.IsError())
+
+	// the archive is read again from the decoded offset once it is complete
+	writeArchive(t, archivePath, "first line\nsecond line\n")
+	tailer = NewArchiveTailer(outputChan, NewFile(path, source, false), archivePath, true, decoder.NewDecoderFromSource(source))
+	require.NoError(t, tailer.Start(int64(len("first line\n"))))
+	msg = <-outputChan
+	assert.Equal(t, "second line", string(msg.Content))
+	assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, tailer.NotReady())
+	tailer.Stop()
+}
+
+func (suite *TailerTestSuite) TestFindRotatedArchive() {
+	_, err := suite.testFile.WriteString("hello world\n")
+	suite.Nil(err)
+	suite.Nil(suite.tailer.StartFromBeginning())
+	<-suite.outputChan
+
+	writeArchive(suite.T(), suite.testPath+".1.gz", "hello world\nmissed line\n")
+	suite.Equal(suite.testPath+".1.gz", suite.tailer.FindRotatedArchive())
+	suite.Eventually(func() bool { return suite.tailer.GetLastReadOffset() == 12 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,35 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+)
+
+// ComputeFingerprint returns a hash of the first size bytes of the file at path.
+// It returns an empty string when fingerprinting is disabled (size <= 0), when
+// the file can't be read or when it is smaller than size, as its content may
+// still change.
+func ComputeFingerprint(path string, size int) string {
+	if size <= 0 {
+		return ""
+	}
+	f, err := openFile(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		return ""
+	}
+	h := fnv.New64a()
+	h.Write(buf) //nolint:errcheck
+	return fmt.Sprintf("%016x", h.Sum64())
+}
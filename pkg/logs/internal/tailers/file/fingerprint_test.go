@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows
+// +build !windows
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	moved := filepath.Join(dir, "moved.log")
+	other := filepath.Join(dir, "other.log")
+
+	require.NoError(t, os.WriteFile(path, []byte("first line\nsecond line\n"), 0644))
+	require.NoError(t, os.WriteFile(moved, []byte("first line\nsecond line\nthird line\n"), 0644))
+	require.NoError(t, os.WriteFile(other, []byte("another line\n"), 0644))
+
+	fingerprint := ComputeFingerprint(path, 10)
+	assert.Len(t, fingerprint, 16)
+	assert.Equal(t, fingerprint, ComputeFingerprint(moved, 10))
+	assert.NotEqual(t, fingerprint, ComputeFingerprint(other, 10))
+
+	// disabled, too small or missing files are not fingerprinted
+	assert.Equal(t, "", ComputeFingerprint(path, 0))
+	assert.Equal(t, "", ComputeFingerprint(other, 100))
+	assert.Equal(t, "", ComputeFingerprint(filepath.Join(dir, "missing.log"), 10))
+}
+
+func (suite *TailerTestSuite) TestTailerSetsFingerprint() {
+	suite.tailer.fingerprintSize = 10
+	_, err := suite.testFile.WriteString("hello\n")
+	suite.Nil(err)
+
+	// the file is too small to be fingerprinted yet
+	suite.Equal("", suite.tailer.Fingerprint())
+	suite.Nil(suite.tailer.StartFromBeginning())
+	msg := <-suite.outputChan
+	suite.Equal("", msg.Origin.Fingerprint)
+
+	_, err = suite.testFile.WriteString("world\n")
+	suite.Nil(err)
+	msg = <-suite.outputChan
+	suite.Equal("world", string(msg.Content))
+	suite.Equal(ComputeFingerprint(suite.testPath, 10), msg.Origin.Fingerprint)
+	suite.NotEqual("", msg.Origin.Fingerprint)
+}
@@ -58,6 +58,18 @@ type Tailer struct {
 	// fullpath is the absolute path to file.Path.
 	fullpath string
 
+	// fingerprintSize is the number of bytes at the beginning of the file used
+	// to compute its fingerprint, 0 if fingerprinting is disabled.
+	fingerprintSize int
+
+	// fingerprint identifies the content of the file, it is empty until the
+	// file holds at least fingerprintSize bytes.
+	fingerprint string
+
+	// header holds the first bytes of the file, captured when the tailer
+	// starts.  It is used to find the file back once compressed by a log rotation.
+	header []byte
+
 	// osFile is the os.File object from which log data is read.  The read implementation
 	// is platform-specific.
 	osFile *os.File
@@ -129,19 +141,21 @@ func NewTailer(outputChan chan *message.Message, file *File, sleepDuration time.
 
 	forwardContext, stopForward := context.WithCancel(context.Background())
 	closeTimeout := coreConfig.Datadog.GetDuration("logs_config.close_timeout") * time.Second
+	fingerprintSize := coreConfig.Datadog.GetInt("logs_config.fingerprint_size")
 
 	return &Tailer{
-		file:           file,
-		outputChan:     outputChan,
-		decoder:        decoder,
-		tagProvider:    tagProvider,
-		lastReadOffset: 0,
-		sleepDuration:  sleepDuration,
-		closeTimeout:   closeTimeout,
-		stop:           make(chan struct{}, 1),
-		done:           make(chan struct{}, 1),
-		forwardContext: forwardContext,
-		stopForward:    stopForward,
+		file:            file,
+		outputChan:      outputChan,
+		decoder:         decoder,
+		tagProvider:     tagProvider,
+		lastReadOffset:  0,
+		sleepDuration:   sleepDuration,
+		closeTimeout:    closeTimeout,
+		fingerprintSize: fingerprintSize,
+		stop:            make(chan struct{}, 1),
+		done:            make(chan struct{}, 1),
+		forwardContext:  forwardContext,
+		stopForward:     stopForward,
 	}
 }
 
@@ -163,6 +177,15 @@ func (t *Tailer) Identifier() string {
 	return fmt.Sprintf("file:%s", t.file.Path)
 }
 
+// Fingerprint returns the fingerprint of the tailed file, or an empty string if
+// it can't be computed yet. It must be called before the tailer is started.
+func (t *Tailer) Fingerprint() string {
+	if t.fingerprint == "" {
+		t.fingerprint = ComputeFingerprint(t.file.Path, t.fingerprintSize)
+	}
+	return t.fingerprint
+}
+
 // Start begins the tailer's operation in a dedicated goroutine.
 func (t *Tailer) Start(offset int64, whence int) error {
 	err := t.setup(offset, whence)
@@ -170,6 +193,7 @@ func (t *Tailer) Start(offset int64, whence int) error {
 		t.file.Source.Status.Error(err)
 		return err
 	}
+	t.header = readFileHeader(t.fullpath)
 	t.file.Source.Status.Success()
 	t.file.Source.AddInput(t.file.Path)
 
@@ -270,9 +294,16 @@ func (t *Tailer) forwardMessages() {
 			identifier = ""
 		}
 		t.decodedOffset = offset
+		if identifier != "" && t.fingerprint == "" && t.fingerprintSize > 0 && offset >= int64(t.fingerprintSize) {
+			// the file was too small to be fingerprinted when the tailer started
+			t.fingerprint = ComputeFingerprint(t.file.Path, t.fingerprintSize)
+		}
 		origin := message.NewOrigin(t.file.Source)
 		origin.Identifier = identifier
 		origin.Offset = strconv.FormatInt(offset, 10)
+		if identifier != "" {
+			origin.Fingerprint = t.fingerprint
+		}
 		origin.SetTags(append(t.tags, t.tagProvider.GetTags()...))
 		// Ignore empty lines once the registry offset is updated
 		if len(output.Content) == 0 {
@@ -299,6 +330,22 @@ func (t *Tailer) getLastReadOffset() int64 {
 	return atomic.LoadInt64(&t.lastReadOffset)
 }
 
+// FindRotatedArchive returns the path of the archive logrotate compressed the
+// tailed file into, or an empty string if there is none.
+func (t *Tailer) FindRotatedArchive() string {
+	return FindRotatedArchive(t.fullpath, t.header)
+}
+
+// GetLastReadOffset returns the last file offset that was read.
+func (t *Tailer) GetLastReadOffset() int64 {
+	return t.getLastReadOffset()
+}
+
+// GetFile returns the file tailed by this tailer.
+func (t *Tailer) GetFile() *File {
+	return t.file
+}
+
 // GetDetectedPattern returns the decoder's detected pattern.
 func (t *Tailer) GetDetectedPattern() *regexp.Regexp {
 	return t.decoder.GetDetectedPattern()
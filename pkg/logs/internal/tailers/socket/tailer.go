@@ -13,7 +13,9 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/framer"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers/noop"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers/syslog"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 )
 
@@ -36,12 +38,20 @@ func NewTailer(source *config.LogSource, conn net.Conn, outputChan chan *message
 		Conn:       conn,
 		outputChan: outputChan,
 		read:       read,
-		decoder:    decoder.InitializeDecoder(source, noop.New()),
+		decoder:    buildDecoder(source),
 		stop:       make(chan struct{}, 1),
 		done:       make(chan struct{}, 1),
 	}
 }
 
+// buildDecoder returns the decoder matching the format of the source
+func buildDecoder(source *config.LogSource) *decoder.Decoder {
+	if source.Config.Format == config.SyslogFormat {
+		return decoder.NewDecoderWithFraming(source, syslog.New(), framer.Syslog, nil)
+	}
+	return decoder.InitializeDecoder(source, noop.New())
+}
+
 // Start prepares the tailer to read and decode data from the connection
 func (t *Tailer) Start() {
 	go t.forwardMessages()
@@ -64,11 +74,29 @@ func (t *Tailer) forwardMessages() {
 	}()
 	for output := range t.decoder.OutputChan {
 		if len(output.Content) > 0 {
-			t.outputChan <- message.NewMessageWithSource(output.Content, message.StatusInfo, t.source, output.IngestionTimestamp)
+			t.outputChan <- t.toMessage(output)
 		}
 	}
 }
 
+// toMessage builds a message from the decoder output, applying the status,
+// hostname and service parsed from the content, if any
+func (t *Tailer) toMessage(output *decoder.Message) *message.Message {
+	status := output.Status
+	if status == "" {
+		status = message.StatusInfo
+	}
+
+	origin := message.NewOrigin(t.source)
+	// the service of the message is still overridden by the integration
+	// config when defined
+	origin.SetService(output.Service)
+
+	msg := message.NewMessage(output.Content, origin, status, output.IngestionTimestamp)
+	msg.Hostname = output.Hostname
+	return msg
+}
+
 // readForever reads the data from conn.
 func (t *Tailer) readForever() {
 	defer func() {
@@ -39,6 +39,32 @@ func TestReadAndForwardShouldSucceedWithSuccessfulRead(t *testing.T) {
 	tailer.Stop()
 }
 
+func TestReadAndForwardSyslogMessages(t *testing.T) {
+	msgChan := make(chan *message.Message)
+	r, w := net.Pipe()
+	tailer := NewTailer(config.NewLogSource("", &config.LogsConfig{Format: config.SyslogFormat}), r, msgChan, read)
+	tailer.Start()
+
+	var msg *message.Message
+
+	// should parse an octet-counted message
+	w.Write([]byte("50 <11>1 2021-01-01T00:00:00Z myhost myapp - - - oops"))
+	msg = <-msgChan
+	assert.JSONEq(t, `{"message":"oops","syslog":{"facility":1,"severity":3,"version":1,"timestamp":"2021-01-01T00:00:00Z","hostname":"myhost","appname":"myapp"}}`, string(msg.Content))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "myhost", msg.GetHostname())
+	assert.Equal(t, "myapp", msg.Origin.Service())
+
+	// should parse a newline-terminated message
+	w.Write([]byte("<14>Jan  2 03:04:05 otherhost otherapp[42]: hello\n"))
+	msg = <-msgChan
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	assert.Equal(t, "otherhost", msg.GetHostname())
+	assert.Equal(t, "otherapp", msg.Origin.Service())
+
+	tailer.Stop()
+}
+
 func TestReadShouldFailWithError(t *testing.T) {
 	msgChan := make(chan *message.Message)
 	r, w := net.Pipe()
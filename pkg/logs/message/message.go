@@ -37,6 +37,9 @@ type Message struct {
 	// Optional.
 	// Used in the Serverless Agent
 	Lambda *Lambda
+	// Optional. Overrides the agent hostname.
+	// Used for logs forwarded from other hosts, such as syslog
+	Hostname string
 }
 
 // Lambda is a struct storing information about the Lambda function and function execution.
@@ -94,6 +97,9 @@ func (m *Message) GetHostname() string {
 	if m.Lambda != nil {
 		return m.Lambda.ARN
 	}
+	if m.Hostname != "" {
+		return m.Hostname
+	}
 	hostname, err := util.GetHostname(context.TODO())
 	if err != nil {
 		// this scenario is not likely to happen since
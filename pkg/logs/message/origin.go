@@ -16,9 +16,11 @@ type Origin struct {
 	Identifier string
 	LogSource  *config.LogSource
 	Offset     string
-	service    string
-	source     string
-	tags       []string
+	// Fingerprint identifies the content of the file the offset applies to.
+	Fingerprint string
+	service     string
+	source      string
+	tags        []string
 }
 
 // NewOrigin returns a new Origin
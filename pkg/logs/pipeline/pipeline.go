@@ -115,7 +115,12 @@ func getStrategy(inputChan chan *message.Message, outputChan chan *message.Paylo
 	if endpoints.UseHTTP || serverless {
 		encoder := sender.IdentityContentType
 		if endpoints.Main.UseCompression {
-			encoder = sender.NewGzipContentEncoding(endpoints.Main.CompressionLevel)
+			switch endpoints.Main.CompressionKind {
+			case config.ZstdCompressionKind:
+				encoder = sender.NewZstdContentEncoding(endpoints.Main.CompressionLevel)
+			default:
+				encoder = sender.NewGzipContentEncoding(endpoints.Main.CompressionLevel)
+			}
 		}
 		return sender.NewBatchStrategy(inputChan, outputChan, sender.ArraySerializer, endpoints.BatchWait, endpoints.BatchMaxSize, endpoints.BatchMaxContentSize, "logs", encoder)
 	}
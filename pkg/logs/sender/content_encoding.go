@@ -8,6 +8,8 @@ package sender
 import (
 	"bytes"
 	"compress/gzip"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 // ContentEncoding encodes the payload
@@ -71,3 +73,23 @@ func (c *GzipContentEncoding) encode(payload []byte) ([]byte, error) {
 	}
 	return compressedPayload.Bytes(), nil
 }
+
+// ZstdContentEncoding encodes the payload using zstd algorithm
+type ZstdContentEncoding struct {
+	compressor *compression.ZstdCompressor
+}
+
+// NewZstdContentEncoding creates a new Zstd content type
+func NewZstdContentEncoding(level int) *ZstdContentEncoding {
+	return &ZstdContentEncoding{
+		compressor: compression.NewZstdCompressor(level),
+	}
+}
+
+func (c *ZstdContentEncoding) name() string {
+	return c.compressor.ContentEncoding()
+}
+
+func (c *ZstdContentEncoding) encode(payload []byte) ([]byte, error) {
+	return c.compressor.Compress(payload)
+}
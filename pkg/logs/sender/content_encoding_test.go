@@ -11,6 +11,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func TestIdentityContentType(t *testing.T) {
@@ -42,6 +44,22 @@ func TestGzipContentEncodingName(t *testing.T) {
 	assert.Equal(t, NewGzipContentEncoding(gzip.BestCompression).name(), "gzip")
 }
 
+func TestZstdContentEncoding(t *testing.T) {
+	payload := []byte("my payload")
+
+	encodedPayload, err := NewZstdContentEncoding(6).encode(payload)
+	assert.Nil(t, err)
+
+	decompressedPayload, err := compression.NewZstdCompressor(6).Decompress(encodedPayload)
+	assert.Nil(t, err)
+
+	assert.Equal(t, payload, decompressedPayload)
+}
+
+func TestZstdContentEncodingName(t *testing.T) {
+	assert.Equal(t, NewZstdContentEncoding(6).name(), "zstd")
+}
+
 func decompress(payload []byte) ([]byte, error) {
 	reader, err := gzip.NewReader(bytes.NewReader(payload))
 	if err != nil {
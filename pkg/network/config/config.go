@@ -25,6 +25,9 @@ const (
 
 	defaultOffsetThreshold = 400
 	maxOffsetThreshold     = 3000
+
+	defaultMaxHTTPPathsPerHost       = 500
+	defaultHTTPPathTemplateThreshold = 50
 )
 
 // Config stores all flags used by the network eBPF tracer
@@ -107,6 +110,18 @@ type Config struct {
 	// get flushed on every client request (default 30s check interval)
 	MaxHTTPStatsBuffered int
 
+	// EnableHTTPPathNormalization replaces the identifiers found in the HTTP paths, such as numeric IDs
+	// or UUIDs, by wildcards, and learns the path templates of each host
+	EnableHTTPPathNormalization bool
+
+	// MaxHTTPPathsPerHost represents the maximum number of distinct HTTP paths of a host between two
+	// client requests, the requests with the other paths are aggregated in the "other" path
+	MaxHTTPPathsPerHost int
+
+	// HTTPPathTemplateThreshold is the number of distinct path segments after the same path prefix
+	// turning them into a wildcard, e.g. /users/alice, /users/bob... into /users/*
+	HTTPPathTemplateThreshold int
+
 	// MaxConnectionsStateBuffered represents the maximum number of state objects that we'll store in memory. These state objects store
 	// the stats for a connection so we can accurately determine traffic change between client requests.
 	MaxConnectionsStateBuffered int
@@ -208,6 +223,10 @@ func New() *Config {
 		EnableHTTPSMonitoring: cfg.GetBool(join(netNS, "enable_https_monitoring")),
 		MaxHTTPStatsBuffered:  100000,
 
+		EnableHTTPPathNormalization: cfg.GetBool(join(netNS, "enable_http_path_normalization")),
+		MaxHTTPPathsPerHost:         cfg.GetInt(join(netNS, "max_http_paths_per_host")),
+		HTTPPathTemplateThreshold:   cfg.GetInt(join(netNS, "http_path_template_threshold")),
+
 		EnableConntrack:              cfg.GetBool(join(spNS, "enable_conntrack")),
 		ConntrackMaxStateSize:        cfg.GetInt(join(spNS, "conntrack_max_state_size")),
 		ConntrackRateLimit:           cfg.GetInt(join(spNS, "conntrack_rate_limit")),
@@ -236,6 +255,16 @@ func New() *Config {
 		c.OffsetGuessThreshold = defaultOffsetThreshold
 	}
 
+	if c.MaxHTTPPathsPerHost <= 0 {
+		log.Warnf("max_http_paths_per_host must be positive, got %d. Setting it to the default of %d", c.MaxHTTPPathsPerHost, defaultMaxHTTPPathsPerHost)
+		c.MaxHTTPPathsPerHost = defaultMaxHTTPPathsPerHost
+	}
+
+	if c.HTTPPathTemplateThreshold <= 0 {
+		log.Warnf("http_path_template_threshold must be positive, got %d. Setting it to the default of %d", c.HTTPPathTemplateThreshold, defaultHTTPPathTemplateThreshold)
+		c.HTTPPathTemplateThreshold = defaultHTTPPathTemplateThreshold
+	}
+
 	if !kernel.IsIPv6Enabled() {
 		c.CollectIPv6Conns = false
 		log.Info("network tracer IPv6 tracing disabled by system")
@@ -237,6 +237,35 @@ func TestSettingMaxDNSStats(t *testing.T) {
 	})
 }
 
+func TestHTTPPathNormalizationLimits(t *testing.T) {
+	newConfig()
+	defer restoreGlobalConfig()
+
+	t.Run("default values", func(t *testing.T) {
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.Equal(t, 500, cfg.MaxHTTPPathsPerHost)
+		assert.Equal(t, 50, cfg.HTTPPathTemplateThreshold)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		newConfig()
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_MAX_HTTP_PATHS_PER_HOST", "0")
+		os.Setenv("DD_SYSTEM_PROBE_NETWORK_HTTP_PATH_TEMPLATE_THRESHOLD", "-1")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_MAX_HTTP_PATHS_PER_HOST")
+		defer os.Unsetenv("DD_SYSTEM_PROBE_NETWORK_HTTP_PATH_TEMPLATE_THRESHOLD")
+
+		_, err := sysconfig.New("")
+		require.NoError(t, err)
+		cfg := New()
+
+		assert.Equal(t, 500, cfg.MaxHTTPPathsPerHost)
+		assert.Equal(t, 50, cfg.HTTPPathTemplateThreshold)
+	})
+}
+
 func TestHTTPReplaceRules(t *testing.T) {
 	expected := []*ReplaceRule{
 		{
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+)
+
+var _ packetSource = &pcapPacketSource{}
+
+// pcapngMagic is the block type of the section header starting pcapng files
+var pcapngMagic = []byte{0x0a, 0x0d, 0x0d, 0x0a}
+
+// pcapReader is implemented by the readers of the pcap and pcapng formats
+type pcapReader interface {
+	ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	LinkType() layers.LinkType
+}
+
+// pcapPacketSource reads the packets of a pcap or pcapng capture file, so that
+// DNS traffic can be replayed offline
+type pcapPacketSource struct {
+	file       *os.File
+	reader     pcapReader
+	packetType gopacket.LayerType
+	packets    int64
+	done       bool
+}
+
+// newPcapPacketSource returns a packet source reading the capture file at path
+func newPcapPacketSource(path string) (*pcapPacketSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader pcapReader
+	buf := bufio.NewReader(f)
+	if magic, _ := buf.Peek(len(pcapngMagic)); bytes.Equal(magic, pcapngMagic) {
+		reader, err = pcapgo.NewNgReader(buf, pcapgo.DefaultNgReaderOptions)
+	} else {
+		reader, err = pcapgo.NewReader(buf)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not read capture file %s: %s", path, err)
+	}
+
+	var packetType gopacket.LayerType
+	switch reader.LinkType() {
+	case layers.LinkTypeEthernet:
+		packetType = layers.LayerTypeEthernet
+	case layers.LinkTypeLinuxSLL:
+		packetType = layers.LayerTypeLinuxSLL
+	case layers.LinkTypeRaw, layers.LinkTypeIPv4:
+		packetType = layers.LayerTypeIPv4
+	case layers.LinkTypeIPv6:
+		packetType = layers.LayerTypeIPv6
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unsupported link type %s of capture file %s", reader.LinkType(), path)
+	}
+
+	return &pcapPacketSource{
+		file:       f,
+		reader:     reader,
+		packetType: packetType,
+	}, nil
+}
+
+// VisitPackets reads all the packets of the capture file, the following calls
+// return immediately
+func (p *pcapPacketSource) VisitPackets(exit <-chan struct{}, visit func([]byte, time.Time) error) error {
+	for !p.done {
+		data, ci, err := p.reader.ZeroCopyReadPacketData()
+		if err == io.EOF {
+			p.done = true
+			return nil
+		}
+		if err != nil {
+			p.done = true
+			return err
+		}
+
+		p.packets++
+		if err := visit(data, ci.Timestamp); err != nil {
+			return err
+		}
+
+		// break out of loop if exit is closed
+		select {
+		case <-exit:
+			return nil
+		default:
+		}
+	}
+	return nil
+}
+
+func (p *pcapPacketSource) PacketType() gopacket.LayerType {
+	return p.packetType
+}
+
+func (p *pcapPacketSource) Stats() map[string]int64 {
+	return map[string]int64{
+		"captured_packets": p.packets,
+	}
+}
+
+func (p *pcapPacketSource) Close() {
+	_ = p.file.Close()
+}
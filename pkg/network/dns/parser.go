@@ -82,6 +82,7 @@ func newDNSParser(layerType gopacket.LayerType, cfg *config.Config) *dnsParser {
 
 	stack := []gopacket.DecodingLayer{
 		&layers.Ethernet{},
+		&layers.LinuxSLL{},
 		ipv4Payload,
 		ipv6Payload,
 		udpPayload,
@@ -203,6 +204,7 @@ func (p *dnsParser) parseAnswerInto(
 	}
 
 	pktInfo.rCode = uint8(dns.ResponseCode)
+	pktInfo.truncated = dns.TC
 	if dns.ResponseCode != 0 {
 		pktInfo.pktType = failedResponse
 		return nil
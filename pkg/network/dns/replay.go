@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package dns
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+)
+
+// ReplayPcap runs the packets of a pcap or pcapng capture file through the DNS
+// parser and stat keeper used by the snooper. The states are expired with the
+// timestamps of the packets rather than the wall clock, so that the result only
+// depends on the capture, and the queries still unanswered at the end of the
+// capture are counted as timeouts.
+func ReplayPcap(cfg *config.Config, path string) (*ReplayResult, error) {
+	source, err := newPcapPacketSource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// the stats are the point of the replay
+	replayCfg := *cfg
+	replayCfg.CollectDNSStats = true
+
+	statKeeper := newOfflineDNSStatkeeper(cfg.DNSTimeout, cfg.MaxDNSStats)
+	snooper := &socketFilterSnooper{
+		source:          source,
+		parser:          newDNSParser(source.PacketType(), &replayCfg),
+		cache:           newReverseDNSCache(dnsCacheSize, dnsCacheExpirationPeriod),
+		statKeeper:      statKeeper,
+		translation:     new(translation),
+		exit:            make(chan struct{}),
+		collectLocalDNS: cfg.CollectLocalDNS,
+	}
+	defer snooper.Close()
+
+	var lastTs, nextExpiration time.Time
+	err = source.VisitPackets(snooper.exit, func(data []byte, ts time.Time) error {
+		if nextExpiration.IsZero() {
+			nextExpiration = ts.Add(cfg.DNSTimeout)
+		} else if ts.After(nextExpiration) {
+			statKeeper.removeExpiredStates(ts.Add(-cfg.DNSTimeout))
+			nextExpiration = ts.Add(cfg.DNSTimeout)
+		}
+		lastTs = ts
+		return snooper.processPacket(data, ts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	statKeeper.removeExpiredStates(lastTs.Add(time.Microsecond))
+
+	result := &ReplayResult{Stats: statKeeper.GetAndResetAllStats()}
+	result.Telemetry = snooper.GetStats()
+	delete(result.Telemetry, "timestamp_micro_secs")
+	return result, nil
+}
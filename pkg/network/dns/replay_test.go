@@ -0,0 +1,218 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build windows || linux_bpf
+// +build windows linux_bpf
+
+package dns
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go4.org/intern"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+var (
+	replayClientIP = net.ParseIP("10.0.0.1").To4()
+	replayServerIP = net.ParseIP("8.8.8.8").To4()
+)
+
+// replayPacket is a DNS packet written in a capture file
+type replayPacket struct {
+	ts         time.Duration
+	tcp        bool
+	clientPort uint16
+	dns        *layers.DNS
+}
+
+func dnsQuery(id uint16, name string) *layers.DNS {
+	return &layers.DNS{
+		ID:      id,
+		RD:      true,
+		OpCode:  layers.DNSOpCodeQuery,
+		QDCount: 1,
+		Questions: []layers.DNSQuestion{
+			{Name: []byte(name), Type: layers.DNSTypeA, Class: layers.DNSClassIN},
+		},
+	}
+}
+
+func dnsResponse(id uint16, name string, rcode layers.DNSResponseCode, truncated bool, ip string) *layers.DNS {
+	resp := dnsQuery(id, name)
+	resp.QR = true
+	resp.TC = truncated
+	resp.ResponseCode = rcode
+	if ip != "" {
+		resp.ANCount = 1
+		resp.Answers = []layers.DNSResourceRecord{
+			{Name: []byte(name), Type: layers.DNSTypeA, Class: layers.DNSClassIN, TTL: 60, IP: net.ParseIP(ip).To4()},
+		}
+	}
+	return resp
+}
+
+func writeCapture(t *testing.T, packets []replayPacket) string {
+	path := filepath.Join(t.TempDir(), "dns.pcap")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	w := pcapgo.NewWriter(f)
+	require.NoError(t, w.WriteFileHeader(65536, layers.LinkTypeEthernet))
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, p := range packets {
+		dnsBuf := gopacket.NewSerializeBuffer()
+		require.NoError(t, p.dns.SerializeTo(dnsBuf, gopacket.SerializeOptions{FixLengths: true}))
+		payload := dnsBuf.Bytes()
+
+		srcIP, dstIP := replayClientIP, replayServerIP
+		srcPort, dstPort := p.clientPort, uint16(53)
+		if p.dns.QR {
+			srcIP, dstIP = dstIP, srcIP
+			srcPort, dstPort = dstPort, srcPort
+		}
+
+		eth := &layers.Ethernet{
+			SrcMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 1},
+			DstMAC:       net.HardwareAddr{0, 0, 0, 0, 0, 2},
+			EthernetType: layers.EthernetTypeIPv4,
+		}
+		ip := &layers.IPv4{Version: 4, TTL: 64, SrcIP: srcIP, DstIP: dstIP, Protocol: layers.IPProtocolUDP}
+		var transport gopacket.SerializableLayer
+		if p.tcp {
+			ip.Protocol = layers.IPProtocolTCP
+			tcp := &layers.TCP{SrcPort: layers.TCPPort(srcPort), DstPort: layers.TCPPort(dstPort), PSH: true, ACK: true, Window: 1024}
+			require.NoError(t, tcp.SetNetworkLayerForChecksum(ip))
+			transport = tcp
+			// DNS messages sent over TCP are prefixed by their length
+			prefixed := make([]byte, 2+len(payload))
+			binary.BigEndian.PutUint16(prefixed, uint16(len(payload)))
+			copy(prefixed[2:], payload)
+			payload = prefixed
+		} else {
+			udp := &layers.UDP{SrcPort: layers.UDPPort(srcPort), DstPort: layers.UDPPort(dstPort)}
+			require.NoError(t, udp.SetNetworkLayerForChecksum(ip))
+			transport = udp
+		}
+
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		require.NoError(t, gopacket.SerializeLayers(buf, opts, eth, ip, transport, gopacket.Payload(payload)))
+		data := buf.Bytes()
+		require.NoError(t, w.WritePacket(gopacket.CaptureInfo{
+			Timestamp:     start.Add(p.ts),
+			CaptureLength: len(data),
+			Length:        len(data),
+		}, data))
+	}
+	return path
+}
+
+func replayConfig() *config.Config {
+	cfg := config.New()
+	cfg.CollectDNSDomains = true
+	cfg.DNSTimeout = 5 * time.Second
+	return cfg
+}
+
+func TestReplayPcap(t *testing.T) {
+	path := writeCapture(t, []replayPacket{
+		// truncated UDP response retried over TCP
+		{ts: 0, clientPort: 1000, dns: dnsQuery(1, "example.com")},
+		{ts: time.Millisecond, clientPort: 1000, dns: dnsResponse(1, "example.com", layers.DNSResponseCodeNoErr, true, "")},
+		{ts: 2 * time.Millisecond, tcp: true, clientPort: 2000, dns: dnsQuery(2, "example.com")},
+		{ts: 5 * time.Millisecond, tcp: true, clientPort: 2000, dns: dnsResponse(2, "example.com", layers.DNSResponseCodeNoErr, false, "93.184.216.34")},
+		// errors
+		{ts: 10 * time.Millisecond, clientPort: 1001, dns: dnsQuery(3, "nx.example.com")},
+		{ts: 11 * time.Millisecond, clientPort: 1001, dns: dnsResponse(3, "nx.example.com", layers.DNSResponseCodeNXDomain, false, "")},
+		{ts: 12 * time.Millisecond, clientPort: 1001, dns: dnsQuery(4, "nx.example.com")},
+		{ts: 13 * time.Millisecond, clientPort: 1001, dns: dnsResponse(4, "nx.example.com", layers.DNSResponseCodeServFail, false, "")},
+		{ts: 14 * time.Millisecond, clientPort: 1001, dns: dnsQuery(5, "nx.example.com")},
+		{ts: 15 * time.Millisecond, clientPort: 1001, dns: dnsResponse(5, "nx.example.com", layers.DNSResponseCodeRefused, false, "")},
+		// a query answered after the timeout, and another one never answered
+		{ts: 20 * time.Millisecond, clientPort: 1002, dns: dnsQuery(6, "slow.example.com")},
+		{ts: 10 * time.Second, clientPort: 1003, dns: dnsQuery(7, "lost.example.com")},
+		{ts: 10*time.Second + time.Millisecond, clientPort: 1002, dns: dnsResponse(6, "slow.example.com", layers.DNSResponseCodeNoErr, false, "1.2.3.4")},
+	})
+
+	result, err := ReplayPcap(replayConfig(), path)
+	require.NoError(t, err)
+
+	key := func(port uint16, protocol uint8) Key {
+		return Key{
+			ServerIP:   util.AddressFromNetIP(replayServerIP),
+			ClientIP:   util.AddressFromNetIP(replayClientIP),
+			ClientPort: port,
+			Protocol:   protocol,
+		}
+	}
+	stats := func(k Key, domain string) Stats {
+		require.Contains(t, result.Stats, k)
+		require.Contains(t, result.Stats[k], intern.GetByString(domain))
+		return result.Stats[k][intern.GetByString(domain)][TypeA]
+	}
+
+	udp := stats(key(1000, syscall.IPPROTO_UDP), "example.com")
+	assert.Equal(t, uint32(1), udp.Truncated)
+	assert.Equal(t, uint32(0), udp.TCPFallbacks)
+	assert.Equal(t, map[uint32]uint32{0: 1}, udp.CountByRcode)
+	assert.Equal(t, uint64(1000), udp.SuccessLatencySum)
+
+	tcp := stats(key(2000, syscall.IPPROTO_TCP), "example.com")
+	assert.Equal(t, uint32(0), tcp.Truncated)
+	assert.Equal(t, uint32(1), tcp.TCPFallbacks)
+	assert.Equal(t, uint64(3000), tcp.SuccessLatencySum)
+
+	errors := stats(key(1001, syscall.IPPROTO_UDP), "nx.example.com")
+	assert.Equal(t, map[uint32]uint32{
+		uint32(layers.DNSResponseCodeNXDomain): 1,
+		uint32(layers.DNSResponseCodeServFail): 1,
+		uint32(layers.DNSResponseCodeRefused):  1,
+	}, errors.CountByRcode)
+	assert.Equal(t, uint64(3000), errors.FailureLatencySum)
+
+	// the slow query is expired by the timestamp of the following packet
+	assert.Equal(t, uint32(1), stats(key(1002, syscall.IPPROTO_UDP), "slow.example.com").Timeouts)
+	// the unanswered query is expired at the end of the capture
+	assert.Equal(t, uint32(1), stats(key(1003, syscall.IPPROTO_UDP), "lost.example.com").Timeouts)
+
+	assert.Equal(t, int64(13), result.Telemetry["captured_packets"])
+	assert.Equal(t, int64(0), result.Telemetry["decoding_errors"])
+	assert.Equal(t, int64(7), result.Telemetry["queries"])
+}
+
+func TestReplayPcapIsDeterministic(t *testing.T) {
+	path := writeCapture(t, []replayPacket{
+		{ts: 0, clientPort: 1000, dns: dnsQuery(1, "example.com")},
+		{ts: time.Millisecond, clientPort: 1000, dns: dnsResponse(1, "example.com", layers.DNSResponseCodeNXDomain, false, "")},
+	})
+
+	first, err := ReplayPcap(replayConfig(), path)
+	require.NoError(t, err)
+	second, err := ReplayPcap(replayConfig(), path)
+	require.NoError(t, err)
+	assert.Equal(t, first.Stats, second.Stats)
+	assert.Equal(t, first.Telemetry, second.Telemetry)
+}
+
+func TestReplayPcapUnknownFile(t *testing.T) {
+	_, err := ReplayPcap(replayConfig(), filepath.Join(t.TempDir(), "missing.pcap"))
+	assert.Error(t, err)
+}
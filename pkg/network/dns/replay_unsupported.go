@@ -0,0 +1,20 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !windows && !linux_bpf
+// +build !windows,!linux_bpf
+
+package dns
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+)
+
+// ReplayPcap is not supported
+func ReplayPcap(_ *config.Config, _ string) (*ReplayResult, error) {
+	return nil, fmt.Errorf("DNS replay is not supported on this platform")
+}
@@ -11,8 +11,10 @@ package dns
 import (
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"go4.org/intern"
 )
@@ -42,6 +44,7 @@ type dnsPacketInfo struct {
 	rCode         uint8         // responseCode
 	question      *intern.Value // only relevant for query packets
 	queryType     QueryType
+	truncated     bool // only relevant for response packets
 }
 
 type stateKey struct {
@@ -53,6 +56,18 @@ type stateValue struct {
 	ts       uint64
 	question *intern.Value
 	qtype    QueryType
+	// fallback is whether the query retries over TCP a truncated UDP response
+	fallback bool
+}
+
+// fallbackKey identifies the truncated UDP responses which can be retried over
+// TCP. The client port is not part of it since the TCP query is sent from
+// another port.
+type fallbackKey struct {
+	clientIP util.Address
+	serverIP util.Address
+	question *intern.Value
+	qtype    QueryType
 }
 
 type dnsStatKeeper struct {
@@ -69,17 +84,13 @@ type dnsStatKeeper struct {
 	droppedStats     int
 	lastNumStats     int32
 	lastDroppedStats int32
+	// truncated maps the truncated UDP responses to their timestamp, to
+	// detect the queries retrying them over TCP
+	truncated map[fallbackKey]uint64
 }
 
 func newDNSStatkeeper(timeout time.Duration, maxStats int) *dnsStatKeeper {
-	statsKeeper := &dnsStatKeeper{
-		stats:            make(StatsByKeyByNameByType),
-		state:            make(map[stateKey]stateValue),
-		expirationPeriod: timeout,
-		exit:             make(chan struct{}),
-		maxSize:          maxStateMapSize,
-		maxStats:         maxStats,
-	}
+	statsKeeper := newOfflineDNSStatkeeper(timeout, maxStats)
 
 	ticker := time.NewTicker(statsKeeper.expirationPeriod)
 	go func() {
@@ -96,6 +107,21 @@ func newDNSStatkeeper(timeout time.Duration, maxStats int) *dnsStatKeeper {
 	return statsKeeper
 }
 
+// newOfflineDNSStatkeeper returns a dnsStatKeeper which doesn't expire its
+// states periodically, removeExpiredStates must be called with the timestamps
+// of the packets
+func newOfflineDNSStatkeeper(timeout time.Duration, maxStats int) *dnsStatKeeper {
+	return &dnsStatKeeper{
+		stats:            make(StatsByKeyByNameByType),
+		state:            make(map[stateKey]stateValue),
+		truncated:        make(map[fallbackKey]uint64),
+		expirationPeriod: timeout,
+		exit:             make(chan struct{}),
+		maxSize:          maxStateMapSize,
+		maxStats:         maxStats,
+	}
+}
+
 func microSecs(t time.Time) uint64 {
 	return uint64(t.UnixNano() / 1000)
 }
@@ -111,7 +137,12 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 		}
 
 		if _, ok := d.state[sk]; !ok {
-			d.state[sk] = stateValue{question: info.question, ts: microSecs(ts), qtype: info.queryType}
+			d.state[sk] = stateValue{
+				question: info.question,
+				ts:       microSecs(ts),
+				qtype:    info.queryType,
+				fallback: d.isTCPFallback(info, ts),
+			}
 		}
 		return
 	}
@@ -128,6 +159,10 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 
 	latency := microSecs(ts) - start.ts
 
+	if info.truncated && info.key.Protocol == syscall.IPPROTO_UDP && len(d.truncated) < d.maxSize {
+		d.truncated[newFallbackKey(info.key, start)] = microSecs(ts)
+	}
+
 	allStats, ok := d.stats[info.key]
 	if !ok {
 		allStats = make(map[*intern.Value]map[QueryType]Stats)
@@ -155,6 +190,12 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 		byqtype.Timeouts++
 	} else {
 		byqtype.CountByRcode[uint32(info.rCode)]++
+		if info.truncated {
+			byqtype.Truncated++
+		}
+		if start.fallback {
+			byqtype.TCPFallbacks++
+		}
 		if info.pktType == successfulResponse {
 			byqtype.SuccessLatencySum += latency
 		} else if info.pktType == failedResponse {
@@ -166,6 +207,30 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 	d.stats[info.key] = allStats
 }
 
+// isTCPFallback returns whether a query retries over TCP a truncated UDP
+// response received less than the expiration period ago
+func (d *dnsStatKeeper) isTCPFallback(info dnsPacketInfo, ts time.Time) bool {
+	if info.key.Protocol != syscall.IPPROTO_TCP || len(d.truncated) == 0 {
+		return false
+	}
+	fk := newFallbackKey(info.key, stateValue{question: info.question, qtype: info.queryType})
+	truncatedTs, ok := d.truncated[fk]
+	if !ok {
+		return false
+	}
+	delete(d.truncated, fk)
+	return microSecs(ts)-truncatedTs <= uint64(d.expirationPeriod.Microseconds())
+}
+
+func newFallbackKey(key Key, query stateValue) fallbackKey {
+	return fallbackKey{
+		clientIP: key.ClientIP,
+		serverIP: key.ServerIP,
+		question: query.question,
+		qtype:    query.qtype,
+	}
+}
+
 func (d *dnsStatKeeper) GetNumStats() (int32, int32) {
 	numStats := atomic.LoadInt32(&d.lastNumStats)
 	droppedStats := atomic.LoadInt32(&d.lastDroppedStats)
@@ -246,6 +311,12 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 		}
 	}
 
+	for k, ts := range d.truncated {
+		if ts < threshold {
+			delete(d.truncated, k)
+		}
+	}
+
 	if d.deleteCount < deleteThreshold {
 		return
 	}
@@ -260,5 +331,5 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 }
 
 func (d *dnsStatKeeper) Close() {
-	d.exit <- struct{}{}
+	close(d.exit)
 }
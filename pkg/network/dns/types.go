@@ -6,6 +6,8 @@
 package dns
 
 import (
+	"fmt"
+
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/google/gopacket/layers"
 	"go4.org/intern"
@@ -38,6 +40,34 @@ const (
 	TypeURI   QueryType = 256 // URI RR [RFC7553]
 )
 
+// String returns the name of the query type, e.g. "A" or "AAAA"
+func (q QueryType) String() string {
+	return layers.DNSType(q).String()
+}
+
+// responseCodeNames are the names of the DNS response codes
+var responseCodeNames = map[uint32]string{
+	0:  "NOERROR",
+	1:  "FORMERR",
+	2:  "SERVFAIL",
+	3:  "NXDOMAIN",
+	4:  "NOTIMP",
+	5:  "REFUSED",
+	6:  "YXDOMAIN",
+	7:  "YXRRSET",
+	8:  "NXRRSET",
+	9:  "NOTAUTH",
+	10: "NOTZONE",
+}
+
+// ResponseCodeName returns the name of a DNS response code, e.g. "NXDOMAIN"
+func ResponseCodeName(rcode uint32) string {
+	if name, ok := responseCodeNames[rcode]; ok {
+		return name
+	}
+	return fmt.Sprintf("RCODE%d", rcode)
+}
+
 // StatsByKeyByNameByType provides a type name for the map of
 // DNS stats based on the host key->the lookup name->querytype
 type StatsByKeyByNameByType map[Key]map[*intern.Value]map[QueryType]Stats
@@ -65,4 +95,16 @@ type Stats struct {
 	SuccessLatencySum uint64
 	FailureLatencySum uint64
 	CountByRcode      map[uint32]uint32
+	// Truncated is the number of responses with the truncation flag set
+	Truncated uint32
+	// TCPFallbacks is the number of queries sent over TCP to retry a
+	// truncated UDP response
+	TCPFallbacks uint32
+}
+
+// ReplayResult holds the DNS stats computed from the packets of a capture file
+type ReplayResult struct {
+	Stats StatsByKeyByNameByType
+	// Telemetry holds the counters reported by ReverseDNS.GetStats
+	Telemetry map[string]int64
 }
@@ -6,6 +6,7 @@
 package encoding
 
 import (
+	"sort"
 	"sync"
 
 	model "github.com/DataDog/agent-payload/v5/process"
@@ -160,7 +161,12 @@ func formatDNSStatsByDomain(stats map[*intern.Value]map[dns.QueryType]dns.Stats,
 
 			} else {
 				var ms model.DNSStats
-				ms.DnsCountByRcode = stat.CountByRcode
+				// the counts of the other query types are added to the map,
+				// it can't be shared with the stats
+				ms.DnsCountByRcode = make(map[uint32]uint32, len(stat.CountByRcode))
+				for rcode, count := range stat.CountByRcode {
+					ms.DnsCountByRcode[rcode] = count
+				}
 				ms.DnsFailureLatencySum = stat.FailureLatencySum
 				ms.DnsSuccessLatencySum = stat.SuccessLatencySum
 				ms.DnsTimeouts = stat.Timeouts
@@ -171,3 +177,65 @@ func formatDNSStatsByDomain(stats map[*intern.Value]map[dns.QueryType]dns.Stats,
 	}
 	return m
 }
+
+// DNSDomainStats is the breakdown of the DNS responses of a domain for a query
+// type, summed over the clients and servers
+type DNSDomainStats struct {
+	Domain    string `json:"domain"`
+	QueryType string `json:"query_type"`
+	// Responses is the number of responses by response code name, e.g.
+	// NOERROR, NXDOMAIN, SERVFAIL or REFUSED
+	Responses         map[string]uint32 `json:"responses"`
+	Timeouts          uint32            `json:"timeouts"`
+	Truncated         uint32            `json:"truncated"`
+	TCPFallbacks      uint32            `json:"tcp_fallbacks"`
+	SuccessLatencySum uint64            `json:"success_latency_sum"`
+	FailureLatencySum uint64            `json:"failure_latency_sum"`
+}
+
+// FormatDNSDomainStats returns the breakdown of the DNS stats by domain and
+// query type, sorted by domain and query type. Unlike the payload sent to the
+// backend, it includes the truncation and TCP fallback counts.
+func FormatDNSDomainStats(stats dns.StatsByKeyByNameByType) []*DNSDomainStats {
+	type domainKey struct {
+		domain string
+		qtype  dns.QueryType
+	}
+	byDomain := make(map[domainKey]*DNSDomainStats)
+	for _, statsByDomain := range stats {
+		for d, statsByQType := range statsByDomain {
+			for qtype, stat := range statsByQType {
+				k := domainKey{domain: d.Get().(string), qtype: qtype}
+				ds, ok := byDomain[k]
+				if !ok {
+					ds = &DNSDomainStats{
+						Domain:    k.domain,
+						QueryType: qtype.String(),
+						Responses: make(map[string]uint32),
+					}
+					byDomain[k] = ds
+				}
+				for rcode, count := range stat.CountByRcode {
+					ds.Responses[dns.ResponseCodeName(rcode)] += count
+				}
+				ds.Timeouts += stat.Timeouts
+				ds.Truncated += stat.Truncated
+				ds.TCPFallbacks += stat.TCPFallbacks
+				ds.SuccessLatencySum += stat.SuccessLatencySum
+				ds.FailureLatencySum += stat.FailureLatencySum
+			}
+		}
+	}
+
+	res := make([]*DNSDomainStats, 0, len(byDomain))
+	for _, ds := range byDomain {
+		res = append(res, ds)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Domain != res[j].Domain {
+			return res[i].Domain < res[j].Domain
+		}
+		return res[i].QueryType < res[j].QueryType
+	})
+	return res
+}
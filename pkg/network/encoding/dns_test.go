@@ -171,3 +171,75 @@ func TestDNSPIDCollision(t *testing.T) {
 	assert.NotNil(t, out1.DnsStatsByDomain)
 	assert.Nil(t, out2.DnsStatsByDomain)
 }
+
+func TestFormatDNSStatsByDomainDoesNotAliasStats(t *testing.T) {
+	domain := intern.GetByString("foo.com")
+	stats := map[*intern.Value]map[dns.QueryType]dns.Stats{
+		domain: {
+			dns.TypeA:    {CountByRcode: map[uint32]uint32{3: 1}},
+			dns.TypeAAAA: {CountByRcode: map[uint32]uint32{3: 2}},
+		},
+	}
+
+	formatted := formatDNSStatsByDomain(stats, make(map[string]int))
+	assert.Equal(t, map[uint32]uint32{3: 3}, formatted[0].DnsCountByRcode)
+	assert.Equal(t, map[uint32]uint32{3: 1}, stats[domain][dns.TypeA].CountByRcode)
+	assert.Equal(t, map[uint32]uint32{3: 2}, stats[domain][dns.TypeAAAA].CountByRcode)
+}
+
+func TestFormatDNSDomainStats(t *testing.T) {
+	key := func(port uint16, protocol uint8) dns.Key {
+		return dns.Key{
+			ClientIP:   util.AddressFromString("10.1.1.1"),
+			ServerIP:   util.AddressFromString("8.8.8.8"),
+			ClientPort: port,
+			Protocol:   protocol,
+		}
+	}
+	foo := intern.GetByString("foo.com")
+	bar := intern.GetByString("bar.com")
+	stats := dns.StatsByKeyByNameByType{
+		key(1000, syscall.IPPROTO_UDP): {
+			foo: {
+				dns.TypeA: {
+					CountByRcode:      map[uint32]uint32{0: 1, 3: 2},
+					Truncated:         1,
+					SuccessLatencySum: 10,
+					FailureLatencySum: 20,
+				},
+			},
+			bar: {
+				dns.TypeA: {CountByRcode: map[uint32]uint32{2: 1, 5: 1, 16: 1}, Timeouts: 2},
+			},
+		},
+		key(2000, syscall.IPPROTO_TCP): {
+			foo: {
+				dns.TypeA:    {CountByRcode: map[uint32]uint32{0: 1}, TCPFallbacks: 1, SuccessLatencySum: 5},
+				dns.TypeAAAA: {CountByRcode: map[uint32]uint32{0: 1}},
+			},
+		},
+	}
+
+	assert.Equal(t, []*DNSDomainStats{
+		{
+			Domain:    "bar.com",
+			QueryType: "A",
+			Responses: map[string]uint32{"SERVFAIL": 1, "REFUSED": 1, "RCODE16": 1},
+			Timeouts:  2,
+		},
+		{
+			Domain:            "foo.com",
+			QueryType:         "A",
+			Responses:         map[string]uint32{"NOERROR": 2, "NXDOMAIN": 2},
+			Truncated:         1,
+			TCPFallbacks:      1,
+			SuccessLatencySum: 15,
+			FailureLatencySum: 20,
+		},
+		{
+			Domain:    "foo.com",
+			QueryType: "AAAA",
+			Responses: map[string]uint32{"NOERROR": 1},
+		},
+	}, FormatDNSDomainStats(stats))
+}
@@ -23,6 +23,9 @@ type httpStatKeeper struct {
 	// replace rules for HTTP path
 	replaceRules []*config.ReplaceRule
 
+	// normalizer templates the HTTP paths, nil when disabled
+	normalizer *pathNormalizer
+
 	// http path buffer
 	buffer []byte
 
@@ -32,11 +35,17 @@ type httpStatKeeper struct {
 }
 
 func newHTTPStatkeeper(c *config.Config, telemetry *telemetry) *httpStatKeeper {
+	var normalizer *pathNormalizer
+	if c.EnableHTTPPathNormalization {
+		normalizer = newPathNormalizer(c.MaxHTTPPathsPerHost, c.HTTPPathTemplateThreshold)
+	}
+
 	return &httpStatKeeper{
 		stats:        make(map[Key]RequestStats),
 		incomplete:   make(map[Key]httpTX),
 		maxEntries:   c.MaxHTTPStatsBuffered,
 		replaceRules: c.HTTPReplaceRules,
+		normalizer:   normalizer,
 		buffer:       make([]byte, HTTPBufferSize),
 		interned:     make(map[string]string),
 		telemetry:    telemetry,
@@ -61,6 +70,9 @@ func (h *httpStatKeeper) GetAndResetAllStats() map[Key]RequestStats {
 	h.stats = make(map[Key]RequestStats)
 	h.incomplete = make(map[Key]httpTX)
 	h.interned = make(map[string]string)
+	if h.normalizer != nil {
+		h.normalizer.flush()
+	}
 	return ret
 }
 
@@ -153,6 +165,18 @@ func (h *httpStatKeeper) processHTTPPath(tx httpTX) (pathStr string, rejected bo
 		}
 	}
 
+	if h.normalizer != nil {
+		host := hostKey{
+			ipHigh: uint64(tx.tup.daddr_h),
+			ipLow:  uint64(tx.tup.daddr_l),
+			port:   uint16(tx.tup.dport),
+		}
+		var other bool
+		if path, other = h.normalizer.normalize(host, path); other {
+			atomic.AddInt64(&h.telemetry.other, 1)
+		}
+	}
+
 	return h.intern(path), false
 }
 
@@ -196,3 +196,60 @@ func TestPathProcessing(t *testing.T) {
 	})
 
 }
+
+func TestPathNormalization(t *testing.T) {
+	var (
+		sourceIP   = util.AddressFromString("1.1.1.1")
+		sourcePort = 1234
+		destIP     = util.AddressFromString("2.2.2.2")
+		statusCode = 200
+		latency    = time.Second
+	)
+
+	c := &config.Config{
+		MaxHTTPStatsBuffered:        1000,
+		EnableHTTPPathNormalization: true,
+		MaxHTTPPathsPerHost:         2,
+		HTTPPathTemplateThreshold:   2,
+	}
+	telemetry := newTelemetry()
+	sk := newHTTPStatkeeper(c, telemetry)
+
+	transactions := []httpTX{
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, 8080, "/users/1", statusCode, latency),
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, 8080, "/users/22", statusCode, latency),
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, 8080, "/orders/d41d8cd9", statusCode, latency),
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, 8080, "/products", statusCode, latency),
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, 9090, "/products", statusCode, latency),
+	}
+	sk.Process(transactions)
+	stats := sk.GetAndResetAllStats()
+
+	counts := make(map[string]int)
+	for key, metrics := range stats {
+		counts[fmt.Sprintf("%d%s", key.DstPort, key.Path)] = metrics[statusCode/100-1].Count
+	}
+	assert.Equal(t, map[string]int{
+		"8080/users/*":     2,
+		"8080/orders/*":    1,
+		"8080" + OtherPath: 1,
+		"9090/products":    1,
+	}, counts)
+	assert.Equal(t, int64(1), telemetry.other)
+
+	// the distinct paths are counted again after a flush: /users/carol turns
+	// /users/ into a template, but the host already has 2 distinct paths
+	transactions = []httpTX{
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, 9090, "/users/alice", statusCode, latency),
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, 9090, "/users/bob", statusCode, latency),
+		generateIPv4HTTPTransaction(sourceIP, destIP, sourcePort, 9090, "/users/carol", statusCode, latency),
+	}
+	sk.Process(transactions)
+	stats = sk.GetAndResetAllStats()
+
+	paths := make(map[string]int)
+	for key, metrics := range stats {
+		paths[key.Path] = metrics[statusCode/100-1].Count
+	}
+	assert.Equal(t, map[string]int{"/users/alice": 1, "/users/bob": 1, OtherPath: 1}, paths)
+}
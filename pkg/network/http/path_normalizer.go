@@ -0,0 +1,279 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"bytes"
+	"strings"
+)
+
+const (
+	// pathWildcard replaces the path segments holding identifiers
+	pathWildcard = "*"
+	// OtherPath is the path of the transactions sent to a host which already
+	// has too many distinct paths
+	OtherPath = "other"
+
+	// minHexHashLength is the minimal length of a segment to be considered as
+	// a hex hash, e.g. a short git commit SHA
+	minHexHashLength = 7
+	// minTokenLength is the minimal length of a segment to be considered as a
+	// random token
+	minTokenLength = 16
+	// minTokenTransitions is the minimal number of transitions between letters
+	// and digits of a random token
+	minTokenTransitions = 4
+)
+
+var (
+	wildcardSegment = []byte(pathWildcard)
+	otherPath       = []byte(OtherPath)
+)
+
+// hostKey identifies the server of HTTP transactions
+type hostKey struct {
+	ipHigh uint64
+	ipLow  uint64
+	port   uint16
+}
+
+// pathNode is a node of the tree of the path templates learned for a host.
+// Once a node has seen more distinct segments than the template threshold, it
+// is turned into a wildcard and all its segments are replaced.
+type pathNode struct {
+	children map[string]*pathNode
+	wildcard bool
+}
+
+// hostPaths holds the path templates of a host
+type hostPaths struct {
+	root  *pathNode
+	nodes int
+	// paths are the distinct paths since the last flush
+	paths map[string]struct{}
+	// seen is whether the host was seen since the last flush
+	seen bool
+}
+
+// pathNormalizer replaces the identifiers in the HTTP paths by wildcards to
+// limit the number of distinct paths of each host:
+//   - the segments holding numeric IDs, UUIDs, hex hashes or random tokens are
+//     replaced, e.g. /users/12345 becomes /users/*
+//   - the segments following the same path prefix with too many distinct values
+//     are learned as a wildcard, e.g. /users/alice becomes /users/*
+//   - the paths of a host with too many distinct paths are aggregated in the
+//     "other" path
+type pathNormalizer struct {
+	// maxPathsPerHost is the maximum number of distinct paths of a host between
+	// two flushes
+	maxPathsPerHost int
+	// templateThreshold is the number of distinct segments after the same
+	// prefix turning it into a template
+	templateThreshold int
+	// maxNodesPerHost bounds the memory used to learn the templates of a host
+	maxNodesPerHost int
+
+	hosts map[hostKey]*hostPaths
+	// buffer holds the normalized path
+	buffer []byte
+}
+
+func newPathNormalizer(maxPathsPerHost int, templateThreshold int) *pathNormalizer {
+	return &pathNormalizer{
+		maxPathsPerHost:   maxPathsPerHost,
+		templateThreshold: templateThreshold,
+		maxNodesPerHost:   maxPathsPerHost * templateThreshold,
+		hosts:             make(map[hostKey]*hostPaths),
+	}
+}
+
+// normalize returns the templated path of a transaction sent to host, and
+// whether it was aggregated in the "other" path. The returned slice is only
+// valid until the next call.
+func (n *pathNormalizer) normalize(host hostKey, path []byte) ([]byte, bool) {
+	h, ok := n.hosts[host]
+	if !ok {
+		h = &hostPaths{root: &pathNode{}, paths: make(map[string]struct{})}
+		n.hosts[host] = h
+	}
+	h.seen = true
+
+	n.buffer = n.buffer[:0]
+	node := h.root
+	for {
+		segment := path
+		idx := bytes.IndexByte(path, '/')
+		if idx >= 0 {
+			segment = path[:idx]
+		}
+		if len(segment) > 0 && isIdentifier(segment) {
+			segment = wildcardSegment
+		}
+
+		segment, node = n.learn(h, node, segment)
+		if node == nil {
+			return otherPath, true
+		}
+		n.buffer = append(n.buffer, segment...)
+
+		if idx < 0 {
+			break
+		}
+		n.buffer = append(n.buffer, '/')
+		path = path[idx+1:]
+	}
+
+	if _, ok := h.paths[string(n.buffer)]; !ok {
+		if len(h.paths) >= n.maxPathsPerHost {
+			return otherPath, true
+		}
+		h.paths[string(n.buffer)] = struct{}{}
+	}
+	return n.buffer, false
+}
+
+// learn returns the segment to use in the templated path and the child of node
+// for this segment, turning node into a wildcard when it has too many distinct
+// children. The child is nil if the host has too many nodes to learn a new
+// segment.
+func (n *pathNormalizer) learn(h *hostPaths, node *pathNode, segment []byte) ([]byte, *pathNode) {
+	if node.wildcard && len(segment) > 0 {
+		segment = wildcardSegment
+	}
+	if child, ok := node.children[string(segment)]; ok {
+		return segment, child
+	}
+	if h.nodes >= n.maxNodesPerHost {
+		return segment, nil
+	}
+
+	if !node.wildcard && len(segment) > 0 && len(node.children) >= n.templateThreshold {
+		// too many distinct segments: the following ones are learned again
+		// below the wildcard
+		h.nodes -= countNodes(node) - 1
+		node.wildcard = true
+		node.children = nil
+		segment = wildcardSegment
+	}
+	if node.children == nil {
+		node.children = make(map[string]*pathNode)
+	}
+
+	child := &pathNode{}
+	node.children[string(segment)] = child
+	h.nodes++
+	return segment, child
+}
+
+// countNodes returns the number of nodes of the tree rooted at node
+func countNodes(node *pathNode) int {
+	count := 1
+	for _, child := range node.children {
+		count += countNodes(child)
+	}
+	return count
+}
+
+// flush forgets the distinct paths of the hosts, and the templates of the
+// hosts which were not seen since the previous flush
+func (n *pathNormalizer) flush() {
+	for host, h := range n.hosts {
+		if !h.seen {
+			delete(n.hosts, host)
+			continue
+		}
+		h.seen = false
+		h.paths = make(map[string]struct{})
+	}
+}
+
+// isIdentifier returns whether a path segment holds a numeric ID, a UUID, a
+// hex hash or a random token
+func isIdentifier(segment []byte) bool {
+	return isNumeric(segment) || isUUID(segment) || isHexHash(segment) || isRandomToken(segment)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isNumeric(segment []byte) bool {
+	for _, c := range segment {
+		if !isDigit(c) {
+			return false
+		}
+	}
+	return len(segment) > 0
+}
+
+// isUUID matches the 8-4-4-4-12 hex digits format
+func isUUID(segment []byte) bool {
+	if len(segment) != 36 {
+		return false
+	}
+	for i, c := range segment {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isHexHash matches the hex strings containing at least a digit, to not
+// match words such as "facade"
+func isHexHash(segment []byte) bool {
+	if len(segment) < minHexHashLength {
+		return false
+	}
+	hasDigit := false
+	for _, c := range segment {
+		if !isHexDigit(c) {
+			return false
+		}
+		hasDigit = hasDigit || isDigit(c)
+	}
+	return hasDigit
+}
+
+// isRandomToken matches the long segments made of letters and digits mixed
+// together, such as base64 or base62 tokens, but not names such as
+// "release-v1-2-3"
+func isRandomToken(segment []byte) bool {
+	if len(segment) < minTokenLength {
+		return false
+	}
+	transitions := 0
+	var previous byte
+	for _, c := range segment {
+		switch {
+		case isDigit(c), isLetter(c):
+			if previous != 0 && isDigit(previous) != isDigit(c) {
+				transitions++
+			}
+			previous = c
+		case strings.IndexByte("-_.~+=%", c) >= 0:
+			previous = 0
+		default:
+			return false
+		}
+	}
+	return transitions >= minTokenTransitions
+}
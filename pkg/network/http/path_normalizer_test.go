@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsIdentifier(t *testing.T) {
+	for segment, expected := range map[string]bool{
+		"12345":                                true,
+		"0":                                    true,
+		"3fa85f64-5717-4562-b3fc-2c963f66afa6": true,
+		"d41d8cd98f00b204e9800998ecf8427e":     true,
+		"5f1d7a3c9b2e4f6a8c0d1e2f":             true,
+		"a3f9c21":                              true,
+		"aGVsbG8gd29ybGQ1MjM0NTY3ODkw":         true,
+		"eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOjF9":    true,
+		"users":                                false,
+		"v1":                                   false,
+		"facade":                               false,
+		"deadbeef":                             false,
+		"index.html":                           false,
+		"release-v1-2-3-notes":                 false,
+		"getUserPreferencesForAccount":         false,
+		"":                                     false,
+	} {
+		assert.Equal(t, expected, isIdentifier([]byte(segment)), segment)
+	}
+}
+
+func normalizeString(n *pathNormalizer, host hostKey, path string) string {
+	normalized, _ := n.normalize(host, []byte(path))
+	return string(normalized)
+}
+
+func TestNormalizeIdentifiers(t *testing.T) {
+	n := newPathNormalizer(100, 10)
+	host := hostKey{ipLow: 1, port: 80}
+
+	for path, expected := range map[string]string{
+		"/users/12345": "/users/*",
+		"/users/12345/orders/3fa85f64-5717-4562-b3fc-2c963f66afa6": "/users/*/orders/*",
+		"/commits/d41d8cd98f00b204e9800998ecf8427e/diff":           "/commits/*/diff",
+		"/":                    "/",
+		"/users/":              "/users/",
+		"":                     "",
+		"/static/index.html":   "/static/index.html",
+		"//double//slashes/42": "//double//slashes/*",
+	} {
+		assert.Equal(t, expected, normalizeString(n, host, path), path)
+	}
+}
+
+func TestLearnTemplates(t *testing.T) {
+	n := newPathNormalizer(100, 3)
+	host := hostKey{ipLow: 1, port: 80}
+
+	// the segments are kept until the prefix has more distinct segments than
+	// the threshold
+	assert.Equal(t, "/users/alice/profile", normalizeString(n, host, "/users/alice/profile"))
+	assert.Equal(t, "/users/bob/profile", normalizeString(n, host, "/users/bob/profile"))
+	assert.Equal(t, "/users/carol/profile", normalizeString(n, host, "/users/carol/profile"))
+	assert.Equal(t, "/users/*/profile", normalizeString(n, host, "/users/dave/profile"))
+	assert.Equal(t, "/users/*/profile", normalizeString(n, host, "/users/alice/profile"))
+	assert.Equal(t, "/users/*/settings", normalizeString(n, host, "/users/erin/settings"))
+	assert.Equal(t, "/users/", normalizeString(n, host, "/users/"))
+
+	// the templates are learned per host, and kept after a flush
+	other := hostKey{ipLow: 2, port: 80}
+	assert.Equal(t, "/users/dave/profile", normalizeString(n, other, "/users/dave/profile"))
+	n.flush()
+	assert.Equal(t, "/users/*/profile", normalizeString(n, host, "/users/frank/profile"))
+
+	// the templates of the hosts which were not seen are forgotten
+	n.flush()
+	n.flush()
+	assert.NotContains(t, n.hosts, host)
+	assert.Empty(t, n.hosts)
+}
+
+func TestMaxPathsPerHost(t *testing.T) {
+	n := newPathNormalizer(3, 100)
+	host := hostKey{ipLow: 1, port: 80}
+
+	for i := 0; i < 3; i++ {
+		path, other := n.normalize(host, []byte(fmt.Sprintf("/path%c", 'a'+i)))
+		assert.False(t, other)
+		assert.Equal(t, fmt.Sprintf("/path%c", 'a'+i), string(path))
+	}
+
+	path, other := n.normalize(host, []byte("/pathd"))
+	assert.True(t, other)
+	assert.Equal(t, OtherPath, string(path))
+
+	// the known paths are still reported, and each host has its own limit
+	assert.Equal(t, "/patha", normalizeString(n, host, "/patha"))
+	assert.Equal(t, "/pathd", normalizeString(n, hostKey{ipLow: 1, port: 8080}, "/pathd"))
+
+	// the distinct paths are counted between two flushes
+	n.flush()
+	assert.Equal(t, "/pathd", normalizeString(n, host, "/pathd"))
+}
+
+func TestMaxNodesPerHost(t *testing.T) {
+	n := newPathNormalizer(2, 2)
+	host := hostKey{ipLow: 1, port: 80}
+
+	// the host can't learn more than 4 nodes
+	assert.Equal(t, "/a/b", normalizeString(n, host, "/a/b"))
+	path, other := n.normalize(host, []byte("/c/d/e"))
+	assert.True(t, other)
+	assert.Equal(t, OtherPath, string(path))
+}
+
+func BenchmarkNormalize(b *testing.B) {
+	n := newPathNormalizer(500, 50)
+	host := hostKey{ipLow: 1, port: 80}
+	paths := make([][]byte, 1000)
+	for i := range paths {
+		paths[i] = []byte(fmt.Sprintf("/api/v1/users/%d/orders/%x", i, i*7919))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n.normalize(host, paths[i%len(paths)])
+	}
+}
@@ -23,6 +23,7 @@ type telemetry struct {
 	misses       int64 // this happens when we can't cope with the rate of events
 	dropped      int64 // this happens when httpStatKeeper reaches capacity
 	rejected     int64 // this happens when an user-defined reject-filter matches a request
+	other        int64 // this happens when a host has too many distinct paths
 	aggregations int64
 }
 
@@ -52,6 +53,7 @@ func (t *telemetry) reset() telemetry {
 		misses:       atomic.SwapInt64(&t.misses, 0),
 		dropped:      atomic.SwapInt64(&t.dropped, 0),
 		rejected:     atomic.SwapInt64(&t.rejected, 0),
+		other:        atomic.SwapInt64(&t.other, 0),
 		aggregations: atomic.SwapInt64(&t.aggregations, 0),
 		elapsed:      now.Unix() - then,
 	}
@@ -70,7 +72,7 @@ func (t *telemetry) report() {
 	}
 
 	log.Debugf(
-		"http stats summary: requests_processed=%d(%.2f/s) requests_missed=%d(%.2f/s) requests_dropped=%d(%.2f/s) requests_rejected=%d(%.2f/s) aggregations=%d",
+		"http stats summary: requests_processed=%d(%.2f/s) requests_missed=%d(%.2f/s) requests_dropped=%d(%.2f/s) requests_rejected=%d(%.2f/s) requests_other_path=%d(%.2f/s) aggregations=%d",
 		totalRequests,
 		float64(totalRequests)/float64(t.elapsed),
 		t.misses,
@@ -79,6 +81,8 @@ func (t *telemetry) report() {
 		float64(t.dropped)/float64(t.elapsed),
 		t.rejected,
 		float64(t.rejected)/float64(t.elapsed),
+		t.other,
+		float64(t.other)/float64(t.elapsed),
 		t.aggregations,
 	)
 }
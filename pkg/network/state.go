@@ -327,6 +327,8 @@ func (ns *networkState) storeDNSStats(stats dns.StatsByKeyByNameByType) {
 						prev.Timeouts += dnsStats.Timeouts
 						prev.SuccessLatencySum += dnsStats.SuccessLatencySum
 						prev.FailureLatencySum += dnsStats.FailureLatencySum
+						prev.Truncated += dnsStats.Truncated
+						prev.TCPFallbacks += dnsStats.TCPFallbacks
 						for rcode, count := range dnsStats.CountByRcode {
 							prev.CountByRcode[rcode] += count
 						}
@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redact
+
+// ScrubUnstructured scrubs sensitive information from an arbitrary resource
+// represented as nested maps and slices (e.g. an unstructured custom resource).
+// String values whose key contains a sensitive word are redacted, as well as
+// the value of name/value pairs (e.g. env vars) whose name is sensitive.
+func ScrubUnstructured(obj map[string]interface{}, scrubber *DataScrubber) {
+	scrubMap(obj, scrubber)
+}
+
+func scrubMap(m map[string]interface{}, scrubber *DataScrubber) {
+	if name, ok := m["name"].(string); ok && scrubber.ContainsSensitiveWord(name) {
+		if _, ok := m["value"].(string); ok {
+			m["value"] = redactedValue
+		}
+	}
+
+	for k, v := range m {
+		switch value := v.(type) {
+		case string:
+			if scrubber.ContainsSensitiveWord(k) {
+				m[k] = redactedValue
+			}
+		case map[string]interface{}:
+			scrubMap(value, scrubber)
+		case []interface{}:
+			scrubSlice(value, scrubber)
+		}
+	}
+}
+
+func scrubSlice(s []interface{}, scrubber *DataScrubber) {
+	for _, v := range s {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			scrubMap(value, scrubber)
+		case []interface{}:
+			scrubSlice(value, scrubber)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrubUnstructured(t *testing.T) {
+	obj := map[string]interface{}{
+		"apiVersion": "datadoghq.com/v1alpha1",
+		"kind":       "Foo",
+		"metadata": map[string]interface{}{
+			"name": "foo",
+			"annotations": map[string]interface{}{
+				"other": "annotation",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"password": "hunter2",
+			"database": map[string]interface{}{
+				"host":   "db",
+				"apiKey": "abcdef",
+			},
+			"env": []interface{}{
+				map[string]interface{}{"name": "DB_PASSWORD", "value": "hunter2"},
+				map[string]interface{}{"name": "DB_HOST", "value": "db"},
+			},
+			"nested": []interface{}{
+				[]interface{}{
+					map[string]interface{}{"auth_token": "xyz"},
+				},
+			},
+		},
+	}
+
+	ScrubUnstructured(obj, NewDefaultDataScrubber())
+
+	expected := map[string]interface{}{
+		"apiVersion": "datadoghq.com/v1alpha1",
+		"kind":       "Foo",
+		"metadata": map[string]interface{}{
+			"name": "foo",
+			"annotations": map[string]interface{}{
+				"other": "annotation",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"password": "********",
+			"database": map[string]interface{}{
+				"host":   "db",
+				"apiKey": "********",
+			},
+			"env": []interface{}{
+				map[string]interface{}{"name": "DB_PASSWORD", "value": "********"},
+				map[string]interface{}{"name": "DB_HOST", "value": "db"},
+			},
+			"nested": []interface{}{
+				[]interface{}{
+					map[string]interface{}{"auth_token": "********"},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, obj)
+}
@@ -54,6 +54,18 @@ const (
 	K8sServiceAccount
 	// K8sIngress represents a Kubernetes Ingress
 	K8sIngress
+	// K8sCR represents a Kubernetes Custom Resource
+	K8sCR
+	// K8sHorizontalPodAutoscaler represents a Kubernetes HorizontalPodAutoscaler
+	K8sHorizontalPodAutoscaler
+	// K8sNetworkPolicy represents a Kubernetes NetworkPolicy
+	K8sNetworkPolicy
+	// K8sNamespace represents a Kubernetes Namespace
+	K8sNamespace
+	// K8sLimitRange represents a Kubernetes LimitRange
+	K8sLimitRange
+	// K8sResourceQuota represents a Kubernetes ResourceQuota
+	K8sResourceQuota
 )
 
 // NodeTypes returns the current existing NodesTypes as a slice to iterate over.
@@ -77,6 +89,12 @@ func NodeTypes() []NodeType {
 		K8sClusterRoleBinding,
 		K8sServiceAccount,
 		K8sIngress,
+		K8sCR,
+		K8sHorizontalPodAutoscaler,
+		K8sNetworkPolicy,
+		K8sNamespace,
+		K8sLimitRange,
+		K8sResourceQuota,
 	}
 }
 
@@ -118,6 +136,18 @@ func (n NodeType) String() string {
 		return "ServiceAccount"
 	case K8sIngress:
 		return "Ingress"
+	case K8sCR:
+		return "CustomResource"
+	case K8sHorizontalPodAutoscaler:
+		return "HorizontalPodAutoscaler"
+	case K8sNetworkPolicy:
+		return "NetworkPolicy"
+	case K8sNamespace:
+		return "Namespace"
+	case K8sLimitRange:
+		return "LimitRange"
+	case K8sResourceQuota:
+		return "ResourceQuota"
 	default:
 		log.Errorf("Trying to convert unknown NodeType iota: %d", n)
 		return "Unknown"
@@ -144,7 +174,13 @@ func (n NodeType) Orchestrator() string {
 		K8sClusterRole,
 		K8sClusterRoleBinding,
 		K8sServiceAccount,
-		K8sIngress:
+		K8sIngress,
+		K8sCR,
+		K8sHorizontalPodAutoscaler,
+		K8sNetworkPolicy,
+		K8sNamespace,
+		K8sLimitRange,
+		K8sResourceQuota:
 		return "k8s"
 	default:
 		log.Errorf("Unknown NodeType %v", n)
@@ -63,12 +63,22 @@ type ProcessCheck struct {
 
 	maxBatchSize  int
 	maxBatchBytes int
+
+	// eventCollector reports the processes which exited between two runs,
+	// it is nil if the event collection is disabled
+	eventCollector *procutil.ProcessEventCollector
+	exitedCursor   uint64
+	hostName       string
+	scrubber       *config.DataScrubber
 }
 
 // Init initializes the singleton ProcessCheck.
-func (p *ProcessCheck) Init(_ *config.AgentConfig, info *model.SystemInfo) {
+func (p *ProcessCheck) Init(cfg *config.AgentConfig, info *model.SystemInfo) {
 	p.sysInfo = info
 	p.probe = getProcessProbe()
+	p.eventCollector = getProcessEventCollector()
+	p.hostName = cfg.HostName
+	p.scrubber = cfg.Scrubber
 	p.containerProvider = util.GetSharedContainerProvider()
 
 	p.notInitializedLogLimit = util.NewLogLimit(1, time.Minute*10)
@@ -172,6 +182,9 @@ func (p *ProcessCheck) run(cfg *config.AgentConfig, groupID int32, collectRealTi
 		p.lastCPUTime = cpuTimes[0]
 		p.lastRun = time.Now()
 		p.storeCreateTimes()
+		if p.eventCollector != nil {
+			_, p.exitedCursor = p.eventCollector.Table().ExitedAfter(p.exitedCursor)
+		}
 
 		if collectRealTime {
 			p.realtimeLastCPUTime = p.lastCPUTime
@@ -183,6 +196,11 @@ func (p *ProcessCheck) run(cfg *config.AgentConfig, groupID int32, collectRealTi
 
 	connsByPID := Connections.getLastConnectionsByPID()
 	procsByCtr := fmtProcesses(cfg, procs, p.lastProcs, pidToCid, cpuTimes[0], p.lastCPUTime, p.lastRun, connsByPID)
+	if p.eventCollector != nil {
+		var exited []*procutil.ProcessLifecycle
+		exited, p.exitedCursor = p.eventCollector.Table().ExitedAfter(p.exitedCursor)
+		fmtExitedProcesses(cfg, exited, procs, p.lastProcs, pidToCid, procsByCtr)
+	}
 	messages, totalProcs, totalContainers := createProcCtrMessages(procsByCtr, containers, cfg, p.maxBatchSize, p.maxBatchBytes, p.sysInfo, groupID, p.networkID)
 
 	// Store the last state for comparison on the next run.
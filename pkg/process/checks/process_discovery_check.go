@@ -7,6 +7,8 @@ package checks
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	model "github.com/DataDog/agent-payload/v5/process"
@@ -26,6 +28,20 @@ type ProcessDiscoveryCheck struct {
 	initCalled bool
 
 	maxBatchSize int
+
+	// services are the services inferred for the processes of the last run.
+	// The pinned agent-payload has no field for them in the ProcessDiscovery
+	// model, so they are served by the process-agent API.
+	servicesMux sync.RWMutex
+	services    []*DiscoveredService
+}
+
+// DiscoveredService is a discovered process with its inferred service
+type DiscoveredService struct {
+	Pid  int32  `json:"pid"`
+	Name string `json:"name"`
+	ServiceInfo
+	Tags []string `json:"tags,omitempty"`
 }
 
 // Init initializes the ProcessDiscoveryCheck. It is a runtime error to call Run without first having called Init.
@@ -61,6 +77,8 @@ func (d *ProcessDiscoveryCheck) Run(cfg *config.AgentConfig, groupID int32) ([]m
 		NumCpus:     calculateNumCores(d.info),
 		TotalMemory: d.info.TotalMemory,
 	}
+	d.setServices(inferServices(procs, collectServiceHints(procs)))
+
 	procDiscoveryChunks := chunkProcessDiscoveries(pidMapToProcDiscoveries(procs), d.maxBatchSize)
 	payload := make([]model.MessageBody, len(procDiscoveryChunks))
 	for i, procDiscoveryChunk := range procDiscoveryChunks {
@@ -91,6 +109,35 @@ func pidMapToProcDiscoveries(pidMap map[int32]*procutil.Process) []*model.Proces
 	return pd
 }
 
+// Services returns the services inferred for the processes discovered by the
+// last run, sorted by PID
+func (d *ProcessDiscoveryCheck) Services() []*DiscoveredService {
+	d.servicesMux.RLock()
+	defer d.servicesMux.RUnlock()
+	return d.services
+}
+
+func (d *ProcessDiscoveryCheck) setServices(services []*DiscoveredService) {
+	d.servicesMux.Lock()
+	defer d.servicesMux.Unlock()
+	d.services = services
+}
+
+func inferServices(pidMap map[int32]*procutil.Process, hints map[int32]serviceHints) []*DiscoveredService {
+	services := make([]*DiscoveredService, 0, len(pidMap))
+	for pid, proc := range pidMap {
+		info := inferServiceInfo(proc, hints[pid])
+		services = append(services, &DiscoveredService{
+			Pid:         pid,
+			Name:        proc.Name,
+			ServiceInfo: *info,
+			Tags:        info.Tags(),
+		})
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].Pid < services[j].Pid })
+	return services
+}
+
 // chunkProcessDiscoveries split non-container processes into chunks and return a list of chunks
 // This function is patiently awaiting go to support generics, so that we don't need two chunkProcesses functions :)
 func chunkProcessDiscoveries(procs []*model.ProcessDiscovery, size int) [][]*model.ProcessDiscovery {
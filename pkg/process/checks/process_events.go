@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"errors"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+// ErrProcessEventsDisabled is returned when the process lifecycle events are
+// requested while their collection is disabled
+var ErrProcessEventsDisabled = errors.New("process event collection is disabled, see process_config.event_collection.enabled")
+
+// ProcessEventsPayload holds the process lifecycle events collected since a
+// cursor. The agent-payload has no process event model yet so the events are
+// served as JSON by the process-agent API.
+type ProcessEventsPayload struct {
+	Hostname string `json:"hostname"`
+	// Mode is how the events are collected, netlink or polling
+	Mode   string          `json:"mode"`
+	Events []*ProcessEvent `json:"events"`
+	// NextCursor is the cursor to request the following events with
+	NextCursor uint64 `json:"next_cursor"`
+	// Dropped is the number of events dropped before they were requested
+	Dropped int64 `json:"dropped"`
+}
+
+// ProcessEvent is a process lifecycle event of a ProcessEventsPayload
+type ProcessEvent struct {
+	Type      string    `json:"type"`
+	Pid       int32     `json:"pid"`
+	Ppid      int32     `json:"ppid,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"name,omitempty"`
+	Exe       string    `json:"exe,omitempty"`
+	Cmdline   []string  `json:"cmdline,omitempty"`
+	Uid       *int32    `json:"uid,omitempty"`
+	Euid      *int32    `json:"euid,omitempty"`
+	ExitCode  *int32    `json:"exit_code,omitempty"`
+	Signal    int32     `json:"signal,omitempty"`
+	// DurationMs is how long the process ran, for the exit events
+	DurationMs int64 `json:"duration_ms,omitempty"`
+}
+
+// ProcessEvents returns the process lifecycle events collected after cursor
+func (p *ProcessCheck) ProcessEvents(cursor uint64) (*ProcessEventsPayload, error) {
+	if p.eventCollector == nil {
+		return nil, ErrProcessEventsDisabled
+	}
+
+	table := p.eventCollector.Table()
+	events, next := table.EventsAfter(cursor)
+	return &ProcessEventsPayload{
+		Hostname:   p.hostName,
+		Mode:       p.eventCollector.Mode(),
+		Events:     fmtProcessEvents(p.scrubber, events),
+		NextCursor: next,
+		Dropped:    table.DroppedEvents(),
+	}, nil
+}
+
+func fmtProcessEvents(scrubber *config.DataScrubber, events []*procutil.ProcessEvent) []*ProcessEvent {
+	formatted := make([]*ProcessEvent, 0, len(events))
+	for _, e := range events {
+		fe := &ProcessEvent{
+			Type:      e.Type.String(),
+			Pid:       e.Pid,
+			Ppid:      e.Ppid,
+			Timestamp: e.Timestamp,
+		}
+		switch e.Type {
+		case procutil.ProcessEventExec:
+			if e.Process != nil {
+				fe.Name = e.Process.Name
+				fe.Exe = e.Process.Exe
+				fe.Cmdline = scrubber.ScrubCommandline(e.Process.Cmdline)
+			}
+		case procutil.ProcessEventUIDChange:
+			uid, euid := e.Uid, e.Euid
+			fe.Uid, fe.Euid = &uid, &euid
+		case procutil.ProcessEventExit:
+			exitCode := e.ExitCode
+			fe.ExitCode = &exitCode
+			fe.Signal = e.Signal
+			if lc := e.Lifecycle; lc != nil {
+				fe.Ppid = lc.Ppid
+				fe.Name = lc.Name
+				fe.Exe = lc.Exe
+				fe.Cmdline = scrubber.ScrubCommandline(lc.Cmdline)
+				fe.DurationMs = lc.Duration(e.Timestamp).Milliseconds()
+			}
+		}
+		formatted = append(formatted, fe)
+	}
+	return formatted
+}
+
+// fmtExitedProcesses converts the processes which exited without being
+// collected by any run of the check, because they lived shorter than the check
+// interval, and groups them by container
+func fmtExitedProcesses(
+	cfg *config.AgentConfig,
+	exited []*procutil.ProcessLifecycle,
+	procs, lastProcs map[int32]*procutil.Process,
+	ctrByProc map[int]string,
+	procsByCtr map[string][]*model.Process,
+) {
+	for _, lc := range exited {
+		if _, ok := lastProcs[lc.Pid]; ok {
+			continue
+		}
+		if _, ok := procs[lc.Pid]; ok {
+			continue
+		}
+
+		var createTime int64
+		if !lc.StartTime.IsZero() {
+			createTime = lc.StartTime.UnixNano() / int64(time.Millisecond)
+		}
+		fp := &procutil.Process{
+			Pid:     lc.Pid,
+			Ppid:    lc.Ppid,
+			Name:    lc.Name,
+			Exe:     lc.Exe,
+			Cmdline: lc.Cmdline,
+			Uids:    lc.Uids,
+			Gids:    lc.Gids,
+			Stats:   &procutil.Stats{CreateTime: createTime},
+		}
+		if len(fp.Cmdline) == 0 || config.IsBlacklisted(fp.Cmdline, cfg.Blacklist) {
+			continue
+		}
+		fp.Cmdline = cfg.Scrubber.ScrubProcessCommand(fp)
+		proc := &model.Process{
+			Pid:         fp.Pid,
+			Command:     formatCommand(fp),
+			User:        formatUser(fp),
+			Memory:      &model.MemoryStat{},
+			Cpu:         &model.CPUStat{},
+			IoStat:      &model.IOStat{},
+			CreateTime:  fp.Stats.CreateTime,
+			State:       model.ProcessState_X,
+			ContainerId: ctrByProc[int(fp.Pid)],
+		}
+		procsByCtr[proc.ContainerId] = append(procsByCtr[proc.ContainerId], proc)
+	}
+}
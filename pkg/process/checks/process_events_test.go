@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+func TestFmtExitedProcesses(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+	cfg.Blacklist = []*regexp.Regexp{regexp.MustCompile("^blacklisted")}
+
+	start := time.Unix(1000, 0)
+	exited := []*procutil.ProcessLifecycle{
+		{Pid: 1, Cmdline: []string{"reported", "by", "the", "last", "run"}, Exited: true},
+		{Pid: 2, Cmdline: []string{"mysql", "--password=secret"}, Ppid: 1, StartTime: start, Exited: true},
+		{Pid: 3, Cmdline: []string{"blacklisted"}, Exited: true},
+		{Pid: 4, Exited: true},
+		{Pid: 5, Cmdline: []string{"pid", "reused"}, Exited: true},
+	}
+	lastProcs := procsToHash([]*procutil.Process{makeProcess(1, "reported by the last run")})
+	procs := procsToHash([]*procutil.Process{makeProcess(5, "new process")})
+	procsByCtr := map[string][]*model.Process{}
+
+	fmtExitedProcesses(cfg, exited, procs, lastProcs, map[int]string{2: "ctr"}, procsByCtr)
+
+	require.Len(t, procsByCtr["ctr"], 1)
+	assert.Empty(t, procsByCtr[emptyCtrID])
+	proc := procsByCtr["ctr"][0]
+	assert.Equal(t, int32(2), proc.Pid)
+	assert.Equal(t, model.ProcessState_X, proc.State)
+	assert.Equal(t, int64(1000000), proc.CreateTime)
+	assert.Equal(t, []string{"mysql", "--password=********"}, proc.Command.Args)
+	assert.Equal(t, int32(1), proc.Command.Ppid)
+}
+
+func TestFmtProcessEvents(t *testing.T) {
+	scrubber := config.NewDefaultDataScrubber()
+	start := time.Unix(1000, 0)
+
+	table := procutil.NewProcessTable()
+	table.Handle(&procutil.ProcessEvent{Type: procutil.ProcessEventFork, Pid: 2, Ppid: 1, Timestamp: start})
+	table.Handle(&procutil.ProcessEvent{
+		Type:      procutil.ProcessEventExec,
+		Pid:       2,
+		Ppid:      1,
+		Timestamp: start,
+		Process:   &procutil.Process{Pid: 2, Name: "mysql", Cmdline: []string{"mysql", "--password=secret"}},
+	})
+	table.Handle(&procutil.ProcessEvent{Type: procutil.ProcessEventUIDChange, Pid: 2, Uid: 1000, Euid: 0, Timestamp: start})
+	table.Handle(&procutil.ProcessEvent{Type: procutil.ProcessEventExit, Pid: 2, ExitCode: 1, Timestamp: start.Add(1500 * time.Millisecond)})
+
+	events, _ := table.EventsAfter(0)
+	formatted := fmtProcessEvents(scrubber, events)
+	require.Len(t, formatted, 4)
+
+	assert.Equal(t, []string{"fork", "exec", "uid_change", "exit"}, []string{formatted[0].Type, formatted[1].Type, formatted[2].Type, formatted[3].Type})
+	assert.Equal(t, []string{"mysql", "--password=********"}, formatted[1].Cmdline)
+	assert.Equal(t, int32(0), *formatted[2].Euid)
+	assert.Nil(t, formatted[2].ExitCode)
+
+	exit := formatted[3]
+	assert.Equal(t, int32(1), *exit.ExitCode)
+	assert.Equal(t, int32(1), exit.Ppid)
+	assert.Equal(t, "mysql", exit.Name)
+	assert.Equal(t, int64(1500), exit.DurationMs)
+}
+
+func TestProcessEventsDisabled(t *testing.T) {
+	_, err := (&ProcessCheck{}).ProcessEvents(0)
+	assert.Equal(t, ErrProcessEventsDisabled, err)
+}
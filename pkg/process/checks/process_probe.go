@@ -8,6 +8,7 @@ package checks
 import (
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/process/procutil"
@@ -34,3 +35,26 @@ func getProcessProbe() procutil.Probe {
 	})
 	return processProbe
 }
+
+var (
+	processEventCollector     *procutil.ProcessEventCollector
+	processEventCollectorOnce sync.Once
+)
+
+// getProcessEventCollector returns the collector of the process lifecycle
+// events, or nil if the event collection is disabled
+func getProcessEventCollector() *procutil.ProcessEventCollector {
+	processEventCollectorOnce.Do(func() {
+		if runtime.GOOS != "linux" || !config.Datadog.GetBool("process_config.event_collection.enabled") {
+			return
+		}
+		interval := config.Datadog.GetDuration("process_config.event_collection.interval")
+		if interval <= 0 {
+			log.Warnf("Invalid process_config.event_collection.interval -- %s, using default value of 10s", interval)
+			interval = 10 * time.Second
+		}
+		processEventCollector = procutil.NewProcessEventCollector(getProcessProbe(), interval)
+		processEventCollector.Start()
+	})
+	return processEventCollector
+}
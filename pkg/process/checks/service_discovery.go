@@ -0,0 +1,358 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+// Languages inferred for the discovered processes
+const (
+	LanguageUnknown = ""
+	LanguageJava    = "java"
+	LanguagePython  = "python"
+	LanguageNode    = "node"
+	LanguageRuby    = "ruby"
+	LanguageDotnet  = "dotnet"
+	LanguageGo      = "go"
+)
+
+// Sources of the service names inferred for the discovered processes
+const (
+	ServiceSourceEnv     = "env"
+	ServiceSourceCmdline = "cmdline"
+	ServiceSourcePort    = "port"
+	ServiceSourceName    = "process_name"
+)
+
+// ServiceInfo is the language and probable service name inferred for a
+// discovered process
+type ServiceInfo struct {
+	Language      string   `json:"language,omitempty"`
+	Service       string   `json:"service,omitempty"`
+	ServiceSource string   `json:"service_source,omitempty"`
+	Ports         []uint16 `json:"ports,omitempty"`
+}
+
+// Tags returns the tags describing the inferred service
+func (s *ServiceInfo) Tags() []string {
+	var tags []string
+	if s.Language != LanguageUnknown {
+		tags = append(tags, "language:"+s.Language)
+	}
+	if s.Service != "" {
+		tags = append(tags, "service:"+s.Service)
+	}
+	return tags
+}
+
+// serviceHints are the information about a process which isn't in its
+// procutil.Process
+type serviceHints struct {
+	// ddService is the DD_SERVICE variable of the environment of the process
+	ddService string
+	// listeningPorts are the TCP ports the process listens on
+	listeningPorts []uint16
+	// goBinary tells whether the executable was built by the Go toolchain
+	goBinary bool
+}
+
+// wellKnownPorts are the services commonly listening on a port, used when the
+// command line of a process doesn't tell its service
+var wellKnownPorts = map[uint16]string{
+	2181:  "zookeeper",
+	3306:  "mysql",
+	5432:  "postgres",
+	5672:  "rabbitmq",
+	6379:  "redis",
+	8500:  "consul",
+	9042:  "cassandra",
+	9092:  "kafka",
+	9200:  "elasticsearch",
+	11211: "memcached",
+	27017: "mongodb",
+}
+
+var (
+	// versionSuffix matches the version suffix of jar names, e.g. -1.2.3-SNAPSHOT
+	versionSuffix = regexp.MustCompile(`[-_]v?\d+(\.\d+)*([-.][A-Za-z0-9]+)*$`)
+
+	// genericScripts are the entrypoint names telling nothing about the
+	// service, their directory is used instead
+	genericScripts = map[string]struct{}{
+		"app":    {},
+		"index":  {},
+		"main":   {},
+		"manage": {},
+		"server": {},
+		"start":  {},
+		"wsgi":   {},
+		"asgi":   {},
+	}
+
+	genericDirs = map[string]struct{}{
+		"app":   {},
+		"bin":   {},
+		"build": {},
+		"dist":  {},
+		"lib":   {},
+		"src":   {},
+	}
+
+	pythonLaunchers = map[string]struct{}{
+		"gunicorn": {},
+		"uwsgi":    {},
+		"celery":   {},
+		"uvicorn":  {},
+		"daphne":   {},
+	}
+
+	rubyLaunchers = map[string]struct{}{
+		"puma":    {},
+		"unicorn": {},
+		"rails":   {},
+		"rackup":  {},
+		"sidekiq": {},
+		"bundle":  {},
+	}
+)
+
+// inferServiceInfo infers the language and the probable service name of a
+// process from its command line, environment and listening ports
+func inferServiceInfo(proc *procutil.Process, hints serviceHints) *ServiceInfo {
+	info := &ServiceInfo{Ports: hints.listeningPorts}
+	args := proc.Cmdline
+	// the whole command line is sometimes in the first argument
+	if len(args) == 1 {
+		args = strings.Fields(args[0])
+	}
+
+	var service string
+	info.Language, service = inferFromCmdline(proc, args)
+	if info.Language == LanguageUnknown && hints.goBinary {
+		info.Language = LanguageGo
+	}
+
+	switch {
+	case hints.ddService != "":
+		info.Service, info.ServiceSource = hints.ddService, ServiceSourceEnv
+	case service != "":
+		info.Service, info.ServiceSource = normalizeServiceName(service), ServiceSourceCmdline
+	case portService(hints.listeningPorts) != "":
+		info.Service, info.ServiceSource = portService(hints.listeningPorts), ServiceSourcePort
+	default:
+		if name := processName(proc, args); name != "" {
+			info.Service, info.ServiceSource = normalizeServiceName(name), ServiceSourceName
+		}
+	}
+	return info
+}
+
+// inferFromCmdline returns the language and the service name found in the
+// command line of a process, if any
+func inferFromCmdline(proc *procutil.Process, args []string) (string, string) {
+	if len(args) == 0 {
+		return LanguageUnknown, ""
+	}
+
+	exe := executableName(args[0])
+	switch {
+	case exe == "java":
+		return LanguageJava, javaService(args[1:])
+	case strings.HasPrefix(exe, "python") || strings.HasPrefix(exe, "pypy"):
+		return LanguagePython, pythonService(args[1:])
+	case isLauncher(exe, pythonLaunchers):
+		return LanguagePython, launcherService(args[1:])
+	case exe == "node" || exe == "nodejs":
+		return LanguageNode, scriptService(firstArgument(args[1:]), ".js", ".mjs", ".cjs", ".ts")
+	case exe == "ruby":
+		if launcher := firstArgument(args[1:]); launcher != "" && isLauncher(executableName(launcher), rubyLaunchers) {
+			return LanguageRuby, dirService(proc.Cwd)
+		}
+		return LanguageRuby, scriptService(firstArgument(args[1:]), ".rb")
+	case isLauncher(exe, rubyLaunchers):
+		return LanguageRuby, dirService(proc.Cwd)
+	case exe == "dotnet":
+		return LanguageDotnet, scriptService(firstArgument(args[1:]), ".dll")
+	case exe == "w3wp":
+		return LanguageDotnet, ""
+	}
+	return LanguageUnknown, ""
+}
+
+// javaService returns the service name from the system properties, the jar
+// or the main class of a java command line
+func javaService(args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case strings.HasPrefix(arg, "-Ddd.service="):
+			return strings.TrimPrefix(arg, "-Ddd.service=")
+		case arg == "-jar" && i+1 < len(args):
+			return versionSuffix.ReplaceAllString(strings.TrimSuffix(filepath.Base(args[i+1]), ".jar"), "")
+		case arg == "-cp" || arg == "-classpath" || arg == "--class-path" || arg == "-p" || arg == "--module-path":
+			// skip the value of the option
+			i++
+		case arg == "-m" || arg == "--module":
+			if i+1 < len(args) {
+				module := strings.SplitN(args[i+1], "/", 2)[0]
+				return module[strings.LastIndex(module, ".")+1:]
+			}
+		case strings.HasPrefix(arg, "-"):
+		default:
+			// the main class
+			return arg[strings.LastIndex(arg, ".")+1:]
+		}
+	}
+	return ""
+}
+
+// pythonService returns the service name from the module or the script of a
+// python command line
+func pythonService(args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-m" && i+1 < len(args):
+			module := args[i+1]
+			if isLauncher(module, pythonLaunchers) {
+				return launcherService(args[i+2:])
+			}
+			return strings.SplitN(module, ".", 2)[0]
+		case arg == "-c":
+			return ""
+		case arg == "-W" || arg == "-X":
+			i++
+		case strings.HasPrefix(arg, "-"):
+		default:
+			if isLauncher(executableName(arg), pythonLaunchers) {
+				return launcherService(args[i+1:])
+			}
+			return scriptService(arg, ".py")
+		}
+	}
+	return ""
+}
+
+// launcherService returns the service name from the arguments of an
+// application server, such as gunicorn, which are its name option or the
+// module of the application
+func launcherService(args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case (arg == "-n" || arg == "--name") && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(arg, "--name="):
+			return strings.TrimPrefix(arg, "--name=")
+		case arg == "-A" || arg == "--app":
+			if i+1 < len(args) {
+				return strings.SplitN(args[i+1], ".", 2)[0]
+			}
+		case strings.HasPrefix(arg, "--app="):
+			return strings.SplitN(strings.TrimPrefix(arg, "--app="), ".", 2)[0]
+		case strings.HasPrefix(arg, "-"):
+			// the options taking a value are written with = by convention,
+			// except the common ones
+			if len(arg) == 2 && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				i++
+			}
+		case strings.Contains(arg, ":"):
+			// module:application
+			module := strings.SplitN(arg, ":", 2)[0]
+			return strings.SplitN(module, ".", 2)[0]
+		}
+	}
+	return ""
+}
+
+// scriptService returns the service name from the path of the script run by
+// an interpreter, using its directory for the generic script names
+func scriptService(script string, extensions ...string) string {
+	if script == "" {
+		return ""
+	}
+	name := filepath.Base(script)
+	for _, ext := range extensions {
+		name = strings.TrimSuffix(name, ext)
+	}
+	if _, ok := genericScripts[strings.ToLower(name)]; ok {
+		return dirService(filepath.Dir(script))
+	}
+	return name
+}
+
+// dirService returns the service name from a directory of the application,
+// skipping the directories with generic names
+func dirService(dir string) string {
+	for dir != "" && dir != "." && dir != "/" {
+		base := filepath.Base(dir)
+		if _, ok := genericDirs[base]; !ok {
+			return base
+		}
+		dir = filepath.Dir(dir)
+	}
+	return ""
+}
+
+// portService returns the service commonly listening on one of the ports
+func portService(ports []uint16) string {
+	for _, port := range ports {
+		if service, ok := wellKnownPorts[port]; ok {
+			return service
+		}
+	}
+	return ""
+}
+
+func processName(proc *procutil.Process, args []string) string {
+	if proc.Name != "" {
+		return proc.Name
+	}
+	if len(args) > 0 {
+		return executableName(args[0])
+	}
+	return ""
+}
+
+// firstArgument returns the first argument which is not an option
+func firstArgument(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
+
+// executableName returns the name of an executable without its directory,
+// extension or version, e.g. python3.9 for /usr/bin/python3.9
+func executableName(path string) string {
+	name := filepath.Base(strings.ReplaceAll(path, `\`, "/"))
+	return strings.ToLower(strings.TrimSuffix(name, ".exe"))
+}
+
+func isLauncher(name string, launchers map[string]struct{}) bool {
+	_, ok := launchers[name]
+	return ok
+}
+
+// normalizeServiceName lowercases a service name and replaces the characters
+// not allowed in tag values
+func normalizeServiceName(name string) string {
+	name = strings.ToLower(name)
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+}
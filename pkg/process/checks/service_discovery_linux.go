@@ -0,0 +1,153 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package checks
+
+import (
+	"bufio"
+	"bytes"
+	"debug/elf"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// tcpListenState is the state of the listening sockets in /proc/net/tcp
+const tcpListenState = "0A"
+
+// collectServiceHints reads the environment, the listening ports and the
+// executable of the processes from procfs, with best effort since most of
+// them require the same user as the process
+func collectServiceHints(procs map[int32]*procutil.Process) map[int32]serviceHints {
+	procRoot := util.HostProc()
+
+	// the listening sockets by inode, per network namespace
+	listenersByNetNS := make(map[string]map[string]uint16)
+	hints := make(map[int32]serviceHints, len(procs))
+	for pid, proc := range procs {
+		pidPath := filepath.Join(procRoot, strconv.Itoa(int(pid)))
+
+		var h serviceHints
+		h.ddService = readEnvVar(pidPath, "DD_SERVICE")
+
+		netNS, err := os.Readlink(filepath.Join(pidPath, "ns", "net"))
+		if err == nil {
+			listeners, ok := listenersByNetNS[netNS]
+			if !ok {
+				listeners = readListeningSockets(pidPath)
+				listenersByNetNS[netNS] = listeners
+			}
+			if len(listeners) > 0 {
+				h.listeningPorts = listeningPorts(pidPath, listeners)
+			}
+		}
+
+		if lang, _ := inferFromCmdline(proc, proc.Cmdline); lang == LanguageUnknown {
+			h.goBinary = isGoBinary(filepath.Join(pidPath, "exe"))
+		}
+		hints[pid] = h
+	}
+	return hints
+}
+
+// readEnvVar returns the value of a variable of the environment of a process
+func readEnvVar(pidPath, name string) string {
+	environ, err := ioutil.ReadFile(filepath.Join(pidPath, "environ"))
+	if err != nil {
+		return ""
+	}
+	prefix := []byte(name + "=")
+	for _, kv := range bytes.Split(environ, []byte{0}) {
+		if bytes.HasPrefix(kv, prefix) {
+			return string(kv[len(prefix):])
+		}
+	}
+	return ""
+}
+
+// readListeningSockets returns the ports of the listening TCP sockets of the
+// network namespace of a process, by socket inode
+func readListeningSockets(pidPath string) map[string]uint16 {
+	listeners := make(map[string]uint16)
+	for _, file := range []string{"tcp", "tcp6"} {
+		f, err := os.Open(filepath.Join(pidPath, "net", file))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		// skip the header
+		scanner.Scan()
+		for scanner.Scan() {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 || fields[3] != tcpListenState {
+				continue
+			}
+			idx := strings.LastIndexByte(fields[1], ':')
+			if idx < 0 {
+				continue
+			}
+			port, err := strconv.ParseUint(fields[1][idx+1:], 16, 16)
+			if err != nil {
+				continue
+			}
+			listeners[fields[9]] = uint16(port)
+		}
+		f.Close()
+	}
+	return listeners
+}
+
+// listeningPorts returns the ports of the listening sockets among the file
+// descriptors of a process
+func listeningPorts(pidPath string, listeners map[string]uint16) []uint16 {
+	fdPath := filepath.Join(pidPath, "fd")
+	fds, err := ioutil.ReadDir(fdPath)
+	if err != nil {
+		return nil
+	}
+
+	ports := make(map[uint16]struct{})
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(fdPath, fd.Name()))
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+		if port, ok := listeners[inode]; ok {
+			ports[port] = struct{}{}
+		}
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+
+	sorted := make([]uint16, 0, len(ports))
+	for port := range ports {
+		sorted = append(sorted, port)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// isGoBinary returns whether an executable was built by the Go toolchain,
+// which adds a section with the build information to the binaries
+func isGoBinary(path string) bool {
+	f, err := elf.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	return f.Section(".go.buildinfo") != nil
+}
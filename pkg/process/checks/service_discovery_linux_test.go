@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package checks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadServiceHints(t *testing.T) {
+	pidPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(pidPath, "net"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(pidPath, "fd"), 0755))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pidPath, "environ"), []byte("PATH=/bin\x00DD_SERVICE=billing\x00DD_ENV=prod\x00"), 0644))
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:D2F0 0100007F:1F90 01 00000000:00000000 00:00000000 00000000     0        0 1003 1 0000000000000000 20 4 30 10 -1
+`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pidPath, "net", "tcp"), []byte(tcp), 0644))
+	for fd, target := range map[string]string{"3": "socket:[1002]", "4": "socket:[1001]", "5": "socket:[1003]", "6": "/var/log/app.log"} {
+		require.NoError(t, os.Symlink(target, filepath.Join(pidPath, "fd", fd)))
+	}
+
+	assert.Equal(t, "billing", readEnvVar(pidPath, "DD_SERVICE"))
+	assert.Empty(t, readEnvVar(pidPath, "DD_VERSION"))
+
+	listeners := readListeningSockets(pidPath)
+	assert.Equal(t, map[string]uint16{"1001": 8080, "1002": 5432}, listeners)
+	assert.Equal(t, []uint16{5432, 8080}, listeningPorts(pidPath, listeners))
+}
+
+func TestIsGoBinary(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	assert.True(t, isGoBinary(exe))
+	assert.False(t, isGoBinary("/nonexistent"))
+}
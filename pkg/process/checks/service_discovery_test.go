@@ -0,0 +1,188 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+func TestInferServiceInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		proc     *procutil.Process
+		hints    serviceHints
+		language string
+		service  string
+		source   string
+	}{
+		{
+			name:     "java jar",
+			proc:     &procutil.Process{Name: "java", Cmdline: []string{"/usr/bin/java", "-Xmx1g", "-jar", "/opt/app/billing-api-1.2.3-SNAPSHOT.jar"}},
+			language: LanguageJava,
+			service:  "billing-api",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "java system property",
+			proc:     &procutil.Process{Name: "java", Cmdline: []string{"java", "-Ddd.service=Checkout", "-jar", "app.jar"}},
+			language: LanguageJava,
+			service:  "checkout",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "java main class",
+			proc:     &procutil.Process{Name: "java", Cmdline: []string{"java", "-cp", "lib/*:conf", "org.apache.zookeeper.server.quorum.QuorumPeerMain"}},
+			language: LanguageJava,
+			service:  "quorumpeermain",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "java in a single argument",
+			proc:     &procutil.Process{Name: "java", Cmdline: []string{"java -jar orders.jar"}},
+			language: LanguageJava,
+			service:  "orders",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "gunicorn module",
+			proc:     &procutil.Process{Name: "gunicorn", Cmdline: []string{"/usr/local/bin/gunicorn", "-w", "4", "-b", "0.0.0.0:8000", "shop.wsgi:application"}},
+			language: LanguagePython,
+			service:  "shop",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "gunicorn name",
+			proc:     &procutil.Process{Name: "gunicorn", Cmdline: []string{"gunicorn", "--name", "inventory", "app:app"}},
+			language: LanguagePython,
+			service:  "inventory",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "python gunicorn",
+			proc:     &procutil.Process{Name: "python3", Cmdline: []string{"/usr/bin/python3", "/usr/local/bin/gunicorn", "payments.app:create_app()"}},
+			language: LanguagePython,
+			service:  "payments",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "python module",
+			proc:     &procutil.Process{Name: "python3.9", Cmdline: []string{"python3.9", "-u", "-m", "worker.main"}},
+			language: LanguagePython,
+			service:  "worker",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "python generic script",
+			proc:     &procutil.Process{Name: "python", Cmdline: []string{"python", "/srv/blog/manage.py", "runserver"}},
+			language: LanguagePython,
+			service:  "blog",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "celery app",
+			proc:     &procutil.Process{Name: "celery", Cmdline: []string{"celery", "-A", "tasks.celery", "worker"}},
+			language: LanguagePython,
+			service:  "tasks",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "node entrypoint",
+			proc:     &procutil.Process{Name: "node", Cmdline: []string{"node", "--max-old-space-size=512", "/srv/frontend/dist/index.js"}},
+			language: LanguageNode,
+			service:  "frontend",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "node script",
+			proc:     &procutil.Process{Name: "node", Cmdline: []string{"node", "notifier.js"}},
+			language: LanguageNode,
+			service:  "notifier",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "ruby puma",
+			proc:     &procutil.Process{Name: "ruby", Cwd: "/srv/storefront", Cmdline: []string{"ruby", "/usr/local/bundle/bin/puma", "-C", "config/puma.rb"}},
+			language: LanguageRuby,
+			service:  "storefront",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "dotnet dll",
+			proc:     &procutil.Process{Name: "dotnet", Cmdline: []string{"dotnet", "/app/Catalog.Api.dll"}},
+			language: LanguageDotnet,
+			service:  "catalog.api",
+			source:   ServiceSourceCmdline,
+		},
+		{
+			name:     "DD_SERVICE overrides the command line",
+			proc:     &procutil.Process{Name: "java", Cmdline: []string{"java", "-jar", "app.jar"}},
+			hints:    serviceHints{ddService: "my-service"},
+			language: LanguageJava,
+			service:  "my-service",
+			source:   ServiceSourceEnv,
+		},
+		{
+			name:    "well-known port",
+			proc:    &procutil.Process{Name: "postgres", Cmdline: []string{"postgres: checkpointer"}},
+			hints:   serviceHints{listeningPorts: []uint16{8080, 5432}},
+			service: "postgres",
+			source:  ServiceSourcePort,
+		},
+		{
+			name:     "go binary",
+			proc:     &procutil.Process{Name: "api-server", Cmdline: []string{"/usr/local/bin/api-server", "--port", "8080"}},
+			hints:    serviceHints{goBinary: true},
+			language: LanguageGo,
+			service:  "api-server",
+			source:   ServiceSourceName,
+		},
+		{
+			name:    "process name",
+			proc:    &procutil.Process{Name: "nginx", Cmdline: []string{"nginx: master process /usr/sbin/nginx"}},
+			service: "nginx",
+			source:  ServiceSourceName,
+		},
+		{
+			name: "no command line",
+			proc: &procutil.Process{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := inferServiceInfo(tt.proc, tt.hints)
+			assert.Equal(t, tt.language, info.Language)
+			assert.Equal(t, tt.service, info.Service)
+			assert.Equal(t, tt.source, info.ServiceSource)
+		})
+	}
+}
+
+func TestServiceInfoTags(t *testing.T) {
+	info := &ServiceInfo{Language: LanguagePython, Service: "shop"}
+	assert.Equal(t, []string{"language:python", "service:shop"}, info.Tags())
+	assert.Empty(t, (&ServiceInfo{}).Tags())
+}
+
+func TestInferServices(t *testing.T) {
+	procs := map[int32]*procutil.Process{
+		2: {Pid: 2, Name: "node", Cmdline: []string{"node", "api.js"}},
+		1: {Pid: 1, Name: "redis-server", Cmdline: []string{"redis-server *:6379"}},
+	}
+	hints := map[int32]serviceHints{1: {listeningPorts: []uint16{6379}}}
+
+	services := inferServices(procs, hints)
+	require.Len(t, services, 2)
+	assert.Equal(t, int32(1), services[0].Pid)
+	assert.Equal(t, []string{"service:redis"}, services[0].Tags)
+	assert.Equal(t, []uint16{6379}, services[0].Ports)
+	assert.Equal(t, int32(2), services[1].Pid)
+	assert.Equal(t, []string{"language:node", "service:api"}, services[1].Tags)
+}
@@ -0,0 +1,17 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !linux
+// +build !linux
+
+package checks
+
+import "github.com/DataDog/datadog-agent/pkg/process/procutil"
+
+// collectServiceHints returns no hints, the services are only inferred from
+// the command lines of the processes
+func collectServiceHints(procs map[int32]*procutil.Process) map[int32]serviceHints {
+	return nil
+}
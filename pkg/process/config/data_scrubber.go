@@ -146,6 +146,19 @@ func (ds *DataScrubber) ScrubProcessCommand(p *procutil.Process) []string {
 	return p.Cmdline
 }
 
+// ScrubCommandline scrubs a cmdline like ScrubProcessCommand but without the
+// cache, so that it can be used concurrently with the checks
+func (ds *DataScrubber) ScrubCommandline(cmdline []string) []string {
+	if ds.StripAllArguments {
+		return ds.stripArguments(cmdline)
+	}
+	if !ds.Enabled {
+		return cmdline
+	}
+	scrubbed, _ := ds.ScrubCommand(cmdline)
+	return scrubbed
+}
+
 // IncrementCacheAge increments one cycle of cache memory age. If it reaches
 // cacheMaxCycles, the cache is restarted
 func (ds *DataScrubber) IncrementCacheAge() {
@@ -374,6 +374,18 @@ func TestScrubberStrippingAllArgument(t *testing.T) {
 	}
 }
 
+func TestScrubCommandline(t *testing.T) {
+	scrubber := setupDataScrubber(t)
+	cmdline := []string{"agent", "--password=1234"}
+	assert.Equal(t, []string{"agent", "--password=********"}, scrubber.ScrubCommandline(cmdline))
+
+	scrubber.Enabled = false
+	assert.Equal(t, cmdline, scrubber.ScrubCommandline(cmdline))
+
+	scrubber.StripAllArguments = true
+	assert.Equal(t, []string{"agent"}, scrubber.ScrubCommandline(cmdline))
+}
+
 func TestNoBlacklistedArgs(t *testing.T) {
 	cases := setupInsensitiveCmdlines()
 	scrubber := setupDataScrubber(t)
@@ -0,0 +1,447 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package procutil
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ProcessEventType is the type of a process lifecycle event
+type ProcessEventType int
+
+// Process lifecycle event types
+const (
+	ProcessEventFork ProcessEventType = iota
+	ProcessEventExec
+	ProcessEventExit
+	ProcessEventUIDChange
+)
+
+// UnknownExitCode is the exit code of the processes whose exit wasn't reported
+// by the kernel, when the events are polled
+const UnknownExitCode = -1
+
+const (
+	// defaultMaxProcessEvents is the number of events kept in the table for
+	// the consumers lagging behind
+	defaultMaxProcessEvents = 10000
+	// defaultMaxExitedProcesses is the number of exited processes kept in the
+	// table for the consumers lagging behind
+	defaultMaxExitedProcesses = 10000
+)
+
+// String returns the name of the event type
+func (t ProcessEventType) String() string {
+	switch t {
+	case ProcessEventFork:
+		return "fork"
+	case ProcessEventExec:
+		return "exec"
+	case ProcessEventExit:
+		return "exit"
+	case ProcessEventUIDChange:
+		return "uid_change"
+	}
+	return "unknown"
+}
+
+// ProcessEvent is a process lifecycle event
+type ProcessEvent struct {
+	Type      ProcessEventType
+	Pid       int32
+	Ppid      int32
+	Timestamp time.Time
+	// Uid and Euid are the real and effective users of a uid_change event
+	Uid  int32
+	Euid int32
+	// ExitCode and Signal are set for exit events, Signal is the signal
+	// which terminated the process or 0
+	ExitCode int32
+	Signal   int32
+	// Process holds the metadata of the process read from procfs for exec
+	// events, it may be nil if the process exited before it could be read
+	Process *Process
+	// Lifecycle is the lifecycle of the process of an exit event, it is set
+	// when the event is handled by a ProcessTable
+	Lifecycle *ProcessLifecycle
+}
+
+// ProcessLifecycle is the lifecycle of a process tracked by a ProcessTable
+type ProcessLifecycle struct {
+	Pid     int32
+	Ppid    int32
+	Name    string
+	Exe     string
+	Cmdline []string
+	Uids    []int32
+	Gids    []int32
+	// StartTime is the time of the last exec of the process, or its fork
+	// or creation time if it didn't exec since it was tracked
+	StartTime time.Time
+	ExitTime  time.Time
+	ExitCode  int32
+	Signal    int32
+	Exited    bool
+}
+
+// Duration returns how long the process ran, or has been running for
+func (p *ProcessLifecycle) Duration(now time.Time) time.Duration {
+	if p.StartTime.IsZero() {
+		return 0
+	}
+	if p.Exited {
+		return p.ExitTime.Sub(p.StartTime)
+	}
+	return now.Sub(p.StartTime)
+}
+
+func (p *ProcessLifecycle) setMetadata(proc *Process) {
+	p.Name = proc.Name
+	p.Exe = proc.Exe
+	p.Cmdline = proc.Cmdline
+	p.Uids = proc.Uids
+	p.Gids = proc.Gids
+}
+
+// sequenced associates a value of the table to its sequence number
+type sequenced struct {
+	seq   uint64
+	event *ProcessEvent
+	proc  *ProcessLifecycle
+}
+
+// ProcessTable is the live table of the processes built from the lifecycle
+// events. Its consumers read the events and the exited processes since their
+// last read through sequence numbers, so that they don't interfere with each
+// other.
+type ProcessTable struct {
+	mux sync.RWMutex
+
+	live    map[int32]*ProcessLifecycle
+	events  []sequenced
+	exited  []sequenced
+	lastSeq uint64
+
+	maxEvents int
+	maxExited int
+	dropped   int64
+}
+
+// NewProcessTable returns an empty ProcessTable
+func NewProcessTable() *ProcessTable {
+	return &ProcessTable{
+		live:      make(map[int32]*ProcessLifecycle),
+		maxEvents: defaultMaxProcessEvents,
+		maxExited: defaultMaxExitedProcesses,
+	}
+}
+
+// seed adds the processes running when the table starts tracking events
+func (t *ProcessTable) seed(procs map[int32]*Process) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	for pid, proc := range procs {
+		lc := &ProcessLifecycle{Pid: pid, Ppid: proc.Ppid}
+		lc.setMetadata(proc)
+		if proc.Stats != nil && proc.Stats.CreateTime > 0 {
+			lc.StartTime = time.Unix(0, proc.Stats.CreateTime*int64(time.Millisecond))
+		}
+		t.live[pid] = lc
+	}
+}
+
+// Handle updates the table with an event
+func (t *ProcessTable) Handle(e *ProcessEvent) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	lc, ok := t.live[e.Pid]
+	if !ok && e.Type != ProcessEventFork {
+		// the process started before the table tracked it
+		lc = &ProcessLifecycle{Pid: e.Pid, Ppid: e.Ppid}
+		t.live[e.Pid] = lc
+	}
+
+	switch e.Type {
+	case ProcessEventFork:
+		lc = &ProcessLifecycle{Pid: e.Pid, Ppid: e.Ppid, StartTime: e.Timestamp}
+		// the child runs the program of its parent until it execs
+		if parent, ok := t.live[e.Ppid]; ok {
+			lc.Name = parent.Name
+			lc.Exe = parent.Exe
+			lc.Cmdline = parent.Cmdline
+			lc.Uids = parent.Uids
+			lc.Gids = parent.Gids
+		}
+		t.live[e.Pid] = lc
+	case ProcessEventExec:
+		lc.StartTime = e.Timestamp
+		if e.Ppid != 0 {
+			lc.Ppid = e.Ppid
+		}
+		if e.Process != nil {
+			lc.setMetadata(e.Process)
+		}
+	case ProcessEventUIDChange:
+		// the saved and filesystem users follow the effective one
+		lc.Uids = []int32{e.Uid, e.Euid, e.Euid, e.Euid}
+	case ProcessEventExit:
+		lc.Exited = true
+		lc.ExitTime = e.Timestamp
+		lc.ExitCode = e.ExitCode
+		lc.Signal = e.Signal
+		e.Lifecycle = lc
+		delete(t.live, e.Pid)
+		t.lastSeq++
+		t.exited = appendBounded(t.exited, sequenced{seq: t.lastSeq, proc: lc}, t.maxExited, nil)
+	}
+
+	t.lastSeq++
+	t.events = appendBounded(t.events, sequenced{seq: t.lastSeq, event: e}, t.maxEvents, &t.dropped)
+}
+
+// appendBounded appends v to values, dropping the oldest values beyond max
+func appendBounded(values []sequenced, v sequenced, max int, dropped *int64) []sequenced {
+	if len(values) >= max {
+		n := len(values) - max + 1
+		if dropped != nil {
+			*dropped += int64(n)
+		}
+		values = append(values[:0], values[n:]...)
+	}
+	return append(values, v)
+}
+
+// Get returns the lifecycle of a running process
+func (t *ProcessTable) Get(pid int32) (ProcessLifecycle, bool) {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+
+	lc, ok := t.live[pid]
+	if !ok {
+		return ProcessLifecycle{}, false
+	}
+	return *lc, true
+}
+
+// Len returns the number of running processes
+func (t *ProcessTable) Len() int {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+	return len(t.live)
+}
+
+// EventsAfter returns the events handled after the given sequence number, and
+// the sequence number to use for the next call
+func (t *ProcessTable) EventsAfter(seq uint64) ([]*ProcessEvent, uint64) {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+
+	var events []*ProcessEvent
+	for _, s := range t.events {
+		if s.seq > seq {
+			events = append(events, s.event)
+		}
+	}
+	return events, t.lastSeq
+}
+
+// ExitedAfter returns the processes which exited after the given sequence
+// number, and the sequence number to use for the next call
+func (t *ProcessTable) ExitedAfter(seq uint64) ([]*ProcessLifecycle, uint64) {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+
+	var exited []*ProcessLifecycle
+	for _, s := range t.exited {
+		if s.seq > seq {
+			exited = append(exited, s.proc)
+		}
+	}
+	return exited, t.lastSeq
+}
+
+// DroppedEvents returns the number of events dropped because the consumers
+// didn't read them fast enough
+func (t *ProcessTable) DroppedEvents() int64 {
+	t.mux.RLock()
+	defer t.mux.RUnlock()
+	return t.dropped
+}
+
+// processEventSource sends the process lifecycle events of the host
+type processEventSource interface {
+	// run sends the events to handle until exit is closed
+	run(handle func(*ProcessEvent), exit <-chan struct{})
+	close()
+}
+
+// ProcessEventCollector maintains a ProcessTable from the process lifecycle
+// events reported by the netlink proc connector, or from the diff of the
+// processes polled on an interval when the connector is unavailable
+type ProcessEventCollector struct {
+	probe        Probe
+	pollInterval time.Duration
+	table        *ProcessTable
+	source       processEventSource
+	mode         string
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	exit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// Modes of the ProcessEventCollector
+const (
+	ProcessEventModeNetlink = "netlink"
+	ProcessEventModePolling = "polling"
+)
+
+// NewProcessEventCollector returns a ProcessEventCollector reading the
+// processes with probe, which are polled every pollInterval if the netlink
+// proc connector is unavailable
+func NewProcessEventCollector(probe Probe, pollInterval time.Duration) *ProcessEventCollector {
+	return &ProcessEventCollector{
+		probe:        probe,
+		pollInterval: pollInterval,
+		table:        NewProcessTable(),
+		exit:         make(chan struct{}),
+	}
+}
+
+// Start starts collecting the process lifecycle events
+func (c *ProcessEventCollector) Start() {
+	c.startOnce.Do(func() {
+		source, err := newNetlinkEventSource(c.probe)
+		if err == nil {
+			c.source = source
+			c.mode = ProcessEventModeNetlink
+		} else {
+			log.Warnf("Could not subscribe to the netlink proc connector, falling back to polling the processes every %s: %s", c.pollInterval, err)
+			c.source = newPollingEventSource(c.probe, c.pollInterval)
+			c.mode = ProcessEventModePolling
+		}
+		log.Infof("Collecting the process lifecycle events with the %s mode", c.mode)
+
+		// the netlink source is subscribed first not to miss the processes
+		// started while the table is seeded
+		if procs, err := c.probe.ProcessesByPID(time.Now(), false); err == nil {
+			c.table.seed(procs)
+			if polling, ok := c.source.(*pollingEventSource); ok {
+				polling.last = procs
+			}
+		} else {
+			log.Warnf("Could not list the running processes: %s", err)
+		}
+
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.source.run(c.table.Handle, c.exit)
+		}()
+	})
+}
+
+// Stop stops collecting the process lifecycle events
+func (c *ProcessEventCollector) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.exit)
+		c.wg.Wait()
+		if c.source != nil {
+			c.source.close()
+		}
+	})
+}
+
+// Table returns the live process table
+func (c *ProcessEventCollector) Table() *ProcessTable {
+	return c.table
+}
+
+// Mode returns how the events are collected, netlink or polling
+func (c *ProcessEventCollector) Mode() string {
+	return c.mode
+}
+
+// pollingEventSource synthesizes the exec and exit events by diffing the
+// processes polled on an interval. The short-lived processes are missed and
+// the exit codes are unknown.
+type pollingEventSource struct {
+	probe    Probe
+	interval time.Duration
+	last     map[int32]*Process
+}
+
+func newPollingEventSource(probe Probe, interval time.Duration) *pollingEventSource {
+	return &pollingEventSource{probe: probe, interval: interval}
+}
+
+func (s *pollingEventSource) run(handle func(*ProcessEvent), exit <-chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.poll(now, handle)
+		case <-exit:
+			return
+		}
+	}
+}
+
+// poll sends the events of the processes started or exited since the
+// previous poll
+func (s *pollingEventSource) poll(now time.Time, handle func(*ProcessEvent)) {
+	procs, err := s.probe.ProcessesByPID(now, false)
+	if err != nil {
+		log.Debugf("Could not poll the processes: %s", err)
+		return
+	}
+
+	for pid, last := range s.last {
+		if proc, ok := procs[pid]; ok && createTime(proc) == createTime(last) {
+			continue
+		}
+		handle(&ProcessEvent{
+			Type:      ProcessEventExit,
+			Pid:       pid,
+			Ppid:      last.Ppid,
+			Timestamp: now,
+			ExitCode:  UnknownExitCode,
+		})
+	}
+	for pid, proc := range procs {
+		if last, ok := s.last[pid]; ok && createTime(proc) == createTime(last) {
+			continue
+		}
+		timestamp := now
+		if ct := createTime(proc); ct > 0 {
+			timestamp = time.Unix(0, ct*int64(time.Millisecond))
+		}
+		handle(&ProcessEvent{
+			Type:      ProcessEventExec,
+			Pid:       pid,
+			Ppid:      proc.Ppid,
+			Timestamp: timestamp,
+			Process:   proc,
+		})
+	}
+	s.last = procs
+}
+
+func (s *pollingEventSource) close() {}
+
+func createTime(proc *Process) int64 {
+	if proc.Stats == nil {
+		return 0
+	}
+	return proc.Stats.CreateTime
+}
@@ -0,0 +1,296 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Constants of the netlink proc connector, see include/uapi/linux/cn_proc.h
+// and include/uapi/linux/connector.h
+const (
+	cnIdxProc = 1
+	cnValProc = 1
+
+	procCnMcastListen = 1
+	procCnMcastIgnore = 2
+
+	procEventFork = 0x00000001
+	procEventExec = 0x00000002
+	procEventUID  = 0x00000004
+	procEventExit = 0x80000000
+
+	// cnMsgSize is the size of struct cn_msg
+	cnMsgSize = 20
+	// procEventHeaderSize is the size of the what, cpu and timestamp_ns
+	// fields of struct proc_event preceding the event data
+	procEventHeaderSize = 16
+
+	netlinkReceiveTimeout = time.Second
+)
+
+// netlinkEventSource reads the process lifecycle events from the netlink proc
+// connector, which requires the CAP_NET_ADMIN capability
+type netlinkEventSource struct {
+	fd    int
+	probe *probe
+
+	// monotonicOffset converts the monotonic timestamps of the events to
+	// wall clock times
+	monotonicOffset time.Duration
+}
+
+func newNetlinkEventSource(p Probe) (processEventSource, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_CONNECTOR)
+	if err != nil {
+		return nil, fmt.Errorf("could not create netlink socket: %s", err)
+	}
+
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: cnIdxProc, Pid: uint32(unix.Getpid())}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("could not bind netlink socket: %s", err)
+	}
+
+	tv := unix.NsecToTimeval(netlinkReceiveTimeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("could not set netlink socket timeout: %s", err)
+	}
+
+	if err := sendProcCnOp(fd, procCnMcastListen); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("could not subscribe to the proc connector: %s", err)
+	}
+
+	linuxProbe, ok := p.(*probe)
+	if !ok {
+		linuxProbe = &probe{procRootLoc: util.HostProc()}
+	}
+
+	offset, err := monotonicOffset()
+	if err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+
+	return &netlinkEventSource{
+		fd:              fd,
+		probe:           linuxProbe,
+		monotonicOffset: offset,
+	}, nil
+}
+
+// sendProcCnOp sends a multicast operation to the proc connector
+func sendProcCnOp(fd int, op uint32) error {
+	order := nativeEndian()
+	msg := make([]byte, unix.SizeofNlMsghdr+cnMsgSize+4)
+
+	// struct nlmsghdr
+	order.PutUint32(msg[0:], uint32(len(msg)))
+	order.PutUint16(msg[4:], unix.NLMSG_DONE)
+	order.PutUint32(msg[12:], uint32(unix.Getpid()))
+
+	// struct cn_msg
+	cn := msg[unix.SizeofNlMsghdr:]
+	order.PutUint32(cn[0:], cnIdxProc)
+	order.PutUint32(cn[4:], cnValProc)
+	order.PutUint16(cn[16:], 4)
+
+	// enum proc_cn_mcast_op
+	order.PutUint32(cn[cnMsgSize:], op)
+
+	return unix.Sendto(fd, msg, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK})
+}
+
+// monotonicOffset returns the offset between the wall clock and the monotonic
+// clock the proc connector timestamps the events with
+func monotonicOffset() (time.Duration, error) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return 0, fmt.Errorf("could not read monotonic clock: %s", err)
+	}
+	return time.Duration(time.Now().UnixNano() - ts.Nano()), nil
+}
+
+func (s *netlinkEventSource) run(handle func(*ProcessEvent), exit <-chan struct{}) {
+	buf := make([]byte, 64*1024)
+	for {
+		select {
+		case <-exit:
+			return
+		default:
+		}
+
+		n, _, err := unix.Recvfrom(s.fd, buf, 0)
+		if err != nil {
+			switch err {
+			case unix.EAGAIN, unix.EINTR:
+			case unix.ENOBUFS:
+				// the socket buffer overflowed, the events are lost
+				log.Debugf("Process events were dropped by the netlink socket")
+			default:
+				log.Warnf("Could not read the process events: %s", err)
+				return
+			}
+			continue
+		}
+
+		events, err := parseProcEvents(buf[:n], s.monotonicOffset)
+		if err != nil {
+			log.Debugf("Could not parse the process events: %s", err)
+			continue
+		}
+		for _, e := range events {
+			if e.Type == ProcessEventExec {
+				s.enrich(e)
+			}
+			handle(e)
+		}
+	}
+}
+
+// enrich reads the metadata of the process of an exec event from procfs
+func (s *netlinkEventSource) enrich(e *ProcessEvent) {
+	pidPath := filepath.Join(s.probe.procRootLoc, strconv.Itoa(int(e.Pid)))
+	cmdline := s.probe.getCmdline(pidPath)
+	if len(cmdline) == 0 {
+		// the process already exited, or is a kernel thread
+		return
+	}
+
+	statusInfo := s.probe.parseStatus(pidPath)
+	statInfo := s.probe.parseStat(pidPath, e.Pid, time.Now())
+	e.Ppid = statInfo.ppid
+	e.Process = &Process{
+		Pid:     e.Pid,
+		Ppid:    statInfo.ppid,
+		Cmdline: cmdline,
+		Name:    statusInfo.name,
+		Uids:    statusInfo.uids,
+		Gids:    statusInfo.gids,
+		Cwd:     s.probe.getLinkWithAuthCheck(pidPath, "cwd"),
+		Exe:     s.probe.getLinkWithAuthCheck(pidPath, "exe"),
+		NsPid:   statusInfo.nspid,
+	}
+}
+
+func (s *netlinkEventSource) close() {
+	if err := sendProcCnOp(s.fd, procCnMcastIgnore); err != nil {
+		log.Debugf("Could not unsubscribe from the proc connector: %s", err)
+	}
+	unix.Close(s.fd)
+}
+
+// parseProcEvents parses the process events of a netlink message, the events
+// of the threads are ignored
+func parseProcEvents(data []byte, monotonicOffset time.Duration) ([]*ProcessEvent, error) {
+	msgs, err := parseNetlinkMessages(data)
+	if err != nil {
+		return nil, err
+	}
+
+	order := nativeEndian()
+	var events []*ProcessEvent
+	for _, msg := range msgs {
+		if len(msg) < cnMsgSize+procEventHeaderSize {
+			continue
+		}
+		if order.Uint32(msg[0:]) != cnIdxProc || order.Uint32(msg[4:]) != cnValProc {
+			continue
+		}
+
+		ev := msg[cnMsgSize:]
+		what := order.Uint32(ev[0:])
+		timestamp := time.Unix(0, int64(order.Uint64(ev[8:]))+int64(monotonicOffset))
+		data := ev[procEventHeaderSize:]
+		field := func(i int) int32 {
+			return int32(order.Uint32(data[4*i:]))
+		}
+
+		switch what {
+		case procEventFork:
+			if len(data) < 16 {
+				continue
+			}
+			// parent_pid, parent_tgid, child_pid, child_tgid
+			if field(2) != field(3) {
+				continue
+			}
+			events = append(events, &ProcessEvent{Type: ProcessEventFork, Pid: field(3), Ppid: field(1), Timestamp: timestamp})
+		case procEventExec:
+			if len(data) < 8 {
+				continue
+			}
+			// process_pid, process_tgid
+			events = append(events, &ProcessEvent{Type: ProcessEventExec, Pid: field(1), Timestamp: timestamp})
+		case procEventUID:
+			if len(data) < 16 {
+				continue
+			}
+			// process_pid, process_tgid, ruid, euid
+			if field(0) != field(1) {
+				continue
+			}
+			events = append(events, &ProcessEvent{Type: ProcessEventUIDChange, Pid: field(1), Uid: field(2), Euid: field(3), Timestamp: timestamp})
+		case procEventExit:
+			if len(data) < 16 {
+				continue
+			}
+			// process_pid, process_tgid, exit_code, exit_signal
+			if field(0) != field(1) {
+				continue
+			}
+			status := field(2)
+			events = append(events, &ProcessEvent{
+				Type:      ProcessEventExit,
+				Pid:       field(1),
+				Timestamp: timestamp,
+				ExitCode:  (status >> 8) & 0xff,
+				Signal:    status & 0x7f,
+			})
+		}
+	}
+	return events, nil
+}
+
+// parseNetlinkMessages returns the payloads of the netlink messages of data
+func parseNetlinkMessages(data []byte) ([][]byte, error) {
+	order := nativeEndian()
+	var msgs [][]byte
+	for len(data) >= unix.SizeofNlMsghdr {
+		length := int(order.Uint32(data[0:]))
+		if length < unix.SizeofNlMsghdr || length > len(data) {
+			return nil, fmt.Errorf("invalid netlink message length %d", length)
+		}
+		msgs = append(msgs, data[unix.SizeofNlMsghdr:length])
+
+		aligned := (length + unix.NLMSG_ALIGNTO - 1) &^ (unix.NLMSG_ALIGNTO - 1)
+		if aligned > len(data) {
+			break
+		}
+		data = data[aligned:]
+	}
+	return msgs, nil
+}
+
+func nativeEndian() binary.ByteOrder {
+	if isBigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
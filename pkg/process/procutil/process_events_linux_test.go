@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package procutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// procEventMessage builds a netlink message of the proc connector
+func procEventMessage(what uint32, timestampNs uint64, fields ...uint32) []byte {
+	order := nativeEndian()
+	length := unix.SizeofNlMsghdr + cnMsgSize + procEventHeaderSize + 4*len(fields)
+	msg := make([]byte, length)
+
+	order.PutUint32(msg[0:], uint32(length))
+	order.PutUint16(msg[4:], unix.NLMSG_DONE)
+
+	cn := msg[unix.SizeofNlMsghdr:]
+	order.PutUint32(cn[0:], cnIdxProc)
+	order.PutUint32(cn[4:], cnValProc)
+	order.PutUint16(cn[16:], uint16(procEventHeaderSize+4*len(fields)))
+
+	ev := cn[cnMsgSize:]
+	order.PutUint32(ev[0:], what)
+	order.PutUint64(ev[8:], timestampNs)
+	for i, f := range fields {
+		order.PutUint32(ev[procEventHeaderSize+4*i:], f)
+	}
+	return msg
+}
+
+func TestParseProcEvents(t *testing.T) {
+	offset := time.Duration(1000) * time.Second
+	ts := uint64(5 * time.Second)
+	at := time.Unix(0, int64(ts)+int64(offset))
+
+	var data []byte
+	data = append(data, procEventMessage(procEventFork, ts, 1, 1, 42, 42)...)
+	// a thread creation is ignored
+	data = append(data, procEventMessage(procEventFork, ts, 42, 42, 43, 42)...)
+	data = append(data, procEventMessage(procEventExec, ts, 42, 42)...)
+	data = append(data, procEventMessage(procEventUID, ts, 42, 42, 1000, 0)...)
+	// exited with status 3
+	data = append(data, procEventMessage(procEventExit, ts, 42, 42, 3<<8, 17)...)
+	// killed by SIGKILL
+	data = append(data, procEventMessage(procEventExit, ts, 44, 44, 9, 17)...)
+	// a thread exit is ignored
+	data = append(data, procEventMessage(procEventExit, ts, 43, 42, 0, 17)...)
+
+	events, err := parseProcEvents(data, offset)
+	require.NoError(t, err)
+	assert.Equal(t, []*ProcessEvent{
+		{Type: ProcessEventFork, Pid: 42, Ppid: 1, Timestamp: at},
+		{Type: ProcessEventExec, Pid: 42, Timestamp: at},
+		{Type: ProcessEventUIDChange, Pid: 42, Uid: 1000, Euid: 0, Timestamp: at},
+		{Type: ProcessEventExit, Pid: 42, ExitCode: 3, Timestamp: at},
+		{Type: ProcessEventExit, Pid: 44, Signal: 9, Timestamp: at},
+	}, events)
+}
+
+func TestParseProcEventsInvalidLength(t *testing.T) {
+	msg := procEventMessage(procEventExec, 0, 42, 42)
+	nativeEndian().PutUint32(msg, uint32(len(msg)+1))
+	_, err := parseProcEvents(msg, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,160 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package procutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProbe returns the processes it is set with
+type fakeProbe struct {
+	procs map[int32]*Process
+}
+
+func (p *fakeProbe) Close() {}
+
+func (p *fakeProbe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*Stats, error) {
+	return nil, nil
+}
+
+func (p *fakeProbe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*Process, error) {
+	return p.procs, nil
+}
+
+func (p *fakeProbe) StatsWithPermByPID(pids []int32) (map[int32]*StatsWithPerm, error) {
+	return nil, nil
+}
+
+func testProcess(pid, ppid int32, createTime int64, cmdline ...string) *Process {
+	return &Process{
+		Pid:     pid,
+		Ppid:    ppid,
+		Name:    cmdline[0],
+		Cmdline: cmdline,
+		Uids:    []int32{1000, 1000, 1000, 1000},
+		Stats:   &Stats{CreateTime: createTime},
+	}
+}
+
+func TestProcessTableLifecycle(t *testing.T) {
+	start := time.Unix(1000, 0)
+	table := NewProcessTable()
+	table.seed(map[int32]*Process{1: testProcess(1, 0, start.UnixNano()/int64(time.Millisecond), "bash")})
+
+	table.Handle(&ProcessEvent{Type: ProcessEventFork, Pid: 2, Ppid: 1, Timestamp: start.Add(time.Second)})
+	forked, ok := table.Get(2)
+	require.True(t, ok)
+	assert.Equal(t, []string{"bash"}, forked.Cmdline)
+
+	table.Handle(&ProcessEvent{
+		Type:      ProcessEventExec,
+		Pid:       2,
+		Timestamp: start.Add(2 * time.Second),
+		Process:   testProcess(2, 1, 0, "curl", "example.com"),
+	})
+	table.Handle(&ProcessEvent{Type: ProcessEventUIDChange, Pid: 2, Uid: 1000, Euid: 0})
+	execed, ok := table.Get(2)
+	require.True(t, ok)
+	assert.Equal(t, []string{"curl", "example.com"}, execed.Cmdline)
+	assert.Equal(t, []int32{1000, 0, 0, 0}, execed.Uids)
+	assert.Equal(t, int32(1), execed.Ppid)
+
+	table.Handle(&ProcessEvent{Type: ProcessEventExit, Pid: 2, Timestamp: start.Add(5 * time.Second), ExitCode: 6})
+	_, ok = table.Get(2)
+	assert.False(t, ok)
+	assert.Equal(t, 1, table.Len())
+
+	exited, cursor := table.ExitedAfter(0)
+	require.Len(t, exited, 1)
+	assert.Equal(t, "curl", exited[0].Name)
+	assert.Equal(t, int32(6), exited[0].ExitCode)
+	assert.True(t, exited[0].Exited)
+	assert.Equal(t, 3*time.Second, exited[0].Duration(time.Now()))
+
+	// the consumers only read what happened since their last read
+	exited, _ = table.ExitedAfter(cursor)
+	assert.Empty(t, exited)
+
+	events, cursor := table.EventsAfter(0)
+	require.Len(t, events, 4)
+	assert.Equal(t, ProcessEventExit, events[3].Type)
+	events, _ = table.EventsAfter(cursor)
+	assert.Empty(t, events)
+}
+
+func TestProcessTableBounded(t *testing.T) {
+	table := NewProcessTable()
+	table.maxEvents = 2
+	table.maxExited = 2
+
+	for pid := int32(1); pid <= 3; pid++ {
+		table.Handle(&ProcessEvent{Type: ProcessEventExit, Pid: pid})
+	}
+
+	exited, _ := table.ExitedAfter(0)
+	require.Len(t, exited, 2)
+	assert.Equal(t, int32(2), exited[0].Pid)
+	assert.Equal(t, int32(3), exited[1].Pid)
+
+	events, _ := table.EventsAfter(0)
+	assert.Len(t, events, 2)
+	assert.Equal(t, int64(1), table.DroppedEvents())
+}
+
+func TestPollingEventSource(t *testing.T) {
+	probe := &fakeProbe{procs: map[int32]*Process{
+		1: testProcess(1, 0, 1000, "init"),
+		2: testProcess(2, 1, 2000, "sleep", "10"),
+		3: testProcess(3, 1, 3000, "nginx"),
+	}}
+	table := NewProcessTable()
+	procs, _ := probe.ProcessesByPID(time.Now(), false)
+	table.seed(procs)
+	source := newPollingEventSource(probe, time.Second)
+	source.last = procs
+
+	now := time.Unix(10, 0)
+	probe.procs = map[int32]*Process{
+		1: testProcess(1, 0, 1000, "init"),
+		// pid 3 was reused
+		3: testProcess(3, 1, 9000, "python"),
+		4: testProcess(4, 1, 8000, "cron"),
+	}
+	source.poll(now, table.Handle)
+
+	exited, _ := table.ExitedAfter(0)
+	require.Len(t, exited, 2)
+	names := map[string]int32{}
+	for _, lc := range exited {
+		names[lc.Name] = lc.ExitCode
+		assert.Equal(t, now, lc.ExitTime)
+	}
+	assert.Equal(t, map[string]int32{"sleep": UnknownExitCode, "nginx": UnknownExitCode}, names)
+
+	python, ok := table.Get(3)
+	require.True(t, ok)
+	assert.Equal(t, "python", python.Name)
+	assert.Equal(t, time.Unix(9, 0), python.StartTime)
+
+	_, ok = table.Get(4)
+	assert.True(t, ok)
+	assert.Equal(t, 3, table.Len())
+}
+
+func TestProcessEventCollectorFallback(t *testing.T) {
+	probe := &fakeProbe{procs: map[int32]*Process{1: testProcess(1, 0, 1000, "init")}}
+	c := NewProcessEventCollector(probe, 10*time.Millisecond)
+	c.Start()
+	defer c.Stop()
+
+	assert.Contains(t, []string{ProcessEventModeNetlink, ProcessEventModePolling}, c.Mode())
+	_, ok := c.Table().Get(1)
+	assert.True(t, ok)
+}
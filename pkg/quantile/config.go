@@ -79,6 +79,12 @@ func (c *Config) binLow(k Key) float64 {
 	return c.powGamma(exp)
 }
 
+// BinLowerBound returns the lower bound of the values counted in the bin of
+// key k.
+func (c *Config) BinLowerBound(k Key) float64 {
+	return c.binLow(k)
+}
+
 // key returns a value k such that:
 //   γ^k <= v < γ^(k+1)
 func (c *Config) key(v float64) Key {
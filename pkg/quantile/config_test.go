@@ -189,3 +189,13 @@ func TestRelativeError(t *testing.T) {
 
 	s.print()
 }
+
+func TestBinLowerBound(t *testing.T) {
+	c := Default()
+	for _, v := range []float64{1e-6, 0.5, 1, 42, 1e6} {
+		k := c.key(v)
+		require.InEpsilon(t, v, c.BinLowerBound(k), 2*defaultEps)
+		require.Equal(t, -c.BinLowerBound(k), c.BinLowerBound(-k))
+	}
+	require.Equal(t, 0.0, c.BinLowerBound(0))
+}
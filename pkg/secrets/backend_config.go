@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package secrets
+
+import "time"
+
+// Names of the secret backends
+const (
+	CommandBackend = "command"
+	FileBackend    = "file"
+	EnvBackend     = "env"
+	VaultBackend   = "vault"
+)
+
+// BackendsConfig configures the secret backends. Since this package is used by
+// the 'config' package to decrypt itself it can't read the configuration.
+type BackendsConfig struct {
+	// Type is the backend decrypting the handles without a backend prefix,
+	// the secret_backend_command when empty
+	Type string
+	// RefreshInterval is the interval at which the decrypted secrets are
+	// fetched again to detect their rotation, 0 disables the refresh
+	RefreshInterval time.Duration
+	File            FileBackendConfig
+	Env             EnvBackendConfig
+	Vault           VaultBackendConfig
+}
+
+// FileBackendConfig configures the backend reading the secrets from the files
+// of a directory, as mounted by Kubernetes or Docker secrets
+type FileBackendConfig struct {
+	Enabled   bool
+	Directory string
+}
+
+// EnvBackendConfig configures the backend reading the secrets from environment
+// variables
+type EnvBackendConfig struct {
+	Enabled bool
+	// Prefix is prepended to the handles to get the name of the variables
+	Prefix string
+}
+
+// VaultBackendConfig configures the backend reading the secrets from a Vault
+// compatible KV secrets engine
+type VaultBackendConfig struct {
+	Enabled   bool
+	Address   string
+	Namespace string
+	// AuthMethod is either "token" or "approle"
+	AuthMethod    string
+	Token         string
+	RoleID        string
+	SecretID      string
+	AppRoleMount  string
+	Mount         string
+	KVVersion     int
+	TLSSkipVerify bool
+	Timeout       time.Duration
+}
+
+// RefreshHandler is called with the secret handles whose value changed during
+// a refresh, and the origins (configuration names) where they were found
+type RefreshHandler func(rotated map[string][]string)
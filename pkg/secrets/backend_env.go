@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build secrets
+// +build secrets
+
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// envBackend reads each secret from the environment variable named after its
+// ID. The variables are prefixed so that the handles, which may come from
+// autodiscovery annotations written by the workloads, can't read the other
+// variables of the Agent.
+type envBackend struct {
+	prefix string
+}
+
+func newEnvBackend(cfg EnvBackendConfig) (*envBackend, error) {
+	if cfg.Prefix == "" {
+		return nil, errors.New("the env secret backend requires secret_backend_env.prefix")
+	}
+	return &envBackend{prefix: cfg.Prefix}, nil
+}
+
+func (b *envBackend) fetch(secretIDs []string) (map[string]string, error) {
+	res := make(map[string]string, len(secretIDs))
+	for _, secretID := range secretIDs {
+		value, found := os.LookupEnv(b.prefix + secretID)
+		if !found {
+			return nil, fmt.Errorf("environment variable '%s' of secret '%s' is not set", b.prefix+secretID, secretID)
+		}
+		res[secretID] = value
+	}
+	return res, nil
+}
@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build secrets
+// +build secrets
+
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	maxSecretFileSize = 8192
+)
+
+// ReadSecretFile reads a secret from a file, refusing to follow symlinks out
+// of the directory of the file
+func ReadSecretFile(path string) Secret {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Secret{Value: "", ErrorMsg: "secret does not exist"}
+		}
+		return Secret{Value: "", ErrorMsg: err.Error()}
+	}
+
+	if fi.Mode()&os.ModeSymlink != 0 {
+		// Ensure that the symlink is in the same dir
+		target, err := os.Readlink(path)
+		if err != nil {
+			return Secret{Value: "", ErrorMsg: fmt.Sprintf("failed to read symlink target: %v", err)}
+		}
+
+		dir := filepath.Dir(path)
+		if !filepath.IsAbs(target) {
+			target, err = filepath.Abs(filepath.Join(dir, target))
+			if err != nil {
+				return Secret{Value: "", ErrorMsg: fmt.Sprintf("failed to resolve symlink absolute path: %v", err)}
+			}
+		}
+
+		dirAbs, err := filepath.Abs(dir)
+		if err != nil {
+			return Secret{Value: "", ErrorMsg: fmt.Sprintf("failed to resolve absolute path of directory: %v", err)}
+		}
+
+		if !filepath.HasPrefix(target, dirAbs) {
+			return Secret{Value: "", ErrorMsg: fmt.Sprintf("not following symlink %q outside of %q", target, dir)}
+		}
+	}
+	fi, err = os.Stat(path)
+	if err != nil {
+		return Secret{Value: "", ErrorMsg: err.Error()}
+	}
+
+	if fi.Size() > maxSecretFileSize {
+		return Secret{Value: "", ErrorMsg: "secret exceeds max allowed size"}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return Secret{Value: "", ErrorMsg: err.Error()}
+	}
+	defer file.Close()
+
+	bytes, err := ioutil.ReadAll(file)
+	if err != nil {
+		return Secret{Value: "", ErrorMsg: err.Error()}
+	}
+
+	return Secret{Value: string(bytes), ErrorMsg: ""}
+}
+
+// fileBackend reads each secret from the file named after its ID in a
+// directory
+type fileBackend struct {
+	directory string
+}
+
+func newFileBackend(cfg FileBackendConfig) (*fileBackend, error) {
+	if cfg.Directory == "" {
+		return nil, fmt.Errorf("the file secret backend requires secret_backend_file.directory")
+	}
+	directory, err := filepath.Abs(cfg.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret_backend_file.directory '%s': %s", cfg.Directory, err)
+	}
+	return &fileBackend{directory: directory}, nil
+}
+
+func (b *fileBackend) fetch(secretIDs []string) (map[string]string, error) {
+	res := make(map[string]string, len(secretIDs))
+	for _, secretID := range secretIDs {
+		path := filepath.Join(b.directory, secretID)
+		if rel, err := filepath.Rel(b.directory, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("secret '%s' is outside of the secrets directory %s", secretID, b.directory)
+		}
+		secret := ReadSecretFile(path)
+		if secret.ErrorMsg != "" {
+			return nil, fmt.Errorf("an error occurred while reading secret '%s' from %s: %s", secretID, b.directory, secret.ErrorMsg)
+		}
+		res[secretID] = secret.Value
+	}
+	return res, nil
+}
@@ -0,0 +1,247 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build secrets
+// +build secrets
+
+package secrets
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	vaultAuthToken   = "token"
+	vaultAuthAppRole = "approle"
+	// vaultKeySeparator separates the path of a secret from its key in the
+	// secret IDs: ENC[vault@datadog/mysql#password]
+	vaultKeySeparator = "#"
+)
+
+// vaultBackend reads the secrets from a Vault compatible KV secrets engine.
+// Since this package is used to decrypt the configuration it can't use the
+// HTTP helpers relying on it, the proxy is read from the environment.
+type vaultBackend struct {
+	cfg    VaultBackendConfig
+	client *http.Client
+
+	// m serializes the fetches, which can be run by Decrypt and by the refresh
+	// of the secrets at the same time, so that they don't race on the token
+	m sync.Mutex
+	// token is the token of the token auth method, or the client token
+	// returned by the last AppRole login
+	token string
+}
+
+// vaultResponse is the response of the Vault HTTP API
+type vaultResponse struct {
+	Data json.RawMessage `json:"data"`
+	Auth struct {
+		ClientToken string `json:"client_token"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+func newVaultBackend(cfg VaultBackendConfig) (*vaultBackend, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("the vault secret backend requires secret_backend_vault.address")
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+	if cfg.AppRoleMount == "" {
+		cfg.AppRoleMount = vaultAuthAppRole
+	}
+	if cfg.KVVersion == 0 {
+		cfg.KVVersion = 2
+	}
+	if cfg.KVVersion != 1 && cfg.KVVersion != 2 {
+		return nil, fmt.Errorf("unsupported secret_backend_vault.kv_version %d, must be 1 or 2", cfg.KVVersion)
+	}
+
+	b := &vaultBackend{cfg: cfg}
+	switch cfg.AuthMethod {
+	case "", vaultAuthToken:
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("the token auth method of the vault secret backend requires secret_backend_vault.token")
+		}
+		b.token = cfg.Token
+	case vaultAuthAppRole:
+		if cfg.RoleID == "" || cfg.SecretID == "" {
+			return nil, fmt.Errorf("the approle auth method of the vault secret backend requires secret_backend_vault.approle_role_id and secret_backend_vault.approle_secret_id")
+		}
+	default:
+		return nil, fmt.Errorf("unknown secret_backend_vault.auth_method '%s', must be %s or %s", cfg.AuthMethod, vaultAuthToken, vaultAuthAppRole)
+	}
+
+	b.client = &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: cfg.TLSSkipVerify,
+			},
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+	return b, nil
+}
+
+func (b *vaultBackend) fetch(secretIDs []string) (map[string]string, error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if b.token == "" {
+		if err := b.login(); err != nil {
+			return nil, err
+		}
+	}
+
+	// secrets stored at the same path are read once
+	secretsByPath := map[string]map[string]interface{}{}
+	res := make(map[string]string, len(secretIDs))
+	for _, secretID := range secretIDs {
+		idx := strings.LastIndex(secretID, vaultKeySeparator)
+		if idx <= 0 || idx == len(secretID)-1 {
+			return nil, fmt.Errorf("invalid vault secret '%s', must be formatted as <path>%s<key>", secretID, vaultKeySeparator)
+		}
+		path, key := secretID[:idx], secretID[idx+1:]
+
+		secrets, ok := secretsByPath[path]
+		if !ok {
+			var err error
+			if secrets, err = b.readSecret(path); err != nil {
+				return nil, fmt.Errorf("could not read vault secret '%s': %s", path, err)
+			}
+			secretsByPath[path] = secrets
+		}
+
+		value, ok := secrets[key]
+		if !ok {
+			return nil, fmt.Errorf("vault secret '%s' has no key '%s'", path, key)
+		}
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("key '%s' of vault secret '%s' is not a string", key, path)
+		}
+		res[secretID] = str
+	}
+	return res, nil
+}
+
+// readSecret returns the key/value pairs stored at path, logging in again
+// once if the AppRole client token expired. It must be called with b.m held.
+func (b *vaultBackend) readSecret(path string) (map[string]interface{}, error) {
+	url := fmt.Sprintf("%s/v1/%s/%s", b.cfg.Address, b.cfg.Mount, strings.TrimPrefix(path, "/"))
+	if b.cfg.KVVersion == 2 {
+		url = fmt.Sprintf("%s/v1/%s/data/%s", b.cfg.Address, b.cfg.Mount, strings.TrimPrefix(path, "/"))
+	}
+
+	resp, status, err := b.do(http.MethodGet, url, nil)
+	if err == nil && status == http.StatusForbidden && b.cfg.AuthMethod == vaultAuthAppRole {
+		if err = b.login(); err != nil {
+			return nil, err
+		}
+		resp, status, err = b.do(http.MethodGet, url, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, vaultError(status, resp)
+	}
+
+	secrets := map[string]interface{}{}
+	if b.cfg.KVVersion == 2 {
+		var data struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			return nil, fmt.Errorf("could not unmarshal the response: %s", err)
+		}
+		secrets = data.Data
+	} else if err := json.Unmarshal(resp.Data, &secrets); err != nil {
+		return nil, fmt.Errorf("could not unmarshal the response: %s", err)
+	}
+	return secrets, nil
+}
+
+// login gets a client token with the AppRole auth method. It must be called
+// with b.m held.
+func (b *vaultBackend) login() error {
+	body, err := json.Marshal(map[string]string{
+		"role_id":   b.cfg.RoleID,
+		"secret_id": b.cfg.SecretID,
+	})
+	if err != nil {
+		return err
+	}
+
+	b.token = ""
+	url := fmt.Sprintf("%s/v1/auth/%s/login", b.cfg.Address, b.cfg.AppRoleMount)
+	resp, status, err := b.do(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not log in to vault with the approle auth method: %s", err)
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("could not log in to vault with the approle auth method: %s", vaultError(status, resp))
+	}
+	if resp.Auth.ClientToken == "" {
+		return fmt.Errorf("could not log in to vault with the approle auth method: no client token in the response")
+	}
+	b.token = resp.Auth.ClientToken
+	return nil
+}
+
+func (b *vaultBackend) do(method string, url string, body io.Reader) (*vaultResponse, int, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if b.token != "" {
+		req.Header.Set("X-Vault-Token", b.token)
+	}
+	if b.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.cfg.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := b.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer httpResp.Body.Close()
+
+	payload, err := ioutil.ReadAll(io.LimitReader(httpResp.Body, int64(SecretBackendOutputMaxSize)))
+	if err != nil {
+		return nil, 0, err
+	}
+	resp := &vaultResponse{}
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, resp); err != nil && httpResp.StatusCode == http.StatusOK {
+			return nil, 0, fmt.Errorf("could not unmarshal the response: %s", err)
+		}
+	}
+	return resp, httpResp.StatusCode, nil
+}
+
+func vaultError(status int, resp *vaultResponse) error {
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("status code %d: %s", status, strings.Join(resp.Errors, ", "))
+	}
+	return fmt.Errorf("status code %d", status)
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build secrets
+// +build secrets
+
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVaultServer mocks a Vault server with a KV secrets engine mounted at
+// "secret", accepting the tokens returned by the AppRole logins
+func newVaultServer(t *testing.T, kvVersion int) (*httptest.Server, *int) {
+	logins := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/approle/login" {
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if body["role_id"] != "role" || body["secret_id"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"errors":["invalid role or secret ID"]}`)
+				return
+			}
+			logins++
+			fmt.Fprintf(w, `{"auth":{"client_token":"token-%d"}}`, logins)
+			return
+		}
+
+		if r.Header.Get("X-Vault-Token") != "root" && r.Header.Get("X-Vault-Token") != fmt.Sprintf("token-%d", logins) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors":["permission denied"]}`)
+			return
+		}
+
+		data := `{"password":"p1","user":"datadog","port":3306}`
+		switch {
+		case kvVersion == 2 && r.URL.Path == "/v1/secret/data/datadog/mysql":
+			fmt.Fprintf(w, `{"data":{"data":%s,"metadata":{"version":1}}}`, data)
+		case kvVersion == 1 && r.URL.Path == "/v1/secret/datadog/mysql":
+			fmt.Fprintf(w, `{"data":%s}`, data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &logins
+}
+
+func TestVaultBackendToken(t *testing.T) {
+	ts, _ := newVaultServer(t, 2)
+
+	b, err := newVaultBackend(VaultBackendConfig{Address: ts.URL, Token: "root"})
+	require.NoError(t, err)
+
+	res, err := b.fetch([]string{"datadog/mysql#password", "datadog/mysql#user"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"datadog/mysql#password": "p1", "datadog/mysql#user": "datadog"}, res)
+
+	_, err = b.fetch([]string{"datadog/mysql#port"})
+	assert.EqualError(t, err, "key 'port' of vault secret 'datadog/mysql' is not a string")
+
+	_, err = b.fetch([]string{"datadog/mysql#missing"})
+	assert.EqualError(t, err, "vault secret 'datadog/mysql' has no key 'missing'")
+
+	_, err = b.fetch([]string{"datadog/redis#password"})
+	assert.EqualError(t, err, "could not read vault secret 'datadog/redis': status code 404")
+
+	_, err = b.fetch([]string{"datadog/mysql"})
+	assert.EqualError(t, err, "invalid vault secret 'datadog/mysql', must be formatted as <path>#<key>")
+}
+
+func TestVaultBackendAppRole(t *testing.T) {
+	ts, logins := newVaultServer(t, 1)
+
+	b, err := newVaultBackend(VaultBackendConfig{Address: ts.URL, AuthMethod: "approle", RoleID: "role", SecretID: "secret", KVVersion: 1})
+	require.NoError(t, err)
+
+	res, err := b.fetch([]string{"datadog/mysql#password"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"datadog/mysql#password": "p1"}, res)
+	assert.Equal(t, 1, *logins)
+
+	// the client token expired, the backend logs in again
+	b.token = "expired"
+	res, err = b.fetch([]string{"datadog/mysql#user"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"datadog/mysql#user": "datadog"}, res)
+	assert.Equal(t, 2, *logins)
+
+	b, err = newVaultBackend(VaultBackendConfig{Address: ts.URL, AuthMethod: "approle", RoleID: "role", SecretID: "wrong"})
+	require.NoError(t, err)
+	_, err = b.fetch([]string{"datadog/mysql#password"})
+	assert.EqualError(t, err, "could not log in to vault with the approle auth method: status code 400: invalid role or secret ID")
+}
@@ -0,0 +1,174 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build secrets
+// +build secrets
+
+package secrets
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backendPrefixSeparator separates the name of a backend from the secret ID in
+// a handle, as the providers of the secret helper: ENC[vault@path#key]
+const backendPrefixSeparator = "@"
+
+// backend fetches secrets from a secret store
+type backend interface {
+	// fetch returns the values of the given secret IDs, or an error if any of
+	// them can't be fetched
+	fetch(secretIDs []string) (map[string]string, error)
+}
+
+// commandBackendFetcher fetches secrets by running the secret_backend_command
+type commandBackendFetcher struct{}
+
+func (commandBackendFetcher) fetch(secretIDs []string) (map[string]string, error) {
+	return fetchFromCommand(secretIDs)
+}
+
+var (
+	// nativeBackends are the enabled backends running in the Agent process,
+	// by name
+	nativeBackends = map[string]backend{}
+	// defaultBackend decrypts the handles without a backend prefix
+	defaultBackend = CommandBackend
+	// refreshInterval is the interval at which the secrets are fetched again
+	refreshInterval time.Duration
+)
+
+// InitBackends enables the secret backends running in the Agent process
+func InitBackends(cfg BackendsConfig) error {
+	backends := map[string]backend{}
+	if cfg.File.Enabled || cfg.Type == FileBackend {
+		b, err := newFileBackend(cfg.File)
+		if err != nil {
+			return err
+		}
+		backends[FileBackend] = b
+	}
+	if cfg.Env.Enabled || cfg.Type == EnvBackend {
+		b, err := newEnvBackend(cfg.Env)
+		if err != nil {
+			return err
+		}
+		backends[EnvBackend] = b
+	}
+	if cfg.Vault.Enabled || cfg.Type == VaultBackend {
+		b, err := newVaultBackend(cfg.Vault)
+		if err != nil {
+			return err
+		}
+		backends[VaultBackend] = b
+	}
+
+	def := CommandBackend
+	switch cfg.Type {
+	case "", CommandBackend:
+	case FileBackend, EnvBackend, VaultBackend:
+		def = cfg.Type
+	default:
+		return fmt.Errorf("unknown secret backend type '%s', must be one of %s, %s, %s or %s", cfg.Type, CommandBackend, FileBackend, EnvBackend, VaultBackend)
+	}
+
+	secretMutex.Lock()
+	defer secretMutex.Unlock()
+	nativeBackends = backends
+	defaultBackend = def
+	refreshInterval = cfg.RefreshInterval
+	return nil
+}
+
+// Enabled returns whether a secret backend is configured to decrypt the
+// secrets
+func Enabled() bool {
+	secretMutex.Lock()
+	defer secretMutex.Unlock()
+	return secretBackendCommand != "" || len(nativeBackends) > 0
+}
+
+// routeHandle returns the name of the backend decrypting a handle, and the ID
+// of the secret in this backend
+func routeHandle(handle string) (string, backend, string) {
+	if idx := strings.Index(handle, backendPrefixSeparator); idx > 0 {
+		if b, ok := nativeBackends[handle[:idx]]; ok {
+			return handle[:idx], b, handle[idx+1:]
+		}
+	}
+	if b, ok := nativeBackends[defaultBackend]; ok {
+		return defaultBackend, b, handle
+	}
+	return CommandBackend, commandBackendFetcher{}, handle
+}
+
+// backendHandles holds the handles routed to a backend, along with the ID of
+// each secret in this backend
+type backendHandles struct {
+	backend   backend
+	handles   []string
+	secretIDs []string
+}
+
+// groupHandles routes the given handles to their backends. The routing reads
+// the configured backends, so it must be called with secretMutex held.
+func groupHandles(handles []string) map[string]*backendHandles {
+	byBackend := map[string]*backendHandles{}
+	for _, handle := range handles {
+		name, b, secretID := routeHandle(handle)
+		if _, ok := byBackend[name]; !ok {
+			byBackend[name] = &backendHandles{backend: b}
+		}
+		byBackend[name].handles = append(byBackend[name].handles, handle)
+		byBackend[name].secretIDs = append(byBackend[name].secretIDs, secretID)
+	}
+	return byBackend
+}
+
+// fetchHandles fetches the given handles from their backends. It returns the
+// values that were fetched and the backend of each handle, along with the
+// first error encountered.
+func fetchHandles(handles []string) (map[string]string, map[string]string, error) {
+	return fetchGroups(groupHandles(handles))
+}
+
+// fetchGroups fetches the handles grouped by groupHandles from their backends.
+// It doesn't touch the cache, so it can be called without holding secretMutex.
+func fetchGroups(byBackend map[string]*backendHandles) (map[string]string, map[string]string, error) {
+	names := make([]string, 0, len(byBackend))
+	for name := range byBackend {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := map[string]string{}
+	backendOf := map[string]string{}
+	var firstErr error
+	for _, name := range names {
+		bh := byBackend[name]
+		secrets, err := bh.backend.fetch(bh.secretIDs)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for idx, handle := range bh.handles {
+			value := secrets[bh.secretIDs[idx]]
+			if value == "" {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("decrypted secret for '%s' is empty", handle)
+				}
+				continue
+			}
+			values[handle] = value
+			backendOf[handle] = name
+		}
+	}
+	return values, backendOf, firstErr
+}
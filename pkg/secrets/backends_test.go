@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build secrets
+// +build secrets
+
+package secrets
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/common"
+)
+
+func resetBackends() {
+	nativeBackends = map[string]backend{}
+	defaultBackend = CommandBackend
+	refreshInterval = 0
+	secretCache = map[string]string{}
+	secretOrigin = map[string]common.StringSet{}
+	secretBackend = map[string]string{}
+	runCommand = execCommand
+}
+
+func TestInitBackendsErrors(t *testing.T) {
+	defer resetBackends()
+
+	assert.EqualError(t, InitBackends(BackendsConfig{Type: "unknown"}), "unknown secret backend type 'unknown', must be one of command, file, env or vault")
+	assert.EqualError(t, InitBackends(BackendsConfig{Type: FileBackend}), "the file secret backend requires secret_backend_file.directory")
+	assert.EqualError(t, InitBackends(BackendsConfig{Vault: VaultBackendConfig{Enabled: true, Address: "http://vault"}}), "the token auth method of the vault secret backend requires secret_backend_vault.token")
+	assert.EqualError(t, InitBackends(BackendsConfig{Env: EnvBackendConfig{Enabled: true}}), "the env secret backend requires secret_backend_env.prefix")
+	assert.False(t, Enabled())
+
+	require.NoError(t, InitBackends(BackendsConfig{Env: EnvBackendConfig{Enabled: true, Prefix: "TEST_SECRET_"}}))
+	assert.True(t, Enabled())
+	assert.Equal(t, CommandBackend, defaultBackend)
+}
+
+func TestFileBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "password"), []byte("secret"), 0600))
+
+	b, err := newFileBackend(FileBackendConfig{Directory: dir})
+	require.NoError(t, err)
+
+	res, err := b.fetch([]string{"password"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"password": "secret"}, res)
+
+	_, err = b.fetch([]string{"missing"})
+	assert.Contains(t, err.Error(), "secret does not exist")
+
+	_, err = b.fetch([]string{"../password"})
+	assert.Contains(t, err.Error(), "is outside of the secrets directory")
+}
+
+func TestEnvBackend(t *testing.T) {
+	os.Setenv("TEST_SECRET_PASSWORD", "secret")
+	defer os.Unsetenv("TEST_SECRET_PASSWORD")
+
+	_, err := newEnvBackend(EnvBackendConfig{})
+	assert.EqualError(t, err, "the env secret backend requires secret_backend_env.prefix")
+
+	b, err := newEnvBackend(EnvBackendConfig{Prefix: "TEST_SECRET_"})
+	require.NoError(t, err)
+	res, err := b.fetch([]string{"PASSWORD"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"PASSWORD": "secret"}, res)
+
+	_, err = b.fetch([]string{"MISSING"})
+	assert.EqualError(t, err, "environment variable 'TEST_SECRET_MISSING' of secret 'MISSING' is not set")
+}
+
+func TestDecryptWithNativeBackends(t *testing.T) {
+	defer resetBackends()
+	os.Setenv("TEST_SECRET_PASS1", "password1")
+	defer os.Unsetenv("TEST_SECRET_PASS1")
+
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "pass2"), []byte("password2"), 0600))
+
+	require.NoError(t, InitBackends(BackendsConfig{
+		Type: EnvBackend,
+		Env:  EnvBackendConfig{Prefix: "TEST_SECRET_"},
+		File: FileBackendConfig{Enabled: true, Directory: dir},
+	}))
+
+	conf := []byte("pass1: ENC[PASS1]\npass2: ENC[file@pass2]\n")
+	newConf, err := Decrypt(conf, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "pass1: password1\npass2: password2\n", string(newConf))
+
+	info, err := GetDebugInfo()
+	require.NoError(t, err)
+	assert.Equal(t, EnvBackend, info.DefaultBackend)
+	assert.Equal(t, []string{EnvBackend, FileBackend}, info.NativeBackends)
+	assert.Equal(t, map[string]string{"PASS1": EnvBackend, "file@pass2": FileBackend}, info.SecretsBackends)
+}
+
+func TestDecryptWithCommandAndNativeBackends(t *testing.T) {
+	defer resetBackends()
+	os.Setenv("TEST_SECRET_PASS1", "password1")
+	defer os.Unsetenv("TEST_SECRET_PASS1")
+
+	require.NoError(t, InitBackends(BackendsConfig{Env: EnvBackendConfig{Enabled: true, Prefix: "TEST_SECRET_"}}))
+	runCommand = func(payload string) ([]byte, error) {
+		assert.Equal(t, `{"secrets":["pass2"],"version":"1.0"}`, payload)
+		return []byte(`{"pass2":{"value":"password2"}}`), nil
+	}
+
+	conf := []byte("pass1: ENC[env@PASS1]\npass2: ENC[pass2]\n")
+	newConf, err := Decrypt(conf, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "pass1: password1\npass2: password2\n", string(newConf))
+	assert.Equal(t, map[string]string{"env@PASS1": EnvBackend, "pass2": CommandBackend}, secretBackend)
+}
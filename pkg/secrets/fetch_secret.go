@@ -112,10 +112,28 @@ type Secret struct {
 // for testing purpose
 var runCommand = execCommand
 
-// fetchSecret receives a list of secrets name to fetch, exec a custom
-// executable to fetch the actual secrets and returns them. Origin should be
-// the name of the configuration where the secret was referenced.
+// fetchSecret receives a list of secrets name to fetch, fetches them from their
+// backend, by default by executing a custom executable, and returns them.
+// Origin should be the name of the configuration where the secret was
+// referenced.
 func fetchSecret(secretsHandle []string, origin string) (map[string]string, error) {
+	res, backends, err := fetchHandles(secretsHandle)
+	if err != nil {
+		return nil, err
+	}
+
+	for sec, value := range res {
+		// add it to the cache
+		secretCache[sec] = value
+		// keep track of place where a handle was found
+		secretOrigin[sec] = common.NewStringSet(origin)
+		secretBackend[sec] = backends[sec]
+	}
+	return res, nil
+}
+
+// fetchFromCommand exec the secret_backend_command to fetch the given secrets
+func fetchFromCommand(secretsHandle []string) (map[string]string, error) {
 	payload := map[string]interface{}{
 		"version": PayloadVersion,
 		"secrets": secretsHandle,
@@ -148,11 +166,6 @@ func fetchSecret(secretsHandle []string, origin string) (map[string]string, erro
 		if v.Value == "" {
 			return nil, fmt.Errorf("decrypted secret for '%s' is empty", sec)
 		}
-
-		// add it to the cache
-		secretCache[sec] = v.Value
-		// keep track of place where a handle was found
-		secretOrigin[sec] = common.NewStringSet(origin)
 		res[sec] = v.Value
 	}
 	return res, nil
@@ -138,6 +138,7 @@ func TestExecCommandError(t *testing.T) {
 	// test buffer limit
 	secretBackendCommand = "./test/response_too_long/response_too_long" + binExtension
 	setCorrectRight(secretBackendCommand)
+	defer func(size int) { SecretBackendOutputMaxSize = size }(SecretBackendOutputMaxSize)
 	SecretBackendOutputMaxSize = 20
 	_, err = execCommand(inputPayload)
 	require.NotNil(t, err)
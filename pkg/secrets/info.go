@@ -19,28 +19,46 @@ type SecretInfo struct {
 	RightDetails   string
 	UnixOwner      string
 	UnixGroup      string
+	// DefaultBackend decrypts the handles without a backend prefix
+	DefaultBackend string
+	// NativeBackends are the backends running in the Agent process
+	NativeBackends []string
 	SecretsHandles map[string][]string
+	// SecretsBackends is the backend which decrypted each handle
+	SecretsBackends map[string]string
 }
 
 // Print output a SecretInfo to a io.Writer
 func (si *SecretInfo) Print(w io.Writer) {
-	fmt.Fprintf(w, "=== Checking executable rights ===\n")
-	fmt.Fprintf(w, "Executable path: %s\n", si.ExecutablePath)
+	fmt.Fprintf(w, "=== Secret backends ===\n")
+	fmt.Fprintf(w, "Default backend: %s\n", si.DefaultBackend)
+	if len(si.NativeBackends) > 0 {
+		fmt.Fprintf(w, "Native backends: %s\n", strings.Join(si.NativeBackends, ", "))
+	}
+
+	if si.ExecutablePath != "" {
+		fmt.Fprintf(w, "\n=== Checking executable rights ===\n")
+		fmt.Fprintf(w, "Executable path: %s\n", si.ExecutablePath)
 
-	fmt.Fprintf(w, "Check Rights: %s\n", si.Rights)
+		fmt.Fprintf(w, "Check Rights: %s\n", si.Rights)
 
-	fmt.Fprintf(w, "\nRights Detail:\n")
-	fmt.Fprintf(w, "%s\n", si.RightDetails)
+		fmt.Fprintf(w, "\nRights Detail:\n")
+		fmt.Fprintf(w, "%s\n", si.RightDetails)
 
-	if runtime.GOOS != "windows" {
-		fmt.Fprintf(w, "Owner username: %s\n", si.UnixOwner)
-		fmt.Fprintf(w, "Group name: %s\n", si.UnixGroup)
+		if runtime.GOOS != "windows" {
+			fmt.Fprintf(w, "Owner username: %s\n", si.UnixOwner)
+			fmt.Fprintf(w, "Group name: %s\n", si.UnixGroup)
+		}
 	}
 
 	fmt.Fprintf(w, "\n=== Secrets stats ===\n")
 	fmt.Fprintf(w, "Number of secrets decrypted: %d\n", len(si.SecretsHandles))
 	fmt.Fprintf(w, "Secrets handle decrypted:\n")
 	for handle, origins := range si.SecretsHandles {
-		fmt.Fprintf(w, "- %s: from %s\n", handle, strings.Join(origins, ", "))
+		if backend, ok := si.SecretsBackends[handle]; ok && backend != "" {
+			fmt.Fprintf(w, "- %s: from %s, decrypted by the %s backend\n", handle, strings.Join(origins, ", "), backend)
+		} else {
+			fmt.Fprintf(w, "- %s: from %s\n", handle, strings.Join(origins, ", "))
+		}
 	}
 }
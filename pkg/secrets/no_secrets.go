@@ -20,6 +20,21 @@ var SecretBackendOutputMaxSize = 1024 * 1024
 // Init placeholder when compiled without the 'secrets' build tag
 func Init(command string, arguments []string, timeout int, maxSize int, groupExecPerm bool) {}
 
+// InitBackends placeholder when compiled without the 'secrets' build tag
+func InitBackends(cfg BackendsConfig) error {
+	return nil
+}
+
+// Enabled returns false as secrets are not available in this version of the agent
+func Enabled() bool {
+	return false
+}
+
+// StartRefresh placeholder when compiled without the 'secrets' build tag
+func StartRefresh(handler RefreshHandler) func() {
+	return func() {}
+}
+
 // Decrypt encrypted secrets are not available on windows
 func Decrypt(data []byte, origin string) ([]byte, error) {
 	return data, nil
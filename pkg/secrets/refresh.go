@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build secrets
+// +build secrets
+
+package secrets
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// StartRefresh fetches again the decrypted secrets every
+// secret_backend_refresh_interval, and calls handler with the secrets whose
+// value changed. It returns a function stopping the refresh.
+func StartRefresh(handler RefreshHandler) func() {
+	secretMutex.Lock()
+	interval := refreshInterval
+	secretMutex.Unlock()
+
+	if interval <= 0 || !Enabled() {
+		return func() {}
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if rotated := refreshSecrets(); len(rotated) > 0 {
+					handler(rotated)
+				}
+			}
+		}
+	}()
+	return func() { close(stop) }
+}
+
+// refreshSecrets fetches again the secrets of the cache, updates the ones
+// whose value changed and returns them with their origins. The backends are
+// queried without holding secretMutex so that a slow backend doesn't block
+// Decrypt.
+func refreshSecrets() map[string][]string {
+	secretMutex.Lock()
+	handles := make([]string, 0, len(secretCache))
+	for handle := range secretCache {
+		handles = append(handles, handle)
+	}
+	byBackend := groupHandles(handles)
+	secretMutex.Unlock()
+
+	if len(handles) == 0 {
+		return nil
+	}
+
+	values, backends, err := fetchGroups(byBackend)
+	if err != nil {
+		log.Warnf("Could not refresh all the secrets, the previous values are kept for the ones which failed: %s", err)
+	}
+
+	secretMutex.Lock()
+	defer secretMutex.Unlock()
+
+	rotated := map[string][]string{}
+	for handle, value := range values {
+		previous, ok := secretCache[handle]
+		// the cache may have been reset while the secrets were fetched
+		if !ok || previous == value {
+			continue
+		}
+		log.Infof("Secret '%s' was rotated in the %s backend", handle, backends[handle])
+		secretCache[handle] = value
+		secretBackend[handle] = backends[handle]
+		if origins, ok := secretOrigin[handle]; ok {
+			rotated[handle] = origins.GetAll()
+		} else {
+			rotated[handle] = []string{}
+		}
+	}
+	return rotated
+}
@@ -0,0 +1,181 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build secrets
+// +build secrets
+
+package secrets
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshSecrets(t *testing.T) {
+	defer resetBackends()
+	os.Setenv("TEST_SECRET_PASS1", "password1")
+	os.Setenv("TEST_SECRET_PASS2", "password2")
+	defer os.Unsetenv("TEST_SECRET_PASS1")
+	defer os.Unsetenv("TEST_SECRET_PASS2")
+
+	require.NoError(t, InitBackends(BackendsConfig{Type: EnvBackend, Env: EnvBackendConfig{Prefix: "TEST_SECRET_"}}))
+	_, err := Decrypt([]byte("pass1: ENC[PASS1]\npass2: ENC[PASS2]\n"), "test")
+	require.NoError(t, err)
+
+	assert.Empty(t, refreshSecrets())
+
+	os.Setenv("TEST_SECRET_PASS1", "rotated")
+	assert.Equal(t, map[string][]string{"PASS1": {"test"}}, refreshSecrets())
+	assert.Equal(t, "rotated", secretCache["PASS1"])
+
+	// the previous value is kept when the secret can't be fetched
+	os.Unsetenv("TEST_SECRET_PASS2")
+	assert.Empty(t, refreshSecrets())
+	assert.Equal(t, "password2", secretCache["PASS2"])
+}
+
+// blockingBackend returns its values once release is closed
+type blockingBackend struct {
+	started chan struct{}
+	release chan struct{}
+	values  map[string]string
+}
+
+func (b *blockingBackend) fetch(secretIDs []string) (map[string]string, error) {
+	close(b.started)
+	<-b.release
+	return b.values, nil
+}
+
+func TestRefreshSecretsDoesNotHoldLock(t *testing.T) {
+	defer resetBackends()
+	b := &blockingBackend{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		values:  map[string]string{"PASS1": "rotated", "PASS2": "rotated"},
+	}
+	nativeBackends = map[string]backend{"blocking": b}
+	defaultBackend = "blocking"
+	secretCache = map[string]string{"PASS1": "password1", "PASS2": "password2"}
+
+	done := make(chan map[string][]string)
+	go func() { done <- refreshSecrets() }()
+	<-b.started
+
+	// the cache can be used while the backend is queried
+	secretMutex.Lock()
+	delete(secretCache, "PASS2")
+	secretMutex.Unlock()
+	close(b.release)
+
+	assert.Equal(t, map[string][]string{"PASS1": {}}, <-done)
+	assert.Equal(t, map[string]string{"PASS1": "rotated"}, secretCache)
+}
+
+// newSingleUseTokenVaultServer mocks a Vault server whose AppRole client
+// tokens can only be used once, so that each fetch logs in again. The returned
+// channel receives a value when a request is received.
+func newSingleUseTokenVaultServer(t *testing.T) (*httptest.Server, chan struct{}) {
+	var m sync.Mutex
+	tokens := map[string]bool{}
+	logins := 0
+	requested := make(chan struct{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		// slow requests let the concurrent fetches overlap
+		time.Sleep(time.Millisecond)
+
+		m.Lock()
+		defer m.Unlock()
+		if r.URL.Path == "/v1/auth/approle/login" {
+			logins++
+			token := fmt.Sprintf("token-%d", logins)
+			tokens[token] = true
+			fmt.Fprintf(w, `{"auth":{"client_token":"%s"}}`, token)
+			return
+		}
+		token := r.Header.Get("X-Vault-Token")
+		if !tokens[token] {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"errors":["permission denied"]}`)
+			return
+		}
+		delete(tokens, token)
+		fmt.Fprint(w, `{"data":{"data":{"password":"p1","user":"datadog"}}}`)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, requested
+}
+
+func TestRefreshSecretsConcurrentDecrypt(t *testing.T) {
+	defer resetBackends()
+	ts, requested := newSingleUseTokenVaultServer(t)
+	require.NoError(t, InitBackends(BackendsConfig{
+		Type:  VaultBackend,
+		Vault: VaultBackendConfig{Address: ts.URL, AuthMethod: "approle", RoleID: "role", SecretID: "secret"},
+	}))
+	_, err := Decrypt([]byte("pass: ENC[datadog/mysql#password]\n"), "test")
+	require.NoError(t, err)
+
+	// Decrypt queries the vault backend, and logs in again, while the refresh
+	// is querying it
+	for i := 0; i < 10; i++ {
+		secretMutex.Lock()
+		delete(secretCache, "datadog/mysql#user")
+		secretMutex.Unlock()
+		select {
+		case <-requested:
+		default:
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Empty(t, refreshSecrets())
+		}()
+		<-requested
+		res, err := Decrypt([]byte("user: ENC[datadog/mysql#user]\n"), "test")
+		require.NoError(t, err)
+		assert.Equal(t, "user: datadog\n", string(res))
+		wg.Wait()
+	}
+}
+
+func TestStartRefresh(t *testing.T) {
+	defer resetBackends()
+	os.Setenv("TEST_SECRET_PASS1", "password1")
+	defer os.Unsetenv("TEST_SECRET_PASS1")
+
+	stop := StartRefresh(func(map[string][]string) { assert.Fail(t, "the refresh is disabled") })
+	stop()
+
+	require.NoError(t, InitBackends(BackendsConfig{Type: EnvBackend, Env: EnvBackendConfig{Prefix: "TEST_SECRET_"}, RefreshInterval: 10 * time.Millisecond}))
+	_, err := Decrypt([]byte("pass1: ENC[PASS1]\n"), "test")
+	require.NoError(t, err)
+
+	rotated := make(chan map[string][]string, 1)
+	stop = StartRefresh(func(r map[string][]string) { rotated <- r })
+	defer stop()
+
+	os.Setenv("TEST_SECRET_PASS1", "rotated")
+	select {
+	case r := <-rotated:
+		assert.Equal(t, map[string][]string{"PASS1": {"test"}}, r)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the rotated secret was not refreshed")
+	}
+}
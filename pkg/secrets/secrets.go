@@ -10,7 +10,9 @@ package secrets
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -22,6 +24,10 @@ var (
 	secretCache map[string]string
 	// list of handles and where they were found
 	secretOrigin map[string]common.StringSet
+	// backend which decrypted each handle
+	secretBackend map[string]string
+	// secretMutex protects the cache from the refresh of the secrets
+	secretMutex sync.Mutex
 
 	secretBackendCommand               string
 	secretBackendArguments             []string
@@ -35,6 +41,7 @@ var (
 func init() {
 	secretCache = make(map[string]string)
 	secretOrigin = make(map[string]common.StringSet)
+	secretBackend = make(map[string]string)
 }
 
 // Init initializes the command and other options of the secrets package. Since
@@ -127,13 +134,17 @@ func isEnc(str string) (bool, string) {
 // testing purpose
 var secretFetcher = fetchSecret
 
-// Decrypt replaces all encrypted secrets in data by executing
-// "secret_backend_command" once if all secrets aren't present in the cache.
+// Decrypt replaces all encrypted secrets in data by fetching them from their
+// backend, e.g. by executing "secret_backend_command" once, if all secrets
+// aren't present in the cache.
 func Decrypt(data []byte, origin string) ([]byte, error) {
-	if data == nil || secretBackendCommand == "" {
+	if data == nil || !Enabled() {
 		return data, nil
 	}
 
+	secretMutex.Lock()
+	defer secretMutex.Unlock()
+
 	var config interface{}
 	err := yaml.Unmarshal(data, &config)
 	if err != nil {
@@ -177,7 +188,7 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 		err = walk(&config, func(str string) (string, error) {
 			if ok, handle := isEnc(str); ok {
 				if secret, ok := secrets[handle]; ok {
-					log.Debugf("Secret '%s' was retrieved from executable", handle)
+					log.Debugf("Secret '%s' was retrieved from its backend", handle)
 					return secret, nil
 				}
 				// This should never happen since fetchSecret will return an error
@@ -200,15 +211,28 @@ func Decrypt(data []byte, origin string) ([]byte, error) {
 
 // GetDebugInfo exposes debug informations about secrets to be included in a flare
 func GetDebugInfo() (*SecretInfo, error) {
-	if secretBackendCommand == "" {
-		return nil, fmt.Errorf("No secret_backend_command set: secrets feature is not enabled")
+	if !Enabled() {
+		return nil, fmt.Errorf("No secret_backend_command or secret_backend_type set: secrets feature is not enabled")
 	}
 	info := &SecretInfo{ExecutablePath: secretBackendCommand}
-	info.populateRights()
+	if secretBackendCommand != "" {
+		info.populateRights()
+	}
+
+	secretMutex.Lock()
+	defer secretMutex.Unlock()
+
+	info.DefaultBackend = defaultBackend
+	for name := range nativeBackends {
+		info.NativeBackends = append(info.NativeBackends, name)
+	}
+	sort.Strings(info.NativeBackends)
 
 	info.SecretsHandles = map[string][]string{}
+	info.SecretsBackends = map[string]string{}
 	for handle, originNames := range secretOrigin {
 		info.SecretsHandles[handle] = originNames.GetAll()
+		info.SecretsBackends[handle] = secretBackend[handle]
 	}
 	return info, nil
 }
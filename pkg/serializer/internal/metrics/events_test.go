@@ -25,6 +25,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func TestMarshal(t *testing.T) {
@@ -324,7 +325,7 @@ func BenchmarkCreateSingleMarshalerOneEventBySource(b *testing.B) {
 
 func benchmarkCreateSingleMarshaler(b *testing.B, createEvents func(numberOfItem int) Events) {
 	runBenchmark(b, func(b *testing.B, numberOfItem int) {
-		payloadBuilder := stream.NewJSONPayloadBuilder(true)
+		payloadBuilder := stream.NewJSONPayloadBuilder(true, compression.NewCompressor(compression.ZlibKind, 0))
 		events := createEvents(numberOfItem)
 
 		b.ResetTimer()
@@ -337,7 +338,7 @@ func benchmarkCreateSingleMarshaler(b *testing.B, createEvents func(numberOfItem
 
 func BenchmarkCreateMarshalersBySourceType(b *testing.B) {
 	runBenchmark(b, func(b *testing.B, numberOfItem int) {
-		payloadBuilder := stream.NewJSONPayloadBuilder(true)
+		payloadBuilder := stream.NewJSONPayloadBuilder(true, compression.NewCompressor(compression.ZlibKind, 0))
 		events := createBenchmarkEvents(numberOfItem)
 
 		b.ResetTimer()
@@ -352,7 +353,7 @@ func BenchmarkCreateMarshalersBySourceType(b *testing.B) {
 
 func BenchmarkCreateMarshalersSeveralSourceTypes(b *testing.B) {
 	runBenchmark(b, func(b *testing.B, numberOfItem int) {
-		payloadBuilder := stream.NewJSONPayloadBuilder(true)
+		payloadBuilder := stream.NewJSONPayloadBuilder(true, compression.NewCompressor(compression.ZlibKind, 0))
 
 		var events Events
 		// Half of events have the same source type
@@ -176,7 +176,7 @@ func marshalSplitCompress(iterator serieIterator, bufferContext *marshaler.Buffe
 		bufferContext.CompressorInput.Reset()
 		bufferContext.CompressorOutput.Reset()
 
-		compressor, err = stream.NewCompressor(bufferContext.CompressorInput, bufferContext.CompressorOutput, []byte{}, []byte{}, []byte{})
+		compressor, err = stream.NewCompressor(bufferContext.CompressorInput, bufferContext.CompressorOutput, []byte{}, []byte{}, []byte{}, bufferContext.Compressor)
 		if err != nil {
 			return err
 		}
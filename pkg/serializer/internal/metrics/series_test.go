@@ -25,6 +25,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -441,7 +442,7 @@ func TestPayloadsSeries(t *testing.T) {
 	}
 
 	originalLength := len(testSeries)
-	builder := stream.NewJSONPayloadBuilder(true)
+	builder := stream.NewJSONPayloadBuilder(true, compression.NewCompressor(compression.ZlibKind, 0))
 	payloads, err := builder.Build(testSeries)
 	require.Nil(t, err)
 	var splitSeries = []Series{}
@@ -485,7 +486,7 @@ func BenchmarkPayloadsSeries(b *testing.B) {
 	}
 
 	var r forwarder.Payloads
-	builder := stream.NewJSONPayloadBuilder(true)
+	builder := stream.NewJSONPayloadBuilder(true, compression.NewCompressor(compression.ZlibKind, 0))
 	for n := 0; n < b.N; n++ {
 		// always record the result of Payloads to prevent
 		// the compiler eliminating the function call.
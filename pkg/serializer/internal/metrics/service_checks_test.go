@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func TestMarshalJSONServiceChecks(t *testing.T) {
@@ -74,7 +75,7 @@ func createServiceCheck(checkName string) *metrics.ServiceCheck {
 }
 
 func buildPayload(t *testing.T, m marshaler.StreamJSONMarshaler) [][]byte {
-	builder := stream.NewJSONPayloadBuilder(true)
+	builder := stream.NewJSONPayloadBuilder(true, compression.NewCompressor(compression.ZlibKind, 0))
 	payloads, err := builder.Build(m)
 	assert.NoError(t, err)
 	var uncompressedPayloads [][]byte
@@ -152,7 +153,7 @@ func createServiceChecks(numberOfItem int) ServiceChecks {
 }
 
 func benchmarkJSONPayloadBuilderServiceCheck(b *testing.B, numberOfItem int) {
-	payloadBuilder := stream.NewJSONPayloadBuilder(true)
+	payloadBuilder := stream.NewJSONPayloadBuilder(true, compression.NewCompressor(compression.ZlibKind, 0))
 	serviceChecks := createServiceChecks(numberOfItem)
 
 	b.ResetTimer()
@@ -193,7 +194,7 @@ func benchmarkPayloadsServiceCheck(b *testing.B, numberOfItem int) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		split.Payloads(serviceChecks, true, split.JSONMarshalFct)
+		split.Payloads(serviceChecks, true, split.JSONMarshalFct, compression.NewCompressor(compression.ZlibKind, 0))
 	}
 }
 
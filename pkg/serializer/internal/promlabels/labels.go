@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package promlabels converts the Datadog metric names and tags to
+// Prometheus metric names and labels.
+package promlabels
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+const (
+	// MetricNameLabel is the label holding the metric name
+	MetricNameLabel = "__name__"
+	// QuantileLabel is the label holding the quantile of a summary
+	QuantileLabel = "quantile"
+
+	hostLabel   = "host"
+	deviceLabel = "device"
+
+	// valuelessTagValue is the label value used for the tags without value
+	valuelessTagValue = "true"
+)
+
+// Label is a Prometheus label
+type Label struct {
+	Name  string
+	Value string
+}
+
+// SanitizeMetricName converts a Datadog metric name to a valid Prometheus
+// metric name, matching [a-zA-Z_:][a-zA-Z0-9_:]*
+func SanitizeMetricName(name string) string {
+	return sanitize(name, true)
+}
+
+// SanitizeLabelName converts a Datadog tag key to a valid Prometheus label
+// name, matching [a-zA-Z_][a-zA-Z0-9_]*
+func SanitizeLabelName(name string) string {
+	return sanitize(name, false)
+}
+
+func sanitize(name string, allowColon bool) string {
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', allowColon && r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// Build returns the labels of a metric, sorted by name as required by
+// the remote-write protocol. Tags are split on their first colon, the values
+// of tags sharing the same key are joined with a comma.
+func Build(name string, host string, device string, tags tagset.CompositeTags) []Label {
+	values := make(map[string][]string, tags.Len()+2)
+	add := func(name, value string) {
+		values[name] = append(values[name], value)
+	}
+
+	tags.ForEach(func(tag string) {
+		key, value := tag, valuelessTagValue
+		if i := strings.IndexByte(tag, ':'); i >= 0 {
+			key, value = tag[:i], tag[i+1:]
+		}
+		key = SanitizeLabelName(key)
+		// labels starting with __ are reserved by Prometheus and the quantile
+		// label is set on the summaries
+		if key == MetricNameLabel || key == QuantileLabel || strings.HasPrefix(key, "__") {
+			key = "tag_" + key
+		}
+		add(key, value)
+	})
+	if host != "" {
+		if _, found := values[hostLabel]; !found {
+			add(hostLabel, host)
+		}
+	}
+	if device != "" {
+		if _, found := values[deviceLabel]; !found {
+			add(deviceLabel, device)
+		}
+	}
+
+	labels := make([]Label, 0, len(values)+1)
+	labels = append(labels, Label{Name: MetricNameLabel, Value: SanitizeMetricName(name)})
+	for key, v := range values {
+		sort.Strings(v)
+		labels = append(labels, Label{Name: key, Value: strings.Join(v, ",")})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+// With returns a copy of the labels with the given label added, keeping
+// them sorted by name.
+func With(labels []Label, l Label) []Label {
+	result := make([]Label, 0, len(labels)+1)
+	added := false
+	for _, existing := range labels {
+		if !added && l.Name < existing.Name {
+			result = append(result, l)
+			added = true
+		}
+		result = append(result, existing)
+	}
+	if !added {
+		result = append(result, l)
+	}
+	return result
+}
+
+// WithMetricName returns a copy of the labels with a different metric name.
+func WithMetricName(labels []Label, name string) []Label {
+	result := make([]Label, len(labels))
+	copy(result, labels)
+	for i := range result {
+		if result[i].Name == MetricNameLabel {
+			result[i].Value = name
+		}
+	}
+	return result
+}
+
+// FormatFloat formats a label value the way Prometheus formats the quantiles.
+func FormatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
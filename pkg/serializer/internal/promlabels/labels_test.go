@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package promlabels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func TestSanitizeNames(t *testing.T) {
+	assert.Equal(t, "system_cpu_user", SanitizeMetricName("system.cpu.user"))
+	assert.Equal(t, "ns:metric_name", SanitizeMetricName("ns:metric-name"))
+	assert.Equal(t, "_2xx", SanitizeMetricName("2xx"))
+	assert.Equal(t, "kube_namespace", SanitizeLabelName("kube.namespace"))
+	assert.Equal(t, "a_b", SanitizeLabelName("a:b"))
+	assert.Equal(t, "_", SanitizeLabelName(""))
+}
+
+func TestBuild(t *testing.T) {
+	tags := tagset.CompositeTagsFromSlice([]string{"env:prod", "role:db", "role:cache", "ready", "__name__:foo", "quantile:0.5", "url:http://foo"})
+	labels := Build("my.metric", "myhost", "sda", tags)
+
+	assert.Equal(t, []Label{
+		{Name: "__name__", Value: "my_metric"},
+		{Name: "device", Value: "sda"},
+		{Name: "env", Value: "prod"},
+		{Name: "host", Value: "myhost"},
+		{Name: "ready", Value: "true"},
+		{Name: "role", Value: "cache,db"},
+		{Name: "tag___name__", Value: "foo"},
+		{Name: "tag_quantile", Value: "0.5"},
+		{Name: "url", Value: "http://foo"},
+	}, labels)
+}
+
+func TestBuildHostTag(t *testing.T) {
+	// a host tag takes precedence over the host of the metric
+	labels := Build("m", "myhost", "", tagset.CompositeTagsFromSlice([]string{"host:other"}))
+	assert.Equal(t, []Label{{Name: "__name__", Value: "m"}, {Name: "host", Value: "other"}}, labels)
+}
+
+func TestWith(t *testing.T) {
+	labels := []Label{{Name: "__name__", Value: "m"}, {Name: "z", Value: "1"}}
+	assert.Equal(t, []Label{{Name: "__name__", Value: "m"}, {Name: "quantile", Value: "0.5"}, {Name: "z", Value: "1"}},
+		With(labels, Label{Name: "quantile", Value: "0.5"}))
+	assert.Equal(t, []Label{{Name: "__name__", Value: "m"}, {Name: "z", Value: "1"}, {Name: "zz", Value: "2"}},
+		With(labels, Label{Name: "zz", Value: "2"}))
+	// the original labels are untouched
+	assert.Len(t, labels, 2)
+
+	assert.Equal(t, []Label{{Name: "__name__", Value: "m_sum"}, {Name: "z", Value: "1"}}, WithMetricName(labels, "m_sum"))
+	assert.Equal(t, "m", labels[0].Value)
+}
@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package promlabels
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// SummaryQuantiles returns the quantiles of the setting used to expose the
+// sketches as summaries, without the quantiles outside of [0, 1].
+func SummaryQuantiles(setting string) []float64 {
+	quantiles, err := config.Datadog.GetFloat64SliceE(setting)
+	if err != nil {
+		log.Warnf("Invalid %s: %v", setting, err)
+	}
+	validQuantiles := make([]float64, 0, len(quantiles))
+	for _, q := range quantiles {
+		if q < 0 || q > 1 {
+			log.Warnf("Ignoring invalid quantile %v in %s", q, setting)
+			continue
+		}
+		validQuantiles = append(validQuantiles, q)
+	}
+	return validQuantiles
+}
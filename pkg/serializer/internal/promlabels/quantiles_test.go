@@ -0,0 +1,22 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package promlabels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestSummaryQuantiles(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("openmetrics_endpoint.summary_quantiles", []string{"-0.1", "0", "0.5", "1", "1.5"})
+	defer mockConfig.Set("openmetrics_endpoint.summary_quantiles", []string{"0.5", "0.75", "0.95", "0.99"})
+
+	assert.Equal(t, []float64{0, 0.5, 1}, SummaryQuantiles("openmetrics_endpoint.summary_quantiles"))
+}
@@ -18,12 +18,15 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 var (
 	maxPayloadSizeDefault = config.Datadog.GetInt("serializer_max_payload_size")
 )
 
+var zlibCompressor = compression.NewCompressor(compression.ZlibKind, 1)
+
 func resetDefaults() {
 	config.Datadog.SetDefault("serializer_max_payload_size", maxPayloadSizeDefault)
 }
@@ -51,7 +54,7 @@ func payloadToString(payload []byte) string {
 }
 
 func TestCompressorSimple(t *testing.T) {
-	c, err := NewCompressor(&bytes.Buffer{}, &bytes.Buffer{}, []byte("{["), []byte("]}"), []byte(","))
+	c, err := NewCompressor(&bytes.Buffer{}, &bytes.Buffer{}, []byte("{["), []byte("]}"), []byte(","), zlibCompressor)
 	require.NoError(t, err)
 
 	for i := 0; i < 5; i++ {
@@ -70,7 +73,7 @@ func TestOnePayloadSimple(t *testing.T) {
 		Footer: "]}",
 	}
 
-	builder := NewJSONPayloadBuilder(true)
+	builder := NewJSONPayloadBuilder(true, zlibCompressor)
 	payloads, err := builder.Build(m)
 	require.NoError(t, err)
 	require.Len(t, payloads, 1)
@@ -87,7 +90,7 @@ func TestMaxCompressedSizePayload(t *testing.T) {
 	config.Datadog.SetDefault("serializer_max_payload_size", 22)
 	defer resetDefaults()
 
-	builder := NewJSONPayloadBuilder(true)
+	builder := NewJSONPayloadBuilder(true, zlibCompressor)
 	payloads, err := builder.Build(m)
 	require.NoError(t, err)
 	require.Len(t, payloads, 1)
@@ -104,7 +107,7 @@ func TestTwoPayload(t *testing.T) {
 	config.Datadog.SetDefault("serializer_max_payload_size", 22)
 	defer resetDefaults()
 
-	builder := NewJSONPayloadBuilder(true)
+	builder := NewJSONPayloadBuilder(true, zlibCompressor)
 	payloads, err := builder.Build(m)
 	require.NoError(t, err)
 	require.Len(t, payloads, 2)
@@ -121,8 +124,8 @@ func TestLockedCompressorProducesSamePayloads(t *testing.T) {
 	}
 	defer resetDefaults()
 
-	builderLocked := NewJSONPayloadBuilder(true)
-	builderUnLocked := NewJSONPayloadBuilder(false)
+	builderLocked := NewJSONPayloadBuilder(true, zlibCompressor)
+	builderUnLocked := NewJSONPayloadBuilder(false, zlibCompressor)
 	payloads1, err := builderLocked.Build(m)
 	require.NoError(t, err)
 	payloads2, err := builderUnLocked.Build(m)
@@ -130,3 +133,21 @@ func TestLockedCompressorProducesSamePayloads(t *testing.T) {
 
 	require.Equal(t, payloadToString(*payloads1[0]), payloadToString(*payloads2[0]))
 }
+
+func TestZstdCompressorPayload(t *testing.T) {
+	m := &marshaler.DummyMarshaller{
+		Items:  []string{"A", "B", "C", "D", "E", "F"},
+		Header: "{[",
+		Footer: "]}",
+	}
+
+	zstdCompressor := compression.NewCompressor(compression.ZstdKind, 1)
+	builder := NewJSONPayloadBuilder(true, zstdCompressor)
+	payloads, err := builder.Build(m)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	decompressed, err := zstdCompressor.Decompress(*payloads[0])
+	require.NoError(t, err)
+	require.Equal(t, "{[A,B,C,D,E,F]}", string(decompressed))
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func benchmarkJSONPayloadBuilderThroughput(points int, items int, tags int, runs int) { //nolint:unuse
@@ -22,7 +23,7 @@ func benchmarkJSONPayloadBuilderThroughput(points int, items int, tags int, runs
 	initialSize := len(json)
 	metricsCount := len(series)
 
-	payloadBuilder := stream.NewJSONPayloadBuilder(true)
+	payloadBuilder := stream.NewJSONPayloadBuilder(true, compression.NewCompressor(compression.ZlibKind, 0))
 	var totalTime time.Duration
 
 	for i := 0; i < runs; i++ {
@@ -9,6 +9,8 @@ import (
 	"bytes"
 
 	jsoniter "github.com/json-iterator/go"
+
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 // JSONMarshaler is a AbstractMarshaler that implement JSON marshaling.
@@ -66,13 +68,15 @@ type BufferContext struct {
 	CompressorInput   *bytes.Buffer
 	CompressorOutput  *bytes.Buffer
 	PrecompressionBuf *bytes.Buffer
+	Compressor        compression.Compressor
 }
 
-// DefaultBufferContext initialize the default compression buffers
+// DefaultBufferContext initialize the default compression buffers, compressing with zlib
 func DefaultBufferContext() *BufferContext {
 	return &BufferContext{
 		bytes.NewBuffer(make([]byte, 0, 1024)),
 		bytes.NewBuffer(make([]byte, 0, 1024)),
 		bytes.NewBuffer(make([]byte, 0, 1024)),
+		compression.NewCompressor(compression.ZlibKind, 0),
 	}
 }
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+)
+
+// snapshotSerializer records the series and sketches in the snapshot before
+// sending them through the wrapped serializer.
+type snapshotSerializer struct {
+	serializer.MetricSerializer
+	snapshot *Snapshot
+}
+
+// NewSnapshotSerializer returns a serializer.MetricSerializer sending the
+// payloads through s, and updating the snapshot with the series and sketches.
+func NewSnapshotSerializer(s serializer.MetricSerializer, snapshot *Snapshot) serializer.MetricSerializer {
+	return &snapshotSerializer{
+		MetricSerializer: s,
+		snapshot:         snapshot,
+	}
+}
+
+// SendSeries updates the snapshot and sends the series.
+func (s *snapshotSerializer) SendSeries(series metrics.Series) error {
+	s.snapshot.UpdateSeries(series)
+	return s.MetricSerializer.SendSeries(series)
+}
+
+// SendSketch updates the snapshot and sends the sketches.
+func (s *snapshotSerializer) SendSketch(sketches metrics.SketchSeriesList) error {
+	s.snapshot.UpdateSketches(sketches)
+	return s.MetricSerializer.SendSketch(sketches)
+}
+
+// IsIterableSeriesSupported returns false: an IterableSeries can only be
+// consumed once, so the series are flushed as a whole to be recorded.
+func (s *snapshotSerializer) IsIterableSeriesSupported() bool {
+	return false
+}
@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	contentType    = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	defaultTimeout = 5 * time.Second
+)
+
+// Serve starts the http server exposing the snapshot on /metrics, on the
+// loopback interface only. It returns an error if the setup failed, or runs
+// the server in a goroutine. Stop the server by cancelling the passed context.
+func Serve(ctx context.Context, port int, snapshot *Snapshot) error {
+	if port == 0 {
+		return errors.New("port should be non-zero")
+	}
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%v", port))
+	if err != nil {
+		return err
+	}
+
+	r := mux.NewRouter()
+	r.Handle("/metrics", snapshot).Methods("GET")
+
+	srv := &http.Server{
+		Handler:           r,
+		ReadTimeout:       defaultTimeout,
+		ReadHeaderTimeout: defaultTimeout,
+		WriteTimeout:      defaultTimeout,
+	}
+
+	go srv.Serve(ln) //nolint:errcheck
+	go closeOnContext(ctx, srv)
+	return nil
+}
+
+func closeOnContext(ctx context.Context, srv *http.Server) {
+	// Wait for the context to be canceled
+	<-ctx.Done()
+
+	// Shutdown the server, it will close the listener
+	timeout, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	srv.Shutdown(timeout) //nolint:errcheck
+}
+
+// ServeHTTP writes the metrics of the snapshot in the OpenMetrics text format.
+// The `prefix` query parameter, which can be repeated, only keeps the metrics
+// whose Datadog or OpenMetrics name starts with one of the given prefixes.
+func (s *Snapshot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+
+	buf := bufio.NewWriter(w)
+	family := ""
+	kind := ""
+	for _, m := range s.metrics(r.URL.Query()["prefix"]) {
+		if m.family != family {
+			family, kind = m.family, m.kind
+			fmt.Fprintf(buf, "# TYPE %s %s\n", family, kind)
+		} else if m.kind != kind {
+			// a family has a single type, this happens when a series and a
+			// sketch have the same name once sanitized
+			continue
+		}
+		for _, line := range m.lines {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	buf.WriteString("# EOF\n")
+	buf.Flush() //nolint:errcheck
+}
@@ -0,0 +1,179 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package openmetrics exposes the last series and sketches flushed by the
+// aggregator in the OpenMetrics text format, to see locally what the Agent
+// sends without going to the backend.
+package openmetrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/serializer/internal/promlabels"
+)
+
+// Metric family types
+const (
+	gaugeType   = "gauge"
+	summaryType = "summary"
+)
+
+// metric is a series or a sketch rendered in the OpenMetrics text format
+type metric struct {
+	// name is the Datadog name of the metric, used to filter the metrics
+	name string
+	// family is the OpenMetrics metric family name
+	family string
+	kind   string
+	// lines are the rendered samples, without line feed
+	lines []string
+}
+
+// Snapshot holds the last flushed series and sketches. They are rendered when
+// they are flushed so that the snapshot doesn't keep a reference to data
+// owned by the aggregator.
+type Snapshot struct {
+	quantiles []float64
+
+	m        sync.RWMutex
+	series   []metric
+	sketches []metric
+}
+
+// NewSnapshot returns an empty Snapshot configured from the
+// `openmetrics_endpoint` configuration.
+func NewSnapshot() *Snapshot {
+	return &Snapshot{quantiles: promlabels.SummaryQuantiles("openmetrics_endpoint.summary_quantiles")}
+}
+
+// UpdateSeries replaces the series of the snapshot. Only the last point of
+// each serie is kept, counts and rates are exposed as gauges.
+func (s *Snapshot) UpdateSeries(series metrics.Series) {
+	rendered := make([]metric, 0, len(series))
+	for _, serie := range series {
+		if len(serie.Points) == 0 {
+			continue
+		}
+		labels := promlabels.Build(serie.Name, serie.Host, serie.Device, serie.Tags)
+		family := promlabels.SanitizeMetricName(serie.Name)
+		p := serie.Points[len(serie.Points)-1]
+		rendered = append(rendered, metric{
+			name:   serie.Name,
+			family: family,
+			kind:   gaugeType,
+			lines:  []string{renderSample(family, labels, p.Value, p.Ts)},
+		})
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.series = rendered
+}
+
+// UpdateSketches replaces the sketches of the snapshot. The last point of
+// each sketch is exposed as a summary.
+func (s *Snapshot) UpdateSketches(sketches metrics.SketchSeriesList) {
+	c := quantile.Default()
+	rendered := make([]metric, 0, len(sketches))
+	for _, sketch := range sketches {
+		if len(sketch.Points) == 0 {
+			continue
+		}
+		labels := promlabels.Build(sketch.Name, sketch.Host, "", sketch.Tags)
+		family := promlabels.SanitizeMetricName(sketch.Name)
+		p := sketch.Points[len(sketch.Points)-1]
+		ts := float64(p.Ts)
+
+		lines := make([]string, 0, len(s.quantiles)+2)
+		for _, q := range s.quantiles {
+			quantileLabels := promlabels.With(labels, promlabels.Label{Name: promlabels.QuantileLabel, Value: promlabels.FormatFloat(q)})
+			lines = append(lines, renderSample(family, quantileLabels, p.Sketch.Quantile(c, q), ts))
+		}
+		lines = append(lines,
+			renderSample(family+"_sum", labels, p.Sketch.Basic.Sum, ts),
+			renderSample(family+"_count", labels, float64(p.Sketch.Basic.Cnt), ts),
+		)
+		rendered = append(rendered, metric{
+			name:   sketch.Name,
+			family: family,
+			kind:   summaryType,
+			lines:  lines,
+		})
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.sketches = rendered
+}
+
+// metrics returns the metrics whose Datadog or OpenMetrics name starts with
+// one of the prefixes, or all of them without prefix, sorted by family.
+func (s *Snapshot) metrics(prefixes []string) []metric {
+	s.m.RLock()
+	all := make([]metric, 0, len(s.series)+len(s.sketches))
+	all = append(all, s.series...)
+	all = append(all, s.sketches...)
+	s.m.RUnlock()
+
+	result := all[:0]
+	for _, m := range all {
+		if matchesPrefix(m, prefixes) {
+			result = append(result, m)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].family < result[j].family
+	})
+	return result
+}
+
+func matchesPrefix(m metric, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(m.name, prefix) || strings.HasPrefix(m.family, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderSample renders a sample: the metric name, the labels other than the
+// metric name, the value and the timestamp in seconds.
+func renderSample(name string, labels []promlabels.Label, value float64, ts float64) string {
+	var b strings.Builder
+	b.WriteString(name)
+	first := true
+	for _, l := range labels {
+		if l.Name == promlabels.MetricNameLabel {
+			continue
+		}
+		if first {
+			b.WriteByte('{')
+			first = false
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(l.Name)
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(l.Value))
+		b.WriteByte('"')
+	}
+	if !first {
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(promlabels.FormatFloat(value))
+	b.WriteByte(' ')
+	b.WriteString(promlabels.FormatFloat(ts))
+	return b.String()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func scrape(t *testing.T, s *Snapshot, query string) string {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", "/metrics"+query, nil))
+
+	resp := w.Result()
+	defer resp.Body.Close()
+	assert.Equal(t, contentType, resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func makeSketchSeries(name string, values ...float64) metrics.SketchSeries {
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), values...)
+	return metrics.SketchSeries{
+		Name:   name,
+		Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod"}),
+		Points: []metrics.SketchPoint{{Ts: 20, Sketch: sketch}},
+	}
+}
+
+func testSeries() metrics.Series {
+	return metrics.Series{
+		&metrics.Serie{
+			Name:   "system.load.1",
+			Host:   "myhost",
+			Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod"}),
+			Points: []metrics.Point{{Ts: 10, Value: 1}, {Ts: 20, Value: 1.5}},
+		},
+		&metrics.Serie{
+			Name:   "app.requests",
+			Tags:   tagset.CompositeTagsFromSlice([]string{`path:/a"b\c`}),
+			Points: []metrics.Point{{Ts: 20, Value: 3}},
+		},
+		&metrics.Serie{
+			Name:   "system.load.1",
+			Host:   "otherhost",
+			Points: []metrics.Point{{Ts: 20, Value: 2}},
+		},
+		&metrics.Serie{Name: "no.points"},
+	}
+}
+
+func TestSnapshotEmpty(t *testing.T) {
+	assert.Equal(t, "# EOF\n", scrape(t, NewSnapshot(), ""))
+}
+
+func TestSnapshotSeries(t *testing.T) {
+	s := NewSnapshot()
+	s.UpdateSeries(testSeries())
+
+	assert.Equal(t, `# TYPE app_requests gauge
+app_requests{path="/a\"b\\c"} 3 20
+# TYPE system_load_1 gauge
+system_load_1{env="prod",host="myhost"} 1.5 20
+system_load_1{host="otherhost"} 2 20
+# EOF
+`, scrape(t, s, ""))
+
+	// a new flush replaces the series
+	s.UpdateSeries(metrics.Series{&metrics.Serie{Name: "m", Points: []metrics.Point{{Ts: 30, Value: 1}}}})
+	assert.Equal(t, "# TYPE m gauge\nm 1 30\n# EOF\n", scrape(t, s, ""))
+}
+
+func TestSnapshotSketches(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("openmetrics_endpoint.summary_quantiles", []string{"0.5", "1.5"})
+	defer mockConfig.Set("openmetrics_endpoint.summary_quantiles", []string{"0.5", "0.75", "0.95", "0.99"})
+
+	s := NewSnapshot()
+	assert.Equal(t, []float64{0.5}, s.quantiles)
+	s.UpdateSketches(metrics.SketchSeriesList{makeSketchSeries("latency", 0, 0, 0)})
+
+	assert.Equal(t, `# TYPE latency summary
+latency{env="prod",quantile="0.5"} 0 20
+latency_sum{env="prod"} 0 20
+latency_count{env="prod"} 3 20
+# EOF
+`, scrape(t, s, ""))
+}
+
+func TestSnapshotPrefixFilter(t *testing.T) {
+	s := NewSnapshot()
+	s.UpdateSeries(testSeries())
+	s.UpdateSketches(metrics.SketchSeriesList{makeSketchSeries("app.latency", 1)})
+
+	// Datadog names
+	body := scrape(t, s, "?prefix=app.")
+	assert.Contains(t, body, "# TYPE app_latency summary\n")
+	assert.Contains(t, body, "# TYPE app_requests gauge\n")
+	assert.NotContains(t, body, "system_load_1")
+
+	// OpenMetrics names, several prefixes
+	body = scrape(t, s, "?prefix=system_load&prefix=app.req")
+	assert.Contains(t, body, "# TYPE app_requests gauge\n")
+	assert.Contains(t, body, "# TYPE system_load_1 gauge\n")
+	assert.NotContains(t, body, "app_latency")
+
+	assert.Equal(t, "# EOF\n", scrape(t, s, "?prefix=unknown"))
+}
+
+func TestSnapshotFamilyConflict(t *testing.T) {
+	s := NewSnapshot()
+	s.UpdateSeries(metrics.Series{&metrics.Serie{Name: "a.b", Points: []metrics.Point{{Ts: 1, Value: 1}}}})
+	s.UpdateSketches(metrics.SketchSeriesList{makeSketchSeries("a_b", 1)})
+
+	// the sketch is dropped, a family has a single type
+	assert.Equal(t, "# TYPE a_b gauge\na_b 1 1\n# EOF\n", scrape(t, s, ""))
+}
+
+type serializerMock struct {
+	serializer.MetricSerializer
+	series   metrics.Series
+	sketches metrics.SketchSeriesList
+}
+
+func (s *serializerMock) SendSeries(series metrics.Series) error {
+	s.series = series
+	return nil
+}
+
+func (s *serializerMock) SendSketch(sketches metrics.SketchSeriesList) error {
+	s.sketches = sketches
+	return nil
+}
+
+func TestSnapshotSerializer(t *testing.T) {
+	primary := &serializerMock{}
+	snapshot := NewSnapshot()
+	s := NewSnapshotSerializer(primary, snapshot)
+
+	assert.False(t, s.IsIterableSeriesSupported())
+
+	series := metrics.Series{&metrics.Serie{Name: "m", Points: []metrics.Point{{Ts: 1, Value: 1}}}}
+	require.NoError(t, s.SendSeries(series))
+	assert.Equal(t, series, primary.series)
+
+	sketches := metrics.SketchSeriesList{makeSketchSeries("latency", 1)}
+	require.NoError(t, s.SendSketch(sketches))
+	assert.Equal(t, sketches, primary.sketches)
+
+	assert.Len(t, snapshot.metrics(nil), 2)
+}
@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// dualShippingSerializer sends everything to the Datadog serializer and the
+// series and sketches to the remote-write exporter as well.
+type dualShippingSerializer struct {
+	serializer.MetricSerializer
+	exporter *Exporter
+}
+
+// NewDualShippingSerializer returns a serializer.MetricSerializer sending the
+// payloads through s, and the series and sketches through the exporter too.
+// The errors of the exporter are logged, only the errors of s are returned.
+func NewDualShippingSerializer(s serializer.MetricSerializer, exporter *Exporter) serializer.MetricSerializer {
+	return &dualShippingSerializer{
+		MetricSerializer: s,
+		exporter:         exporter,
+	}
+}
+
+// SendSeries sends the series to both destinations.
+func (d *dualShippingSerializer) SendSeries(series metrics.Series) error {
+	err := d.MetricSerializer.SendSeries(series)
+	if exportErr := d.exporter.SendSeries(series); exportErr != nil {
+		log.Warnf("Could not send the series to the remote-write endpoint: %v", exportErr)
+	}
+	return err
+}
+
+// SendSketch sends the sketches to both destinations.
+func (d *dualShippingSerializer) SendSketch(sketches metrics.SketchSeriesList) error {
+	err := d.MetricSerializer.SendSketch(sketches)
+	if exportErr := d.exporter.SendSketch(sketches); exportErr != nil {
+		log.Warnf("Could not send the sketches to the remote-write endpoint: %v", exportErr)
+	}
+	return err
+}
+
+// IsIterableSeriesSupported returns false: an IterableSeries can only be
+// consumed once, so the series are flushed as a whole to be sent twice.
+func (d *dualShippingSerializer) IsIterableSeriesSupported() bool {
+	return false
+}
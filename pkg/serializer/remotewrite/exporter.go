@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package remotewrite implements an exporter sending the aggregated series and
+// sketches to a Prometheus remote-write endpoint.
+package remotewrite
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/serializer/internal/promlabels"
+	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Ways to send the sketches
+const (
+	// SketchesAsSummary sends the sketches as summaries
+	SketchesAsSummary = "summary"
+	// SketchesAsNativeHistogram sends the sketches as native histograms
+	SketchesAsNativeHistogram = "native_histogram"
+)
+
+// Submitter sends remote-write payloads, it is implemented by the
+// forwarder.RemoteWriteForwarder.
+type Submitter interface {
+	SubmitRemoteWrite(payload forwarder.Payloads, extra http.Header) error
+}
+
+// Exporter is a serializer.MetricSerializer sending the series and sketches
+// to a Prometheus remote-write endpoint. The other kinds of payloads have no
+// remote-write equivalent and are ignored.
+type Exporter struct {
+	forwarder           Submitter
+	headers             http.Header
+	sketchesAs          string
+	quantiles           []float64
+	maxSeriesPerPayload int
+}
+
+var _ serializer.MetricSerializer = &Exporter{}
+
+// NewExporter returns a new Exporter configured from the
+// `prometheus_remote_write` configuration.
+func NewExporter(forwarder Submitter) *Exporter {
+	sketchesAs := config.Datadog.GetString("prometheus_remote_write.sketches_as")
+	if sketchesAs != SketchesAsSummary && sketchesAs != SketchesAsNativeHistogram {
+		log.Warnf("Invalid prometheus_remote_write.sketches_as %q, using %q", sketchesAs, SketchesAsSummary)
+		sketchesAs = SketchesAsSummary
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Encoding", "snappy")
+	headers.Set("Content-Type", "application/x-protobuf")
+	headers.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	return &Exporter{
+		forwarder:           forwarder,
+		headers:             headers,
+		sketchesAs:          sketchesAs,
+		quantiles:           promlabels.SummaryQuantiles("prometheus_remote_write.summary_quantiles"),
+		maxSeriesPerPayload: config.Datadog.GetInt("prometheus_remote_write.max_series_per_payload"),
+	}
+}
+
+// SendSeries sends the series as gauges.
+func (e *Exporter) SendSeries(series metrics.Series) error {
+	return e.send(fromSeries(series))
+}
+
+// SendIterableSeries sends the series as gauges.
+func (e *Exporter) SendIterableSeries(series *metrics.IterableSeries) error {
+	var all metrics.Series
+	for series.MoveNext() {
+		all = append(all, series.Current())
+	}
+	return e.SendSeries(all)
+}
+
+// IsIterableSeriesSupported returns false, the exporter is meant to receive
+// the same series as the Datadog serializer.
+func (e *Exporter) IsIterableSeriesSupported() bool {
+	return false
+}
+
+// SendSketch sends the sketches as summaries or native histograms.
+func (e *Exporter) SendSketch(sketches metrics.SketchSeriesList) error {
+	if e.sketchesAs == SketchesAsNativeHistogram {
+		return e.send(fromSketchesAsNativeHistograms(sketches))
+	}
+	return e.send(fromSketchesAsSummaries(sketches, e.quantiles))
+}
+
+func (e *Exporter) send(series []timeSeries) error {
+	if len(series) == 0 {
+		return nil
+	}
+	payloads, err := marshalPayloads(series, e.maxSeriesPerPayload)
+	if err != nil {
+		return fmt.Errorf("could not encode the remote-write payloads: %v", err)
+	}
+	return e.forwarder.SubmitRemoteWrite(payloads, e.headers)
+}
+
+// SendEvents is a no-op, events have no remote-write equivalent.
+func (e *Exporter) SendEvents(metrics.Events) error { return nil }
+
+// SendServiceChecks is a no-op, service checks have no remote-write equivalent.
+func (e *Exporter) SendServiceChecks(metrics.ServiceChecks) error { return nil }
+
+// SendMetadata is a no-op.
+func (e *Exporter) SendMetadata(marshaler.JSONMarshaler) error { return nil }
+
+// SendHostMetadata is a no-op.
+func (e *Exporter) SendHostMetadata(marshaler.JSONMarshaler) error { return nil }
+
+// SendProcessesMetadata is a no-op.
+func (e *Exporter) SendProcessesMetadata(interface{}) error { return nil }
+
+// SendAgentchecksMetadata is a no-op.
+func (e *Exporter) SendAgentchecksMetadata(marshaler.JSONMarshaler) error { return nil }
+
+// SendOrchestratorMetadata is a no-op.
+func (e *Exporter) SendOrchestratorMetadata([]serializer.ProcessMessageBody, string, string, int) error {
+	return nil
+}
+
+// SendContainerLifecycleEvent is a no-op.
+func (e *Exporter) SendContainerLifecycleEvent([]serializer.ContainerLifecycleMessage, string) error {
+	return nil
+}
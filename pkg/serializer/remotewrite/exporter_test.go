@@ -0,0 +1,310 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/richardartoul/molecule"
+	"github.com/richardartoul/molecule/src/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/serializer/internal/promlabels"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+type submitterMock struct {
+	payloads forwarder.Payloads
+	headers  http.Header
+}
+
+func (s *submitterMock) SubmitRemoteWrite(payload forwarder.Payloads, extra http.Header) error {
+	s.payloads = append(s.payloads, payload...)
+	s.headers = extra
+	return nil
+}
+
+// decodeWriteRequest decodes the time series of a snappy compressed
+// WriteRequest.
+func decodeWriteRequest(t *testing.T, payload []byte) []timeSeries {
+	decoded, err := snappy.Decode(nil, payload)
+	require.NoError(t, err)
+
+	var result []timeSeries
+	err = molecule.MessageEach(codec.NewBuffer(decoded), func(fieldNum int32, value molecule.Value) (bool, error) {
+		require.EqualValues(t, writeRequestTimeseries, fieldNum)
+		result = append(result, decodeTimeSeries(t, value.Bytes))
+		return true, nil
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func decodeTimeSeries(t *testing.T, data []byte) timeSeries {
+	var ts timeSeries
+	err := molecule.MessageEach(codec.NewBuffer(data), func(fieldNum int32, value molecule.Value) (bool, error) {
+		switch fieldNum {
+		case timeSeriesLabels:
+			var l promlabels.Label
+			err := molecule.MessageEach(codec.NewBuffer(value.Bytes), func(fieldNum int32, value molecule.Value) (bool, error) {
+				s, err := value.AsStringSafe()
+				if fieldNum == labelName {
+					l.Name = s
+				} else {
+					l.Value = s
+				}
+				return true, err
+			})
+			ts.labels = append(ts.labels, l)
+			return true, err
+		case timeSeriesSamples:
+			var s sample
+			err := molecule.MessageEach(codec.NewBuffer(value.Bytes), func(fieldNum int32, value molecule.Value) (bool, error) {
+				var err error
+				if fieldNum == sampleValue {
+					s.value, err = value.AsDouble()
+				} else {
+					s.timestamp, err = value.AsInt64()
+				}
+				return true, err
+			})
+			ts.samples = append(ts.samples, s)
+			return true, err
+		case timeSeriesHistograms:
+			ts.histograms = append(ts.histograms, decodeHistogram(t, value.Bytes))
+		}
+		return true, nil
+	})
+	require.NoError(t, err)
+	return ts
+}
+
+func decodeHistogram(t *testing.T, data []byte) histogram {
+	var h histogram
+	err := molecule.MessageEach(codec.NewBuffer(data), func(fieldNum int32, value molecule.Value) (bool, error) {
+		var err error
+		switch fieldNum {
+		case histogramCountInt:
+			h.count, err = value.AsUint64()
+		case histogramSum:
+			h.sum, err = value.AsDouble()
+		case histogramSchema:
+			var schema int32
+			schema, err = value.AsSint32()
+			assert.EqualValues(t, nativeHistogramSchema, schema)
+		case histogramZeroThreshold:
+			h.zeroThreshold, err = value.AsDouble()
+		case histogramZeroCountInt:
+			h.zeroCount, err = value.AsUint64()
+		case histogramPositiveSpans:
+			h.positive.spans = append(h.positive.spans, decodeSpan(t, value.Bytes))
+		case histogramPositiveDeltas:
+			err = molecule.PackedRepeatedEach(codec.NewBuffer(value.Bytes), codec.FieldType_SINT64, func(v molecule.Value) (bool, error) {
+				d, err := v.AsSint64()
+				h.positive.deltas = append(h.positive.deltas, d)
+				return true, err
+			})
+		case histogramResetHint:
+			var hint int32
+			hint, err = value.AsInt32()
+			assert.EqualValues(t, resetHintGauge, hint)
+		case histogramTimestamp:
+			h.timestamp, err = value.AsInt64()
+		}
+		return true, err
+	})
+	require.NoError(t, err)
+	return h
+}
+
+func decodeSpan(t *testing.T, data []byte) bucketSpan {
+	var span bucketSpan
+	err := molecule.MessageEach(codec.NewBuffer(data), func(fieldNum int32, value molecule.Value) (bool, error) {
+		var err error
+		if fieldNum == bucketSpanOffset {
+			span.offset, err = value.AsSint32()
+		} else {
+			span.length, err = value.AsUint32()
+		}
+		return true, err
+	})
+	require.NoError(t, err)
+	return span
+}
+
+func makeSketchSeries(name string, values ...float64) metrics.SketchSeries {
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), values...)
+	return metrics.SketchSeries{
+		Name:   name,
+		Host:   "myhost",
+		Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod"}),
+		Points: []metrics.SketchPoint{{Ts: 10, Sketch: sketch}},
+	}
+}
+
+func TestExporterSendSeries(t *testing.T) {
+	submitter := &submitterMock{}
+	exporter := NewExporter(submitter)
+
+	err := exporter.SendSeries(metrics.Series{
+		&metrics.Serie{
+			Name:   "system.load.1",
+			Host:   "myhost",
+			Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod"}),
+			MType:  metrics.APIGaugeType,
+			Points: []metrics.Point{{Ts: 10, Value: 1.5}, {Ts: 20, Value: 0}},
+		},
+		&metrics.Serie{
+			Name:   "requests",
+			MType:  metrics.APICountType,
+			Points: []metrics.Point{{Ts: 10.5, Value: 3}},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "snappy", submitter.headers.Get("Content-Encoding"))
+	assert.Equal(t, "application/x-protobuf", submitter.headers.Get("Content-Type"))
+	assert.Equal(t, "0.1.0", submitter.headers.Get("X-Prometheus-Remote-Write-Version"))
+
+	require.Len(t, submitter.payloads, 1)
+	series := decodeWriteRequest(t, *submitter.payloads[0])
+	assert.Equal(t, []timeSeries{
+		{
+			labels:  []promlabels.Label{{Name: "__name__", Value: "system_load_1"}, {Name: "env", Value: "prod"}, {Name: "host", Value: "myhost"}},
+			samples: []sample{{value: 1.5, timestamp: 10000}, {value: 0, timestamp: 20000}},
+		},
+		{
+			labels:  []promlabels.Label{{Name: "__name__", Value: "requests"}},
+			samples: []sample{{value: 3, timestamp: 10500}},
+		},
+	}, series)
+}
+
+func TestExporterSplitsPayloads(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("prometheus_remote_write.max_series_per_payload", 2)
+	defer mockConfig.Set("prometheus_remote_write.max_series_per_payload", 2000)
+
+	submitter := &submitterMock{}
+	exporter := NewExporter(submitter)
+
+	var series metrics.Series
+	for i := 0; i < 5; i++ {
+		series = append(series, &metrics.Serie{Name: "m", Points: []metrics.Point{{Ts: float64(i), Value: 1}}})
+	}
+	require.NoError(t, exporter.SendSeries(series))
+
+	require.Len(t, submitter.payloads, 3)
+	assert.Len(t, decodeWriteRequest(t, *submitter.payloads[0]), 2)
+	assert.Len(t, decodeWriteRequest(t, *submitter.payloads[2]), 1)
+}
+
+func TestExporterSendSketchAsSummary(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("prometheus_remote_write.summary_quantiles", []string{"0.5", "0.99", "2"})
+	defer mockConfig.Set("prometheus_remote_write.summary_quantiles", []string{"0.5", "0.75", "0.95", "0.99"})
+
+	submitter := &submitterMock{}
+	exporter := NewExporter(submitter)
+	assert.Equal(t, []float64{0.5, 0.99}, exporter.quantiles)
+
+	require.NoError(t, exporter.SendSketch(metrics.SketchSeriesList{makeSketchSeries("latency", 1, 2, 3, 4, 5)}))
+
+	require.Len(t, submitter.payloads, 1)
+	series := decodeWriteRequest(t, *submitter.payloads[0])
+	require.Len(t, series, 4)
+
+	assert.Equal(t, []promlabels.Label{{Name: "__name__", Value: "latency"}, {Name: "env", Value: "prod"}, {Name: "host", Value: "myhost"}, {Name: "quantile", Value: "0.5"}}, series[0].labels)
+	assert.InEpsilon(t, 3, series[0].samples[0].value, 0.02)
+	assert.Equal(t, int64(10000), series[0].samples[0].timestamp)
+	assert.Equal(t, "0.99", series[1].labels[3].Value)
+	assert.InEpsilon(t, 5, series[1].samples[0].value, 0.02)
+
+	assert.Equal(t, "latency_sum", series[2].labels[0].Value)
+	assert.Equal(t, 15.0, series[2].samples[0].value)
+	assert.Equal(t, "latency_count", series[3].labels[0].Value)
+	assert.Equal(t, 5.0, series[3].samples[0].value)
+}
+
+func TestExporterSendSketchAsNativeHistogram(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("prometheus_remote_write.sketches_as", SketchesAsNativeHistogram)
+	defer mockConfig.Set("prometheus_remote_write.sketches_as", SketchesAsSummary)
+
+	submitter := &submitterMock{}
+	exporter := NewExporter(submitter)
+
+	require.NoError(t, exporter.SendSketch(metrics.SketchSeriesList{makeSketchSeries("latency", 0, 1, 1, 1.01, 2, 4)}))
+
+	require.Len(t, submitter.payloads, 1)
+	series := decodeWriteRequest(t, *submitter.payloads[0])
+	require.Len(t, series, 1)
+	assert.Equal(t, "latency", series[0].labels[0].Value)
+	require.Len(t, series[0].histograms, 1)
+
+	h := series[0].histograms[0]
+	assert.Equal(t, uint64(6), h.count)
+	assert.Equal(t, 9.01, h.sum)
+	assert.Equal(t, uint64(1), h.zeroCount)
+	assert.Greater(t, h.zeroThreshold, 0.0)
+	assert.Equal(t, int64(10000), h.timestamp)
+
+	// 1 and 1 share the bucket 0, 1.01, 2 and 4 fall in their own buckets
+	assert.Equal(t, []bucketSpan{{offset: 0, length: 2}, {offset: 31, length: 1}, {offset: 30, length: 1}}, h.positive.spans)
+	assert.Equal(t, []int64{2, -1, 0, 0}, h.positive.deltas)
+}
+
+func TestToBuckets(t *testing.T) {
+	b := toBuckets(map[int32]uint64{-3: 1, -2: 4, 5: 2, 6: 2, 7: 1})
+	assert.Equal(t, []bucketSpan{{offset: -3, length: 2}, {offset: 6, length: 3}}, b.spans)
+	assert.Equal(t, []int64{1, 3, -2, 0, -1}, b.deltas)
+
+	assert.Equal(t, buckets{}, toBuckets(map[int32]uint64{}))
+}
+
+type primaryMock struct {
+	serializer.MetricSerializer
+	series   metrics.Series
+	sketches metrics.SketchSeriesList
+}
+
+func (p *primaryMock) SendSeries(series metrics.Series) error {
+	p.series = series
+	return errors.New("primary error")
+}
+
+func (p *primaryMock) SendSketch(sketches metrics.SketchSeriesList) error {
+	p.sketches = sketches
+	return nil
+}
+
+func TestDualShippingSerializer(t *testing.T) {
+	primary := &primaryMock{}
+	submitter := &submitterMock{}
+	s := NewDualShippingSerializer(primary, NewExporter(submitter))
+
+	assert.False(t, s.IsIterableSeriesSupported())
+
+	series := metrics.Series{&metrics.Serie{Name: "m", Points: []metrics.Point{{Ts: 1, Value: 1}}}}
+	assert.EqualError(t, s.SendSeries(series), "primary error")
+	assert.Equal(t, series, primary.series)
+	assert.Len(t, submitter.payloads, 1)
+
+	sketches := metrics.SketchSeriesList{makeSketchSeries("latency", 1)}
+	assert.NoError(t, s.SendSketch(sketches))
+	assert.Equal(t, sketches, primary.sketches)
+	assert.Len(t, submitter.payloads, 2)
+}
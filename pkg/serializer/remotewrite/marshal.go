@@ -0,0 +1,332 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"bytes"
+	"math"
+	"sort"
+
+	"github.com/golang/snappy"
+	"github.com/richardartoul/molecule"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/quantile"
+	"github.com/DataDog/datadog-agent/pkg/serializer/internal/promlabels"
+)
+
+// Protobuf field numbers of the remote-write protocol, from
+// https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto
+// and https://github.com/prometheus/prometheus/blob/main/prompb/types.proto
+const (
+	writeRequestTimeseries = 1
+
+	timeSeriesLabels     = 1
+	timeSeriesSamples    = 2
+	timeSeriesHistograms = 4
+
+	labelName  = 1
+	labelValue = 2
+
+	sampleValue     = 1
+	sampleTimestamp = 2
+
+	histogramCountInt       = 1
+	histogramSum            = 3
+	histogramSchema         = 4
+	histogramZeroThreshold  = 5
+	histogramZeroCountInt   = 6
+	histogramNegativeSpans  = 8
+	histogramNegativeDeltas = 9
+	histogramPositiveSpans  = 11
+	histogramPositiveDeltas = 12
+	histogramResetHint      = 14
+	histogramTimestamp      = 15
+
+	bucketSpanOffset = 1
+	bucketSpanLength = 2
+
+	// resetHintGauge flags gauge histograms: the sketches are not cumulative,
+	// each of them describes the values of a single flush interval.
+	resetHintGauge = 3
+)
+
+// nativeHistogramSchema is the resolution of the native histograms, the
+// bucket boundaries grow by a factor of 2^(2^-schema), about 2.2% for schema
+// 5, which is the closest to the ~1.6% of the relative accuracy of the
+// sketches.
+const nativeHistogramSchema = 5
+
+// timeSeries is a remote-write time series
+type timeSeries struct {
+	labels     []promlabels.Label
+	samples    []sample
+	histograms []histogram
+}
+
+type sample struct {
+	value     float64
+	timestamp int64 // in milliseconds
+}
+
+type histogram struct {
+	count         uint64
+	sum           float64
+	zeroThreshold float64
+	zeroCount     uint64
+	negative      buckets
+	positive      buckets
+	timestamp     int64 // in milliseconds
+}
+
+// buckets are the sparse buckets of a native histogram: spans of consecutive
+// bucket indexes and the count of each bucket, delta encoded.
+type buckets struct {
+	spans  []bucketSpan
+	deltas []int64
+}
+
+type bucketSpan struct {
+	offset int32
+	length uint32
+}
+
+// fromSeries converts series to remote-write time series. Counts and rates
+// are sent as gauges as they are not cumulative.
+func fromSeries(series metrics.Series) []timeSeries {
+	result := make([]timeSeries, 0, len(series))
+	for _, serie := range series {
+		ts := timeSeries{
+			labels:  promlabels.Build(serie.Name, serie.Host, serie.Device, serie.Tags),
+			samples: make([]sample, 0, len(serie.Points)),
+		}
+		for _, p := range serie.Points {
+			ts.samples = append(ts.samples, sample{value: p.Value, timestamp: int64(p.Ts * 1000)})
+		}
+		result = append(result, ts)
+	}
+	return result
+}
+
+// fromSketchesAsSummaries converts sketches to summaries: one time series
+// per quantile with a quantile label, plus the _sum and _count time series.
+func fromSketchesAsSummaries(sketches metrics.SketchSeriesList, quantiles []float64) []timeSeries {
+	c := quantile.Default()
+	result := make([]timeSeries, 0, len(sketches)*(len(quantiles)+2))
+	for _, sketch := range sketches {
+		labels := promlabels.Build(sketch.Name, sketch.Host, "", sketch.Tags)
+		name := promlabels.SanitizeMetricName(sketch.Name)
+
+		quantileSeries := make([]timeSeries, len(quantiles))
+		for i, q := range quantiles {
+			quantileSeries[i].labels = promlabels.With(labels, promlabels.Label{Name: promlabels.QuantileLabel, Value: promlabels.FormatFloat(q)})
+		}
+		sum := timeSeries{labels: promlabels.WithMetricName(labels, name+"_sum")}
+		count := timeSeries{labels: promlabels.WithMetricName(labels, name+"_count")}
+
+		for _, p := range sketch.Points {
+			timestamp := p.Ts * 1000
+			for i, q := range quantiles {
+				quantileSeries[i].samples = append(quantileSeries[i].samples, sample{value: p.Sketch.Quantile(c, q), timestamp: timestamp})
+			}
+			sum.samples = append(sum.samples, sample{value: p.Sketch.Basic.Sum, timestamp: timestamp})
+			count.samples = append(count.samples, sample{value: float64(p.Sketch.Basic.Cnt), timestamp: timestamp})
+		}
+
+		result = append(result, quantileSeries...)
+		result = append(result, sum, count)
+	}
+	return result
+}
+
+// fromSketchesAsNativeHistograms converts sketches to native histograms.
+func fromSketchesAsNativeHistograms(sketches metrics.SketchSeriesList) []timeSeries {
+	c := quantile.Default()
+	result := make([]timeSeries, 0, len(sketches))
+	for _, sketch := range sketches {
+		ts := timeSeries{
+			labels:     promlabels.Build(sketch.Name, sketch.Host, "", sketch.Tags),
+			histograms: make([]histogram, 0, len(sketch.Points)),
+		}
+		for _, p := range sketch.Points {
+			ts.histograms = append(ts.histograms, toNativeHistogram(c, p.Sketch, p.Ts*1000))
+		}
+		result = append(result, ts)
+	}
+	return result
+}
+
+// toNativeHistogram moves the count of each bin of the sketch to the native
+// histogram bucket containing the lower bound of the bin.
+func toNativeHistogram(c *quantile.Config, sketch *quantile.Sketch, timestamp int64) histogram {
+	h := histogram{
+		count:         uint64(sketch.Basic.Cnt),
+		sum:           sketch.Basic.Sum,
+		zeroThreshold: c.BinLowerBound(1),
+		timestamp:     timestamp,
+	}
+
+	positive := make(map[int32]uint64)
+	negative := make(map[int32]uint64)
+	keys, counts := sketch.Cols()
+	for i, k := range keys {
+		v := c.BinLowerBound(quantile.Key(k))
+		switch {
+		case v == 0:
+			h.zeroCount += uint64(counts[i])
+		case v > 0:
+			positive[bucketIndex(v)] += uint64(counts[i])
+		default:
+			negative[bucketIndex(-v)] += uint64(counts[i])
+		}
+	}
+	h.positive = toBuckets(positive)
+	h.negative = toBuckets(negative)
+	return h
+}
+
+// bucketIndex returns the index of the native histogram bucket containing
+// v > 0: bucket i holds the values in (base^(i-1), base^i].
+func bucketIndex(v float64) int32 {
+	if math.IsInf(v, 1) {
+		v = math.MaxFloat64
+	}
+	return int32(math.Ceil(math.Log2(v) * (1 << nativeHistogramSchema)))
+}
+
+// toBuckets encodes the bucket counts as spans and deltas.
+func toBuckets(counts map[int32]uint64) buckets {
+	indexes := make([]int32, 0, len(counts))
+	for index := range counts {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	var b buckets
+	var previousIndex int32
+	var previousCount int64
+	for i, index := range indexes {
+		switch {
+		case i == 0:
+			b.spans = append(b.spans, bucketSpan{offset: index, length: 1})
+		case index == previousIndex+1:
+			b.spans[len(b.spans)-1].length++
+		default:
+			b.spans = append(b.spans, bucketSpan{offset: index - previousIndex - 1, length: 1})
+		}
+		count := int64(counts[index])
+		b.deltas = append(b.deltas, count-previousCount)
+		previousIndex, previousCount = index, count
+	}
+	return b
+}
+
+// marshalPayloads encodes the time series in snappy compressed WriteRequest
+// payloads of at most maxSeriesPerPayload time series each.
+func marshalPayloads(series []timeSeries, maxSeriesPerPayload int) (forwarder.Payloads, error) {
+	if maxSeriesPerPayload <= 0 {
+		maxSeriesPerPayload = len(series)
+	}
+
+	var payloads forwarder.Payloads
+	buf := bytes.NewBuffer(nil)
+	ps := molecule.NewProtoStream(buf)
+	for start := 0; start < len(series); start += maxSeriesPerPayload {
+		end := start + maxSeriesPerPayload
+		if end > len(series) {
+			end = len(series)
+		}
+
+		buf.Reset()
+		for _, ts := range series[start:end] {
+			if err := writeTimeSeries(ps, ts); err != nil {
+				return nil, err
+			}
+		}
+		payload := snappy.Encode(nil, buf.Bytes())
+		payloads = append(payloads, &payload)
+	}
+	return payloads, nil
+}
+
+func writeTimeSeries(ps *molecule.ProtoStream, ts timeSeries) error {
+	return ps.Embedded(writeRequestTimeseries, func(ps *molecule.ProtoStream) error {
+		for _, l := range ts.labels {
+			err := ps.Embedded(timeSeriesLabels, func(ps *molecule.ProtoStream) error {
+				if err := ps.String(labelName, l.Name); err != nil {
+					return err
+				}
+				return ps.String(labelValue, l.Value)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, s := range ts.samples {
+			err := ps.Embedded(timeSeriesSamples, func(ps *molecule.ProtoStream) error {
+				if err := ps.Double(sampleValue, s.value); err != nil {
+					return err
+				}
+				return ps.Int64(sampleTimestamp, s.timestamp)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, h := range ts.histograms {
+			if err := ps.Embedded(timeSeriesHistograms, h.write); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (h histogram) write(ps *molecule.ProtoStream) error {
+	if err := ps.Uint64(histogramCountInt, h.count); err != nil {
+		return err
+	}
+	if err := ps.Double(histogramSum, h.sum); err != nil {
+		return err
+	}
+	if err := ps.Sint32(histogramSchema, nativeHistogramSchema); err != nil {
+		return err
+	}
+	if err := ps.Double(histogramZeroThreshold, h.zeroThreshold); err != nil {
+		return err
+	}
+	if err := ps.Uint64(histogramZeroCountInt, h.zeroCount); err != nil {
+		return err
+	}
+	if err := h.negative.write(ps, histogramNegativeSpans, histogramNegativeDeltas); err != nil {
+		return err
+	}
+	if err := h.positive.write(ps, histogramPositiveSpans, histogramPositiveDeltas); err != nil {
+		return err
+	}
+	if err := ps.Int32(histogramResetHint, resetHintGauge); err != nil {
+		return err
+	}
+	return ps.Int64(histogramTimestamp, h.timestamp)
+}
+
+func (b buckets) write(ps *molecule.ProtoStream, spansField int, deltasField int) error {
+	for _, span := range b.spans {
+		err := ps.Embedded(spansField, func(ps *molecule.ProtoStream) error {
+			if err := ps.Sint32(bucketSpanOffset, span.offset); err != nil {
+				return err
+			}
+			return ps.Uint32(bucketSpanLength, span.length)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return ps.Sint64Packed(deltasField, b.deltas)
+}
@@ -41,10 +41,8 @@ var (
 	// used to serialize to protobuf
 	AgentPayloadVersion string
 
-	jsonExtraHeaders                    http.Header
-	protobufExtraHeaders                http.Header
-	jsonExtraHeadersWithCompression     http.Header
-	protobufExtraHeadersWithCompression http.Header
+	jsonExtraHeaders     http.Header
+	protobufExtraHeaders http.Header
 
 	expvars                                 = expvar.NewMap("serializer")
 	expvarsSendEventsErrItemTooBigs         = expvar.Int{}
@@ -63,24 +61,22 @@ func initExtraHeaders() {
 	jsonExtraHeaders = make(http.Header)
 	jsonExtraHeaders.Set("Content-Type", jsonContentType)
 
-	jsonExtraHeadersWithCompression = make(http.Header)
-	for k := range jsonExtraHeaders {
-		jsonExtraHeadersWithCompression.Set(k, jsonExtraHeaders.Get(k))
-	}
-
 	protobufExtraHeaders = make(http.Header)
 	protobufExtraHeaders.Set("Content-Type", protobufContentType)
 	protobufExtraHeaders.Set(payloadVersionHTTPHeader, AgentPayloadVersion)
+}
 
-	protobufExtraHeadersWithCompression = make(http.Header)
-	for k := range protobufExtraHeaders {
-		protobufExtraHeadersWithCompression.Set(k, protobufExtraHeaders.Get(k))
+// withContentEncoding returns a copy of headers with the given Content-Encoding,
+// if any.
+func withContentEncoding(headers http.Header, contentEncoding string) http.Header {
+	h := make(http.Header)
+	for k := range headers {
+		h.Set(k, headers.Get(k))
 	}
-
-	if compression.ContentEncoding != "" {
-		jsonExtraHeadersWithCompression.Set("Content-Encoding", compression.ContentEncoding)
-		protobufExtraHeadersWithCompression.Set("Content-Encoding", compression.ContentEncoding)
+	if contentEncoding != "" {
+		h.Set("Content-Encoding", contentEncoding)
 	}
+	return h
 }
 
 // MetricSerializer represents the interface of method needed by the aggregator to serialize its data
@@ -107,6 +103,12 @@ type Serializer struct {
 
 	seriesJSONPayloadBuilder *stream.JSONPayloadBuilder
 
+	// compressor compresses the payloads, the Content-Encoding of the
+	// extra headers with compression matches it.
+	compressor                          compression.Compressor
+	jsonExtraHeadersWithCompression     http.Header
+	protobufExtraHeadersWithCompression http.Header
+
 	// Those variables allow users to blacklist any kind of payload
 	// from being sent by the agent. This was introduced for
 	// environment where, for example, events or serviceChecks
@@ -126,20 +128,24 @@ type Serializer struct {
 
 // NewSerializer returns a new Serializer initialized
 func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder, contlcycleForwarder forwarder.Forwarder) *Serializer {
+	compressor := compression.NewCompressor(config.Datadog.GetString("serializer_compressor_kind"), config.Datadog.GetInt("serializer_zstd_compressor_level"))
 	s := &Serializer{
-		Forwarder:                     forwarder,
-		orchestratorForwarder:         orchestratorForwarder,
-		contlcycleForwarder:           contlcycleForwarder,
-		seriesJSONPayloadBuilder:      stream.NewJSONPayloadBuilder(config.Datadog.GetBool("enable_json_stream_shared_compressor_buffers")),
-		enableEvents:                  config.Datadog.GetBool("enable_payloads.events"),
-		enableSeries:                  config.Datadog.GetBool("enable_payloads.series"),
-		enableServiceChecks:           config.Datadog.GetBool("enable_payloads.service_checks"),
-		enableSketches:                config.Datadog.GetBool("enable_payloads.sketches"),
-		enableJSONToV1Intake:          config.Datadog.GetBool("enable_payloads.json_to_v1_intake"),
-		enableJSONStream:              stream.Available && config.Datadog.GetBool("enable_stream_payload_serialization"),
-		enableServiceChecksJSONStream: stream.Available && config.Datadog.GetBool("enable_service_checks_stream_payload_serialization"),
-		enableEventsJSONStream:        stream.Available && config.Datadog.GetBool("enable_events_stream_payload_serialization"),
-		enableSketchProtobufStream:    stream.Available && config.Datadog.GetBool("enable_sketch_stream_payload_serialization"),
+		Forwarder:                           forwarder,
+		orchestratorForwarder:               orchestratorForwarder,
+		contlcycleForwarder:                 contlcycleForwarder,
+		seriesJSONPayloadBuilder:            stream.NewJSONPayloadBuilder(config.Datadog.GetBool("enable_json_stream_shared_compressor_buffers"), compressor),
+		compressor:                          compressor,
+		jsonExtraHeadersWithCompression:     withContentEncoding(jsonExtraHeaders, compressor.ContentEncoding()),
+		protobufExtraHeadersWithCompression: withContentEncoding(protobufExtraHeaders, compressor.ContentEncoding()),
+		enableEvents:                        config.Datadog.GetBool("enable_payloads.events"),
+		enableSeries:                        config.Datadog.GetBool("enable_payloads.series"),
+		enableServiceChecks:                 config.Datadog.GetBool("enable_payloads.service_checks"),
+		enableSketches:                      config.Datadog.GetBool("enable_payloads.sketches"),
+		enableJSONToV1Intake:                config.Datadog.GetBool("enable_payloads.json_to_v1_intake"),
+		enableJSONStream:                    stream.Available && config.Datadog.GetBool("enable_stream_payload_serialization"),
+		enableServiceChecksJSONStream:       stream.Available && config.Datadog.GetBool("enable_service_checks_stream_payload_serialization"),
+		enableEventsJSONStream:              stream.Available && config.Datadog.GetBool("enable_events_stream_payload_serialization"),
+		enableSketchProtobufStream:          stream.Available && config.Datadog.GetBool("enable_sketch_stream_payload_serialization"),
 	}
 
 	if !s.enableEvents {
@@ -161,6 +167,13 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder, contlcy
 	return s
 }
 
+// bufferContext returns new compression buffers using the compressor of the serializer
+func (s Serializer) bufferContext() *marshaler.BufferContext {
+	bufferContext := marshaler.DefaultBufferContext()
+	bufferContext.Compressor = s.compressor
+	return bufferContext
+}
+
 func (s Serializer) serializePayload(
 	jsonMarshaler marshaler.JSONMarshaler,
 	protoMarshaler marshaler.ProtoMarshaler,
@@ -176,7 +189,7 @@ func (s Serializer) serializePayloadJSON(payload marshaler.JSONMarshaler, compre
 	var extraHeaders http.Header
 
 	if compress {
-		extraHeaders = jsonExtraHeadersWithCompression
+		extraHeaders = s.jsonExtraHeadersWithCompression
 	} else {
 		extraHeaders = jsonExtraHeaders
 	}
@@ -187,7 +200,7 @@ func (s Serializer) serializePayloadJSON(payload marshaler.JSONMarshaler, compre
 func (s Serializer) serializePayloadProto(payload marshaler.ProtoMarshaler, compress bool) (forwarder.Payloads, http.Header, error) {
 	var extraHeaders http.Header
 	if compress {
-		extraHeaders = protobufExtraHeadersWithCompression
+		extraHeaders = s.protobufExtraHeadersWithCompression
 	} else {
 		extraHeaders = protobufExtraHeaders
 	}
@@ -195,7 +208,7 @@ func (s Serializer) serializePayloadProto(payload marshaler.ProtoMarshaler, comp
 }
 
 func (s Serializer) serializePayloadInternal(payload marshaler.AbstractMarshaler, compress bool, extraHeaders http.Header, marshalFct split.MarshalFct) (forwarder.Payloads, http.Header, error) {
-	payloads, err := split.Payloads(payload, compress, marshalFct)
+	payloads, err := split.Payloads(payload, compress, marshalFct, s.compressor)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not split payload into small enough chunks: %s", err)
@@ -211,7 +224,7 @@ func (s Serializer) serializeStreamablePayload(payload marshaler.StreamJSONMarsh
 
 func (s Serializer) serializeIterableStreamablePayload(payload marshaler.IterableStreamJSONMarshaler, policy stream.OnErrItemTooBigPolicy) (forwarder.Payloads, http.Header, error) {
 	payloads, err := s.seriesJSONPayloadBuilder.BuildWithOnErrItemTooBigPolicy(payload, policy)
-	return payloads, jsonExtraHeadersWithCompression, err
+	return payloads, s.jsonExtraHeadersWithCompression, err
 }
 
 // As events are gathered by SourceType, the serialization logic is more complex than for the other serializations.
@@ -315,8 +328,8 @@ func (s *Serializer) SendIterableSeries(series *metrics.IterableSeries) error {
 	if useV1API {
 		seriesPayloads, extraHeaders, err = s.serializeIterableStreamablePayload(seriesSerializer, stream.DropItemOnErrItemTooBig)
 	} else {
-		seriesPayloads, err = seriesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext())
-		extraHeaders = protobufExtraHeadersWithCompression
+		seriesPayloads, err = seriesSerializer.MarshalSplitCompress(s.bufferContext())
+		extraHeaders = s.protobufExtraHeadersWithCompression
 	}
 
 	if err != nil {
@@ -353,8 +366,8 @@ func (s *Serializer) SendSeries(series metrics.Series) error {
 	} else if useV1API && !s.enableJSONStream {
 		seriesPayloads, extraHeaders, err = s.serializePayloadJSON(seriesSerializer, true)
 	} else {
-		seriesPayloads, err = seriesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext())
-		extraHeaders = protobufExtraHeadersWithCompression
+		seriesPayloads, err = seriesSerializer.MarshalSplitCompress(s.bufferContext())
+		extraHeaders = s.protobufExtraHeadersWithCompression
 	}
 
 	if err != nil {
@@ -375,9 +388,9 @@ func (s *Serializer) SendSketch(sketches metrics.SketchSeriesList) error {
 	}
 	sketchesSerializer := metricsserializer.SketchSeriesList(sketches)
 	if s.enableSketchProtobufStream {
-		payloads, err := sketchesSerializer.MarshalSplitCompress(marshaler.DefaultBufferContext())
+		payloads, err := sketchesSerializer.MarshalSplitCompress(s.bufferContext())
 		if err == nil {
-			return s.Forwarder.SubmitSketchSeries(payloads, protobufExtraHeadersWithCompression)
+			return s.Forwarder.SubmitSketchSeries(payloads, s.protobufExtraHeadersWithCompression)
 		}
 		log.Warnf("Error: %v trying to stream compress SketchSeriesList - falling back to split/compress method", err)
 	}
@@ -408,7 +421,7 @@ func (s *Serializer) SendAgentchecksMetadata(m marshaler.JSONMarshaler) error {
 }
 
 func (s *Serializer) sendMetadata(m marshaler.JSONMarshaler, submit func(payload forwarder.Payloads, extra http.Header) error) error {
-	mustSplit, compressedPayload, payload, err := split.CheckSizeAndSerialize(m, true, split.JSONMarshalFct)
+	mustSplit, compressedPayload, payload, err := split.CheckSizeAndSerialize(m, true, split.JSONMarshalFct, s.compressor)
 	if err != nil {
 		return fmt.Errorf("could not determine size of metadata payload: %s", err)
 	}
@@ -419,7 +432,7 @@ func (s *Serializer) sendMetadata(m marshaler.JSONMarshaler, submit func(payload
 		return fmt.Errorf("metadata payload was too big to send (%d bytes compressed, %d bytes uncompressed), metadata payloads cannot be split", len(compressedPayload), len(payload))
 	}
 
-	if err := submit(forwarder.Payloads{&compressedPayload}, jsonExtraHeadersWithCompression); err != nil {
+	if err := submit(forwarder.Payloads{&compressedPayload}, s.jsonExtraHeadersWithCompression); err != nil {
 		return err
 	}
 
@@ -439,11 +452,11 @@ func (s *Serializer) SendProcessesMetadata(data interface{}) error {
 	if err != nil {
 		return fmt.Errorf("could not serialize processes metadata payload: %s", err)
 	}
-	compressedPayload, err := compression.Compress(nil, payload)
+	compressedPayload, err := s.compressor.Compress(payload)
 	if err != nil {
 		return fmt.Errorf("could not compress processes metadata payload: %s", err)
 	}
-	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&compressedPayload}, jsonExtraHeadersWithCompression); err != nil {
+	if err := s.Forwarder.SubmitV1Intake(forwarder.Payloads{&compressedPayload}, s.jsonExtraHeadersWithCompression); err != nil {
 		return err
 	}
 
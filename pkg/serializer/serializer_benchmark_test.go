@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
 func buildSeries(numberOfSeries int) metricsserializer.Series {
@@ -43,7 +44,7 @@ var results forwarder.Payloads
 
 func benchmarkJSONStream(b *testing.B, passes int, sharedBuffers bool, numberOfSeries int) {
 	series := buildSeries(numberOfSeries)
-	payloadBuilder := stream.NewJSONPayloadBuilder(sharedBuffers)
+	payloadBuilder := stream.NewJSONPayloadBuilder(sharedBuffers, compression.NewCompressor(compression.ZlibKind, 0))
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
@@ -58,7 +59,7 @@ func benchmarkSplit(b *testing.B, numberOfSeries int) {
 	b.ResetTimer()
 
 	for n := 0; n < b.N; n++ {
-		results, _ = split.Payloads(series, true, split.JSONMarshalFct)
+		results, _ = split.Payloads(series, true, split.JSONMarshalFct, compression.NewCompressor(compression.ZlibKind, 0))
 	}
 }
 
@@ -27,17 +27,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/compression"
 )
 
-var initialContentEncoding = compression.ContentEncoding
-
-func resetContentEncoding() {
-	compression.ContentEncoding = initialContentEncoding
-	initExtraHeaders()
-}
-
-func TestInitExtraHeadersNoopCompression(t *testing.T) {
-	compression.ContentEncoding = ""
-	defer resetContentEncoding()
+// testCompressor matches the compressor used by the serializer with the
+// default configuration
+var testCompressor = compression.NewCompressor(compression.ZlibKind, 1)
 
+func TestInitExtraHeaders(t *testing.T) {
 	initExtraHeaders()
 
 	expected := make(http.Header)
@@ -48,44 +42,44 @@ func TestInitExtraHeadersNoopCompression(t *testing.T) {
 	expected.Set(payloadVersionHTTPHeader, AgentPayloadVersion)
 	expected.Set("Content-Type", protobufContentType)
 	assert.Equal(t, expected, protobufExtraHeaders)
-
-	// No "Content-Encoding" header
-	expected = make(http.Header)
-	expected.Set("Content-Type", jsonContentType)
-	assert.Equal(t, expected, jsonExtraHeadersWithCompression)
-
-	expected = make(http.Header)
-	expected.Set("Content-Type", protobufContentType)
-	expected.Set(payloadVersionHTTPHeader, AgentPayloadVersion)
-	assert.Equal(t, expected, protobufExtraHeadersWithCompression)
 }
 
-func TestInitExtraHeadersWithCompression(t *testing.T) {
-	compression.ContentEncoding = "zstd"
-	defer resetContentEncoding()
+func TestExtraHeadersNoopCompression(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("serializer_compressor_kind", compression.NoneKind)
+	defer mockConfig.Set("serializer_compressor_kind", compression.ZlibKind)
 
-	initExtraHeaders()
+	s := NewSerializer(&forwarder.MockedForwarder{}, nil, nil)
 
+	// No "Content-Encoding" header
 	expected := make(http.Header)
 	expected.Set("Content-Type", jsonContentType)
-	assert.Equal(t, expected, jsonExtraHeaders)
+	assert.Equal(t, expected, s.jsonExtraHeadersWithCompression)
 
 	expected = make(http.Header)
 	expected.Set("Content-Type", protobufContentType)
 	expected.Set(payloadVersionHTTPHeader, AgentPayloadVersion)
-	assert.Equal(t, expected, protobufExtraHeaders)
+	assert.Equal(t, expected, s.protobufExtraHeadersWithCompression)
+}
+
+func TestExtraHeadersWithCompression(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("serializer_compressor_kind", compression.ZstdKind)
+	defer mockConfig.Set("serializer_compressor_kind", compression.ZlibKind)
+
+	s := NewSerializer(&forwarder.MockedForwarder{}, nil, nil)
 
 	// "Content-Encoding" header present with correct value
-	expected = make(http.Header)
+	expected := make(http.Header)
 	expected.Set("Content-Type", jsonContentType)
-	expected.Set("Content-Encoding", compression.ContentEncoding)
-	assert.Equal(t, expected, jsonExtraHeadersWithCompression)
+	expected.Set("Content-Encoding", "zstd")
+	assert.Equal(t, expected, s.jsonExtraHeadersWithCompression)
 
 	expected = make(http.Header)
 	expected.Set("Content-Type", protobufContentType)
-	expected.Set("Content-Encoding", compression.ContentEncoding)
+	expected.Set("Content-Encoding", "zstd")
 	expected.Set(payloadVersionHTTPHeader, AgentPayloadVersion)
-	assert.Equal(t, expected, protobufExtraHeadersWithCompression)
+	assert.Equal(t, expected, s.protobufExtraHeadersWithCompression)
 }
 
 func TestAgentPayloadVersion(t *testing.T) {
@@ -113,7 +107,7 @@ func (p *testPayload) MarshalJSON() ([]byte, error) { return jsonString, nil }
 func (p *testPayload) Marshal() ([]byte, error)     { return protobufString, nil }
 func (p *testPayload) MarshalSplitCompress(bufferContext *marshaler.BufferContext) ([]*[]byte, error) {
 	payloads := forwarder.Payloads{}
-	payload, err := compression.Compress(nil, protobufString)
+	payload, err := testCompressor.Compress(protobufString)
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +159,7 @@ func mkPayloads(payload []byte, compress bool) (forwarder.Payloads, error) {
 	payloads := forwarder.Payloads{}
 	var err error
 	if compress {
-		payload, err = compression.Compress(nil, payload)
+		payload, err = testCompressor.Compress(payload)
 		if err != nil {
 			return nil, err
 		}
@@ -177,7 +171,7 @@ func mkPayloads(payload []byte, compress bool) (forwarder.Payloads, error) {
 func createJSONPayloadMatcher(prefix string) interface{} {
 	return mock.MatchedBy(func(payloads forwarder.Payloads) bool {
 		for _, compressedPayload := range payloads {
-			if payload, err := compression.Decompress(nil, *compressedPayload); err != nil {
+			if payload, err := testCompressor.Decompress(*compressedPayload); err != nil {
 				return false
 			} else {
 				if strings.HasPrefix(string(payload), prefix) {
@@ -192,7 +186,7 @@ func createJSONPayloadMatcher(prefix string) interface{} {
 func createProtoPayloadMatcher(content []byte) interface{} {
 	return mock.MatchedBy(func(payloads forwarder.Payloads) bool {
 		for _, compressedPayload := range payloads {
-			if payload, err := compression.Decompress(nil, *compressedPayload); err != nil {
+			if payload, err := testCompressor.Decompress(*compressedPayload); err != nil {
 				return false
 			} else {
 				if reflect.DeepEqual(content, payload) {
@@ -210,9 +204,9 @@ func TestSendV1Events(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 
 	matcher := createJSONPayloadMatcher(`{"apiKey":"","events":{},"internalHostname"`)
-	f.On("SubmitV1Intake", matcher, jsonExtraHeadersWithCompression).Return(nil).Times(1)
-
 	s := NewSerializer(f, nil, nil)
+	f.On("SubmitV1Intake", matcher, s.jsonExtraHeadersWithCompression).Return(nil).Times(1)
+
 	err := s.SendEvents([]*metrics.Event{})
 	require.Nil(t, err)
 	f.AssertExpectations(t)
@@ -239,7 +233,7 @@ func TestSendV1EventsCreateMarshalersBySourceType(t *testing.T) {
 		})
 	}
 
-	f.On("SubmitV1Intake", payloadsCountMatcher(1), jsonExtraHeadersWithCompression).Return(nil)
+	f.On("SubmitV1Intake", payloadsCountMatcher(1), s.jsonExtraHeadersWithCompression).Return(nil)
 	err := s.SendEvents(events)
 	assert.NoError(t, err)
 	f.AssertExpectations(t)
@@ -247,7 +241,7 @@ func TestSendV1EventsCreateMarshalersBySourceType(t *testing.T) {
 	config.Datadog.Set("serializer_max_payload_size", 20)
 	defer config.Datadog.Set("serializer_max_payload_size", nil)
 
-	f.On("SubmitV1Intake", payloadsCountMatcher(3), jsonExtraHeadersWithCompression).Return(nil)
+	f.On("SubmitV1Intake", payloadsCountMatcher(3), s.jsonExtraHeadersWithCompression).Return(nil)
 	err = s.SendEvents(events)
 	assert.NoError(t, err)
 	f.AssertExpectations(t)
@@ -256,11 +250,12 @@ func TestSendV1EventsCreateMarshalersBySourceType(t *testing.T) {
 func TestSendV1ServiceChecks(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 	matcher := createJSONPayloadMatcher(`[{"check":"","host_name":"","timestamp":0,"status":0,"message":"","tags":null}]`)
-	f.On("SubmitV1CheckRuns", matcher, jsonExtraHeadersWithCompression).Return(nil).Times(1)
 	config.Datadog.Set("enable_service_checks_stream_payload_serialization", false)
 	defer config.Datadog.Set("enable_service_checks_stream_payload_serialization", nil)
 
 	s := NewSerializer(f, nil, nil)
+	f.On("SubmitV1CheckRuns", matcher, s.jsonExtraHeadersWithCompression).Return(nil).Times(1)
+
 	err := s.SendServiceChecks(metrics.ServiceChecks{&metrics.ServiceCheck{}})
 	require.Nil(t, err)
 	f.AssertExpectations(t)
@@ -270,11 +265,11 @@ func TestSendV1Series(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 	matcher := createJSONPayloadMatcher(`{"series":[]}`)
 
-	f.On("SubmitV1Series", matcher, jsonExtraHeadersWithCompression).Return(nil).Times(1)
 	config.Datadog.Set("enable_stream_payload_serialization", false)
 	defer config.Datadog.Set("enable_stream_payload_serialization", nil)
 
 	s := NewSerializer(f, nil, nil)
+	f.On("SubmitV1Series", matcher, s.jsonExtraHeadersWithCompression).Return(nil).Times(1)
 
 	err := s.SendSeries(metrics.Series{})
 	require.Nil(t, err)
@@ -284,11 +279,11 @@ func TestSendV1Series(t *testing.T) {
 func TestSendSeries(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 	matcher := createProtoPayloadMatcher([]byte{10, 8, 10, 6, 10, 4, 104, 111, 115, 116})
-	f.On("SubmitSeries", matcher, protobufExtraHeadersWithCompression).Return(nil).Times(1)
 	config.Datadog.Set("use_v2_api.series", true)
 	defer config.Datadog.Set("use_v2_api.series", false)
 
 	s := NewSerializer(f, nil, nil)
+	f.On("SubmitSeries", matcher, s.protobufExtraHeadersWithCompression).Return(nil).Times(1)
 
 	err := s.SendSeries(metrics.Series{&metrics.Serie{}})
 	require.Nil(t, err)
@@ -299,9 +294,9 @@ func TestSendSketch(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 
 	matcher := createProtoPayloadMatcher([]byte{18, 0})
-	f.On("SubmitSketchSeries", matcher, protobufExtraHeadersWithCompression).Return(nil).Times(1)
-
 	s := NewSerializer(f, nil, nil)
+	f.On("SubmitSketchSeries", matcher, s.protobufExtraHeadersWithCompression).Return(nil).Times(1)
+
 	err := s.SendSketch(metrics.SketchSeriesList{})
 	require.Nil(t, err)
 	f.AssertExpectations(t)
@@ -309,16 +304,15 @@ func TestSendSketch(t *testing.T) {
 
 func TestSendMetadata(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
-	f.On("SubmitMetadata", jsonPayloads, jsonExtraHeadersWithCompression).Return(nil).Times(1)
-
 	s := NewSerializer(f, nil, nil)
+	f.On("SubmitMetadata", jsonPayloads, s.jsonExtraHeadersWithCompression).Return(nil).Times(1)
 
 	payload := &testPayload{}
 	err := s.SendMetadata(payload)
 	require.Nil(t, err)
 	f.AssertExpectations(t)
 
-	f.On("SubmitMetadata", jsonPayloads, jsonExtraHeadersWithCompression).Return(fmt.Errorf("some error")).Times(1)
+	f.On("SubmitMetadata", jsonPayloads, s.jsonExtraHeadersWithCompression).Return(fmt.Errorf("some error")).Times(1)
 	err = s.SendMetadata(payload)
 	require.NotNil(t, err)
 	f.AssertExpectations(t)
@@ -332,15 +326,14 @@ func TestSendProcessesMetadata(t *testing.T) {
 	f := &forwarder.MockedForwarder{}
 	payload := []byte("\"test\"")
 	payloads, _ := mkPayloads(payload, true)
-	f.On("SubmitV1Intake", payloads, jsonExtraHeadersWithCompression).Return(nil).Times(1)
-
 	s := NewSerializer(f, nil, nil)
+	f.On("SubmitV1Intake", payloads, s.jsonExtraHeadersWithCompression).Return(nil).Times(1)
 
 	err := s.SendProcessesMetadata("test")
 	require.Nil(t, err)
 	f.AssertExpectations(t)
 
-	f.On("SubmitV1Intake", payloads, jsonExtraHeadersWithCompression).Return(fmt.Errorf("some error")).Times(1)
+	f.On("SubmitV1Intake", payloads, s.jsonExtraHeadersWithCompression).Return(fmt.Errorf("some error")).Times(1)
 	err = s.SendProcessesMetadata("test")
 	require.NotNil(t, err)
 	f.AssertExpectations(t)
@@ -385,7 +378,7 @@ func TestSendWithDisabledKind(t *testing.T) {
 	f.AssertNotCalled(t, "SubmitSketchSeries")
 
 	// We never disable metadata
-	f.On("SubmitMetadata", jsonPayloads, jsonExtraHeadersWithCompression).Return(nil).Times(1)
+	f.On("SubmitMetadata", jsonPayloads, s.jsonExtraHeadersWithCompression).Return(nil).Times(1)
 	s.SendMetadata(payload)
 	f.AssertNumberOfCalls(t, "SubmitMetadata", 1) // called once for the metadata
 }
@@ -17,7 +17,9 @@ import (
 	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
+	"github.com/DataDog/datadog-agent/pkg/serializer/split"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/util/compression"
 	"github.com/stretchr/testify/require"
 )
 
@@ -73,16 +75,30 @@ func BenchmarkSeries(b *testing.B) {
 			b.ReportMetric(float64(payloadCompressedSize)/float64(b.N), "compressed-payload-bytes")
 		}
 	}
-	bufferContext := marshaler.DefaultBufferContext()
-	pb := func(series metricsserializer.Series) (forwarder.Payloads, error) {
-		return series.MarshalSplitCompress(bufferContext)
-	}
+	for _, kind := range []string{compression.ZlibKind, compression.ZstdKind} {
+		compressor := compression.NewCompressor(kind, 1)
+
+		bufferContext := marshaler.DefaultBufferContext()
+		bufferContext.Compressor = compressor
+		pb := func(series metricsserializer.Series) (forwarder.Payloads, error) {
+			return series.MarshalSplitCompress(bufferContext)
+		}
+
+		payloadBuilder := stream.NewJSONPayloadBuilder(true, compressor)
+		json := func(series metricsserializer.Series) (forwarder.Payloads, error) {
+			return payloadBuilder.Build(series)
+		}
 
-	payloadBuilder := stream.NewJSONPayloadBuilder(true)
-	json := func(series metricsserializer.Series) (forwarder.Payloads, error) {
-		return payloadBuilder.Build(series)
+		b.Run(kind, func(b *testing.B) {
+			benchmarkSeriesWith(b, bench, pb, json)
+		})
 	}
+}
 
+func benchmarkSeriesWith(b *testing.B,
+	bench func(points, items, tags int, build func(series metricsserializer.Series) (forwarder.Payloads, error)) func(b *testing.B),
+	pb func(series metricsserializer.Series) (forwarder.Payloads, error),
+	json func(series metricsserializer.Series) (forwarder.Payloads, error)) {
 	for _, items := range []int{5, 10, 100, 500, 1000, 10000, 100000} {
 		b.Run(fmt.Sprintf("%06d-items", items), func(b *testing.B) {
 			for _, points := range []int{5, 10} {
@@ -98,3 +114,33 @@ func BenchmarkSeries(b *testing.B) {
 		})
 	}
 }
+
+// BenchmarkCompressors compares the CPU cost and the compression ratio of the
+// supported compression algorithms on uncompressed series payloads.
+func BenchmarkCompressors(b *testing.B) {
+	for _, items := range []int{10, 100, 1000} {
+		series := generateData(10, items, 10)
+		uncompressed, err := split.Payloads(series, false, split.JSONMarshalFct, compression.NewCompressor(compression.NoneKind, 0))
+		require.NoError(b, err)
+
+		for _, kind := range []string{compression.ZlibKind, compression.ZstdKind} {
+			compressor := compression.NewCompressor(kind, 1)
+			b.Run(fmt.Sprintf("%06d-items/%s", items, kind), func(b *testing.B) {
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				var uncompressedSize, compressedSize int
+				for n := 0; n < b.N; n++ {
+					for _, payload := range uncompressed {
+						compressed, err := compressor.Compress(*payload)
+						require.NoError(b, err)
+						uncompressedSize += len(*payload)
+						compressedSize += len(compressed)
+					}
+				}
+				b.SetBytes(int64(uncompressedSize / b.N))
+				b.ReportMetric(float64(uncompressedSize)/float64(compressedSize), "ratio")
+			})
+		}
+	}
+}
@@ -72,7 +72,8 @@ func testSplitPayloadsSeries(t *testing.T, numPoints int, compress bool) {
 		testSeries = append(testSeries, &point)
 	}
 
-	payloads, err := Payloads(testSeries, compress, JSONMarshalFct)
+	compressor := compression.NewCompressor(compression.ZlibKind, 0)
+	payloads, err := Payloads(testSeries, compress, JSONMarshalFct, compressor)
 	require.Nil(t, err)
 
 	originalLength := len(testSeries)
@@ -81,7 +82,7 @@ func testSplitPayloadsSeries(t *testing.T, numPoints int, compress bool) {
 		var s = map[string]metricsserializer.Series{}
 
 		if compress {
-			*payload, err = compression.Decompress(nil, *payload)
+			*payload, err = compressor.Decompress(*payload)
 			require.Nil(t, err)
 		}
 
@@ -122,7 +123,7 @@ func BenchmarkSplitPayloadsSeries(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		// always record the result of Payloads to prevent
 		// the compiler eliminating the function call.
-		r, _ = Payloads(testSeries, true, JSONMarshalFct)
+		r, _ = Payloads(testSeries, true, JSONMarshalFct, compression.NewCompressor(compression.ZlibKind, 0))
 
 	}
 	// ensure we actually had to split
@@ -184,7 +185,8 @@ func testSplitPayloadsEvents(t *testing.T, numPoints int, compress bool) {
 		testEvent = append(testEvent, &event)
 	}
 
-	payloads, err := Payloads(testEvent, compress, JSONMarshalFct)
+	compressor := compression.NewCompressor(compression.ZlibKind, 0)
+	payloads, err := Payloads(testEvent, compress, JSONMarshalFct, compressor)
 	require.Nil(t, err)
 
 	originalLength := len(testEvent)
@@ -193,7 +195,7 @@ func testSplitPayloadsEvents(t *testing.T, numPoints int, compress bool) {
 		var s map[string]interface{}
 
 		if compress {
-			*payload, err = compression.Decompress(nil, *payload)
+			*payload, err = compressor.Decompress(*payload)
 			require.Nil(t, err)
 		}
 
@@ -249,7 +251,8 @@ func testSplitPayloadsServiceChecks(t *testing.T, numPoints int, compress bool)
 		testServiceChecks = append(testServiceChecks, &sc)
 	}
 
-	payloads, err := Payloads(testServiceChecks, compress, JSONMarshalFct)
+	compressor := compression.NewCompressor(compression.ZlibKind, 0)
+	payloads, err := Payloads(testServiceChecks, compress, JSONMarshalFct, compressor)
 	require.Nil(t, err)
 
 	originalLength := len(testServiceChecks)
@@ -258,7 +261,7 @@ func testSplitPayloadsServiceChecks(t *testing.T, numPoints int, compress bool)
 		var s []interface{}
 
 		if compress {
-			*payload, err = compression.Decompress(nil, *payload)
+			*payload, err = compressor.Decompress(*payload)
 			require.Nil(t, err)
 		}
 
@@ -304,7 +307,8 @@ func testSplitPayloadsSketches(t *testing.T, numPoints int, compress bool) {
 		testSketchSeries[i] = metricsserializer.Makeseries(i)
 	}
 
-	payloads, err := Payloads(testSketchSeries, compress, JSONMarshalFct)
+	compressor := compression.NewCompressor(compression.ZlibKind, 0)
+	payloads, err := Payloads(testSketchSeries, compress, JSONMarshalFct, compressor)
 	require.Nil(t, err)
 
 	var splitSketches = []metricsserializer.SketchSeriesList{}
@@ -312,7 +316,7 @@ func testSplitPayloadsSketches(t *testing.T, numPoints int, compress bool) {
 		var s = map[string]metricsserializer.SketchSeriesList{}
 
 		if compress {
-			*payload, err = compression.Decompress(nil, *payload)
+			*payload, err = compressor.Decompress(*payload)
 			require.Nil(t, err)
 		}
 
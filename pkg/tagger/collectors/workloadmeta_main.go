@@ -81,7 +81,15 @@ func (c *WorkloadMetaCollector) Stream(ctx context.Context) {
 		}
 	}()
 
-	ch := c.store.Subscribe(name, workloadmeta.TaggerPriority, nil)
+	// process entities are not tagged, so they are filtered out to avoid
+	// handling an event for every process started on the host
+	filter := workloadmeta.NewFilter([]workloadmeta.Kind{
+		workloadmeta.KindContainer,
+		workloadmeta.KindKubernetesPod,
+		workloadmeta.KindECSTask,
+	}, workloadmeta.SourceAll)
+
+	ch := c.store.Subscribe(name, workloadmeta.TaggerPriority, filter)
 
 	log.Infof("workloadmeta tagger collector started")
 
@@ -7,16 +7,24 @@ package alibaba
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 )
 
 func init() {
-	diagnosis.Register("Alibaba Metadata availability", diagnose)
+	diagnosis.Register("Alibaba Metadata availability", diagnosis.CategoryCloudProvider, diagnose)
 }
 
 // diagnose the alibaba metadata API availability
-func diagnose() error {
-	_, err := GetHostAliases(context.TODO())
-	return err
+func diagnose() []diagnosis.Result {
+	aliases, err := GetHostAliases(context.TODO())
+	if err != nil {
+		return []diagnosis.Result{diagnosis.Warning(
+			fmt.Sprintf("cannot get the host aliases from the metadata API: %v", err),
+			"Ignore this warning if the host does not run on Alibaba Cloud, otherwise check that the metadata API is reachable from the Agent.",
+		)}
+	}
+	return []diagnosis.Result{diagnosis.OK("successfully got the host aliases from the metadata API").
+		WithDetails(map[string]interface{}{"host_aliases": aliases})}
 }
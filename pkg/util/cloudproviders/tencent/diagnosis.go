@@ -7,16 +7,24 @@ package tencent
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 )
 
 func init() {
-	diagnosis.Register("Tencent Metadata availability", diagnose)
+	diagnosis.Register("Tencent Metadata availability", diagnosis.CategoryCloudProvider, diagnose)
 }
 
 // diagnose the tencent cloud metadata API availability
-func diagnose() error {
-	_, err := GetInstanceID(context.TODO())
-	return err
+func diagnose() []diagnosis.Result {
+	instanceID, err := GetInstanceID(context.TODO())
+	if err != nil {
+		return []diagnosis.Result{diagnosis.Warning(
+			fmt.Sprintf("cannot get the instance ID from the metadata API: %v", err),
+			"Ignore this warning if the host does not run on Tencent Cloud, otherwise check that the metadata API is reachable from the Agent.",
+		)}
+	}
+	return []diagnosis.Result{diagnosis.OK("successfully got the instance ID from the metadata API").
+		WithDetails(map[string]interface{}{"instance_id": instanceID})}
 }
@@ -10,10 +10,13 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Cluster Agent availability", diagnose)
+	diagnosis.Register("Cluster Agent availability", diagnosis.CategoryOrchestrator, diagnose)
 }
 
-func diagnose() error {
+func diagnose() []diagnosis.Result {
 	_, err := GetClusterAgentClient()
-	return err
+	return []diagnosis.Result{diagnosis.FromError(err,
+		"successfully connected to the Cluster Agent",
+		"Check that the Cluster Agent is running, that cluster_agent.url or the Cluster Agent service is reachable and that cluster_agent.auth_token matches the token of the Cluster Agent.",
+	)}
 }
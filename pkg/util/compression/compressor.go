@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compression
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// ZlibKind compresses payloads with zlib, it is only available in builds with the zlib build tag
+	ZlibKind = "zlib"
+	// ZstdKind compresses payloads with zstd
+	ZstdKind = "zstd"
+	// NoneKind doesn't compress payloads
+	NoneKind = "none"
+)
+
+// Compressor compresses payloads with a given algorithm
+type Compressor interface {
+	// Compress compresses a whole payload
+	Compress(src []byte) ([]byte, error)
+	// Decompress decompresses a whole payload
+	Decompress(src []byte) ([]byte, error)
+	// CompressBound returns the worst case size needed for a destination buffer
+	CompressBound(sourceLen int) int
+	// ContentEncoding returns the HTTP header value associated with the compression method
+	ContentEncoding() string
+	// NewStreamCompressor returns a writer compressing its input into output
+	NewStreamCompressor(output *bytes.Buffer) StreamCompressor
+}
+
+// StreamCompressor compresses the data written to it on the fly
+type StreamCompressor interface {
+	io.WriteCloser
+	// Flush writes any pending compressed data to the output
+	Flush() error
+}
+
+// NewCompressor returns the compressor of the given kind. When the kind is not
+// available in this build, it falls back on zlib, or on no compression at all
+// if zlib is not available either.
+func NewCompressor(kind string, level int) Compressor {
+	switch kind {
+	case ZstdKind:
+		return NewZstdCompressor(level)
+	case NoneKind:
+		return &NoopCompressor{}
+	case ZlibKind:
+	default:
+		log.Warnf("Unknown compressor kind %q, falling back on %s", kind, ZlibKind)
+	}
+	return newZlibCompressor()
+}
+
+// NoopCompressor doesn't compress anything
+type NoopCompressor struct{}
+
+// Compress returns src as is
+func (c *NoopCompressor) Compress(src []byte) ([]byte, error) {
+	return src, nil
+}
+
+// Decompress returns src as is
+func (c *NoopCompressor) Decompress(src []byte) ([]byte, error) {
+	return src, nil
+}
+
+// CompressBound returns sourceLen
+func (c *NoopCompressor) CompressBound(sourceLen int) int {
+	return sourceLen
+}
+
+// ContentEncoding returns an empty string since there's no compression
+func (c *NoopCompressor) ContentEncoding() string {
+	return ""
+}
+
+// NewStreamCompressor returns a writer copying its input into output
+func (c *NoopCompressor) NewStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	return &noopStreamCompressor{output}
+}
+
+type noopStreamCompressor struct {
+	*bytes.Buffer
+}
+
+func (s *noopStreamCompressor) Flush() error {
+	return nil
+}
+
+func (s *noopStreamCompressor) Close() error {
+	return nil
+}
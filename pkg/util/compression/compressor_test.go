@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compression
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCompressor(t *testing.T) {
+	assert.IsType(t, &ZstdCompressor{}, NewCompressor(ZstdKind, 1))
+	assert.IsType(t, &NoopCompressor{}, NewCompressor(NoneKind, 1))
+	// unknown kinds fall back on zlib
+	assert.IsType(t, NewCompressor(ZlibKind, 1), NewCompressor("lz4", 1))
+}
+
+func TestCompressorRoundTrip(t *testing.T) {
+	payload := bytes.Repeat([]byte(`{"metric":"system.cpu.user","points":[[1646000000,1.5]],"tags":["env:prod"]},`), 100)
+
+	for _, kind := range []string{ZlibKind, ZstdKind, NoneKind} {
+		t.Run(kind, func(t *testing.T) {
+			c := NewCompressor(kind, 1)
+
+			compressed, err := c.Compress(payload)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(compressed), c.CompressBound(len(payload)))
+
+			decompressed, err := c.Decompress(compressed)
+			require.NoError(t, err)
+			assert.Equal(t, payload, decompressed)
+
+			var output bytes.Buffer
+			w := c.NewStreamCompressor(&output)
+			_, err = w.Write(payload[:100])
+			require.NoError(t, err)
+			require.NoError(t, w.Flush())
+			_, err = w.Write(payload[100:])
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			decompressed, err = c.Decompress(output.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, payload, decompressed)
+		})
+	}
+}
+
+func TestZstdCompressor(t *testing.T) {
+	c := NewZstdCompressor(1)
+	assert.Equal(t, "zstd", c.ContentEncoding())
+
+	payload := bytes.Repeat([]byte("datadog "), 1000)
+	compressed, err := c.Compress(payload)
+	require.NoError(t, err)
+	assert.Less(t, len(compressed), len(payload))
+}
+
+func TestZstdCompressorConcurrentUse(t *testing.T) {
+	c := NewZstdCompressor(1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			payload := bytes.Repeat([]byte{byte('a' + i)}, 1000*(i+1))
+			for j := 0; j < 10; j++ {
+				compressed, err := c.Compress(payload)
+				require.NoError(t, err)
+				decompressed, err := c.Decompress(compressed)
+				require.NoError(t, err)
+				assert.Equal(t, payload, decompressed)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build zlib
+// +build zlib
+
+package compression
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io/ioutil"
+)
+
+// ZlibCompressor compresses payloads with zlib
+type ZlibCompressor struct{}
+
+func newZlibCompressor() Compressor {
+	return &ZlibCompressor{}
+}
+
+// Compress compresses src with zlib
+func (c *ZlibCompressor) Compress(src []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Decompress decompresses src with zlib
+func (c *ZlibCompressor) Decompress(src []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// CompressBound returns the worst case size needed for a destination buffer
+func (c *ZlibCompressor) CompressBound(sourceLen int) int {
+	// From https://code.woboq.org/gcc/zlib/compress.c.html#compressBound
+	return sourceLen + (sourceLen >> 12) + (sourceLen >> 14) + (sourceLen >> 25) + 13
+}
+
+// ContentEncoding returns the HTTP header value associated with zlib
+func (c *ZlibCompressor) ContentEncoding() string {
+	return "deflate"
+}
+
+// NewStreamCompressor returns a zlib writer compressing into output
+func (c *ZlibCompressor) NewStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	return zlib.NewWriter(output)
+}
@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !zlib
+// +build !zlib
+
+package compression
+
+// newZlibCompressor returns a compressor that doesn't compress anything, since
+// zlib is not available in this build
+func newZlibCompressor() Compressor {
+	return &NoopCompressor{}
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package compression
+
+import (
+	"bytes"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ZstdCompressor compresses payloads with zstd
+type ZstdCompressor struct {
+	level zstd.EncoderLevel
+	// encoder and decoder are shared by all the payloads, EncodeAll and
+	// DecodeAll are safe for concurrent use, the concurrent calls wait for
+	// each other
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// NewZstdCompressor returns a zstd compressor using the given zstd compression level
+func NewZstdCompressor(level int) *ZstdCompressor {
+	encoderLevel := zstd.EncoderLevelFromZstd(level)
+	// the options are valid, so NewWriter and NewReader can't fail
+	encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel), zstd.WithEncoderConcurrency(1))
+	decoder, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+	return &ZstdCompressor{
+		level:   encoderLevel,
+		encoder: encoder,
+		decoder: decoder,
+	}
+}
+
+// Compress compresses src with zstd
+func (c *ZstdCompressor) Compress(src []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(src, make([]byte, 0, c.CompressBound(len(src)))), nil
+}
+
+// Decompress decompresses src with zstd
+func (c *ZstdCompressor) Decompress(src []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(src, nil)
+}
+
+// CompressBound returns the worst case size needed for a destination buffer
+func (c *ZstdCompressor) CompressBound(sourceLen int) int {
+	// From ZSTD_COMPRESSBOUND in https://github.com/facebook/zstd/blob/dev/lib/zstd.h
+	bound := sourceLen + (sourceLen >> 8)
+	if sourceLen < 128<<10 {
+		bound += (128<<10 - sourceLen) >> 11
+	}
+	return bound
+}
+
+// ContentEncoding returns the HTTP header value associated with zstd
+func (c *ZstdCompressor) ContentEncoding() string {
+	return "zstd"
+}
+
+// NewStreamCompressor returns a zstd writer compressing into output
+func (c *ZstdCompressor) NewStreamCompressor(output *bytes.Buffer) StreamCompressor {
+	// the options are valid, so NewWriter can't fail
+	encoder, _ := zstd.NewWriter(output, zstd.WithEncoderLevel(c.level), zstd.WithEncoderConcurrency(1))
+	return encoder
+}
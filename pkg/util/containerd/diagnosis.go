@@ -13,15 +13,17 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Containerd availability", diagnose)
+	diagnosis.Register("Containerd availability", diagnosis.CategoryContainerRuntime, diagnose)
 }
 
 // diagnose the Containerd socket connectivity
-func diagnose() error {
+func diagnose() []diagnosis.Result {
 	client, err := NewContainerdUtil()
-	if err != nil {
-		return err
+	if err == nil {
+		err = client.Close()
 	}
-
-	return client.Close()
+	return []diagnosis.Result{diagnosis.FromError(err,
+		"successfully connected to containerd",
+		"Check that containerd is running and that its socket, set by cri_socket_path, is mounted and readable by the Agent.",
+	)}
 }
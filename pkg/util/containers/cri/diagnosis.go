@@ -13,11 +13,14 @@ import (
 )
 
 func init() {
-	diagnosis.Register("CRI availability", diagnose)
+	diagnosis.Register("CRI availability", diagnosis.CategoryContainerRuntime, diagnose)
 }
 
 // diagnose the CRI socket connectivity
-func diagnose() error {
+func diagnose() []diagnosis.Result {
 	_, err := GetUtil()
-	return err
+	return []diagnosis.Result{diagnosis.FromError(err,
+		"successfully connected to the CRI runtime",
+		"Check that the container runtime is running and that its socket, set by cri_socket_path, is mounted and readable by the Agent.",
+	)}
 }
@@ -17,22 +17,27 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Docker availability", diagnose)
+	diagnosis.Register("Docker availability", diagnosis.CategoryContainerRuntime, diagnose)
 }
 
 // diagnose the docker availability on the system
-func diagnose() error {
+func diagnose() []diagnosis.Result {
 	_, err := GetDockerUtil()
 	if err != nil {
-		return fmt.Errorf("error connecting to docker: %w", err)
+		return []diagnosis.Result{diagnosis.Fail(
+			fmt.Sprintf("error connecting to docker: %v", err),
+			"Check that docker is running and that its socket is mounted and readable by the Agent, the dd-agent user must be in the docker group.",
+		)}
 	}
 	log.Info("successfully connected to docker")
 
 	hostname, err := HostnameProvider(context.TODO(), nil)
 	if err != nil {
-		return fmt.Errorf("returned hostname %q with error: %w", hostname, err)
+		return []diagnosis.Result{diagnosis.Fail(
+			fmt.Sprintf("returned hostname %q with error: %v", hostname, err),
+			"Set the hostname option of the Agent if the hostname of the docker host cannot be used.",
+		)}
 	}
-	log.Infof("successfully got hostname %q from docker", hostname)
-
-	return nil
+	return []diagnosis.Result{diagnosis.OK(fmt.Sprintf("successfully got hostname %q from docker", hostname)).
+		WithDetails(map[string]interface{}{"hostname": hostname})}
 }
@@ -7,16 +7,24 @@ package ec2
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 )
 
 func init() {
-	diagnosis.Register("EC2 Metadata availability", diagnose)
+	diagnosis.Register("EC2 Metadata availability", diagnosis.CategoryCloudProvider, diagnose)
 }
 
 // diagnose the ec2 metadata API availability
-func diagnose() error {
-	_, err := GetHostname(context.TODO())
-	return err
+func diagnose() []diagnosis.Result {
+	hostname, err := GetHostname(context.TODO())
+	if err != nil {
+		return []diagnosis.Result{diagnosis.Warning(
+			fmt.Sprintf("cannot get the hostname from the metadata API: %v", err),
+			"Ignore this warning if the host does not run on EC2, otherwise check that the metadata API is reachable from the Agent and that its hop limit allows containers to reach it.",
+		)}
+	}
+	return []diagnosis.Result{diagnosis.OK("successfully got the hostname from the metadata API").
+		WithDetails(map[string]interface{}{"hostname": hostname})}
 }
@@ -19,54 +19,56 @@ import (
 )
 
 func init() {
-	diagnosis.Register("ECS Metadata availability", diagnoseECS)
-	diagnosis.Register("ECS Metadata with tags availability", diagnoseECSTags)
-	diagnosis.Register("ECS Fargate Metadata availability", diagnoseFargate)
+	diagnosis.Register("ECS Metadata availability", diagnosis.CategoryOrchestrator, diagnoseECS)
+	diagnosis.Register("ECS Metadata with tags availability", diagnosis.CategoryOrchestrator, diagnoseECSTags)
+	diagnosis.Register("ECS Fargate Metadata availability", diagnosis.CategoryOrchestrator, diagnoseFargate)
+}
+
+const (
+	ecsRemediation     = "Ignore this warning if the host does not run on ECS, otherwise check that the ECS agent is running and that its metadata API is reachable from the Agent."
+	fargateRemediation = "Ignore this warning if the Agent does not run on ECS Fargate, otherwise check that the Agent runs as a sidecar of the task."
+)
+
+// toResult turns the error of an ECS metadata API into a result, the API is
+// not available outside of ECS
+func toResult(err error, okMessage string, remediation string) []diagnosis.Result {
+	if err != nil {
+		return []diagnosis.Result{diagnosis.Warning(err.Error(), remediation)}
+	}
+	return []diagnosis.Result{diagnosis.OK(okMessage)}
 }
 
 // diagnose the ECS metadata API availability
-func diagnoseECS() error {
+func diagnoseECS() []diagnosis.Result {
 	client, err := ecsmeta.V1()
 	if err != nil {
-		return err
+		return toResult(err, "", ecsRemediation)
 	}
 	log.Info("successfully detected ECS metadata server endpoint")
 
-	if _, err = client.GetTasks(context.TODO()); err != nil {
-		return err
-	}
-	log.Info("successfully retrieved task list from ECS metadata server")
-
-	return nil
+	_, err = client.GetTasks(context.TODO())
+	return toResult(err, "successfully retrieved task list from ECS metadata server", ecsRemediation)
 }
 
 // diagnose the ECS metadata with tags API availability
-func diagnoseECSTags() error {
+func diagnoseECSTags() []diagnosis.Result {
 	client, err := ecsmeta.V3orV4FromCurrentTask()
 	if err != nil {
-		return err
+		return toResult(err, "", ecsRemediation)
 	}
 	log.Info("successfully detected ECS metadata server endpoint for resource tags")
 
-	if _, err = client.GetTaskWithTags(context.TODO()); err != nil {
-		return err
-	}
-	log.Info("successfully retrieved task with potential tags from ECS metadata server")
-
-	return nil
+	_, err = client.GetTaskWithTags(context.TODO())
+	return toResult(err, "successfully retrieved task with potential tags from ECS metadata server", ecsRemediation)
 }
 
 // diagnose the ECS Fargate metadata API availability
-func diagnoseFargate() error {
+func diagnoseFargate() []diagnosis.Result {
 	client, err := ecsmeta.V2()
 	if err != nil {
-		return fmt.Errorf("error while initializing ECS metadata V2 client: %w", err)
-	}
-
-	if _, err := client.GetTask(context.TODO()); err != nil {
-		return err
+		return toResult(fmt.Errorf("error while initializing ECS metadata V2 client: %w", err), "", fargateRemediation)
 	}
-	log.Info("successfully retrieved task from Fargate metadata endpoint")
 
-	return nil
+	_, err = client.GetTask(context.TODO())
+	return toResult(err, "successfully retrieved task from Fargate metadata endpoint", fargateRemediation)
 }
@@ -81,6 +81,11 @@ type APIClient struct {
 	// DDInformerFactory gives access to informers for all datadoghq/ custom types
 	DDInformerFactory dynamicinformer.DynamicSharedInformerFactory
 
+	// dynamicInformerFactory gives access to informers for arbitrary resources
+	// It is built on first use by DynamicInformerFactory.
+	dynamicInformerFactory     dynamicinformer.DynamicSharedInformerFactory
+	dynamicInformerFactoryLock sync.Mutex
+
 	// initRetry used to setup the APIClient
 	initRetry retry.Retrier
 
@@ -256,6 +261,17 @@ func getDDInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error
 	return dynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriodSeconds*time.Second), nil
 }
 
+func getDynamicInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error) {
+	// default to 300s
+	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
+	client, err := getKubeDynamicClient(0) // No timeout for the Informers, to allow long watch.
+	if err != nil {
+		log.Infof("Could not get apiserver client: %v", err)
+		return nil, err
+	}
+	return dynamicinformer.NewDynamicSharedInformerFactory(client, resyncPeriodSeconds*time.Second), nil
+}
+
 func getInformerFactory() (informers.SharedInformerFactory, error) {
 	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
 	client, err := GetKubeClient(0) // No timeout for the Informers, to allow long watch.
@@ -317,6 +333,9 @@ func (c *APIClient) connect() error {
 		c.UnassignedPodInformerFactory, err = getInformerFactoryWithOption(
 			informers.WithTweakListOptions(tweakListOptions),
 		)
+		if err != nil {
+			return err
+		}
 	}
 
 	if config.Datadog.GetBool("admission_controller.enabled") {
@@ -381,6 +400,23 @@ func newMetadataMapperBundle() *metadataMapperBundle {
 	}
 }
 
+// DynamicInformerFactory returns the informer factory for arbitrary resources.
+// It is only built on first use, when the orchestrator check is configured to
+// collect custom resources.
+func (c *APIClient) DynamicInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error) {
+	c.dynamicInformerFactoryLock.Lock()
+	defer c.dynamicInformerFactoryLock.Unlock()
+
+	if c.dynamicInformerFactory == nil {
+		factory, err := getDynamicInformerFactory()
+		if err != nil {
+			return nil, err
+		}
+		c.dynamicInformerFactory = factory
+	}
+	return c.dynamicInformerFactory, nil
+}
+
 // ComponentStatuses returns the component status list from the APIServer
 func (c *APIClient) ComponentStatuses() (*v1.ComponentStatusList, error) {
 	return c.Cl.CoreV1().ComponentStatuses().List(context.TODO(), metav1.ListOptions{TimeoutSeconds: &c.timeoutSeconds})
@@ -10,6 +10,7 @@ package apiserver
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
@@ -20,36 +21,47 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Kubernetes API Server availability", diagnose)
+	diagnosis.Register("Kubernetes API Server availability", diagnosis.CategoryOrchestrator, diagnose)
 }
 
 // diagnose the API server availability
-func diagnose() error {
+func diagnose() []diagnosis.Result {
 	c, err := GetAPIClient()
 	if err != nil {
-		return err
+		return []diagnosis.Result{diagnosis.Fail(err.Error(),
+			"Check that the API server is reachable from the Agent and that the service account of the Agent is mounted.",
+		)}
 	}
-	log.Infof("Detecting OpenShift APIs: %s available", c.DetectOpenShiftAPILevel())
+	openShiftAPILevel := c.DetectOpenShiftAPILevel()
+	log.Infof("Detecting OpenShift APIs: %s available", openShiftAPILevel)
+	results := []diagnosis.Result{diagnosis.OK("successfully connected to the API server").
+		WithDetails(map[string]interface{}{"openshift_api_level": string(openShiftAPILevel)})}
 
 	resourcesNamespace := common.GetResourcesNamespace()
-	printRBAC(c, resourcesNamespace)
+	results = append(results, diagnoseRBAC(c, resourcesNamespace))
 
 	myNamespace := common.GetMyNamespace()
 	if myNamespace != resourcesNamespace {
-		printRBAC(c, myNamespace)
+		results = append(results, diagnoseRBAC(c, myNamespace))
 	}
 
-	return nil
+	return results
 }
 
-func printRBAC(client *APIClient, namespace string) {
+func diagnoseRBAC(client *APIClient, namespace string) diagnosis.Result {
+	name := fmt.Sprintf("Kubernetes API Server RBAC in namespace %s", namespace)
 	rulesReview, err := listRBAC(client, namespace)
 	if err != nil {
 		log.Errorf("Unable to get RBACs for namespace: %s", namespace)
+		return diagnosis.Warning(fmt.Sprintf("unable to get RBACs: %v", err),
+			"Check that the cluster role of the Agent allows creating selfsubjectrulesreviews.",
+		).WithName(name)
 	}
 
 	log.Infof("RulesReview result for namespace %s:", namespace)
 	log.Info(rulesReview.String())
+	return diagnosis.OK("successfully listed the RBACs").WithName(name).
+		WithDetails(map[string]interface{}{"rules_review": rulesReview})
 }
 
 func listRBAC(client *APIClient, namespace string) (*authorizationv1.SubjectRulesReviewStatus, error) {
@@ -13,11 +13,14 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Kubelet availability", diagnose)
+	diagnosis.Register("Kubelet availability", diagnosis.CategoryOrchestrator, diagnose)
 }
 
 // diagnose the API server availability
-func diagnose() error {
+func diagnose() []diagnosis.Result {
 	_, err := GetKubeUtil()
-	return err
+	return []diagnosis.Result{diagnosis.FromError(err,
+		"successfully connected to the kubelet",
+		"Check that the kubelet is reachable from the Agent, with kubernetes_kubelet_host set to the host IP, and that the kubelet TLS settings match kubelet_tls_verify.",
+	)}
 }
@@ -16,8 +16,8 @@ import (
 // It includes a set of agent-specific replacers.  It can scrub DataDog App
 // and API keys, passwords from URLs, and multi-line PEM-formatted TLS keys and
 // certificates.  It contains special handling for YAML-like content (with
-// lines of the form "key: value") and can scrub passwords, tokens, and SNMP
-// community strings in such content.
+// lines of the form "key: value") and can scrub passwords, tokens, secret
+// backend credentials and SNMP community strings in such content.
 //
 // See default.go for details of these replacers.
 var DefaultScrubber = &Scrubber{}
@@ -66,6 +66,11 @@ func AddDefaultReplacers(scrubber *Scrubber) {
 		Hints: []string{"token"},
 		Repl:  []byte(`$1 ********`),
 	}
+	secretIDReplacer := Replacer{
+		Regex: matchYAMLKeyEnding(`(secret_id|role_id)`),
+		Hints: []string{"secret_id", "role_id"},
+		Repl:  []byte(`$1 ********`),
+	}
 	snmpReplacer := Replacer{
 		Regex: matchYAMLKey(`(community_string|authKey|privKey|community|authentication_key|privacy_key)`),
 		Hints: []string{"community_string", "authKey", "privKey", "community", "authentication_key", "privacy_key"},
@@ -88,6 +93,7 @@ func AddDefaultReplacers(scrubber *Scrubber) {
 	scrubber.AddReplacer(SingleLine, uriPasswordReplacer)
 	scrubber.AddReplacer(SingleLine, passwordReplacer)
 	scrubber.AddReplacer(SingleLine, tokenReplacer)
+	scrubber.AddReplacer(SingleLine, secretIDReplacer)
 	scrubber.AddReplacer(SingleLine, snmpReplacer)
 	scrubber.AddReplacer(MultiLine, snmpMultilineReplacer)
 	scrubber.AddReplacer(MultiLine, certReplacer)
@@ -346,6 +346,30 @@ other_config_with_list: [abc]
 		`privacy_key: ********`)
 }
 
+func TestSecretBackendConfig(t *testing.T) {
+	assertClean(t,
+		`approle_secret_id: 6a174c20-f6de-a53c-74d2-6018fcceff64`,
+		`approle_secret_id: ********`)
+	assertClean(t,
+		`approle_role_id: 59d6d1ca-47bb-4e7e-a40b-8be3bc5a0ba8`,
+		`approle_role_id: ********`)
+	assertClean(t,
+		`secret_backend_vault:
+  enabled: true
+  address: https://vault:8200
+  auth_method: approle
+  token: s.Fq7m2lzMXWdD
+  approle_role_id: 59d6d1ca-47bb-4e7e-a40b-8be3bc5a0ba8
+  approle_secret_id: "6a174c20-f6de-a53c-74d2-6018fcceff64"`,
+		`secret_backend_vault:
+  enabled: true
+  address: https://vault:8200
+  auth_method: approle
+  token: ********
+  approle_role_id: ********
+  approle_secret_id: ********`)
+}
+
 func TestYamlConfig(t *testing.T) {
 	contents := `foobar: baz`
 	cleaned, err := ScrubBytes([]byte(contents))
@@ -9,9 +9,11 @@
 // Use of this source code is governed by Apache License 2.0
 // license that can be found here: https://github.com/coreos/go-systemd/blob/master/LICENSE
 
-//go:build systemd
-// +build systemd
+//go:build linux
+// +build linux
 
+// Package systemd provides helpers to talk to systemd over D-Bus. It doesn't
+// depend on libsystemd, so it can be used without the systemd build tag.
 package systemd
 
 import (
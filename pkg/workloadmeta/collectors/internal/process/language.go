@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package process
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// languageExecutables maps executable names to the language of the process
+// running them. Versioned executables like python3.9 or php-fpm7.4 are
+// matched as well, see matchExecutable.
+var languageExecutables = []struct {
+	name     string
+	language workloadmeta.ProcessLanguage
+}{
+	{"java", workloadmeta.ProcessLanguageJava},
+	{"python", workloadmeta.ProcessLanguagePython},
+	{"node", workloadmeta.ProcessLanguageNode},
+	{"nodejs", workloadmeta.ProcessLanguageNode},
+	{"ruby", workloadmeta.ProcessLanguageRuby},
+	{"dotnet", workloadmeta.ProcessLanguageDotnet},
+	{"php", workloadmeta.ProcessLanguagePHP},
+}
+
+// detectLanguage guesses the language of a process from the name of its
+// executable, falling back to the first argument of its command line when the
+// executable path can't be read.
+func detectLanguage(exe string, cmdline []string) workloadmeta.ProcessLanguage {
+	if exe == "" && len(cmdline) > 0 {
+		if fields := strings.Fields(cmdline[0]); len(fields) > 0 {
+			exe = fields[0]
+		}
+	}
+
+	if exe == "" {
+		return workloadmeta.ProcessLanguageUnknown
+	}
+
+	name := strings.ToLower(filepath.Base(exe))
+	for _, l := range languageExecutables {
+		if matchExecutable(name, l.name) {
+			return l.language
+		}
+	}
+
+	return workloadmeta.ProcessLanguageUnknown
+}
+
+// matchExecutable returns whether name is the executable, optionally followed
+// by a version or a variant (python3, python3.9, php-fpm).
+func matchExecutable(name, executable string) bool {
+	if !strings.HasPrefix(name, executable) {
+		return false
+	}
+
+	suffix := name[len(executable):]
+	if suffix == "" {
+		return true
+	}
+
+	switch c := suffix[0]; {
+	case c >= '0' && c <= '9', c == '.', c == '-':
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package process
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		exe      string
+		cmdline  []string
+		expected workloadmeta.ProcessLanguage
+	}{
+		{
+			name:     "java",
+			exe:      "/usr/lib/jvm/java-11-openjdk/bin/java",
+			cmdline:  []string{"java", "-jar", "app.jar"},
+			expected: workloadmeta.ProcessLanguageJava,
+		},
+		{
+			name:     "versioned python",
+			exe:      "/usr/bin/python3.9",
+			cmdline:  []string{"python3", "app.py"},
+			expected: workloadmeta.ProcessLanguagePython,
+		},
+		{
+			name:     "nodejs",
+			exe:      "/usr/bin/nodejs",
+			expected: workloadmeta.ProcessLanguageNode,
+		},
+		{
+			name:     "php-fpm",
+			exe:      "/usr/sbin/php-fpm7.4",
+			expected: workloadmeta.ProcessLanguagePHP,
+		},
+		{
+			name:     "exe not readable, fallback on cmdline",
+			exe:      "",
+			cmdline:  []string{"/usr/local/bin/ruby", "server.rb"},
+			expected: workloadmeta.ProcessLanguageRuby,
+		},
+		{
+			name:     "cmdline rewritten in a single argument",
+			exe:      "",
+			cmdline:  []string{"dotnet app.dll"},
+			expected: workloadmeta.ProcessLanguageDotnet,
+		},
+		{
+			name:     "executable sharing a language prefix",
+			exe:      "/usr/bin/node_exporter",
+			expected: workloadmeta.ProcessLanguageUnknown,
+		},
+		{
+			name:     "unknown language",
+			exe:      "/usr/sbin/nginx",
+			cmdline:  []string{"nginx", "-g", "daemon off;"},
+			expected: workloadmeta.ProcessLanguageUnknown,
+		},
+		{
+			name:     "empty",
+			cmdline:  []string{""},
+			expected: workloadmeta.ProcessLanguageUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, detectLanguage(tt.exe, tt.cmdline))
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID   = "process"
+	componentName = "workloadmeta-process"
+
+	// cgroupV1BaseController is the controller used to resolve container IDs
+	// on cgroup v1 hosts. On cgroup v2 hosts, the unified hierarchy has an
+	// empty controller name.
+	cgroupV1BaseController = "memory"
+)
+
+type collector struct {
+	store    workloadmeta.Store
+	probe    procutil.Probe
+	procPath string
+
+	// seen holds the creation time of the processes already sent to the
+	// store, indexed by PID, so that only new and exited processes
+	// generate events and a PID reuse is detected.
+	seen map[int32]int64
+
+	containerIDForPID func(pid int32) string
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{
+			seen: make(map[int32]int64),
+		}
+	})
+}
+
+func (c *collector) Start(ctx context.Context, store workloadmeta.Store) error {
+	if !config.Datadog.GetBool("workloadmeta.process_collector.enabled") {
+		return errors.NewDisabled(componentName, "process collection is disabled")
+	}
+
+	c.store = store
+	c.probe = procutil.NewProcessProbe()
+	c.procPath = config.Datadog.GetString("container_proc_root")
+	c.containerIDForPID = c.readContainerID
+
+	go func() {
+		<-ctx.Done()
+		c.probe.Close()
+	}()
+
+	return nil
+}
+
+func (c *collector) Pull(ctx context.Context) error {
+	procs, err := c.probe.ProcessesByPID(time.Now(), false)
+	if err != nil {
+		return err
+	}
+
+	c.store.Notify(c.parseProcesses(procs))
+
+	return nil
+}
+
+// parseProcesses returns set events for the processes that were not sent to
+// the store yet, and unset events for the processes that have exited since
+// the last call.
+func (c *collector) parseProcesses(procs map[int32]*procutil.Process) []workloadmeta.CollectorEvent {
+	events := []workloadmeta.CollectorEvent{}
+
+	for pid, createTime := range c.seen {
+		if proc, ok := procs[pid]; ok && proc.Stats.CreateTime == createTime {
+			continue
+		}
+
+		delete(c.seen, pid)
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceProcessCollector,
+			Entity: workloadmeta.EntityID{
+				Kind: workloadmeta.KindProcess,
+				ID:   strconv.Itoa(int(pid)),
+			},
+		})
+	}
+
+	for pid, proc := range procs {
+		if _, ok := c.seen[pid]; ok {
+			continue
+		}
+
+		c.seen[pid] = proc.Stats.CreateTime
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceProcessCollector,
+			Entity: &workloadmeta.Process{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindProcess,
+					ID:   strconv.Itoa(int(pid)),
+				},
+				Pid:          int(pid),
+				Ppid:         int(proc.Ppid),
+				Name:         proc.Name,
+				Exe:          proc.Exe,
+				Cmdline:      proc.Cmdline,
+				CreationTime: time.Unix(0, proc.Stats.CreateTime*int64(time.Millisecond)),
+				ContainerID:  c.containerIDForPID(pid),
+				Language:     detectLanguage(proc.Exe, proc.Cmdline),
+			},
+		})
+	}
+
+	return events
+}
+
+// readContainerID returns the ID of the container a process is running in,
+// or an empty string if it is not running in a container.
+func (c *collector) readContainerID(pid int32) string {
+	strPid := strconv.Itoa(int(pid))
+
+	for _, controller := range []string{cgroupV1BaseController, ""} {
+		containerID, _, err := cgroups.ContainerIDFromCgroupReferences(c.procPath, strPid, controller)
+		if err != nil {
+			log.Debugf("cannot get container ID for pid %d: %s", pid, err)
+			return ""
+		}
+
+		if containerID != "" {
+			return containerID
+		}
+	}
+
+	return ""
+}
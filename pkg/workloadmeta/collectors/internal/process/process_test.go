@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func fakeProcess(pid int32, createTime int64, cmdline ...string) *procutil.Process {
+	return &procutil.Process{
+		Pid:     pid,
+		Ppid:    1,
+		Name:    cmdline[0],
+		Exe:     "/usr/bin/" + cmdline[0],
+		Cmdline: cmdline,
+		Stats: &procutil.Stats{
+			CreateTime: createTime,
+		},
+	}
+}
+
+func TestParseProcesses(t *testing.T) {
+	c := &collector{
+		seen: make(map[int32]int64),
+		containerIDForPID: func(pid int32) string {
+			if pid == 2 {
+				return "abcdef"
+			}
+			return ""
+		},
+	}
+
+	// first run: all processes are new
+	events := c.parseProcesses(map[int32]*procutil.Process{
+		1: fakeProcess(1, 1000, "init"),
+		2: fakeProcess(2, 2000, "python3", "app.py"),
+	})
+
+	assert.ElementsMatch(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceProcessCollector,
+			Entity: &workloadmeta.Process{
+				EntityID:     workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "1"},
+				Pid:          1,
+				Ppid:         1,
+				Name:         "init",
+				Exe:          "/usr/bin/init",
+				Cmdline:      []string{"init"},
+				CreationTime: time.Unix(1, 0),
+			},
+		},
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceProcessCollector,
+			Entity: &workloadmeta.Process{
+				EntityID:     workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "2"},
+				Pid:          2,
+				Ppid:         1,
+				Name:         "python3",
+				Exe:          "/usr/bin/python3",
+				Cmdline:      []string{"python3", "app.py"},
+				CreationTime: time.Unix(2, 0),
+				ContainerID:  "abcdef",
+				Language:     workloadmeta.ProcessLanguagePython,
+			},
+		},
+	}, events)
+
+	// second run: nothing changed
+	events = c.parseProcesses(map[int32]*procutil.Process{
+		1: fakeProcess(1, 1000, "init"),
+		2: fakeProcess(2, 2000, "python3", "app.py"),
+	})
+	assert.Empty(t, events)
+
+	// third run: pid 2 exited and was reused
+	events = c.parseProcesses(map[int32]*procutil.Process{
+		1: fakeProcess(1, 1000, "init"),
+		2: fakeProcess(2, 3000, "java", "-jar", "app.jar"),
+	})
+
+	assert.ElementsMatch(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceProcessCollector,
+			Entity: workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "2"},
+		},
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceProcessCollector,
+			Entity: &workloadmeta.Process{
+				EntityID:     workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "2"},
+				Pid:          2,
+				Ppid:         1,
+				Name:         "java",
+				Exe:          "/usr/bin/java",
+				Cmdline:      []string{"java", "-jar", "app.jar"},
+				CreationTime: time.Unix(3, 0),
+				ContainerID:  "abcdef",
+				Language:     workloadmeta.ProcessLanguageJava,
+			},
+		},
+	}, events)
+
+	// fourth run: pid 2 exited
+	events = c.parseProcesses(map[int32]*procutil.Process{
+		1: fakeProcess(1, 1000, "init"),
+	})
+
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceProcessCollector,
+			Entity: workloadmeta.EntityID{Kind: workloadmeta.KindProcess, ID: "2"},
+		},
+	}, events)
+}
@@ -7,6 +7,7 @@ package workloadmeta
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -169,3 +170,66 @@ PID: 1
 
 	assert.EqualValues(t, expectedVerbose, verboseDump)
 }
+
+func TestDumpProcess(t *testing.T) {
+	s := newTestStore()
+
+	s.handleEvents([]CollectorEvent{
+		{
+			Type:   EventTypeSet,
+			Source: SourceProcessCollector,
+			Entity: &Process{
+				EntityID: EntityID{
+					Kind: KindProcess,
+					ID:   "42",
+				},
+				Pid:          42,
+				Ppid:         1,
+				Name:         "java",
+				Exe:          "/usr/bin/java",
+				Cmdline:      []string{"java", "-jar", "app.jar"},
+				CreationTime: time.Date(2021, time.November, 2, 10, 0, 0, 0, time.UTC),
+				ContainerID:  "ctr-id",
+				Language:     ProcessLanguageJava,
+			},
+		},
+	})
+
+	assert.EqualValues(t, WorkloadDumpResponse{
+		Entities: map[string]WorkloadEntity{
+			"process": {
+				Infos: map[string]string{
+					"sources(merged):[process_collector] id: 42": `----------- Entity ID -----------
+Kind: process ID: 42
+----------- Process Info -----------
+PID: 42
+Name: java
+Container ID: ctr-id
+Language: java
+`,
+				},
+			},
+		},
+	}, s.Dump(false))
+
+	assert.EqualValues(t, WorkloadDumpResponse{
+		Entities: map[string]WorkloadEntity{
+			"process": {
+				Infos: map[string]string{
+					"sources(merged):[process_collector] id: 42": `----------- Entity ID -----------
+Kind: process ID: 42
+----------- Process Info -----------
+PID: 42
+Name: java
+Container ID: ctr-id
+Language: java
+PPID: 1
+Exe: /usr/bin/java
+Cmdline: java -jar app.jar
+Creation Time: 2021-11-02 10:00:00 +0000 UTC
+`,
+				},
+			},
+		},
+	}, s.Dump(true))
+}
@@ -7,6 +7,7 @@ package testing
 
 import (
 	"context"
+	"strconv"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/errors"
@@ -94,6 +95,31 @@ func (s *Store) GetECSTask(id string) (*workloadmeta.ECSTask, error) {
 	return entity.(*workloadmeta.ECSTask), nil
 }
 
+// GetProcess returns metadata about a process.
+func (s *Store) GetProcess(pid int) (*workloadmeta.Process, error) {
+	entity, err := s.getEntityByKind(workloadmeta.KindProcess, strconv.Itoa(pid))
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*workloadmeta.Process), nil
+}
+
+// ListProcesses returns metadata about all known processes.
+func (s *Store) ListProcesses() ([]*workloadmeta.Process, error) {
+	entities, err := s.listEntitiesByKind(workloadmeta.KindProcess)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]*workloadmeta.Process, 0, len(entities))
+	for _, entity := range entities {
+		processes = append(processes, entity.(*workloadmeta.Process))
+	}
+
+	return processes, nil
+}
+
 // Set sets an entity in the store.
 func (s *Store) Set(entity workloadmeta.Entity) {
 	s.mu.Lock()
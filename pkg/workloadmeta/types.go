@@ -70,6 +70,14 @@ type Store interface {
 	// kind KindECSTask and the given ID.
 	GetECSTask(id string) (*ECSTask, error)
 
+	// GetProcess returns metadata about a process.  It fetches the entity
+	// with kind KindProcess and the given PID.
+	GetProcess(pid int) (*Process, error)
+
+	// ListProcesses returns metadata about all known processes, equivalent
+	// to all entities with kind KindProcess.
+	ListProcesses() ([]*Process, error)
+
 	// Notify notifies the store with a slice of events.  It should only be
 	// used by workloadmeta collectors.
 	Notify(events []CollectorEvent)
@@ -86,6 +94,7 @@ const (
 	KindContainer     Kind = "container"
 	KindKubernetesPod Kind = "kubernetes_pod"
 	KindECSTask       Kind = "ecs_task"
+	KindProcess       Kind = "process"
 )
 
 // Source is the source name of an entity.
@@ -111,6 +120,10 @@ const (
 	// the central component of an orchestrator, or the Datadog Cluster
 	// Agent.  `kube_metadata` and `cloudfoundry` use this.
 	SourceClusterOrchestrator Source = "cluster_orchestrator"
+
+	// SourceProcessCollector represents processes detected by walking
+	// procfs on the host. The `process` collector uses this.
+	SourceProcessCollector Source = "process_collector"
 )
 
 // ContainerRuntime is the container runtime used by a container.
@@ -604,6 +617,79 @@ func (t ECSTask) String(verbose bool) string {
 
 var _ Entity = &ECSTask{}
 
+// Process is an Entity representing a process running on the host.
+type Process struct {
+	EntityID // ID is the PID, as a string
+	Pid          int
+	Ppid         int
+	Name         string
+	Exe          string
+	Cmdline      []string
+	CreationTime time.Time
+	ContainerID  string
+	Language     ProcessLanguage
+}
+
+// ProcessLanguage is the programming language a process is detected to be
+// running.
+type ProcessLanguage string
+
+// Defined ProcessLanguages
+const (
+	ProcessLanguageUnknown ProcessLanguage = ""
+	ProcessLanguageJava    ProcessLanguage = "java"
+	ProcessLanguagePython  ProcessLanguage = "python"
+	ProcessLanguageNode    ProcessLanguage = "node"
+	ProcessLanguageRuby    ProcessLanguage = "ruby"
+	ProcessLanguageDotnet  ProcessLanguage = "dotnet"
+	ProcessLanguagePHP     ProcessLanguage = "php"
+)
+
+// GetID implements Entity#GetID.
+func (p Process) GetID() EntityID {
+	return p.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (p *Process) Merge(e Entity) error {
+	pp, ok := e.(*Process)
+	if !ok {
+		return fmt.Errorf("cannot merge Process with different kind %T", e)
+	}
+
+	return merge(p, pp)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (p Process) DeepCopy() Entity {
+	cp := deepcopy.Copy(p).(Process)
+	return &cp
+}
+
+// String implements Entity#String.
+func (p Process) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Process Info -----------")
+	_, _ = fmt.Fprintln(&sb, "PID:", p.Pid)
+	_, _ = fmt.Fprintln(&sb, "Name:", p.Name)
+	_, _ = fmt.Fprintln(&sb, "Container ID:", p.ContainerID)
+	_, _ = fmt.Fprintln(&sb, "Language:", p.Language)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "PPID:", p.Ppid)
+		_, _ = fmt.Fprintln(&sb, "Exe:", p.Exe)
+		_, _ = fmt.Fprintln(&sb, "Cmdline:", strings.Join(p.Cmdline, " "))
+		_, _ = fmt.Fprintln(&sb, "Creation Time:", p.CreationTime)
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &Process{}
+
 // CollectorEvent is an event generated by a metadata collector, to be handled
 // by the metadata store.
 type CollectorEvent struct {